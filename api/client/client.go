@@ -4,16 +4,20 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"time"
 
 	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/pb"
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
 const (
@@ -29,6 +33,13 @@ type Client interface {
 	// Close the grpc connection.
 	Close() error
 
+	// Query retrieves the rows of 'table' that match all of 'filters'.  If
+	// 'fields' is non-empty, only those fields of each row are retrieved, and
+	// the rest are left zero.  The rows are returned as the slice returned by the
+	// corresponding typed Query method, e.g. a []db.Machine for the MachineTable.
+	Query(table db.TableType, filters []api.Filter, fields []string) (
+		interface{}, error)
+
 	// QueryMachines retrieves the machines tracked by the Quilt daemon.
 	QueryMachines() ([]db.Machine, error)
 
@@ -48,32 +59,97 @@ type Client interface {
 	// QueryClusters retrieves cluster information tracked by the Quilt daemon.
 	QueryClusters() ([]db.Cluster, error)
 
-	// Deploy makes a request to the Quilt daemon to deploy the given deployment.
-	Deploy(deployment string) error
+	// QueryImages retrieves the Docker images built by the cluster and their status.
+	QueryImages() ([]db.Image, error)
+
+	// QueryPlacements retrieves the placement constraints tracked by the cluster.
+	QueryPlacements() ([]db.Placement, error)
+
+	// QueryHostnames retrieves the DNS records tracked by the cluster.
+	QueryHostnames() ([]db.Hostname, error)
+
+	// QueryACLs retrieves the ACLs applied to the cluster's machines.
+	QueryACLs() ([]db.ACL, error)
+
+	// QueryMinions retrieves the minions tracked by the cluster.
+	QueryMinions() ([]db.Minion, error)
+
+	// QueryHistory retrieves the recorded revisions of the given table, or of
+	// all tables if 'table' is empty.  If 'id' is non-zero, only the revisions
+	// of that row are retrieved.
+	QueryHistory(table db.TableType, id int) ([]db.Revision, error)
+
+	// Watch calls 'update' with the contents of the given table each time they
+	// change, until 'stop' is closed or the stream fails.  The contents are the
+	// slice returned by the corresponding Query method, e.g. a []db.Machine for
+	// the MachineTable.
+	Watch(table db.TableType, stop <-chan struct{}, update func(interface{})) error
+
+	// Logs writes the logs of the container with the given StitchID to the
+	// writers in 'opts'.  If 'opts.Follow' is set, it blocks until the container
+	// stops, or 'stop' is closed.
+	Logs(stitchID string, opts api.LogsOptions, stop <-chan struct{}) error
+
+	// Exec runs a command in the container with the given StitchID, and returns
+	// its exit code.  Exec stops waiting for the command if 'stop' is closed.
+	Exec(stitchID string, opts api.ExecOptions, stop <-chan struct{}) (int, error)
+
+	// Deploy makes a request to the Quilt daemon to deploy the given deployment,
+	// and returns the ID with which its progress can be tracked.
+	Deploy(deployment string) (string, error)
+
+	// Stop deploys the given deployment, which must have no machines, and marks
+	// its namespace as stopped so that the daemon forgets it once its machines
+	// are terminated.
+	Stop(deployment string) error
+
+	// DeploymentStatus reports how far the cluster has converged towards the
+	// deployment with the given ID, or if it's empty, towards the current
+	// deployment.  If the deployment was replaced, ErrDeploymentReplaced is
+	// returned.
+	DeploymentStatus(id string) (api.DeploymentStatus, error)
+
+	// SetSecret sets the value of the secret with the given name in the cluster.
+	// The value must already be sealed with the minions' credentials.
+	SetSecret(name, value string) error
 
 	// Version retrieves the Quilt version of the remote daemon.
 	Version() (string, error)
 }
 
-// Getter obtains a client connected to the given address.
-type Getter func(string) (Client, error)
+// ErrDeploymentReplaced is returned by DeploymentStatus for deployments that were
+// replaced by another, and so will never converge.
+var ErrDeploymentReplaced = errors.New("deployment was replaced")
+
+// Getter obtains a client connected to the given address, and authenticated with the
+// given credentials.
+type Getter func(string, certs.Credentials) (Client, error)
 
 type clientImpl struct {
 	pbClient pb.APIClient
 	cc       *grpc.ClientConn
+
+	// The namespace targeted by the client's requests, or "" if none is.
+	namespace string
 }
 
-// New creates a new Quilt client connected to `lAddr`.
-func New(lAddr string) (Client, error) {
+// New creates a new Quilt client connected to `lAddr`.  The connection is mutually
+// authenticated with TLS using `creds`.
+func New(lAddr string, creds certs.Credentials) (Client, error) {
 	proto, addr, err := api.ParseListenAddress(lAddr)
 	if err != nil {
 		return nil, err
 	}
 
+	tlsOpt, err := creds.DialOption()
+	if err != nil {
+		return nil, err
+	}
+
 	dialer := func(dialAddr string, t time.Duration) (net.Conn, error) {
 		return net.DialTimeout(proto, dialAddr, t)
 	}
-	cc, err := grpc.Dial(addr, grpc.WithDialer(dialer), grpc.WithInsecure(),
+	cc, err := grpc.Dial(addr, grpc.WithDialer(dialer), tlsOpt,
 		grpc.WithBlock(), grpc.WithTimeout(connectTimeout))
 	if err != nil {
 		if err == context.DeadlineExceeded {
@@ -92,14 +168,60 @@ func New(lAddr string) (Client, error) {
 	}, nil
 }
 
-func query(pbClient pb.APIClient, table db.TableType) (interface{}, error) {
-	ctx, _ := context.WithTimeout(context.Background(), requestTimeout)
-	reply, err := pbClient.Query(ctx, &pb.DBQuery{Table: string(table)})
+// WithNamespace returns a Getter whose clients target 'namespace' with their
+// requests.  Daemons that manage several namespaces require requests about the
+// contents of a cluster to choose one.
+func WithNamespace(namespace string) Getter {
+	return func(lAddr string, creds certs.Credentials) (Client, error) {
+		c, err := New(lAddr, creds)
+		if err != nil {
+			return nil, err
+		}
+
+		impl := c.(clientImpl)
+		impl.namespace = namespace
+		return impl, nil
+	}
+}
+
+// Local creates a new Quilt client connected to the daemon on this host, and
+// authenticated with the daemon's credentials in certs.DefaultDir.
+func Local() (Client, error) {
+	creds, err := certs.Load(certs.DefaultDir, certs.Daemon)
 	if err != nil {
 		return nil, err
 	}
+	return New(api.DefaultSocket, creds)
+}
 
-	replyBytes := []byte(reply.TableContents)
+// requestContext returns the context in which the client makes requests, which names
+// the namespace they target, if any.
+func (c clientImpl) requestContext() context.Context {
+	ctx := context.Background()
+	if c.namespace != "" {
+		ctx = metadata.NewContext(ctx,
+			metadata.Pairs(api.NamespaceKey, c.namespace))
+	}
+	return ctx
+}
+
+func (c clientImpl) query(table db.TableType) (interface{}, error) {
+	return c.queryWhere(&pb.DBQuery{Table: string(table)})
+}
+
+func (c clientImpl) queryWhere(query *pb.DBQuery) (interface{}, error) {
+	ctx, _ := context.WithTimeout(c.requestContext(), requestTimeout)
+	reply, err := c.pbClient.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalTable(db.TableType(query.Table), []byte(reply.TableContents))
+}
+
+// unmarshalTable decodes the JSON encoded contents of 'table' into a slice of its
+// rows, e.g. a []db.Machine for the MachineTable.
+func unmarshalTable(table db.TableType, replyBytes []byte) (interface{}, error) {
 	switch table {
 	case db.MachineTable:
 		var machines []db.Machine
@@ -137,6 +259,42 @@ func query(pbClient pb.APIClient, table db.TableType) (interface{}, error) {
 			return nil, err
 		}
 		return clusters, nil
+	case db.ImageTable:
+		var images []db.Image
+		if err := json.Unmarshal(replyBytes, &images); err != nil {
+			return nil, err
+		}
+		return images, nil
+	case db.PlacementTable:
+		var placements []db.Placement
+		if err := json.Unmarshal(replyBytes, &placements); err != nil {
+			return nil, err
+		}
+		return placements, nil
+	case db.HostnameTable:
+		var hostnames []db.Hostname
+		if err := json.Unmarshal(replyBytes, &hostnames); err != nil {
+			return nil, err
+		}
+		return hostnames, nil
+	case db.ACLTable:
+		var acls []db.ACL
+		if err := json.Unmarshal(replyBytes, &acls); err != nil {
+			return nil, err
+		}
+		return acls, nil
+	case db.MinionTable:
+		var minions []db.Minion
+		if err := json.Unmarshal(replyBytes, &minions); err != nil {
+			return nil, err
+		}
+		return minions, nil
+	case db.SecretTable:
+		var secrets []db.Secret
+		if err := json.Unmarshal(replyBytes, &secrets); err != nil {
+			return nil, err
+		}
+		return secrets, nil
 	default:
 		panic(fmt.Sprintf("unsupported table type: %s", table))
 	}
@@ -147,9 +305,31 @@ func (c clientImpl) Close() error {
 	return c.cc.Close()
 }
 
+// Query retrieves the rows of 'table' that match all of 'filters', containing just
+// 'fields', or all fields if none are given.
+func (c clientImpl) Query(table db.TableType, filters []api.Filter,
+	fields []string) (interface{}, error) {
+
+	query := &pb.DBQuery{Table: string(table), Fields: fields}
+	for _, filter := range filters {
+		query.Filters = append(query.Filters, &pb.Filter{
+			Field: filter.Field,
+			Op:    filterOps[filter.Op],
+			Value: filter.Value,
+		})
+	}
+	return c.queryWhere(query)
+}
+
+var filterOps = map[api.FilterOp]pb.Filter_Op{
+	api.EqualOp:    pb.Filter_EQUAL,
+	api.PrefixOp:   pb.Filter_PREFIX,
+	api.ContainsOp: pb.Filter_CONTAINS,
+}
+
 // QueryMachines retrieves the machines tracked by the Quilt daemon.
 func (c clientImpl) QueryMachines() ([]db.Machine, error) {
-	rows, err := query(c.pbClient, db.MachineTable)
+	rows, err := c.query(db.MachineTable)
 	if err != nil {
 		return nil, err
 	}
@@ -159,7 +339,7 @@ func (c clientImpl) QueryMachines() ([]db.Machine, error) {
 
 // QueryContainers retrieves the containers tracked by the Quilt daemon.
 func (c clientImpl) QueryContainers() ([]db.Container, error) {
-	rows, err := query(c.pbClient, db.ContainerTable)
+	rows, err := c.query(db.ContainerTable)
 	if err != nil {
 		return nil, err
 	}
@@ -169,7 +349,7 @@ func (c clientImpl) QueryContainers() ([]db.Container, error) {
 
 // QueryEtcd retrieves the etcd information tracked by the Quilt daemon.
 func (c clientImpl) QueryEtcd() ([]db.Etcd, error) {
-	rows, err := query(c.pbClient, db.EtcdTable)
+	rows, err := c.query(db.EtcdTable)
 	if err != nil {
 		return nil, err
 	}
@@ -179,7 +359,7 @@ func (c clientImpl) QueryEtcd() ([]db.Etcd, error) {
 
 // QueryConnections retrieves the connection information tracked by the Quilt daemon.
 func (c clientImpl) QueryConnections() ([]db.Connection, error) {
-	rows, err := query(c.pbClient, db.ConnectionTable)
+	rows, err := c.query(db.ConnectionTable)
 	if err != nil {
 		return nil, err
 	}
@@ -189,7 +369,7 @@ func (c clientImpl) QueryConnections() ([]db.Connection, error) {
 
 // QueryLabels retrieves the label information tracked by the Quilt daemon.
 func (c clientImpl) QueryLabels() ([]db.Label, error) {
-	rows, err := query(c.pbClient, db.LabelTable)
+	rows, err := c.query(db.LabelTable)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +379,7 @@ func (c clientImpl) QueryLabels() ([]db.Label, error) {
 
 // QueryClusters retrieves the cluster information tracked by the Quilt daemon.
 func (c clientImpl) QueryClusters() ([]db.Cluster, error) {
-	rows, err := query(c.pbClient, db.ClusterTable)
+	rows, err := c.query(db.ClusterTable)
 	if err != nil {
 		return nil, err
 	}
@@ -207,16 +387,164 @@ func (c clientImpl) QueryClusters() ([]db.Cluster, error) {
 	return rows.([]db.Cluster), nil
 }
 
-// Deploy makes a request to the Quilt daemon to deploy the given deployment.
-func (c clientImpl) Deploy(deployment string) error {
-	ctx, _ := context.WithTimeout(context.Background(), requestTimeout)
-	_, err := c.pbClient.Deploy(ctx, &pb.DeployRequest{Deployment: deployment})
+// QueryImages retrieves the Docker images built by the cluster and their status.
+func (c clientImpl) QueryImages() ([]db.Image, error) {
+	rows, err := c.query(db.ImageTable)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows.([]db.Image), nil
+}
+
+// QueryPlacements retrieves the placement constraints tracked by the cluster.
+func (c clientImpl) QueryPlacements() ([]db.Placement, error) {
+	rows, err := c.query(db.PlacementTable)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows.([]db.Placement), nil
+}
+
+// QueryHostnames retrieves the DNS records tracked by the cluster.
+func (c clientImpl) QueryHostnames() ([]db.Hostname, error) {
+	rows, err := c.query(db.HostnameTable)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows.([]db.Hostname), nil
+}
+
+// QueryACLs retrieves the ACLs applied to the cluster's machines.
+func (c clientImpl) QueryACLs() ([]db.ACL, error) {
+	rows, err := c.query(db.ACLTable)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows.([]db.ACL), nil
+}
+
+// QueryMinions retrieves the minions tracked by the cluster.
+func (c clientImpl) QueryMinions() ([]db.Minion, error) {
+	rows, err := c.query(db.MinionTable)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows.([]db.Minion), nil
+}
+
+// QueryHistory retrieves the recorded revisions of the given table, or of all tables
+// if 'table' is empty.  If 'id' is non-zero, only the revisions of that row are
+// retrieved.
+func (c clientImpl) QueryHistory(table db.TableType, id int) ([]db.Revision, error) {
+	ctx, _ := context.WithTimeout(c.requestContext(), requestTimeout)
+	reply, err := c.pbClient.QueryHistory(ctx,
+		&pb.HistoryQuery{Table: string(table), ID: int32(id)})
+	if err != nil {
+		return nil, err
+	}
+
+	var revs []db.Revision
+	if err := json.Unmarshal([]byte(reply.TableContents), &revs); err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// Watch calls 'update' with the contents of the given table each time they change,
+// until 'stop' is closed or the stream fails.
+func (c clientImpl) Watch(table db.TableType, stop <-chan struct{},
+	update func(interface{})) error {
+
+	ctx, cancel := stopContext(c.requestContext(), stop)
+	defer cancel()
+
+	stream, err := c.pbClient.Watch(ctx, &pb.DBQuery{Table: string(table)})
+	if err != nil {
+		return err
+	}
+
+	for {
+		reply, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				// The Watch was stopped.
+				return nil
+			}
+			return err
+		}
+
+		rows, err := unmarshalTable(table, []byte(reply.TableContents))
+		if err != nil {
+			return err
+		}
+		update(rows)
+	}
+}
+
+// Deploy makes a request to the Quilt daemon to deploy the given deployment, and
+// returns the deployment's ID.
+func (c clientImpl) Deploy(deployment string) (string, error) {
+	ctx, _ := context.WithTimeout(c.requestContext(), requestTimeout)
+	reply, err := c.pbClient.Deploy(ctx, &pb.DeployRequest{Deployment: deployment})
+	if err != nil {
+		return "", err
+	}
+	return reply.ID, nil
+}
+
+// Stop deploys the given machine-less deployment, and marks its namespace as
+// stopped.
+func (c clientImpl) Stop(deployment string) error {
+	ctx, _ := context.WithTimeout(c.requestContext(), requestTimeout)
+	_, err := c.pbClient.Deploy(ctx,
+		&pb.DeployRequest{Deployment: deployment, Stop: true})
+	return err
+}
+
+// DeploymentStatus reports how far the cluster has converged towards the
+// deployment with the given ID.  If the deployment was replaced,
+// ErrDeploymentReplaced is returned.
+func (c clientImpl) DeploymentStatus(id string) (api.DeploymentStatus, error) {
+	ctx, _ := context.WithTimeout(c.requestContext(), requestTimeout)
+	reply, err := c.pbClient.DeploymentStatus(ctx,
+		&pb.DeploymentStatusRequest{ID: id})
+	if grpc.Code(err) == codes.NotFound {
+		return api.DeploymentStatus{}, ErrDeploymentReplaced
+	} else if err != nil {
+		return api.DeploymentStatus{}, err
+	}
+
+	return api.DeploymentStatus{
+		ID:                reply.ID,
+		Converged:         reply.Converged,
+		Machines:          int(reply.Machines),
+		MachinesBooted:    int(reply.MachinesBooted),
+		MachinesConnected: int(reply.MachinesConnected),
+		Containers:        int(reply.Containers),
+		ContainersPlaced:  int(reply.ContainersPlaced),
+		ContainersRunning: int(reply.ContainersRunning),
+		Images:            int(reply.Images),
+		ImagesBuilt:       int(reply.ImagesBuilt),
+		Stuck:             reply.Stuck,
+	}, nil
+}
+
+// SetSecret sets the value of the secret with the given name in the cluster.  The
+// value must already be sealed with the minions' credentials.
+func (c clientImpl) SetSecret(name, value string) error {
+	ctx, _ := context.WithTimeout(c.requestContext(), requestTimeout)
+	_, err := c.pbClient.SetSecret(ctx, &pb.SecretRequest{Name: name, Value: value})
 	return err
 }
 
 // Version retrieves the Quilt version of the remote daemon.
 func (c clientImpl) Version() (string, error) {
-	ctx, _ := context.WithTimeout(context.Background(), requestTimeout)
+	ctx, _ := context.WithTimeout(c.requestContext(), requestTimeout)
 	version, err := c.pbClient.Version(ctx, &pb.VersionRequest{})
 	if err != nil {
 		return "", err
@@ -1,13 +1,20 @@
 package client
 
 import (
+	"bytes"
 	"errors"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 
+	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/pb"
 	"github.com/quilt/quilt/db"
 )
@@ -23,16 +30,137 @@ func (c mockAPIClient) Query(ctx context.Context, in *pb.DBQuery,
 	return &pb.QueryReply{TableContents: c.mockResponse}, c.mockError
 }
 
+func (c mockAPIClient) QueryHistory(ctx context.Context, in *pb.HistoryQuery,
+	opts ...grpc.CallOption) (*pb.QueryReply, error) {
+
+	return &pb.QueryReply{TableContents: c.mockResponse}, c.mockError
+}
+
+func (c mockAPIClient) Watch(ctx context.Context, in *pb.DBQuery,
+	opts ...grpc.CallOption) (pb.API_WatchClient, error) {
+
+	return &mockWatchClient{ctx: ctx, replies: []string{c.mockResponse}}, c.mockError
+}
+
+// mockWatchClient streams 'replies', and then blocks until its context is cancelled,
+// or fails if 'block' is false.
+type mockWatchClient struct {
+	ctx     context.Context
+	replies []string
+	block   bool
+
+	grpc.ClientStream
+}
+
+func (c *mockWatchClient) Recv() (*pb.QueryReply, error) {
+	if len(c.replies) > 0 {
+		reply := c.replies[0]
+		c.replies = c.replies[1:]
+		return &pb.QueryReply{TableContents: reply}, nil
+	}
+
+	if !c.block {
+		return nil, io.EOF
+	}
+	<-c.ctx.Done()
+	return nil, c.ctx.Err()
+}
+
+func (c mockAPIClient) Logs(ctx context.Context, in *pb.LogsRequest,
+	opts ...grpc.CallOption) (pb.API_LogsClient, error) {
+
+	return &mockLogsClient{outputs: []*pb.Output{
+		{Stdout: []byte(c.mockResponse)},
+		{Stderr: []byte(in.StitchID)},
+	}}, c.mockError
+}
+
+// mockLogsClient streams 'outputs', and then ends.
+type mockLogsClient struct {
+	outputs []*pb.Output
+
+	grpc.ClientStream
+}
+
+func (c *mockLogsClient) Recv() (*pb.Output, error) {
+	if len(c.outputs) == 0 {
+		return nil, io.EOF
+	}
+
+	output := c.outputs[0]
+	c.outputs = c.outputs[1:]
+	return output, nil
+}
+
+func (c mockAPIClient) Exec(ctx context.Context, opts ...grpc.CallOption) (
+	pb.API_ExecClient, error) {
+
+	return &mockExecClient{outputs: make(chan *pb.Output, 8)}, c.mockError
+}
+
+// mockExecClient echoes the standard input it's sent as standard output, and exits
+// with code 3 once the input is closed.
+type mockExecClient struct {
+	outputs chan *pb.Output
+
+	grpc.ClientStream
+}
+
+func (c *mockExecClient) Send(req *pb.ExecRequest) error {
+	if len(req.Stdin) > 0 {
+		c.outputs <- &pb.Output{Stdout: req.Stdin}
+	}
+
+	if req.CloseStdin {
+		c.outputs <- &pb.Output{Exited: true, ExitCode: 3}
+	}
+	return nil
+}
+
+func (c *mockExecClient) Recv() (*pb.Output, error) {
+	return <-c.outputs, nil
+}
+
+func (c *mockExecClient) CloseSend() error {
+	return nil
+}
+
 func (c mockAPIClient) Deploy(ctx context.Context, in *pb.DeployRequest,
 	opts ...grpc.CallOption) (*pb.DeployReply, error) {
 
-	return &pb.DeployReply{}, nil
+	return &pb.DeployReply{ID: "id"}, nil
+}
+
+func (c mockAPIClient) DeploymentStatus(ctx context.Context,
+	in *pb.DeploymentStatusRequest, opts ...grpc.CallOption) (
+	*pb.DeploymentStatusReply, error) {
+
+	if c.mockError != nil {
+		return nil, c.mockError
+	}
+
+	return &pb.DeploymentStatusReply{
+		ID:                in.ID,
+		Machines:          2,
+		MachinesBooted:    2,
+		MachinesConnected: 1,
+		Stuck:             []string{"machine 2: waiting to connect"},
+	}, nil
 }
 
+func (c mockAPIClient) SetSecret(ctx context.Context, in *pb.SecretRequest,
+	opts ...grpc.CallOption) (*pb.SecretReply, error) {
+
+	return &pb.SecretReply{}, c.mockError
+}
+
+// Version replies with the namespace targeted by the request, so that tests can check
+// that it was sent.
 func (c mockAPIClient) Version(ctx context.Context, in *pb.VersionRequest,
 	opts ...grpc.CallOption) (*pb.VersionReply, error) {
 
-	return &pb.VersionReply{}, nil
+	md, _ := metadata.FromContext(ctx)
+	return &pb.VersionReply{Version: strings.Join(md[api.NamespaceKey], ",")}, nil
 }
 
 func TestUnmarshalMachine(t *testing.T) {
@@ -98,6 +226,247 @@ func TestUnmarshalContainer(t *testing.T) {
 	}
 }
 
+func TestUnmarshalImage(t *testing.T) {
+	t.Parallel()
+
+	apiClient := mockAPIClient{
+		mockResponse: `[{"ID":1,"Name":"image","Dockerfile":"FROM foo",` +
+			`"DockerID":"docker-id"}]`,
+	}
+	c := clientImpl{pbClient: apiClient}
+	res, err := c.QueryImages()
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+		return
+	}
+
+	exp := []db.Image{
+		{
+			ID:         1,
+			Name:       "image",
+			Dockerfile: "FROM foo",
+			DockerID:   "docker-id",
+		},
+	}
+
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Bad unmarshalling of images: expected %v, got %v.",
+			exp, res)
+	}
+}
+
+// recordingAPIClient records the last DBQuery it received.
+type recordingAPIClient struct {
+	mockAPIClient
+	query *pb.DBQuery
+}
+
+func (c *recordingAPIClient) Query(ctx context.Context, in *pb.DBQuery,
+	opts ...grpc.CallOption) (*pb.QueryReply, error) {
+
+	c.query = in
+	return c.mockAPIClient.Query(ctx, in, opts...)
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	apiClient := &recordingAPIClient{mockAPIClient: mockAPIClient{
+		mockResponse: `[{"StitchID":"abc"}]`,
+	}}
+	c := clientImpl{pbClient: apiClient}
+	res, err := c.Query(db.ContainerTable, []api.Filter{
+		api.Prefix("StitchID", "ab"),
+		api.Contains("Labels", "web"),
+	}, []string{"StitchID"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+		return
+	}
+
+	expQuery := &pb.DBQuery{
+		Table: string(db.ContainerTable),
+		Filters: []*pb.Filter{
+			{Field: "StitchID", Op: pb.Filter_PREFIX, Value: "ab"},
+			{Field: "Labels", Op: pb.Filter_CONTAINS, Value: "web"},
+		},
+		Fields: []string{"StitchID"},
+	}
+	if !reflect.DeepEqual(expQuery, apiClient.query) {
+		t.Errorf("Bad query: expected %v, got %v.", expQuery, apiClient.query)
+	}
+
+	exp := []db.Container{{StitchID: "abc"}}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Bad unmarshalling of containers: expected %v, got %v.",
+			exp, res)
+	}
+}
+
+func TestUnmarshalHistory(t *testing.T) {
+	t.Parallel()
+
+	apiClient := mockAPIClient{
+		mockResponse: `[{"Time":"0001-01-01T00:00:00Z","Tag":"engine",` +
+			`"Table":"db.Machine","ID":1,"Old":null,"New":{"Size":"size"}}]`,
+	}
+	c := clientImpl{pbClient: apiClient}
+	res, err := c.QueryHistory(db.MachineTable, 1)
+	if err != nil {
+		t.Errorf("Unexpected error when querying history: %s", err)
+	}
+
+	exp := []db.Revision{{
+		Tag:   "engine",
+		Table: db.MachineTable,
+		ID:    1,
+		New:   map[string]interface{}{"Size": "size"},
+	}}
+	if !reflect.DeepEqual(exp, res) {
+		t.Errorf("Bad unmarshalling of history: expected %v, got %v.",
+			exp, res)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	apiClient := mockAPIClient{
+		mockResponse: `[{"ID":1,"PublicIP":"8.8.8.8"}]`,
+	}
+	c := clientImpl{pbClient: apiClient}
+
+	var updates []interface{}
+	update := func(rows interface{}) {
+		updates = append(updates, rows)
+	}
+
+	err := c.Watch(db.MachineTable, nil, update)
+	if err != io.EOF {
+		t.Errorf("Expected the Watch to end with the stream: got %v", err)
+	}
+
+	exp := []interface{}{[]db.Machine{{ID: 1, PublicIP: "8.8.8.8"}}}
+	if !reflect.DeepEqual(exp, updates) {
+		t.Errorf("Bad Watch updates: expected %v, got %v.", exp, updates)
+	}
+
+	apiClient.mockError = errors.New("timeout")
+	c = clientImpl{pbClient: apiClient}
+	if err := c.Watch(db.MachineTable, nil, update); err != apiClient.mockError {
+		t.Errorf("Expected the Watch to fail: got %v", err)
+	}
+}
+
+func TestWatchStop(t *testing.T) {
+	t.Parallel()
+
+	stop := make(chan struct{})
+	c := clientImpl{pbClient: blockingWatchAPIClient{}}
+	err := c.Watch(db.MachineTable, stop, func(rows interface{}) {
+		close(stop)
+	})
+	if err != nil {
+		t.Errorf("Stopped Watches shouldn't fail: got %v", err)
+	}
+}
+
+type blockingWatchAPIClient struct {
+	mockAPIClient
+}
+
+func (c blockingWatchAPIClient) Watch(ctx context.Context, in *pb.DBQuery,
+	opts ...grpc.CallOption) (pb.API_WatchClient, error) {
+
+	return &mockWatchClient{ctx: ctx, replies: []string{"[]"}, block: true}, nil
+}
+
+func TestLogs(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	c := clientImpl{pbClient: mockAPIClient{mockResponse: "logs"}}
+	err := c.Logs("stitchID", api.LogsOptions{Stdout: &stdout, Stderr: &stderr},
+		nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "logs", stdout.String())
+	assert.Equal(t, "stitchID", stderr.String())
+
+	c = clientImpl{pbClient: mockAPIClient{mockError: errors.New("err")}}
+	err = c.Logs("stitchID", api.LogsOptions{}, nil)
+	assert.EqualError(t, err, "err")
+}
+
+func TestExec(t *testing.T) {
+	t.Parallel()
+
+	var stdout bytes.Buffer
+	c := clientImpl{pbClient: mockAPIClient{}}
+	exitCode, err := c.Exec("stitchID", api.ExecOptions{
+		Command: []string{"cat"},
+		Stdin:   strings.NewReader("input"),
+		Stdout:  &stdout,
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, exitCode)
+	assert.Equal(t, "input", stdout.String())
+}
+
+func TestDeployment(t *testing.T) {
+	t.Parallel()
+
+	c := clientImpl{pbClient: mockAPIClient{}}
+	id, err := c.Deploy("{}")
+	assert.NoError(t, err)
+	assert.Equal(t, "id", id)
+
+	status, err := c.DeploymentStatus(id)
+	assert.NoError(t, err)
+	assert.Equal(t, api.DeploymentStatus{
+		ID:                "id",
+		Machines:          2,
+		MachinesBooted:    2,
+		MachinesConnected: 1,
+		Stuck:             []string{"machine 2: waiting to connect"},
+	}, status)
+	assert.Equal(t, "Machines: 2/2 booted, 1/2 connected. "+
+		"Containers: 0/0 placed, 0/0 running. Images: 0/0 built.",
+		status.String())
+
+	c = clientImpl{pbClient: mockAPIClient{mockError: errors.New("err")}}
+	_, err = c.DeploymentStatus(id)
+	assert.EqualError(t, err, "err")
+
+	c = clientImpl{pbClient: mockAPIClient{
+		mockError: grpc.Errorf(codes.NotFound, "deployment id was replaced")}}
+	_, err = c.DeploymentStatus(id)
+	assert.Equal(t, ErrDeploymentReplaced, err)
+}
+
+func TestSetSecret(t *testing.T) {
+	t.Parallel()
+
+	c := clientImpl{pbClient: mockAPIClient{}}
+	assert.NoError(t, c.SetSecret("name", "sealed"))
+
+	c = clientImpl{pbClient: mockAPIClient{mockError: errors.New("err")}}
+	assert.EqualError(t, c.SetSecret("name", "sealed"), "err")
+}
+
+func TestNamespace(t *testing.T) {
+	t.Parallel()
+
+	c := clientImpl{pbClient: mockAPIClient{}, namespace: "ns"}
+	version, err := c.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, "ns", version)
+
+	c.namespace = ""
+	version, err = c.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, "", version)
+}
+
 func TestUnmarshalError(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"errors"
+	"io"
+
+	"github.com/quilt/quilt/api"
+	"github.com/quilt/quilt/api/pb"
+
+	"golang.org/x/net/context"
+)
+
+// Logs writes the logs of the container with the given StitchID to the writers in
+// 'opts'.
+func (c clientImpl) Logs(stitchID string, opts api.LogsOptions,
+	stop <-chan struct{}) error {
+
+	ctx, cancel := stopContext(c.requestContext(), stop)
+	defer cancel()
+
+	stream, err := c.pbClient.Logs(ctx, &pb.LogsRequest{
+		StitchID:   stitchID,
+		Follow:     opts.Follow,
+		Timestamps: opts.Timestamps,
+		Since:      opts.Since,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		output, err := stream.Recv()
+		switch {
+		case err == io.EOF:
+			return nil
+		case err != nil && ctx.Err() != nil:
+			// The request was stopped.
+			return nil
+		case err != nil:
+			return err
+		}
+
+		if err := writeOutput(output, opts.Stdout, opts.Stderr); err != nil {
+			return err
+		}
+	}
+}
+
+// Exec runs a command in the container with the given StitchID, and returns its
+// exit code.
+func (c clientImpl) Exec(stitchID string, opts api.ExecOptions,
+	stop <-chan struct{}) (int, error) {
+
+	ctx, cancel := stopContext(c.requestContext(), stop)
+	defer cancel()
+
+	stream, err := c.pbClient.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	err = stream.Send(&pb.ExecRequest{
+		StitchID: stitchID,
+		Command:  opts.Command,
+		TTY:      opts.TTY,
+	})
+	if err != nil {
+		return 0, err
+	}
+	go sendStdin(stream, opts.Stdin)
+
+	for {
+		output, err := stream.Recv()
+		switch {
+		case err == io.EOF:
+			return 0, errors.New("command ended without an exit code")
+		case err != nil:
+			return 0, err
+		}
+
+		if err := writeOutput(output, opts.Stdout, opts.Stderr); err != nil {
+			return 0, err
+		}
+
+		if output.Exited {
+			return int(output.ExitCode), nil
+		}
+	}
+}
+
+// sendStdin forwards 'stdin' over 'stream' until it's exhausted.
+func sendStdin(stream pb.API_ExecClient, stdin io.Reader) {
+	if stdin != nil {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := stdin.Read(buf)
+			if n > 0 {
+				data := append([]byte(nil), buf[:n]...)
+				if stream.Send(&pb.ExecRequest{Stdin: data}) != nil {
+					return
+				}
+			}
+
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if stream.Send(&pb.ExecRequest{CloseStdin: true}) == nil {
+		stream.CloseSend()
+	}
+}
+
+func writeOutput(output *pb.Output, stdout, stderr io.Writer) error {
+	if len(output.Stdout) > 0 && stdout != nil {
+		if _, err := stdout.Write(output.Stdout); err != nil {
+			return err
+		}
+	}
+
+	if len(output.Stderr) > 0 && stderr != nil {
+		if _, err := stderr.Write(output.Stderr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stopContext returns a copy of 'parent' that's cancelled when 'stop' is closed.
+func stopContext(parent context.Context, stop <-chan struct{}) (context.Context,
+	context.CancelFunc) {
+
+	ctx, cancel := context.WithCancel(parent)
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
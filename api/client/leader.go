@@ -6,22 +6,24 @@ import (
 
 	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/util"
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 
 	log "github.com/Sirupsen/logrus"
 )
 
-// Leader obtains a Client connected to the Leader of the cluster.
-func Leader(machines []db.Machine) (Client, error) {
+// Leader obtains a Client connected to the Leader of the cluster, and authenticated
+// with `creds`.
+func Leader(machines []db.Machine, creds certs.Credentials) (Client, error) {
 	// Try to figure out the lead minion's IP by asking each of the machines.
 	for _, m := range machines {
 		if m.PublicIP == "" {
 			continue
 		}
 
-		ip, err := getLeaderIP(machines, m.PublicIP)
+		ip, err := getLeaderIP(machines, m.PublicIP, creds)
 		if err == nil {
-			return newClient(api.RemoteAddress(ip))
+			return newClient(api.RemoteAddress(ip), creds)
 		}
 		log.WithError(err).Debug("Unable to get leader IP")
 	}
@@ -32,8 +34,10 @@ func Leader(machines []db.Machine) (Client, error) {
 // Get the public IP of the lead minion by querying the remote machine's etcd
 // table for the private IP, and then searching for the public IP in the local
 // daemon.
-func getLeaderIP(machines []db.Machine, daemonIP string) (string, error) {
-	remoteClient, err := newClient(api.RemoteAddress(daemonIP))
+func getLeaderIP(machines []db.Machine, daemonIP string,
+	creds certs.Credentials) (string, error) {
+
+	remoteClient, err := newClient(api.RemoteAddress(daemonIP), creds)
 	if err != nil {
 		return "", err
 	}
@@ -7,12 +7,13 @@ import (
 
 	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client/mocks"
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 )
 
 func TestLeader(t *testing.T) {
 	leaderClient := new(mocks.Client)
-	newClient = func(host string) (Client, error) {
+	newClient = func(host string, _ certs.Credentials) (Client, error) {
 		mc := new(mocks.Client)
 		mc.On("Close").Return(nil)
 		on := mc.On("QueryEtcd")
@@ -44,14 +45,14 @@ func TestLeader(t *testing.T) {
 			PublicIP:  "leader",
 			PrivateIP: "leader-priv",
 		},
-	})
+	}, certs.Credentials{})
 
 	assert.Nil(t, err)
 	assert.Equal(t, leaderClient, res)
 }
 
 func TestNoLeader(t *testing.T) {
-	newClient = func(host string) (Client, error) {
+	newClient = func(host string, _ certs.Credentials) (Client, error) {
 		mc := new(mocks.Client)
 		mc.On("Close").Return(nil)
 
@@ -67,6 +68,6 @@ func TestNoLeader(t *testing.T) {
 		{
 			PublicIP: "9.9.9.9",
 		},
-	})
+	}, certs.Credentials{})
 	assert.EqualError(t, err, "no leader found")
 }
@@ -2,6 +2,7 @@
 
 package mocks
 
+import api "github.com/quilt/quilt/api"
 import db "github.com/quilt/quilt/db"
 import mock "github.com/stretchr/testify/mock"
 
@@ -25,12 +26,75 @@ func (_m *Client) Close() error {
 }
 
 // Deploy provides a mock function with given fields: deployment
-func (_m *Client) Deploy(deployment string) error {
+func (_m *Client) Deploy(deployment string) (string, error) {
 	ret := _m.Called(deployment)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
 		r0 = rf(deployment)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(deployment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeploymentStatus provides a mock function with given fields: id
+func (_m *Client) DeploymentStatus(id string) (api.DeploymentStatus, error) {
+	ret := _m.Called(id)
+
+	var r0 api.DeploymentStatus
+	if rf, ok := ret.Get(0).(func(string) api.DeploymentStatus); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(api.DeploymentStatus)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Exec provides a mock function with given fields: stitchID, opts, stop
+func (_m *Client) Exec(stitchID string, opts api.ExecOptions, stop <-chan struct{}) (int, error) {
+	ret := _m.Called(stitchID, opts, stop)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(string, api.ExecOptions, <-chan struct{}) int); ok {
+		r0 = rf(stitchID, opts, stop)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, api.ExecOptions, <-chan struct{}) error); ok {
+		r1 = rf(stitchID, opts, stop)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Logs provides a mock function with given fields: stitchID, opts, stop
+func (_m *Client) Logs(stitchID string, opts api.LogsOptions, stop <-chan struct{}) error {
+	ret := _m.Called(stitchID, opts, stop)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, api.LogsOptions, <-chan struct{}) error); ok {
+		r0 = rf(stitchID, opts, stop)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -38,6 +102,52 @@ func (_m *Client) Deploy(deployment string) error {
 	return r0
 }
 
+// Query provides a mock function with given fields: table, filters, fields
+func (_m *Client) Query(table db.TableType, filters []api.Filter, fields []string) (interface{}, error) {
+	ret := _m.Called(table, filters, fields)
+
+	var r0 interface{}
+	if rf, ok := ret.Get(0).(func(db.TableType, []api.Filter, []string) interface{}); ok {
+		r0 = rf(table, filters, fields)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interface{})
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(db.TableType, []api.Filter, []string) error); ok {
+		r1 = rf(table, filters, fields)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryACLs provides a mock function with given fields:
+func (_m *Client) QueryACLs() ([]db.ACL, error) {
+	ret := _m.Called()
+
+	var r0 []db.ACL
+	if rf, ok := ret.Get(0).(func() []db.ACL); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ACL)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // QueryClusters provides a mock function with given fields:
 func (_m *Client) QueryClusters() ([]db.Cluster, error) {
 	ret := _m.Called()
@@ -130,6 +240,75 @@ func (_m *Client) QueryEtcd() ([]db.Etcd, error) {
 	return r0, r1
 }
 
+// QueryHistory provides a mock function with given fields: table, id
+func (_m *Client) QueryHistory(table db.TableType, id int) ([]db.Revision, error) {
+	ret := _m.Called(table, id)
+
+	var r0 []db.Revision
+	if rf, ok := ret.Get(0).(func(db.TableType, int) []db.Revision); ok {
+		r0 = rf(table, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Revision)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(db.TableType, int) error); ok {
+		r1 = rf(table, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryHostnames provides a mock function with given fields:
+func (_m *Client) QueryHostnames() ([]db.Hostname, error) {
+	ret := _m.Called()
+
+	var r0 []db.Hostname
+	if rf, ok := ret.Get(0).(func() []db.Hostname); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Hostname)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryImages provides a mock function with given fields:
+func (_m *Client) QueryImages() ([]db.Image, error) {
+	ret := _m.Called()
+
+	var r0 []db.Image
+	if rf, ok := ret.Get(0).(func() []db.Image); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Image)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // QueryLabels provides a mock function with given fields:
 func (_m *Client) QueryLabels() ([]db.Label, error) {
 	ret := _m.Called()
@@ -176,6 +355,80 @@ func (_m *Client) QueryMachines() ([]db.Machine, error) {
 	return r0, r1
 }
 
+// QueryMinions provides a mock function with given fields:
+func (_m *Client) QueryMinions() ([]db.Minion, error) {
+	ret := _m.Called()
+
+	var r0 []db.Minion
+	if rf, ok := ret.Get(0).(func() []db.Minion); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Minion)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryPlacements provides a mock function with given fields:
+func (_m *Client) QueryPlacements() ([]db.Placement, error) {
+	ret := _m.Called()
+
+	var r0 []db.Placement
+	if rf, ok := ret.Get(0).(func() []db.Placement); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Placement)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetSecret provides a mock function with given fields: name, value
+func (_m *Client) SetSecret(name string, value string) error {
+	ret := _m.Called(name, value)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(name, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Stop provides a mock function with given fields: deployment
+func (_m *Client) Stop(deployment string) error {
+	ret := _m.Called(deployment)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(deployment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Version provides a mock function with given fields:
 func (_m *Client) Version() (string, error) {
 	ret := _m.Called()
@@ -196,3 +449,17 @@ func (_m *Client) Version() (string, error) {
 
 	return r0, r1
 }
+
+// Watch provides a mock function with given fields: table, stop, update
+func (_m *Client) Watch(table db.TableType, stop <-chan struct{}, update func(interface{})) error {
+	ret := _m.Called(table, stop, update)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(db.TableType, <-chan struct{}, func(interface{})) error); ok {
+		r0 = rf(table, stop, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
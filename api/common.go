@@ -13,6 +13,10 @@ var DefaultSocket = "unix:///tmp/quilt.sock"
 // DefaultRemotePort is the port remote Quilt daemons (the minion) listen on by default.
 const DefaultRemotePort = 9000
 
+// NamespaceKey is the gRPC metadata key with which clients name the namespace targeted
+// by their requests, on daemons that manage several.
+const NamespaceKey = "namespace"
+
 // ParseListenAddress validates and parses a socket address into the
 // protocol and address.
 func ParseListenAddress(lAddr string) (string, string, error) {
@@ -0,0 +1,35 @@
+package api
+
+import "fmt"
+
+// DeploymentStatus describes how far the cluster has converged towards the
+// blueprint of a deployment.
+type DeploymentStatus struct {
+	ID        string
+	Converged bool
+
+	Machines          int
+	MachinesBooted    int
+	MachinesConnected int
+
+	Containers        int
+	ContainersPlaced  int
+	ContainersRunning int
+
+	Images      int
+	ImagesBuilt int
+
+	// Descriptions of the parts of the blueprint that haven't converged yet.
+	Stuck []string
+}
+
+// String returns a one line summary of the deployment's progress.
+func (status DeploymentStatus) String() string {
+	return fmt.Sprintf("Machines: %d/%d booted, %d/%d connected. "+
+		"Containers: %d/%d placed, %d/%d running. Images: %d/%d built.",
+		status.MachinesBooted, status.Machines,
+		status.MachinesConnected, status.Machines,
+		status.ContainersPlaced, status.Containers,
+		status.ContainersRunning, status.Containers,
+		status.ImagesBuilt, status.Images)
+}
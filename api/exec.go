@@ -0,0 +1,25 @@
+package api
+
+import "io"
+
+// LogsOptions changes the behavior of the Logs method of the API client.
+type LogsOptions struct {
+	Follow     bool
+	Timestamps bool
+
+	// Only show logs written after this time, given in RFC 3339 format, as a Unix
+	// timestamp, or as a duration before now, e.g. "10m".
+	Since string
+
+	Stdout, Stderr io.Writer
+}
+
+// ExecOptions changes the behavior of the Exec method of the API client.
+type ExecOptions struct {
+	Command []string
+	TTY     bool
+
+	// The command's standard input, or nil if it has none.
+	Stdin          io.Reader
+	Stdout, Stderr io.Writer
+}
@@ -0,0 +1,39 @@
+package api
+
+// A Filter restricts the rows returned by a Query to those whose Field compares to
+// Value according to Op.  Fields are named as in the JSON encoding of the row.
+type Filter struct {
+	Field string
+	Op    FilterOp
+	Value string
+}
+
+// FilterOp is the comparison made by a Filter.
+type FilterOp int
+
+const (
+	// EqualOp matches fields whose value is Value.
+	EqualOp FilterOp = iota
+
+	// PrefixOp matches fields whose value begins with Value.
+	PrefixOp
+
+	// ContainsOp matches list fields with an element equal to Value, map fields
+	// with a key equal to Value, and other fields whose value contains Value.
+	ContainsOp
+)
+
+// Equal creates a Filter matching rows whose 'field' is 'value'.
+func Equal(field, value string) Filter {
+	return Filter{field, EqualOp, value}
+}
+
+// Prefix creates a Filter matching rows whose 'field' begins with 'prefix'.
+func Prefix(field, prefix string) Filter {
+	return Filter{field, PrefixOp, prefix}
+}
+
+// Contains creates a Filter matching rows whose 'field' contains 'value'.
+func Contains(field, value string) Filter {
+	return Filter{field, ContainsOp, value}
+}
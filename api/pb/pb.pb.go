@@ -9,9 +9,18 @@ It is generated from these files:
 
 It has these top-level messages:
 	DBQuery
+	Filter
+	HistoryQuery
 	QueryReply
+	LogsRequest
+	ExecRequest
+	Output
 	DeployRequest
 	DeployReply
+	DeploymentStatusRequest
+	DeploymentStatusReply
+	SecretRequest
+	SecretReply
 	VersionRequest
 	VersionReply
 */
@@ -37,8 +46,34 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type Filter_Op int32
+
+const (
+	Filter_EQUAL    Filter_Op = 0
+	Filter_PREFIX   Filter_Op = 1
+	Filter_CONTAINS Filter_Op = 2
+)
+
+var Filter_Op_name = map[int32]string{
+	0: "EQUAL",
+	1: "PREFIX",
+	2: "CONTAINS",
+}
+var Filter_Op_value = map[string]int32{
+	"EQUAL":    0,
+	"PREFIX":   1,
+	"CONTAINS": 2,
+}
+
+func (x Filter_Op) String() string {
+	return proto.EnumName(Filter_Op_name, int32(x))
+}
+func (Filter_Op) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1, 0} }
+
 type DBQuery struct {
-	Table string `protobuf:"bytes,1,opt,name=Table" json:"Table,omitempty"`
+	Table   string    `protobuf:"bytes,1,opt,name=Table" json:"Table,omitempty"`
+	Filters []*Filter `protobuf:"bytes,2,rep,name=Filters" json:"Filters,omitempty"`
+	Fields  []string  `protobuf:"bytes,3,rep,name=Fields" json:"Fields,omitempty"`
 }
 
 func (m *DBQuery) Reset()                    { *m = DBQuery{} }
@@ -53,6 +88,76 @@ func (m *DBQuery) GetTable() string {
 	return ""
 }
 
+func (m *DBQuery) GetFilters() []*Filter {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+func (m *DBQuery) GetFields() []string {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+type Filter struct {
+	Field string    `protobuf:"bytes,1,opt,name=Field" json:"Field,omitempty"`
+	Op    Filter_Op `protobuf:"varint,2,opt,name=Op,enum=Filter_Op" json:"Op,omitempty"`
+	Value string    `protobuf:"bytes,3,opt,name=Value" json:"Value,omitempty"`
+}
+
+func (m *Filter) Reset()                    { *m = Filter{} }
+func (m *Filter) String() string            { return proto.CompactTextString(m) }
+func (*Filter) ProtoMessage()               {}
+func (*Filter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Filter) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *Filter) GetOp() Filter_Op {
+	if m != nil {
+		return m.Op
+	}
+	return Filter_EQUAL
+}
+
+func (m *Filter) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type HistoryQuery struct {
+	Table string `protobuf:"bytes,1,opt,name=Table" json:"Table,omitempty"`
+	ID    int32  `protobuf:"varint,2,opt,name=ID" json:"ID,omitempty"`
+}
+
+func (m *HistoryQuery) Reset()                    { *m = HistoryQuery{} }
+func (m *HistoryQuery) String() string            { return proto.CompactTextString(m) }
+func (*HistoryQuery) ProtoMessage()               {}
+func (*HistoryQuery) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *HistoryQuery) GetTable() string {
+	if m != nil {
+		return m.Table
+	}
+	return ""
+}
+
+func (m *HistoryQuery) GetID() int32 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
 type QueryReply struct {
 	TableContents string `protobuf:"bytes,1,opt,name=TableContents" json:"TableContents,omitempty"`
 }
@@ -60,7 +165,7 @@ type QueryReply struct {
 func (m *QueryReply) Reset()                    { *m = QueryReply{} }
 func (m *QueryReply) String() string            { return proto.CompactTextString(m) }
 func (*QueryReply) ProtoMessage()               {}
-func (*QueryReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*QueryReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
 func (m *QueryReply) GetTableContents() string {
 	if m != nil {
@@ -69,14 +174,143 @@ func (m *QueryReply) GetTableContents() string {
 	return ""
 }
 
+type LogsRequest struct {
+	StitchID   string `protobuf:"bytes,1,opt,name=StitchID" json:"StitchID,omitempty"`
+	Follow     bool   `protobuf:"varint,2,opt,name=Follow" json:"Follow,omitempty"`
+	Timestamps bool   `protobuf:"varint,3,opt,name=Timestamps" json:"Timestamps,omitempty"`
+	Since      string `protobuf:"bytes,4,opt,name=Since" json:"Since,omitempty"`
+}
+
+func (m *LogsRequest) Reset()                    { *m = LogsRequest{} }
+func (m *LogsRequest) String() string            { return proto.CompactTextString(m) }
+func (*LogsRequest) ProtoMessage()               {}
+func (*LogsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *LogsRequest) GetStitchID() string {
+	if m != nil {
+		return m.StitchID
+	}
+	return ""
+}
+
+func (m *LogsRequest) GetFollow() bool {
+	if m != nil {
+		return m.Follow
+	}
+	return false
+}
+
+func (m *LogsRequest) GetTimestamps() bool {
+	if m != nil {
+		return m.Timestamps
+	}
+	return false
+}
+
+func (m *LogsRequest) GetSince() string {
+	if m != nil {
+		return m.Since
+	}
+	return ""
+}
+
+type ExecRequest struct {
+	StitchID   string   `protobuf:"bytes,1,opt,name=StitchID" json:"StitchID,omitempty"`
+	Command    []string `protobuf:"bytes,2,rep,name=Command" json:"Command,omitempty"`
+	TTY        bool     `protobuf:"varint,3,opt,name=TTY" json:"TTY,omitempty"`
+	Stdin      []byte   `protobuf:"bytes,4,opt,name=Stdin" json:"Stdin,omitempty"`
+	CloseStdin bool     `protobuf:"varint,5,opt,name=CloseStdin" json:"CloseStdin,omitempty"`
+}
+
+func (m *ExecRequest) Reset()                    { *m = ExecRequest{} }
+func (m *ExecRequest) String() string            { return proto.CompactTextString(m) }
+func (*ExecRequest) ProtoMessage()               {}
+func (*ExecRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *ExecRequest) GetStitchID() string {
+	if m != nil {
+		return m.StitchID
+	}
+	return ""
+}
+
+func (m *ExecRequest) GetCommand() []string {
+	if m != nil {
+		return m.Command
+	}
+	return nil
+}
+
+func (m *ExecRequest) GetTTY() bool {
+	if m != nil {
+		return m.TTY
+	}
+	return false
+}
+
+func (m *ExecRequest) GetStdin() []byte {
+	if m != nil {
+		return m.Stdin
+	}
+	return nil
+}
+
+func (m *ExecRequest) GetCloseStdin() bool {
+	if m != nil {
+		return m.CloseStdin
+	}
+	return false
+}
+
+type Output struct {
+	Stdout   []byte `protobuf:"bytes,1,opt,name=Stdout" json:"Stdout,omitempty"`
+	Stderr   []byte `protobuf:"bytes,2,opt,name=Stderr" json:"Stderr,omitempty"`
+	Exited   bool   `protobuf:"varint,3,opt,name=Exited" json:"Exited,omitempty"`
+	ExitCode int32  `protobuf:"varint,4,opt,name=ExitCode" json:"ExitCode,omitempty"`
+}
+
+func (m *Output) Reset()                    { *m = Output{} }
+func (m *Output) String() string            { return proto.CompactTextString(m) }
+func (*Output) ProtoMessage()               {}
+func (*Output) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *Output) GetStdout() []byte {
+	if m != nil {
+		return m.Stdout
+	}
+	return nil
+}
+
+func (m *Output) GetStderr() []byte {
+	if m != nil {
+		return m.Stderr
+	}
+	return nil
+}
+
+func (m *Output) GetExited() bool {
+	if m != nil {
+		return m.Exited
+	}
+	return false
+}
+
+func (m *Output) GetExitCode() int32 {
+	if m != nil {
+		return m.ExitCode
+	}
+	return 0
+}
+
 type DeployRequest struct {
 	Deployment string `protobuf:"bytes,1,opt,name=Deployment" json:"Deployment,omitempty"`
+	Stop       bool   `protobuf:"varint,2,opt,name=Stop" json:"Stop,omitempty"`
 }
 
 func (m *DeployRequest) Reset()                    { *m = DeployRequest{} }
 func (m *DeployRequest) String() string            { return proto.CompactTextString(m) }
 func (*DeployRequest) ProtoMessage()               {}
-func (*DeployRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*DeployRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
 func (m *DeployRequest) GetDeployment() string {
 	if m != nil {
@@ -85,13 +319,172 @@ func (m *DeployRequest) GetDeployment() string {
 	return ""
 }
 
+func (m *DeployRequest) GetStop() bool {
+	if m != nil {
+		return m.Stop
+	}
+	return false
+}
+
 type DeployReply struct {
+	ID string `protobuf:"bytes,1,opt,name=ID" json:"ID,omitempty"`
 }
 
 func (m *DeployReply) Reset()                    { *m = DeployReply{} }
 func (m *DeployReply) String() string            { return proto.CompactTextString(m) }
 func (*DeployReply) ProtoMessage()               {}
-func (*DeployReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*DeployReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *DeployReply) GetID() string {
+	if m != nil {
+		return m.ID
+	}
+	return ""
+}
+
+type DeploymentStatusRequest struct {
+	ID string `protobuf:"bytes,1,opt,name=ID" json:"ID,omitempty"`
+}
+
+func (m *DeploymentStatusRequest) Reset()                    { *m = DeploymentStatusRequest{} }
+func (m *DeploymentStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeploymentStatusRequest) ProtoMessage()               {}
+func (*DeploymentStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *DeploymentStatusRequest) GetID() string {
+	if m != nil {
+		return m.ID
+	}
+	return ""
+}
+
+type DeploymentStatusReply struct {
+	ID                string   `protobuf:"bytes,1,opt,name=ID" json:"ID,omitempty"`
+	Converged         bool     `protobuf:"varint,2,opt,name=Converged" json:"Converged,omitempty"`
+	Machines          int32    `protobuf:"varint,3,opt,name=Machines" json:"Machines,omitempty"`
+	MachinesBooted    int32    `protobuf:"varint,4,opt,name=MachinesBooted" json:"MachinesBooted,omitempty"`
+	MachinesConnected int32    `protobuf:"varint,5,opt,name=MachinesConnected" json:"MachinesConnected,omitempty"`
+	Containers        int32    `protobuf:"varint,6,opt,name=Containers" json:"Containers,omitempty"`
+	ContainersPlaced  int32    `protobuf:"varint,7,opt,name=ContainersPlaced" json:"ContainersPlaced,omitempty"`
+	ContainersRunning int32    `protobuf:"varint,8,opt,name=ContainersRunning" json:"ContainersRunning,omitempty"`
+	Images            int32    `protobuf:"varint,9,opt,name=Images" json:"Images,omitempty"`
+	ImagesBuilt       int32    `protobuf:"varint,10,opt,name=ImagesBuilt" json:"ImagesBuilt,omitempty"`
+	Stuck             []string `protobuf:"bytes,11,rep,name=Stuck" json:"Stuck,omitempty"`
+}
+
+func (m *DeploymentStatusReply) Reset()                    { *m = DeploymentStatusReply{} }
+func (m *DeploymentStatusReply) String() string            { return proto.CompactTextString(m) }
+func (*DeploymentStatusReply) ProtoMessage()               {}
+func (*DeploymentStatusReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *DeploymentStatusReply) GetID() string {
+	if m != nil {
+		return m.ID
+	}
+	return ""
+}
+
+func (m *DeploymentStatusReply) GetConverged() bool {
+	if m != nil {
+		return m.Converged
+	}
+	return false
+}
+
+func (m *DeploymentStatusReply) GetMachines() int32 {
+	if m != nil {
+		return m.Machines
+	}
+	return 0
+}
+
+func (m *DeploymentStatusReply) GetMachinesBooted() int32 {
+	if m != nil {
+		return m.MachinesBooted
+	}
+	return 0
+}
+
+func (m *DeploymentStatusReply) GetMachinesConnected() int32 {
+	if m != nil {
+		return m.MachinesConnected
+	}
+	return 0
+}
+
+func (m *DeploymentStatusReply) GetContainers() int32 {
+	if m != nil {
+		return m.Containers
+	}
+	return 0
+}
+
+func (m *DeploymentStatusReply) GetContainersPlaced() int32 {
+	if m != nil {
+		return m.ContainersPlaced
+	}
+	return 0
+}
+
+func (m *DeploymentStatusReply) GetContainersRunning() int32 {
+	if m != nil {
+		return m.ContainersRunning
+	}
+	return 0
+}
+
+func (m *DeploymentStatusReply) GetImages() int32 {
+	if m != nil {
+		return m.Images
+	}
+	return 0
+}
+
+func (m *DeploymentStatusReply) GetImagesBuilt() int32 {
+	if m != nil {
+		return m.ImagesBuilt
+	}
+	return 0
+}
+
+func (m *DeploymentStatusReply) GetStuck() []string {
+	if m != nil {
+		return m.Stuck
+	}
+	return nil
+}
+
+type SecretRequest struct {
+	Name  string `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=Value" json:"Value,omitempty"`
+}
+
+func (m *SecretRequest) Reset()                    { *m = SecretRequest{} }
+func (m *SecretRequest) String() string            { return proto.CompactTextString(m) }
+func (*SecretRequest) ProtoMessage()               {}
+func (*SecretRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *SecretRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SecretRequest) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type SecretReply struct {
+}
+
+func (m *SecretReply) Reset()                    { *m = SecretReply{} }
+func (m *SecretReply) String() string            { return proto.CompactTextString(m) }
+func (*SecretReply) ProtoMessage()               {}
+func (*SecretReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
 
 type VersionRequest struct {
 }
@@ -99,7 +492,7 @@ type VersionRequest struct {
 func (m *VersionRequest) Reset()                    { *m = VersionRequest{} }
 func (m *VersionRequest) String() string            { return proto.CompactTextString(m) }
 func (*VersionRequest) ProtoMessage()               {}
-func (*VersionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*VersionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
 type VersionReply struct {
 	Version string `protobuf:"bytes,1,opt,name=Version" json:"Version,omitempty"`
@@ -108,7 +501,7 @@ type VersionReply struct {
 func (m *VersionReply) Reset()                    { *m = VersionReply{} }
 func (m *VersionReply) String() string            { return proto.CompactTextString(m) }
 func (*VersionReply) ProtoMessage()               {}
-func (*VersionReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*VersionReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
 
 func (m *VersionReply) GetVersion() string {
 	if m != nil {
@@ -119,11 +512,21 @@ func (m *VersionReply) GetVersion() string {
 
 func init() {
 	proto.RegisterType((*DBQuery)(nil), "DBQuery")
+	proto.RegisterType((*Filter)(nil), "Filter")
+	proto.RegisterType((*HistoryQuery)(nil), "HistoryQuery")
 	proto.RegisterType((*QueryReply)(nil), "QueryReply")
+	proto.RegisterType((*LogsRequest)(nil), "LogsRequest")
+	proto.RegisterType((*ExecRequest)(nil), "ExecRequest")
+	proto.RegisterType((*Output)(nil), "Output")
 	proto.RegisterType((*DeployRequest)(nil), "DeployRequest")
 	proto.RegisterType((*DeployReply)(nil), "DeployReply")
+	proto.RegisterType((*DeploymentStatusRequest)(nil), "DeploymentStatusRequest")
+	proto.RegisterType((*DeploymentStatusReply)(nil), "DeploymentStatusReply")
+	proto.RegisterType((*SecretRequest)(nil), "SecretRequest")
+	proto.RegisterType((*SecretReply)(nil), "SecretReply")
 	proto.RegisterType((*VersionRequest)(nil), "VersionRequest")
 	proto.RegisterType((*VersionReply)(nil), "VersionReply")
+	proto.RegisterEnum("Filter_Op", Filter_Op_name, Filter_Op_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -138,7 +541,13 @@ const _ = grpc.SupportPackageIsVersion4
 
 type APIClient interface {
 	Query(ctx context.Context, in *DBQuery, opts ...grpc.CallOption) (*QueryReply, error)
+	QueryHistory(ctx context.Context, in *HistoryQuery, opts ...grpc.CallOption) (*QueryReply, error)
+	Watch(ctx context.Context, in *DBQuery, opts ...grpc.CallOption) (API_WatchClient, error)
+	Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (API_LogsClient, error)
+	Exec(ctx context.Context, opts ...grpc.CallOption) (API_ExecClient, error)
 	Deploy(ctx context.Context, in *DeployRequest, opts ...grpc.CallOption) (*DeployReply, error)
+	DeploymentStatus(ctx context.Context, in *DeploymentStatusRequest, opts ...grpc.CallOption) (*DeploymentStatusReply, error)
+	SetSecret(ctx context.Context, in *SecretRequest, opts ...grpc.CallOption) (*SecretReply, error)
 	Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionReply, error)
 }
 
@@ -159,6 +568,110 @@ func (c *aPIClient) Query(ctx context.Context, in *DBQuery, opts ...grpc.CallOpt
 	return out, nil
 }
 
+func (c *aPIClient) QueryHistory(ctx context.Context, in *HistoryQuery, opts ...grpc.CallOption) (*QueryReply, error) {
+	out := new(QueryReply)
+	err := grpc.Invoke(ctx, "/API/QueryHistory", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) Watch(ctx context.Context, in *DBQuery, opts ...grpc.CallOption) (API_WatchClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_API_serviceDesc.Streams[0], c.cc, "/API/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_WatchClient interface {
+	Recv() (*QueryReply, error)
+	grpc.ClientStream
+}
+
+type aPIWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIWatchClient) Recv() (*QueryReply, error) {
+	m := new(QueryReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (API_LogsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_API_serviceDesc.Streams[1], c.cc, "/API/Logs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPILogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_LogsClient interface {
+	Recv() (*Output, error)
+	grpc.ClientStream
+}
+
+type aPILogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPILogsClient) Recv() (*Output, error) {
+	m := new(Output)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) Exec(ctx context.Context, opts ...grpc.CallOption) (API_ExecClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_API_serviceDesc.Streams[2], c.cc, "/API/Exec", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIExecClient{stream}
+	return x, nil
+}
+
+type API_ExecClient interface {
+	Send(*ExecRequest) error
+	Recv() (*Output, error)
+	grpc.ClientStream
+}
+
+type aPIExecClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIExecClient) Send(m *ExecRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *aPIExecClient) Recv() (*Output, error) {
+	m := new(Output)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *aPIClient) Deploy(ctx context.Context, in *DeployRequest, opts ...grpc.CallOption) (*DeployReply, error) {
 	out := new(DeployReply)
 	err := grpc.Invoke(ctx, "/API/Deploy", in, out, c.cc, opts...)
@@ -168,6 +681,24 @@ func (c *aPIClient) Deploy(ctx context.Context, in *DeployRequest, opts ...grpc.
 	return out, nil
 }
 
+func (c *aPIClient) DeploymentStatus(ctx context.Context, in *DeploymentStatusRequest, opts ...grpc.CallOption) (*DeploymentStatusReply, error) {
+	out := new(DeploymentStatusReply)
+	err := grpc.Invoke(ctx, "/API/DeploymentStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) SetSecret(ctx context.Context, in *SecretRequest, opts ...grpc.CallOption) (*SecretReply, error) {
+	out := new(SecretReply)
+	err := grpc.Invoke(ctx, "/API/SetSecret", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionReply, error) {
 	out := new(VersionReply)
 	err := grpc.Invoke(ctx, "/API/Version", in, out, c.cc, opts...)
@@ -181,7 +712,13 @@ func (c *aPIClient) Version(ctx context.Context, in *VersionRequest, opts ...grp
 
 type APIServer interface {
 	Query(context.Context, *DBQuery) (*QueryReply, error)
+	QueryHistory(context.Context, *HistoryQuery) (*QueryReply, error)
+	Watch(*DBQuery, API_WatchServer) error
+	Logs(*LogsRequest, API_LogsServer) error
+	Exec(API_ExecServer) error
 	Deploy(context.Context, *DeployRequest) (*DeployReply, error)
+	DeploymentStatus(context.Context, *DeploymentStatusRequest) (*DeploymentStatusReply, error)
+	SetSecret(context.Context, *SecretRequest) (*SecretReply, error)
 	Version(context.Context, *VersionRequest) (*VersionReply, error)
 }
 
@@ -207,6 +744,92 @@ func _API_Query_Handler(srv interface{}, ctx context.Context, dec func(interface
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_QueryHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).QueryHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/QueryHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).QueryHistory(ctx, req.(*HistoryQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DBQuery)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).Watch(m, &aPIWatchServer{stream})
+}
+
+type API_WatchServer interface {
+	Send(*QueryReply) error
+	grpc.ServerStream
+}
+
+type aPIWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIWatchServer) Send(m *QueryReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _API_Logs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).Logs(m, &aPILogsServer{stream})
+}
+
+type API_LogsServer interface {
+	Send(*Output) error
+	grpc.ServerStream
+}
+
+type aPILogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPILogsServer) Send(m *Output) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _API_Exec_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(APIServer).Exec(&aPIExecServer{stream})
+}
+
+type API_ExecServer interface {
+	Send(*Output) error
+	Recv() (*ExecRequest, error)
+	grpc.ServerStream
+}
+
+type aPIExecServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIExecServer) Send(m *Output) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *aPIExecServer) Recv() (*ExecRequest, error) {
+	m := new(ExecRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _API_Deploy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeployRequest)
 	if err := dec(in); err != nil {
@@ -225,6 +848,42 @@ func _API_Deploy_Handler(srv interface{}, ctx context.Context, dec func(interfac
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_DeploymentStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeploymentStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).DeploymentStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/DeploymentStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).DeploymentStatus(ctx, req.(*DeploymentStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_SetSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).SetSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/SetSecret",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).SetSecret(ctx, req.(*SecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_Version_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(VersionRequest)
 	if err := dec(in); err != nil {
@@ -251,36 +910,102 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Query",
 			Handler:    _API_Query_Handler,
 		},
+		{
+			MethodName: "QueryHistory",
+			Handler:    _API_QueryHistory_Handler,
+		},
 		{
 			MethodName: "Deploy",
 			Handler:    _API_Deploy_Handler,
 		},
+		{
+			MethodName: "DeploymentStatus",
+			Handler:    _API_DeploymentStatus_Handler,
+		},
+		{
+			MethodName: "SetSecret",
+			Handler:    _API_SetSecret_Handler,
+		},
 		{
 			MethodName: "Version",
 			Handler:    _API_Version_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _API_Watch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Logs",
+			Handler:       _API_Logs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Exec",
+			Handler:       _API_Exec_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "pb/pb.proto",
 }
 
 func init() { proto.RegisterFile("pb/pb.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 228 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x90, 0x41, 0x4b, 0xc3, 0x40,
-	0x10, 0x85, 0x53, 0xa4, 0xad, 0xbe, 0x34, 0x55, 0x06, 0x0f, 0x25, 0x07, 0x2d, 0x83, 0x87, 0x80,
-	0xb0, 0x85, 0xfa, 0x0b, 0xd4, 0x5e, 0xbc, 0x69, 0x10, 0xef, 0x06, 0xe6, 0x20, 0xac, 0xbb, 0x6b,
-	0xb2, 0x3d, 0xec, 0xc5, 0xdf, 0x2e, 0x66, 0xb7, 0x6d, 0x72, 0x7c, 0x5f, 0x32, 0x33, 0xdf, 0x5b,
-	0xe4, 0xae, 0xd9, 0xb8, 0x46, 0xb9, 0xd6, 0x7a, 0xcb, 0xb7, 0x98, 0xef, 0x9e, 0xde, 0xf6, 0xd2,
-	0x06, 0xba, 0xc6, 0xf4, 0xfd, 0xb3, 0xd1, 0xb2, 0x9a, 0xac, 0x27, 0xd5, 0x45, 0x1d, 0x03, 0x6f,
-	0x81, 0xfe, 0x73, 0x2d, 0x4e, 0x07, 0xba, 0x43, 0xd1, 0xe3, 0x67, 0x6b, 0xbc, 0x18, 0xdf, 0xa5,
-	0x7f, 0xc7, 0x90, 0x37, 0x28, 0x76, 0xe2, 0xb4, 0x0d, 0xb5, 0xfc, 0xec, 0xa5, 0xf3, 0x74, 0x03,
-	0x44, 0xf0, 0x2d, 0xc6, 0xa7, 0x99, 0x01, 0xe1, 0x02, 0xf9, 0x61, 0xc0, 0xe9, 0xc0, 0x57, 0x58,
-	0x7e, 0x48, 0xdb, 0x7d, 0x59, 0x93, 0x16, 0x70, 0x85, 0xc5, 0x91, 0xfc, 0x7b, 0xac, 0x30, 0x4f,
-	0x39, 0x6d, 0x3b, 0xc4, 0xed, 0x2f, 0xce, 0x1e, 0x5f, 0x5f, 0x68, 0x8d, 0x69, 0x6c, 0x75, 0xae,
-	0x52, 0xbf, 0x32, 0x57, 0xa7, 0x22, 0x9c, 0x51, 0x85, 0x59, 0xbc, 0x49, 0x4b, 0x35, 0xb2, 0x2d,
-	0x17, 0x6a, 0x28, 0x93, 0xd1, 0xfd, 0xf1, 0x18, 0x5d, 0xaa, 0xb1, 0x58, 0x59, 0xa8, 0xa1, 0x17,
-	0x67, 0xcd, 0xac, 0x7f, 0xd7, 0x87, 0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0xcd, 0x14, 0xe7, 0x70,
-	0x66, 0x01, 0x00, 0x00,
+	// 826 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0x5b, 0x8f, 0xdb, 0x44,
+	0x14, 0x4e, 0x9c, 0x38, 0x97, 0x63, 0x27, 0x84, 0x23, 0x28, 0x56, 0x04, 0x55, 0x3a, 0x20, 0x94,
+	0x42, 0x35, 0x54, 0x0b, 0x2f, 0x3c, 0xee, 0x26, 0xbb, 0x22, 0x52, 0xd9, 0x6c, 0x9d, 0x50, 0x2e,
+	0x6f, 0x5e, 0x7b, 0xb4, 0x6b, 0xe1, 0xcc, 0x18, 0x7b, 0x4c, 0x1b, 0xf1, 0xc4, 0x33, 0xe2, 0x3f,
+	0xa3, 0xb9, 0x38, 0xf1, 0xee, 0xb6, 0xa8, 0x6f, 0xf3, 0x7d, 0xfe, 0xe6, 0x5c, 0xe6, 0x5c, 0x0c,
+	0x5e, 0x7e, 0xfd, 0x4d, 0x7e, 0x4d, 0xf3, 0x42, 0x48, 0x41, 0x7e, 0x83, 0xfe, 0xf2, 0xec, 0x65,
+	0xc5, 0x8a, 0x3d, 0x7e, 0x04, 0xee, 0x36, 0xba, 0xce, 0x58, 0xd0, 0x9e, 0xb5, 0xe7, 0xc3, 0xd0,
+	0x00, 0x7c, 0x02, 0xfd, 0x8b, 0x34, 0x93, 0xac, 0x28, 0x03, 0x67, 0xd6, 0x99, 0x7b, 0x27, 0x7d,
+	0x6a, 0x70, 0x58, 0xf3, 0xf8, 0x08, 0x7a, 0x17, 0x29, 0xcb, 0x92, 0x32, 0xe8, 0xcc, 0x3a, 0xf3,
+	0x61, 0x68, 0x11, 0xf9, 0x0b, 0x7a, 0x46, 0xa2, 0x4c, 0x6b, 0xae, 0x36, 0xad, 0x01, 0x4e, 0xc1,
+	0x59, 0xe7, 0x81, 0x33, 0x6b, 0xcf, 0xc7, 0x27, 0x60, 0xad, 0xd2, 0x75, 0x1e, 0x3a, 0xeb, 0x5c,
+	0xdd, 0x78, 0x15, 0x65, 0x15, 0x0b, 0x3a, 0xe6, 0x86, 0x06, 0xe4, 0xa9, 0xba, 0x81, 0x43, 0x70,
+	0xcf, 0x5f, 0xfe, 0x74, 0xfa, 0x62, 0xd2, 0x42, 0x80, 0xde, 0x55, 0x78, 0x7e, 0xb1, 0xfa, 0x65,
+	0xd2, 0x46, 0x1f, 0x06, 0x8b, 0xf5, 0xe5, 0xf6, 0x74, 0x75, 0xb9, 0x99, 0x38, 0xe4, 0x3b, 0xf0,
+	0x7f, 0x48, 0x4b, 0x29, 0x8a, 0xfd, 0xff, 0x65, 0x37, 0x06, 0x67, 0xb5, 0xd4, 0x21, 0xb8, 0xa1,
+	0xb3, 0x5a, 0x92, 0x13, 0x00, 0x2d, 0x0f, 0x59, 0x9e, 0xed, 0xf1, 0x0b, 0x18, 0x69, 0xd9, 0x42,
+	0x70, 0xc9, 0xb8, 0x2c, 0xed, 0xdd, 0xbb, 0x24, 0x79, 0x0d, 0xde, 0x0b, 0x71, 0x53, 0x86, 0xec,
+	0x8f, 0x8a, 0x95, 0x12, 0xa7, 0x30, 0xd8, 0xc8, 0x54, 0xc6, 0xb7, 0xab, 0xa5, 0xd5, 0x1f, 0xb0,
+	0x7e, 0x29, 0x91, 0x65, 0xe2, 0xb5, 0x76, 0x39, 0x08, 0x2d, 0xc2, 0xc7, 0x00, 0xdb, 0x74, 0xc7,
+	0x4a, 0x19, 0xed, 0xf2, 0x52, 0xa7, 0x3c, 0x08, 0x1b, 0x8c, 0x0a, 0x7e, 0x93, 0xf2, 0x98, 0x05,
+	0x5d, 0x13, 0xbc, 0x06, 0xe4, 0x9f, 0x36, 0x78, 0xe7, 0x6f, 0x58, 0xfc, 0x3e, 0x9e, 0x03, 0xe8,
+	0x2f, 0xc4, 0x6e, 0x17, 0xf1, 0x44, 0x97, 0x71, 0x18, 0xd6, 0x10, 0x27, 0xd0, 0xd9, 0x6e, 0x7f,
+	0xb5, 0x4e, 0xd5, 0x51, 0x7b, 0x93, 0x49, 0xca, 0xb5, 0x37, 0x3f, 0x34, 0x40, 0xc5, 0xb8, 0xc8,
+	0x44, 0xc9, 0xcc, 0x27, 0xd7, 0xc4, 0x78, 0x64, 0x48, 0x06, 0xbd, 0x75, 0x25, 0xf3, 0x4a, 0xaa,
+	0x2c, 0x37, 0x32, 0x11, 0x95, 0xd4, 0x51, 0xf8, 0xa1, 0x45, 0x96, 0x67, 0x45, 0x11, 0x38, 0x07,
+	0x9e, 0x15, 0x85, 0xe2, 0xcf, 0xdf, 0xa4, 0x92, 0x25, 0x36, 0x08, 0x8b, 0x54, 0x3e, 0xea, 0xb4,
+	0x10, 0x89, 0x49, 0xdc, 0x0d, 0x0f, 0x98, 0x2c, 0x60, 0xb4, 0x64, 0x79, 0x26, 0xf6, 0x75, 0xf2,
+	0x8f, 0x01, 0x0c, 0xb1, 0x63, 0x5c, 0xda, 0xf4, 0x1b, 0x0c, 0x22, 0x74, 0x37, 0x52, 0xe4, 0xf6,
+	0xe1, 0xf5, 0x99, 0x7c, 0x06, 0x5e, 0x6d, 0x44, 0x95, 0xdb, 0x34, 0x83, 0xb9, 0xaa, 0x9a, 0xe1,
+	0x29, 0x7c, 0x72, 0x34, 0xb0, 0x91, 0x91, 0xac, 0x0e, 0x45, 0xbe, 0x2f, 0xfd, 0xbb, 0x03, 0x1f,
+	0x3f, 0xd4, 0xbe, 0xc5, 0x28, 0x7e, 0x0a, 0xc3, 0x85, 0xe0, 0x7f, 0xb2, 0xe2, 0x86, 0x25, 0x36,
+	0x98, 0x23, 0xa1, 0x52, 0xfe, 0x31, 0x8a, 0x6f, 0x53, 0xce, 0x4c, 0x1b, 0xb8, 0xe1, 0x01, 0xe3,
+	0x97, 0x30, 0xae, 0xcf, 0x67, 0x42, 0xa8, 0xe7, 0x32, 0x8f, 0x72, 0x8f, 0xc5, 0x67, 0xf0, 0x61,
+	0xcd, 0x2c, 0x04, 0xe7, 0x2c, 0x56, 0x52, 0x57, 0x4b, 0x1f, 0x7e, 0xd0, 0x65, 0x15, 0x5c, 0x46,
+	0x29, 0x57, 0x23, 0xde, 0xd3, 0xb2, 0x06, 0x83, 0x5f, 0xc1, 0xe4, 0x88, 0xae, 0xb2, 0x28, 0x66,
+	0x49, 0xd0, 0xd7, 0xaa, 0x07, 0xbc, 0xf2, 0x7c, 0xe4, 0xc2, 0x8a, 0xf3, 0x94, 0xdf, 0x04, 0x03,
+	0xe3, 0xf9, 0xc1, 0x07, 0x55, 0xf6, 0xd5, 0x2e, 0xba, 0x61, 0x65, 0x30, 0xd4, 0x12, 0x8b, 0x70,
+	0x06, 0x9e, 0x39, 0x9d, 0x55, 0x69, 0x26, 0x03, 0xd0, 0x1f, 0x9b, 0x94, 0x69, 0xd0, 0x2a, 0xfe,
+	0x3d, 0xf0, 0x74, 0x2b, 0x1b, 0x40, 0xbe, 0x87, 0xd1, 0x86, 0xc5, 0x05, 0x93, 0x75, 0x91, 0x10,
+	0xba, 0x97, 0xd1, 0xae, 0x9e, 0x78, 0x7d, 0x3e, 0xee, 0x15, 0xa7, 0xb9, 0x57, 0x46, 0xe0, 0xd5,
+	0x57, 0xf3, 0x6c, 0x4f, 0x26, 0x30, 0x7e, 0xc5, 0x8a, 0x32, 0x15, 0xdc, 0x9a, 0x22, 0x73, 0xf0,
+	0x0f, 0x8c, 0xaa, 0x6a, 0x00, 0x7d, 0x8b, 0xad, 0xf5, 0x1a, 0x9e, 0xfc, 0xdb, 0x81, 0xce, 0xe9,
+	0xd5, 0x0a, 0x67, 0xe0, 0x9a, 0xc5, 0x33, 0xa0, 0x76, 0xc1, 0x4e, 0x3d, 0x7a, 0xdc, 0x2d, 0xa4,
+	0x85, 0xcf, 0xc0, 0xd7, 0xd8, 0xae, 0x29, 0x1c, 0xd1, 0xe6, 0xc2, 0xba, 0xaf, 0x26, 0xe0, 0xfe,
+	0x1c, 0xc9, 0xf8, 0xf6, 0x9d, 0xf6, 0x9e, 0xb7, 0xf1, 0x09, 0x74, 0xd5, 0x26, 0x42, 0x9f, 0x36,
+	0x16, 0xd2, 0xb4, 0x4f, 0xcd, 0x5c, 0x6a, 0xc9, 0xe7, 0xd0, 0x55, 0x2b, 0x03, 0x7d, 0xda, 0xd8,
+	0x1c, 0x0d, 0xc9, 0xbc, 0xfd, 0xbc, 0x8d, 0x73, 0xe8, 0x99, 0x66, 0xc6, 0x31, 0xbd, 0x33, 0x65,
+	0x53, 0x9f, 0x36, 0x06, 0x86, 0xb4, 0xf0, 0x02, 0x26, 0xf7, 0xdb, 0x1e, 0x03, 0xfa, 0x8e, 0xa9,
+	0x99, 0x3e, 0xa2, 0x6f, 0x9d, 0x11, 0xd2, 0xc2, 0xaf, 0x61, 0xb8, 0x61, 0xd2, 0xd4, 0x00, 0xc7,
+	0xf4, 0x4e, 0x1d, 0xa7, 0x3e, 0x6d, 0x16, 0x47, 0x89, 0xeb, 0xd7, 0xc6, 0x0f, 0xe8, 0xdd, 0x42,
+	0x4d, 0x47, 0xb4, 0x59, 0x27, 0xd2, 0xba, 0xee, 0xe9, 0xff, 0xdc, 0xb7, 0xff, 0x05, 0x00, 0x00,
+	0xff, 0xff, 0x01, 0x09, 0x6d, 0x64, 0xf6, 0x06, 0x00, 0x00,
 }
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/quilt/quilt/api"
+	"github.com/quilt/quilt/api/client"
+	"github.com/quilt/quilt/api/pb"
+	"github.com/quilt/quilt/db"
+	"github.com/quilt/quilt/minion/docker"
+
+	"golang.org/x/net/context"
+)
+
+// Logs streams the logs of the container with the given StitchID.  The daemon
+// proxies the request to the API server on the worker running the container.
+func (s server) Logs(req *pb.LogsRequest, stream pb.API_LogsServer) error {
+	sender := &outputSender{send: stream.Send}
+	stdout, stderr := outputWriter{sender, false}, outputWriter{sender, true}
+
+	if s.runningOnDaemon {
+		workerClient, err := s.workerClient(stream.Context(), req.StitchID)
+		if err != nil {
+			return err
+		}
+		defer workerClient.Close()
+
+		return workerClient.Logs(req.StitchID, api.LogsOptions{
+			Follow:     req.Follow,
+			Timestamps: req.Timestamps,
+			Since:      req.Since,
+			Stdout:     stdout,
+			Stderr:     stderr,
+		}, stream.Context().Done())
+	}
+
+	dockerID, err := s.localDockerID(req.StitchID)
+	if err != nil {
+		return err
+	}
+
+	since, err := parseSince(req.Since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return s.dk.Logs(stream.Context(), dockerID, docker.LogsOptions{
+		Follow:     req.Follow,
+		Timestamps: req.Timestamps,
+		Since:      since,
+		Stdout:     stdout,
+		Stderr:     stderr,
+	})
+}
+
+// Exec runs a command in the container named by the first request on the stream,
+// forwarding the command's input and output over the stream.  The daemon proxies the
+// request to the API server on the worker running the container.
+func (s server) Exec(stream pb.API_ExecServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	stdin, stdinWriter := io.Pipe()
+	defer stdin.Close()
+	go receiveStdin(req, stream, stdinWriter)
+
+	sender := &outputSender{send: stream.Send}
+	stdout, stderr := outputWriter{sender, false}, outputWriter{sender, true}
+
+	var exitCode int
+	if s.runningOnDaemon {
+		workerClient, err := s.workerClient(stream.Context(), req.StitchID)
+		if err != nil {
+			return err
+		}
+		defer workerClient.Close()
+
+		exitCode, err = workerClient.Exec(req.StitchID, api.ExecOptions{
+			Command: req.Command,
+			TTY:     req.TTY,
+			Stdin:   stdin,
+			Stdout:  stdout,
+			Stderr:  stderr,
+		}, stream.Context().Done())
+		if err != nil {
+			return err
+		}
+	} else {
+		dockerID, err := s.localDockerID(req.StitchID)
+		if err != nil {
+			return err
+		}
+
+		exitCode, err = s.dk.Exec(stream.Context(), dockerID, docker.ExecOptions{
+			Cmd:    req.Command,
+			TTY:    req.TTY,
+			Stdin:  stdin,
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return sender.Send(&pb.Output{Exited: true, ExitCode: int32(exitCode)})
+}
+
+// receiveStdin writes the standard input carried by 'first', and the rest of the
+// requests on 'stream', to 'stdin'.
+func receiveStdin(first *pb.ExecRequest, stream pb.API_ExecServer,
+	stdin *io.PipeWriter) {
+
+	for req := first; ; {
+		if len(req.Stdin) > 0 {
+			if _, err := stdin.Write(req.Stdin); err != nil {
+				return
+			}
+		}
+
+		if req.CloseStdin {
+			stdin.Close()
+			return
+		}
+
+		var err error
+		if req, err = stream.Recv(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			stdin.CloseWithError(err)
+			return
+		}
+	}
+}
+
+// localDockerID returns the Docker ID of the container with the given StitchID, if
+// it's running on this machine.
+func (s server) localDockerID(stitchID string) (string, error) {
+	if s.dk == nil {
+		return "", errors.New("containers are not accessible on this machine")
+	}
+
+	for _, dbc := range s.conn.SelectFromContainerByStitchID(stitchID) {
+		if dbc.DockerID != "" {
+			return dbc.DockerID, nil
+		}
+	}
+	return "", fmt.Errorf("container %s is not running on this machine", stitchID)
+}
+
+// workerClient connects to the API server of the worker running the container with
+// the given StitchID, in the namespace targeted by the request in 'ctx'.
+func (s server) workerClient(ctx context.Context, stitchID string) (
+	client.Client, error) {
+
+	machines, err := s.selectMachines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	leaderClient, err := newLeaderClient(machines, s.creds)
+	if err != nil {
+		return nil, err
+	}
+	defer leaderClient.Close()
+
+	rows, err := leaderClient.Query(db.ContainerTable,
+		[]api.Filter{api.Equal("StitchID", stitchID)},
+		[]string{"StitchID", "Minion"})
+	if err != nil {
+		return nil, err
+	}
+
+	containers, _ := rows.([]db.Container)
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no container with StitchID %s", stitchID)
+	}
+
+	minion := containers[0].Minion
+	if minion == "" {
+		return nil, fmt.Errorf("container %s is not yet scheduled", stitchID)
+	}
+
+	for _, m := range machines {
+		if m.PrivateIP == minion && m.PublicIP != "" {
+			return newClient(api.RemoteAddress(m.PublicIP), s.creds)
+		}
+	}
+	return nil, fmt.Errorf("no machine with private IP %s", minion)
+}
+
+// parseSince parses the time after which to show logs, which is either a timestamp in
+// RFC 3339 format, a Unix timestamp, or a duration before 'now'.
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.Parse("2006-01-02T15:04:05", since); err == nil {
+		return t, nil
+	}
+
+	if secs, err := strconv.ParseInt(since, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+
+	if d, err := time.ParseDuration(since); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("malformed time: %s", since)
+}
+
+// outputSender serializes the Outputs sent on a stream, as gRPC streams don't support
+// concurrent sends.
+type outputSender struct {
+	send func(*pb.Output) error
+	sync.Mutex
+}
+
+func (s *outputSender) Send(output *pb.Output) error {
+	s.Lock()
+	defer s.Unlock()
+	return s.send(output)
+}
+
+// An outputWriter sends the data written to it as the Stdout, or Stderr, of Outputs.
+type outputWriter struct {
+	sender *outputSender
+	stderr bool
+}
+
+func (w outputWriter) Write(p []byte) (int, error) {
+	data := append([]byte(nil), p...)
+	output := &pb.Output{Stdout: data}
+	if w.stderr {
+		output = &pb.Output{Stderr: data}
+	}
+
+	if err := w.sender.Send(output); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/quilt/quilt/api"
+	"github.com/quilt/quilt/api/client"
+	"github.com/quilt/quilt/api/client/mocks"
+	"github.com/quilt/quilt/api/pb"
+	"github.com/quilt/quilt/certs"
+	"github.com/quilt/quilt/db"
+	"github.com/quilt/quilt/minion/docker"
+)
+
+type mockLogsServer struct {
+	outputs []*pb.Output
+
+	grpc.ServerStream
+}
+
+func (s *mockLogsServer) Context() context.Context {
+	return context.Background()
+}
+
+func (s *mockLogsServer) Send(output *pb.Output) error {
+	s.outputs = append(s.outputs, output)
+	return nil
+}
+
+// mockExecServer receives 'requests', and then fails once 'done' is closed.
+type mockExecServer struct {
+	requests []*pb.ExecRequest
+	done     chan struct{}
+
+	mockLogsServer
+}
+
+func (s *mockExecServer) Recv() (*pb.ExecRequest, error) {
+	if len(s.requests) == 0 {
+		if s.done != nil {
+			<-s.done
+		}
+		return nil, errors.New("no more requests")
+	}
+
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+// newMinionServer creates a server whose minion runs a container with the StitchID
+// "stitchID", and returns the container's Docker ID.
+func newMinionServer(t *testing.T) (server, *docker.MockClient, string) {
+	md, dk := docker.NewMock()
+	dockerID, err := dk.Run(docker.RunOptions{Name: "foo", Image: "image"})
+	assert.NoError(t, err)
+
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		dbc := view.InsertContainer()
+		dbc.StitchID = "stitchID"
+		dbc.DockerID = dockerID
+		view.Commit(dbc)
+		return nil
+	})
+	return server{conn: conn, dk: &dk}, md, dockerID
+}
+
+func TestLogs(t *testing.T) {
+	t.Parallel()
+
+	s, md, dockerID := newMinionServer(t)
+	md.ContainerLogs[dockerID] = "logs"
+
+	stream := &mockLogsServer{}
+	assert.NoError(t, s.Logs(&pb.LogsRequest{StitchID: "stitchID"}, stream))
+	assert.Equal(t, []*pb.Output{{Stdout: []byte("logs")}}, stream.outputs)
+
+	err := s.Logs(&pb.LogsRequest{StitchID: "missing"}, stream)
+	assert.EqualError(t, err, "container missing is not running on this machine")
+
+	err = s.Logs(&pb.LogsRequest{StitchID: "stitchID", Since: "foo"}, stream)
+	assert.EqualError(t, err, "malformed time: foo")
+
+	s.dk = nil
+	err = s.Logs(&pb.LogsRequest{StitchID: "stitchID"}, stream)
+	assert.EqualError(t, err, "containers are not accessible on this machine")
+}
+
+func TestExec(t *testing.T) {
+	t.Parallel()
+
+	s, md, dockerID := newMinionServer(t)
+	md.ExecExitCode = 2
+
+	stream := &mockExecServer{requests: []*pb.ExecRequest{
+		{StitchID: "stitchID", Command: []string{"cat"}, Stdin: []byte("in")},
+		{CloseStdin: true},
+	}}
+	assert.NoError(t, s.Exec(stream))
+	assert.Equal(t, []*pb.Output{
+		{Stdout: []byte("in")},
+		{Exited: true, ExitCode: 2},
+	}, stream.outputs)
+	assert.Equal(t, []string{"cat"}, md.Executions[dockerID])
+
+	stream = &mockExecServer{requests: []*pb.ExecRequest{{StitchID: "missing"}}}
+	assert.EqualError(t, s.Exec(stream),
+		"container missing is not running on this machine")
+}
+
+func TestExecDaemon(t *testing.T) {
+	newLeaderClient = func(_ []db.Machine, _ certs.Credentials) (
+		client.Client, error) {
+
+		mc := new(mocks.Client)
+		mc.On("Query", db.ContainerTable, []api.Filter{
+			api.Equal("StitchID", "stitchID")}, mock.Anything).Return(
+			[]db.Container{{StitchID: "stitchID", Minion: "9.9.9.9"}}, nil)
+		mc.On("Query", db.ContainerTable, mock.Anything, mock.Anything).Return(
+			[]db.Container{}, nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	// The mocks read the ExecOptions after the command runs, so the stdin pipe
+	// mustn't be closed until then.
+	done := make(chan struct{})
+	newClient = func(host string, _ certs.Credentials) (client.Client, error) {
+		assert.Equal(t, api.RemoteAddress("8.8.8.8"), host)
+
+		mc := new(mocks.Client)
+		mc.On("Exec", "stitchID", mock.Anything, mock.Anything).Return(
+			4, nil).Run(func(args mock.Arguments) {
+			opts := args.Get(1).(api.ExecOptions)
+			assert.Equal(t, []string{"ls"}, opts.Command)
+			opts.Stdout.Write([]byte("out"))
+			close(done)
+		})
+		mc.On("Logs", "stitchID", mock.Anything, mock.Anything).Return(
+			nil).Run(func(args mock.Arguments) {
+			opts := args.Get(1).(api.LogsOptions)
+			assert.True(t, opts.Follow)
+			opts.Stderr.Write([]byte("logs"))
+		})
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		m := view.InsertMachine()
+		m.PrivateIP = "9.9.9.9"
+		m.PublicIP = "8.8.8.8"
+		view.Commit(m)
+		return nil
+	})
+	s := server{conn: conn, runningOnDaemon: true}
+
+	stream := &mockExecServer{requests: []*pb.ExecRequest{
+		{StitchID: "stitchID", Command: []string{"ls"}},
+	}, done: done}
+	assert.NoError(t, s.Exec(stream))
+	assert.Equal(t, []*pb.Output{
+		{Stdout: []byte("out")},
+		{Exited: true, ExitCode: 4},
+	}, stream.outputs)
+
+	logsStream := &mockLogsServer{}
+	assert.NoError(t, s.Logs(&pb.LogsRequest{StitchID: "stitchID", Follow: true},
+		logsStream))
+	assert.Equal(t, []*pb.Output{{Stderr: []byte("logs")}}, logsStream.outputs)
+
+	stream = &mockExecServer{requests: []*pb.ExecRequest{{StitchID: "missing"}}}
+	assert.EqualError(t, s.Exec(stream), "no container with StitchID missing")
+}
+
+func TestParseSince(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1000, 0)
+	check := func(since string, exp time.Time) {
+		actual, err := parseSince(since, now)
+		assert.NoError(t, err)
+		assert.True(t, exp.Equal(actual), "parsed %s as %s", since, actual)
+	}
+
+	check("", time.Time{})
+	check("1970-01-01T00:10:00Z", time.Unix(600, 0))
+	check("1970-01-01T00:10:00", time.Unix(600, 0))
+	check("600", time.Unix(600, 0))
+	check("10m", time.Unix(400, 0))
+
+	_, err := parseSince("yesterday", now)
+	assert.EqualError(t, err, "malformed time: yesterday")
+}
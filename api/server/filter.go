@@ -0,0 +1,148 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/quilt/quilt/api/pb"
+)
+
+// selectRows returns the elements of 'rows', a slice of database rows, that match
+// all of 'filters'.  If 'fields' is non-empty, each row is replaced by a map holding
+// only those fields, so that unneeded columns aren't sent to the client.
+func selectRows(rows interface{}, filters []*pb.Filter, fields []string) (
+	interface{}, error) {
+
+	if len(filters) == 0 && len(fields) == 0 {
+		return rows, nil
+	}
+
+	rowsVal := reflect.ValueOf(rows)
+	names := fieldNames(rowsVal.Type().Elem())
+	for _, filter := range filters {
+		if _, ok := names[filter.Field]; !ok {
+			return nil, unknownFieldError{rowsVal.Type().Elem(), filter.Field}
+		}
+	}
+	for _, field := range fields {
+		if _, ok := names[field]; !ok {
+			return nil, unknownFieldError{rowsVal.Type().Elem(), field}
+		}
+	}
+
+	matches := reflect.MakeSlice(rowsVal.Type(), 0, 0)
+	for i := 0; i < rowsVal.Len(); i++ {
+		row := rowsVal.Index(i)
+		if matchesAll(row, names, filters) {
+			matches = reflect.Append(matches, row)
+		}
+	}
+
+	if len(fields) == 0 {
+		return matches.Interface(), nil
+	}
+	return project(matches.Interface(), fields)
+}
+
+// fieldNames maps the JSON name of each field of 'rowType' that appears in its JSON
+// encoding to the name of the field in Go.
+func fieldNames(rowType reflect.Type) map[string]string {
+	names := map[string]string{}
+	for i := 0; i < rowType.NumField(); i++ {
+		field := rowType.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		switch tag {
+		case "-":
+		case "":
+			names[field.Name] = field.Name
+		default:
+			names[tag] = field.Name
+		}
+	}
+	return names
+}
+
+func matchesAll(row reflect.Value, names map[string]string,
+	filters []*pb.Filter) bool {
+
+	for _, filter := range filters {
+		if !matches(row.FieldByName(names[filter.Field]), filter) {
+			return false
+		}
+	}
+	return true
+}
+
+func matches(val reflect.Value, filter *pb.Filter) bool {
+	switch filter.Op {
+	case pb.Filter_EQUAL:
+		return valueStr(val) == filter.Value
+	case pb.Filter_PREFIX:
+		return strings.HasPrefix(valueStr(val), filter.Value)
+	case pb.Filter_CONTAINS:
+		switch val.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < val.Len(); i++ {
+				if valueStr(val.Index(i)) == filter.Value {
+					return true
+				}
+			}
+			return false
+		case reflect.Map:
+			for _, key := range val.MapKeys() {
+				if valueStr(key) == filter.Value {
+					return true
+				}
+			}
+			return false
+		default:
+			return strings.Contains(valueStr(val), filter.Value)
+		}
+	default:
+		return false
+	}
+}
+
+func valueStr(val reflect.Value) string {
+	if val.Kind() == reflect.String {
+		return val.String()
+	}
+	return fmt.Sprint(val.Interface())
+}
+
+// project converts each of 'rows' into a map holding just 'fields' of its JSON
+// encoding.
+func project(rows interface{}, fields []string) (interface{}, error) {
+	rowsJSON, err := json.Marshal(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var maps []map[string]interface{}
+	if err := json.Unmarshal(rowsJSON, &maps); err != nil {
+		return nil, err
+	}
+
+	projected := []map[string]interface{}{}
+	for _, row := range maps {
+		projectedRow := map[string]interface{}{}
+		for _, field := range fields {
+			if val, ok := row[field]; ok {
+				projectedRow[field] = val
+			}
+		}
+		projected = append(projected, projectedRow)
+	}
+	return projected, nil
+}
+
+type unknownFieldError struct {
+	rowType reflect.Type
+	field   string
+}
+
+func (err unknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %s in %s", err.field, err.rowType)
+}
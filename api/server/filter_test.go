@@ -0,0 +1,106 @@
+package server
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quilt/quilt/api/pb"
+	"github.com/quilt/quilt/db"
+)
+
+func TestSelectRows(t *testing.T) {
+	t.Parallel()
+
+	dbcs := []db.Container{{
+		StitchID: "abc",
+		Minion:   "1.1.1.1",
+		Labels:   []string{"web", "lb"},
+		Env:      map[string]string{"PORT": "80"},
+	}, {
+		StitchID: "abd",
+		Minion:   "2.2.2.2",
+		Labels:   []string{"db"},
+	}, {
+		StitchID: "bcd",
+		Minion:   "1.1.1.1",
+	}}
+
+	checkSelect := func(filters []*pb.Filter, fields []string,
+		exp interface{}) {
+
+		res, err := selectRows(dbcs, filters, fields)
+		assert.NoError(t, err)
+		assert.Equal(t, exp, res)
+	}
+
+	// Without filters or fields, the rows are returned untouched.
+	checkSelect(nil, nil, dbcs)
+
+	checkSelect([]*pb.Filter{{Field: "StitchID", Op: pb.Filter_PREFIX, Value: "ab"}},
+		nil, dbcs[:2])
+	checkSelect([]*pb.Filter{
+		{Field: "StitchID", Op: pb.Filter_PREFIX, Value: "ab"},
+		{Field: "Minion", Op: pb.Filter_EQUAL, Value: "1.1.1.1"},
+	}, nil, dbcs[:1])
+	checkSelect([]*pb.Filter{{Field: "Labels", Op: pb.Filter_CONTAINS, Value: "db"}},
+		nil, dbcs[1:2])
+	checkSelect([]*pb.Filter{{Field: "Env", Op: pb.Filter_CONTAINS, Value: "PORT"}},
+		nil, dbcs[:1])
+	checkSelect([]*pb.Filter{{Field: "Minion", Op: pb.Filter_EQUAL, Value: "3"}},
+		nil, []db.Container{})
+
+	minionFilter := &pb.Filter{Field: "Minion", Op: pb.Filter_EQUAL, Value: "2.2.2.2"}
+	checkSelect([]*pb.Filter{minionFilter}, []string{"StitchID", "Labels"},
+		[]map[string]interface{}{{
+			"StitchID": "abd",
+			"Labels":   []interface{}{"db"},
+		}})
+
+	// Fields that aren't part of the row's JSON encoding are unknown.
+	_, err := selectRows(dbcs, []*pb.Filter{{Field: "Hostname"}}, nil)
+	assert.EqualError(t, err, "unknown field Hostname in db.Container")
+
+	_, err = selectRows(dbcs, nil, []string{"Foo"})
+	assert.EqualError(t, err, "unknown field Foo in db.Container")
+
+	// Non-string fields are compared by their printed value.
+	conns := []db.Connection{{From: "a", MinPort: 80}, {From: "b", MinPort: 443}}
+	res, err := selectRows(conns, []*pb.Filter{
+		{Field: "MinPort", Op: pb.Filter_EQUAL, Value: "443"}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, conns[1:], res)
+}
+
+func TestQueryFilters(t *testing.T) {
+	t.Parallel()
+
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		for _, ip := range []string{"8.8.8.8", "9.9.9.9"} {
+			m := view.InsertMachine()
+			m.PublicIP = ip
+			m.Role = db.Worker
+			view.Commit(m)
+		}
+		return nil
+	})
+
+	s := server{conn: conn}
+	reply, err := s.Query(context.Background(), &pb.DBQuery{
+		Table:   string(db.MachineTable),
+		Filters: []*pb.Filter{{Field: "PublicIP", Value: "9.9.9.9"}},
+		Fields:  []string{"PublicIP", "Role"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"PublicIP":"9.9.9.9","Role":"Worker"}]`,
+		reply.TableContents)
+
+	_, err = s.Query(context.Background(), &pb.DBQuery{
+		Table:  string(db.MachineTable),
+		Fields: []string{"Foo"},
+	})
+	assert.EqualError(t, err, "unknown field Foo in db.Machine")
+}
@@ -0,0 +1,246 @@
+package server
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/quilt/quilt/api/pb"
+	"github.com/quilt/quilt/certs"
+	"github.com/quilt/quilt/db"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// The path under which the gateway serves each RPC, e.g. /v1/Query.
+const gatewayPrefix = "/v1/"
+
+// The HTTP header with which gateway clients name the namespace targeted by their
+// requests, as gRPC clients do with the api.NamespaceKey metadata.
+const namespaceHeader = "Quilt-Namespace"
+
+// RunGateway serves the API as JSON over HTTPS at 'listenAddr', so that clients that
+// can't speak gRPC can use it.  Each RPC is served at /v1/<RPC name>, and takes and
+// returns the JSON encoding of its request and reply messages in api/pb.  The
+// gateway shares its handlers, and its authentication requirements, with the gRPC
+// server started by Run.
+func RunGateway(conn db.Conn, listenAddr string, runningOnDaemon bool,
+	creds certs.Credentials) error {
+
+	config, err := creds.ServerConfig()
+	if err != nil {
+		return err
+	}
+
+	sock, err := tls.Listen("tcp", listenAddr, config)
+	if err != nil {
+		return err
+	}
+
+	return http.Serve(sock, newGateway(server{conn, runningOnDaemon, creds, nil}))
+}
+
+// newGateway creates a handler for each RPC of 'apiServer'.  Unary RPCs are found
+// by reflection, so they need no changes here when they're added to api/pb.
+// Streaming RPCs need handlers of their own, and those without one are reported as
+// unsupported rather than missing.
+func newGateway(apiServer pb.APIServer) http.Handler {
+	streamHandlers := map[string]http.Handler{
+		"Watch": watchHandler(apiServer),
+		"Logs":  logsHandler(apiServer),
+	}
+
+	mux := http.NewServeMux()
+	apiType := reflect.TypeOf((*pb.APIServer)(nil)).Elem()
+	apiValue := reflect.ValueOf(apiServer)
+	for i := 0; i < apiType.NumMethod(); i++ {
+		method := apiType.Method(i)
+		path := gatewayPrefix + method.Name
+		if handler, ok := streamHandlers[method.Name]; ok {
+			mux.Handle(path, handler)
+		} else if isUnary(method.Type) {
+			mux.Handle(path, unaryHandler(apiValue.MethodByName(method.Name)))
+		} else {
+			mux.Handle(path, unsupportedHandler(method.Name))
+		}
+	}
+	return mux
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// isUnary returns whether 'method' has the signature of a unary RPC, i.e.
+// func(context.Context, *Request) (*Reply, error).
+func isUnary(method reflect.Type) bool {
+	return method.NumIn() == 2 && method.In(0) == contextType &&
+		method.In(1).Kind() == reflect.Ptr &&
+		method.NumOut() == 2 && method.Out(1) == errorType
+}
+
+func unaryHandler(method reflect.Value) http.HandlerFunc {
+	reqType := method.Type().In(1).Elem()
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := reflect.New(reqType)
+		if !decodeRequest(w, r, req.Interface()) {
+			return
+		}
+
+		ctx := reflect.ValueOf(requestContext(r))
+		ret := method.Call([]reflect.Value{ctx, req})
+		if err, _ := ret[1].Interface().(error); err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ret[0].Interface())
+	}
+}
+
+// unsupportedHandler reports that the RPC 'name' can't be reached through the
+// gateway.  This is the case for Exec, whose client streams its standard input.
+func unsupportedHandler(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf(
+			"%s is a streaming RPC that the HTTP gateway doesn't support, "+
+				"use the gRPC API instead", name))
+	}
+}
+
+// watchHandler streams the replies of the Watch RPC as newline delimited JSON.
+func watchHandler(apiServer pb.APIServer) http.HandlerFunc {
+	return streamHandler(func() interface{} { return &pb.DBQuery{} },
+		func(req interface{}, stream *httpStream) error {
+			return apiServer.Watch(req.(*pb.DBQuery), httpWatchServer{stream})
+		})
+}
+
+// logsHandler streams the output of the Logs RPC as newline delimited JSON.
+func logsHandler(apiServer pb.APIServer) http.HandlerFunc {
+	return streamHandler(func() interface{} { return &pb.LogsRequest{} },
+		func(req interface{}, stream *httpStream) error {
+			return apiServer.Logs(req.(*pb.LogsRequest),
+				httpLogsServer{stream})
+		})
+}
+
+// streamHandler serves an RPC that streams its replies.  The request is decoded into
+// the message returned by 'newRequest', and passed to 'call' along with the stream
+// to which the replies are written.
+func streamHandler(newRequest func() interface{},
+	call func(interface{}, *httpStream) error) http.HandlerFunc {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := newRequest()
+		if !decodeRequest(w, r, req) {
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported",
+				http.StatusInternalServerError)
+			return
+		}
+
+		stream := &httpStream{ctx: requestContext(r), w: w, flusher: flusher}
+		err := call(req, stream)
+		if err != nil && !stream.sent {
+			writeError(w, errorStatus(err), err)
+		} else if err != nil {
+			log.WithError(err).Debug("HTTP stream failed")
+		}
+	}
+}
+
+// httpStream implements the stream of an RPC by writing each reply to an HTTP
+// response as a line of JSON.
+type httpStream struct {
+	ctx     context.Context
+	w       http.ResponseWriter
+	flusher http.Flusher
+	sent    bool
+
+	grpc.ServerStream
+}
+
+func (s *httpStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *httpStream) send(reply interface{}) error {
+	if !s.sent {
+		s.w.Header().Set("Content-Type", "application/x-ndjson")
+		s.sent = true
+	}
+
+	if err := json.NewEncoder(s.w).Encode(reply); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+type httpWatchServer struct {
+	*httpStream
+}
+
+func (s httpWatchServer) Send(reply *pb.QueryReply) error {
+	return s.send(reply)
+}
+
+type httpLogsServer struct {
+	*httpStream
+}
+
+func (s httpLogsServer) Send(output *pb.Output) error {
+	return s.send(output)
+}
+
+// requestContext returns the context in which the RPC requested by 'r' runs.
+func requestContext(r *http.Request) context.Context {
+	return withNamespace(r.Context(), r.Header.Get(namespaceHeader))
+}
+
+// decodeRequest decodes the JSON body of 'r' into 'req'.  Requests without a body,
+// such as GETs, leave 'req' zero.  If the request can't be decoded, an error is
+// written to 'w' and false is returned.
+func decodeRequest(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+// errorStatus returns the HTTP status code describing an error returned by an RPC.
+func errorStatus(err error) int {
+	switch err.(type) {
+	case unrecognizedTableError, unknownFieldError:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		Error string
+	}{err.Error()})
+}
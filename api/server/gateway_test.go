@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quilt/quilt/api/pb"
+	"github.com/quilt/quilt/db"
+	"github.com/quilt/quilt/version"
+)
+
+func gatewayRequest(t *testing.T, gateway http.Handler, method, path,
+	body string) *httptest.ResponseRecorder {
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	gateway.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestGateway(t *testing.T) {
+	t.Parallel()
+
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		m := view.InsertMachine()
+		m.PublicIP = "8.8.8.8"
+		view.Commit(m)
+		return nil
+	})
+	gateway := newGateway(server{conn: conn})
+
+	resp := gatewayRequest(t, gateway, "GET", "/v1/Version", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+
+	var versionReply pb.VersionReply
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &versionReply))
+	assert.Equal(t, version.Version, versionReply.Version)
+
+	resp = gatewayRequest(t, gateway, "POST", "/v1/Query",
+		`{"Table":"db.Machine","Fields":["PublicIP"]}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var queryReply pb.QueryReply
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &queryReply))
+	assert.Equal(t, `[{"PublicIP":"8.8.8.8"}]`, queryReply.TableContents)
+
+	// Errors in the request are the client's fault.
+	resp = gatewayRequest(t, gateway, "POST", "/v1/Query", `{"Table":"foo"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, `{"Error":"unrecognized table: foo"}`+"\n", resp.Body.String())
+
+	resp = gatewayRequest(t, gateway, "POST", "/v1/Query", `{"Table":`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = gatewayRequest(t, gateway, "DELETE", "/v1/Query", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+
+	resp = gatewayRequest(t, gateway, "GET", "/v1/Foo", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// Errors from the handlers are reported as server errors.
+	resp = gatewayRequest(t, gateway, "POST", "/v1/Deploy",
+		`{"Deployment":"bad"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
+func TestGatewayNamespace(t *testing.T) {
+	t.Parallel()
+
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		for _, ns := range []string{"ns1", "ns2"} {
+			m := view.InsertMachine()
+			m.Namespace = ns
+			m.PublicIP = ns + "-ip"
+			view.Commit(m)
+		}
+		return nil
+	})
+	gateway := newGateway(server{conn: conn, runningOnDaemon: true})
+
+	req := httptest.NewRequest("POST", "/v1/Query", strings.NewReader(
+		`{"Table":"db.Machine","Fields":["PublicIP"]}`))
+	req.Header.Set("Quilt-Namespace", "ns2")
+	resp := httptest.NewRecorder()
+	gateway.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var queryReply pb.QueryReply
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &queryReply))
+	assert.Equal(t, `[{"PublicIP":"ns2-ip"}]`, queryReply.TableContents)
+}
+
+func TestGatewayWatch(t *testing.T) {
+	t.Parallel()
+
+	conn := db.New()
+	httpServer := httptest.NewServer(newGateway(server{conn: conn}))
+	defer httpServer.Close()
+
+	resp, err := http.Post(httpServer.URL+"/v1/Watch", "application/json",
+		strings.NewReader(`{"Table":"db.Machine","Fields":["PublicIP"]}`))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	replies := bufio.NewScanner(resp.Body)
+	assert.True(t, replies.Scan())
+	assert.Equal(t, `{"TableContents":"[]"}`, replies.Text())
+
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		m := view.InsertMachine()
+		m.PublicIP = "8.8.8.8"
+		view.Commit(m)
+		return nil
+	})
+
+	assert.True(t, replies.Scan())
+	assert.Equal(t, `{"TableContents":"[{\"PublicIP\":\"8.8.8.8\"}]"}`,
+		replies.Text())
+
+	resp, err = http.Post(httpServer.URL+"/v1/Watch", "application/json",
+		strings.NewReader(`{"Table":"foo"}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGatewayLogs(t *testing.T) {
+	t.Parallel()
+
+	s, md, dockerID := newMinionServer(t)
+	md.ContainerLogs[dockerID] = "logs"
+	gateway := newGateway(s)
+
+	resp := gatewayRequest(t, gateway, "POST", "/v1/Logs", `{"StitchID":"stitchID"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/x-ndjson", resp.Header().Get("Content-Type"))
+
+	var output pb.Output
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &output))
+	assert.Equal(t, pb.Output{Stdout: []byte("logs")}, output)
+
+	resp = gatewayRequest(t, gateway, "POST", "/v1/Logs", `{"StitchID":"missing"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, `{"Error":"container missing is not running on this machine"}`+
+		"\n", resp.Body.String())
+}
+
+func TestGatewayUnsupported(t *testing.T) {
+	t.Parallel()
+
+	gateway := newGateway(server{conn: db.New()})
+
+	// Exec streams its standard input from the client, which a single HTTP request
+	// can't do, so it's reported as unsupported rather than missing.
+	resp := gatewayRequest(t, gateway, "POST", "/v1/Exec",
+		`{"StitchID":"stitchID","Command":["ls"]}`)
+	assert.Equal(t, http.StatusNotImplemented, resp.Code)
+	assert.Equal(t, `{"Error":"Exec is a streaming RPC that the HTTP gateway `+
+		`doesn't support, use the gRPC API instead"}`+"\n", resp.Body.String())
+}
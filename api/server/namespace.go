@@ -0,0 +1,80 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/quilt/quilt/api"
+	"github.com/quilt/quilt/api/pb"
+	"github.com/quilt/quilt/db"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+var errAmbiguousNamespace = errors.New(
+	"the daemon manages several namespaces, so one must be chosen")
+
+// requestNamespace returns the namespace named in the metadata of 'ctx', or "" if
+// the request doesn't target a particular namespace.
+func requestNamespace(ctx context.Context) string {
+	md, ok := metadata.FromContext(ctx)
+	if !ok || len(md[api.NamespaceKey]) == 0 {
+		return ""
+	}
+	return md[api.NamespaceKey][0]
+}
+
+// withNamespace returns a copy of 'ctx' whose requests target 'namespace'.  If
+// 'namespace' is empty, 'ctx' is returned unchanged.
+func withNamespace(ctx context.Context, namespace string) context.Context {
+	if namespace == "" {
+		return ctx
+	}
+	return metadata.NewContext(ctx, metadata.Pairs(api.NamespaceKey, namespace))
+}
+
+// resolveNamespace returns the namespace targeted by the request in 'ctx'.  Requests
+// that don't name a namespace target the only one managed by the daemon.
+func (s server) resolveNamespace(ctx context.Context) (string, error) {
+	if namespace := requestNamespace(ctx); namespace != "" {
+		return namespace, nil
+	}
+
+	namespaces := s.conn.GetClusterNamespaces()
+	switch len(namespaces) {
+	case 0:
+		return "", nil
+	case 1:
+		return namespaces[0], nil
+	default:
+		return "", errAmbiguousNamespace
+	}
+}
+
+// selectMachines returns the machines of the namespace targeted by the request in
+// 'ctx'.
+func (s server) selectMachines(ctx context.Context) ([]db.Machine, error) {
+	namespace, err := s.resolveNamespace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.conn.SelectFromMachine(func(m db.Machine) bool {
+		return m.Namespace == namespace
+	}), nil
+}
+
+// namespaceFilters restricts queries of the daemon's tables to the namespace named
+// in 'ctx', if any.
+func namespaceFilters(ctx context.Context, filters []*pb.Filter) []*pb.Filter {
+	namespace := requestNamespace(ctx)
+	if namespace == "" {
+		return filters
+	}
+
+	return append(filters, &pb.Filter{
+		Field: "Namespace",
+		Op:    pb.Filter_EQUAL,
+		Value: namespace,
+	})
+}
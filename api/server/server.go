@@ -14,11 +14,14 @@ import (
 	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
 	"github.com/quilt/quilt/api/pb"
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
+	"github.com/quilt/quilt/minion/docker"
 	"github.com/quilt/quilt/stitch"
 	"github.com/quilt/quilt/version"
 
 	"github.com/docker/distribution/reference"
+	"github.com/satori/go.uuid"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 
@@ -31,23 +34,40 @@ type server struct {
 	// The API server runs in two locations:  on minions in the cluster, and on
 	// the daemon. When the server is running on the daemon, we automatically
 	// proxy certain Queries to the cluster because the daemon doesn't track
-	// those tables (e.g. Container, Connection, Label).
+	// those tables (e.g. Container, Connection, Label, Image).
 	runningOnDaemon bool
+
+	// The credentials with which the server authenticates itself, and with which
+	// it connects to other API servers when proxying requests.
+	creds certs.Credentials
+
+	// The client of the local Docker daemon, through which containers' logs are
+	// fetched and commands run in them.  It's nil on the daemon.
+	dk *docker.Client
 }
 
 // Run starts a server that responds to `quiltctl` connections. It runs on both
 // the daemon and on the minion. The server provides various client-relevant
 // methods, such as starting deployments, and querying the state of the system.
 // This is in contrast to the minion server (minion/pb/pb.proto), which facilitates
-// the actual deployment.
-func Run(conn db.Conn, listenAddr string, runningOnDaemon bool) error {
+// the actual deployment.  Clients must authenticate with a certificate signed by the
+// certificate authority in `creds`.  On minions, `dk` is used to serve requests for
+// the logs of, and to run commands in, local containers.
+func Run(conn db.Conn, listenAddr string, runningOnDaemon bool,
+	creds certs.Credentials, dk *docker.Client) error {
+
 	proto, addr, err := api.ParseListenAddress(listenAddr)
 	if err != nil {
 		return err
 	}
 
+	tlsOpt, err := creds.ServerOption()
+	if err != nil {
+		return err
+	}
+
 	var sock net.Listener
-	apiServer := server{conn, runningOnDaemon}
+	apiServer := server{conn, runningOnDaemon, creds, dk}
 	for {
 		sock, err = net.Listen(proto, addr)
 
@@ -69,7 +89,7 @@ func Run(conn db.Conn, listenAddr string, runningOnDaemon bool) error {
 		os.Exit(0)
 	}(sigc)
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(tlsOpt)
 	pb.RegisterAPIServer(s, apiServer)
 	s.Serve(sock)
 
@@ -80,15 +100,19 @@ func Run(conn db.Conn, listenAddr string, runningOnDaemon bool) error {
 // returns the requested table from its local database. If in daemon mode,
 // Query proxies certain table requests (e.g. Container and Connection) to the
 // cluster. This is necessary because some tables are only used on the minions,
-// and aren't synced back to the daemon.
+// and aren't synced back to the daemon.  In either mode, the rows are filtered and
+// projected according to the query before being returned.
 func (s server) Query(cts context.Context, query *pb.DBQuery) (*pb.QueryReply, error) {
 	var rows interface{}
 	var err error
 
 	table := db.TableType(query.Table)
-	if s.runningOnDaemon {
-		rows, err = queryFromDaemon(table, s.conn)
-	} else {
+	switch {
+	case s.runningOnDaemon:
+		rows, err = s.queryFromDaemon(cts, table, query.Filters, query.Fields)
+	case table == db.ContainerTable:
+		rows = queryContainers(s.conn, query.Filters)
+	default:
 		rows, err = queryLocal(table, s.conn)
 	}
 
@@ -96,6 +120,11 @@ func (s server) Query(cts context.Context, query *pb.DBQuery) (*pb.QueryReply, e
 		return nil, err
 	}
 
+	rows, err = selectRows(rows, query.Filters, query.Fields)
+	if err != nil {
+		return nil, err
+	}
+
 	json, err := json.Marshal(rows)
 	if err != nil {
 		return nil, err
@@ -104,6 +133,228 @@ func (s server) Query(cts context.Context, query *pb.DBQuery) (*pb.QueryReply, e
 	return &pb.QueryReply{TableContents: string(json)}, nil
 }
 
+// watchInterval bounds how long a Watch goes without checking for changes that its
+// triggers may have missed.  Watches of local tables query them again this often
+// even if their trigger doesn't fire, and the daemon waits this long before
+// reconnecting to a cluster whose Watch failed, if its machines don't change in the
+// meantime.
+const watchInterval = 30 * time.Second
+
+// Watch streams the contents of the requested table, as returned by Query, each time
+// it changes.  Tables in the local database are watched with a db Trigger.  Tables
+// that the daemon proxies to the cluster are watched by relaying the cluster's own
+// Watch streams.
+func (s server) Watch(query *pb.DBQuery, stream pb.API_WatchServer) error {
+	table := db.TableType(query.Table)
+	if !isTable(table) {
+		return unrecognizedTableError(table)
+	}
+
+	if s.runningOnDaemon && !isDaemonTable(table) {
+		return s.watchCluster(query, stream)
+	}
+
+	trigger := s.conn.TriggerTick(int(watchInterval.Seconds()), table)
+	defer trigger.Stop()
+
+	var lastContents string
+	for {
+		select {
+		case <-trigger.C:
+		case <-stream.Context().Done():
+			return nil
+		}
+
+		reply, err := s.Query(stream.Context(), query)
+		if err != nil {
+			return err
+		}
+
+		if reply.TableContents == lastContents {
+			continue
+		}
+		lastContents = reply.TableContents
+
+		if err := stream.Send(reply); err != nil {
+			return err
+		}
+	}
+}
+
+// watchCluster streams a table that the daemon proxies to the cluster by relaying
+// the leader's Watch stream, along with those of the workers for the Container
+// table, whose statuses are merged in as by Query.  The streams are reopened when the
+// daemon's machines change, as the leader or the workers may have changed with them.
+func (s server) watchCluster(query *pb.DBQuery, stream pb.API_WatchServer) error {
+	trigger := s.conn.Trigger(db.MachineTable)
+	defer trigger.Stop()
+
+	var lastContents string
+	for stream.Context().Err() == nil {
+		if err := s.relayCluster(query, stream, trigger.C,
+			&lastContents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A clusterUpdate is the contents of a table sent by the Watch stream of one of the
+// clients relayed by relayCluster.
+type clusterUpdate struct {
+	client int
+	rows   interface{}
+}
+
+// relayCluster relays the Watch streams of the cluster until `restart` fires, the
+// stream ends, or sending to it fails.  Failures to watch the cluster aren't
+// returned, as the cluster may not be up yet.  Instead, relayCluster waits for the
+// machines to change, or for watchInterval, before returning.
+func (s server) relayCluster(query *pb.DBQuery, stream pb.API_WatchServer,
+	restart <-chan struct{}, lastContents *string) error {
+
+	ctx := stream.Context()
+	table := db.TableType(query.Table)
+	wait := func(err error) error {
+		log.WithError(err).WithField("table", table).Debug(
+			"Failed to watch the cluster")
+		select {
+		case <-restart:
+		case <-time.After(watchInterval):
+		case <-ctx.Done():
+		}
+		return nil
+	}
+
+	clients, err := s.clusterClients(ctx, table == db.ContainerTable)
+	if err != nil {
+		return wait(err)
+	}
+	defer func() {
+		for _, c := range clients {
+			c.Close()
+		}
+	}()
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	updates := make(chan clusterUpdate)
+	errs := make(chan error, len(clients))
+	for i, c := range clients {
+		go func(i int, c client.Client) {
+			errs <- c.Watch(table, stop, func(rows interface{}) {
+				select {
+				case updates <- clusterUpdate{i, rows}:
+				case <-stop:
+				}
+			})
+		}(i, c)
+	}
+
+	// The leader's rows are first, followed by those of the workers.
+	latest := make([]interface{}, len(clients))
+	for {
+		select {
+		case update := <-updates:
+			latest[update.client] = update.rows
+		case err := <-errs:
+			if err == nil {
+				err = errors.New("watch ended")
+			}
+			return wait(err)
+		case <-restart:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+
+		rows := latest[0]
+		if rows == nil {
+			continue
+		}
+
+		if table == db.ContainerTable {
+			var workerContainers []db.Container
+			for _, wc := range latest[1:] {
+				dbcs, _ := wc.([]db.Container)
+				workerContainers = append(workerContainers, dbcs...)
+			}
+			rows = updateLeaderContainerAttrs(rows.([]db.Container),
+				workerContainers)
+		}
+
+		rows, err := selectRows(rows, query.Filters, query.Fields)
+		if err != nil {
+			return err
+		}
+
+		contents, err := json.Marshal(rows)
+		if err != nil {
+			return err
+		}
+
+		if string(contents) == *lastContents {
+			continue
+		}
+		*lastContents = string(contents)
+
+		err = stream.Send(&pb.QueryReply{TableContents: string(contents)})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// clusterClients connects to the leader of the cluster of the namespace targeted by
+// the request in `ctx`, and if `withWorkers` is true, to its workers as well.  The
+// leader's client is first.
+func (s server) clusterClients(ctx context.Context, withWorkers bool) (
+	[]client.Client, error) {
+
+	machines, err := s.selectMachines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	leaderClient, err := newLeaderClient(machines, s.creds)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := []client.Client{leaderClient}
+	for _, m := range machines {
+		if !withWorkers || m.PublicIP == "" || m.Role != db.Worker {
+			continue
+		}
+
+		c, err := newClient(api.RemoteAddress(m.PublicIP), s.creds)
+		if err != nil {
+			for _, c := range clients {
+				c.Close()
+			}
+			return nil, err
+		}
+		clients = append(clients, c)
+	}
+	return clients, nil
+}
+
+func isTable(table db.TableType) bool {
+	for _, t := range db.AllTables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+type unrecognizedTableError db.TableType
+
+func (err unrecognizedTableError) Error() string {
+	return fmt.Sprintf("unrecognized table: %s", string(err))
+}
+
 func queryLocal(table db.TableType, conn db.Conn) (interface{}, error) {
 	switch table {
 	case db.MachineTable:
@@ -118,38 +369,216 @@ func queryLocal(table db.TableType, conn db.Conn) (interface{}, error) {
 		return conn.SelectFromLabel(nil), nil
 	case db.ClusterTable:
 		return conn.SelectFromCluster(nil), nil
+	case db.ImageTable:
+		return conn.SelectFromImage(nil), nil
+	case db.PlacementTable:
+		return conn.SelectFromPlacement(nil), nil
+	case db.HostnameTable:
+		return conn.SelectFromHostname(nil), nil
+	case db.ACLTable:
+		return conn.SelectFromACL(nil), nil
+	case db.MinionTable:
+		return conn.SelectFromMinion(nil), nil
+	case db.SecretTable:
+		return redactSecrets(conn.SelectFromSecret(nil)), nil
 	default:
-		return nil, fmt.Errorf("unrecognized table: %s", table)
+		return nil, unrecognizedTableError(table)
 	}
 }
 
-func queryFromDaemon(table db.TableType, conn db.Conn) (
-	interface{}, error) {
+// queryContainers returns the containers that may match `filters`.  An equality
+// filter on an indexed field is answered from the index rather than by scanning the
+// table, and selectRows applies the rest of the filters.
+func queryContainers(conn db.Conn, filters []*pb.Filter) []db.Container {
+	for _, filter := range filters {
+		if filter.Op != pb.Filter_EQUAL {
+			continue
+		}
 
-	switch table {
-	case db.MachineTable, db.ClusterTable:
-		return queryLocal(table, conn)
+		switch filter.Field {
+		case "StitchID":
+			return conn.SelectFromContainerByStitchID(filter.Value)
+		case "Minion":
+			return conn.SelectFromContainerByMinion(filter.Value)
+		}
+	}
+	return conn.SelectFromContainer(nil)
+}
+
+// daemonTables are the tables maintained by the daemon itself.  The rest are only
+// populated on the minions, so the daemon proxies queries for them to the cluster.
+var daemonTables = map[db.TableType]struct{}{
+	db.MachineTable: {},
+	db.ClusterTable: {},
+	db.ACLTable:     {},
+}
+
+func isDaemonTable(table db.TableType) bool {
+	_, ok := daemonTables[table]
+	return ok
+}
+
+// queryFromDaemon returns the contents of 'table' in the namespace targeted by the
+// request in 'ctx'.  Requests that don't name a namespace get the daemon's tables in
+// full.  The filters and fields are forwarded to the cluster so that only the rows
+// and fields requested are sent back, although the caller must still apply them to
+// the rows of the daemon's own tables.
+func (s server) queryFromDaemon(ctx context.Context, table db.TableType,
+	filters []*pb.Filter, fields []string) (interface{}, error) {
+
+	if !isTable(table) {
+		return nil, unrecognizedTableError(table)
+	}
+
+	if isDaemonTable(table) {
+		rows, err := queryLocal(table, s.conn)
+		if err != nil {
+			return nil, err
+		}
+		return selectRows(rows, namespaceFilters(ctx, nil), nil)
+	}
+
+	machines, err := s.selectMachines(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	var leaderClient client.Client
-	leaderClient, err := newLeaderClient(conn.SelectFromMachine(nil))
+	leaderClient, err = newLeaderClient(machines, s.creds)
 	if err != nil {
 		return nil, err
 	}
 	defer leaderClient.Close()
 
-	switch table {
-	case db.ContainerTable:
-		return getClusterContainers(conn, leaderClient)
-	case db.ConnectionTable:
-		return leaderClient.QueryConnections()
-	case db.LabelTable:
-		return leaderClient.QueryLabels()
-	default:
-		return nil, fmt.Errorf("unrecognized table: %s", table)
+	// The leader doesn't know the status of containers, so it's merged in from
+	// the workers.
+	if table == db.ContainerTable {
+		return getClusterContainers(machines, leaderClient, s.creds, filters,
+			fields)
+	}
+	return leaderClient.Query(table, apiFilters(filters),
+		forwardedFields(fields, filters))
+}
+
+var apiFilterOps = map[pb.Filter_Op]api.FilterOp{
+	pb.Filter_EQUAL:    api.EqualOp,
+	pb.Filter_PREFIX:   api.PrefixOp,
+	pb.Filter_CONTAINS: api.ContainsOp,
+}
+
+func apiFilters(filters []*pb.Filter) []api.Filter {
+	var res []api.Filter
+	for _, f := range filters {
+		res = append(res, api.Filter{
+			Field: f.Field,
+			Op:    apiFilterOps[f.Op],
+			Value: f.Value,
+		})
+	}
+	return res
+}
+
+// forwardedFields returns the fields to request from the cluster for a query that
+// projects 'fields'.  The filtered fields are requested as well because the rows
+// are filtered again once they reach the daemon.
+func forwardedFields(fields []string, filters []*pb.Filter) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var res []string
+	seen := map[string]struct{}{}
+	add := func(field string) {
+		if _, ok := seen[field]; !ok {
+			seen[field] = struct{}{}
+			res = append(res, field)
+		}
+	}
+
+	for _, field := range fields {
+		add(field)
+	}
+	for _, f := range filters {
+		add(f.Field)
+	}
+	return res
+}
+
+// QueryHistory returns the recorded revisions of the requested table, or of all
+// tables if none is given.  If 'ID' is non-zero, only the revisions of that row are
+// returned.  Like Query, the daemon proxies requests for tables it doesn't track to
+// the leader of the cluster.  Requests for a row of any table are answered by the
+// daemon if it has the row, and by the leader otherwise.
+func (s server) QueryHistory(cts context.Context, query *pb.HistoryQuery) (
+	*pb.QueryReply, error) {
+
+	table := db.TableType(query.Table)
+	id := int(query.ID)
+
+	var revs []db.Revision
+	if !s.runningOnDaemon || table == "" || isDaemonTable(table) {
+		revs = s.conn.SelectFromHistory(func(rev db.Revision) bool {
+			return (table == "" || rev.Table == table) &&
+				(id == 0 || rev.ID == id)
+		})
 	}
+
+	// Rows that the daemon doesn't have may be tracked by the cluster.
+	proxy := table != "" && !isDaemonTable(table) ||
+		table == "" && id != 0 && len(revs) == 0
+	if s.runningOnDaemon && proxy {
+		machines, err := s.selectMachines(cts)
+		if err != nil {
+			return nil, err
+		}
+
+		leaderClient, err := newLeaderClient(machines, s.creds)
+		if err != nil {
+			return nil, err
+		}
+		defer leaderClient.Close()
+
+		if revs, err = leaderClient.QueryHistory(table, id); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, rev := range revs {
+		if rev.Table != db.SecretTable {
+			continue
+		}
+
+		if secret, ok := rev.Old.(db.Secret); ok {
+			revs[i].Old = redactSecrets([]db.Secret{secret})[0]
+		}
+		if secret, ok := rev.New.(db.Secret); ok {
+			revs[i].New = redactSecrets([]db.Secret{secret})[0]
+		}
+	}
+
+	json, err := json.Marshal(revs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.QueryReply{TableContents: string(json)}, nil
 }
 
+// redactSecrets returns a copy of `secrets` without their values.  Although the
+// values are sealed, they're never needed outside of the cluster.
+func redactSecrets(secrets []db.Secret) []db.Secret {
+	var redacted []db.Secret
+	for _, secret := range secrets {
+		secret.Value = ""
+		redacted = append(redacted, secret)
+	}
+	return redacted
+}
+
+// Deploy commits the given blueprint to the cluster of its namespace, and returns
+// without waiting for it to be implemented.  The clusters of other namespaces are
+// unaffected.  The returned ID may be passed to
+// DeploymentStatus to track the deployment's progress.
 func (s server) Deploy(cts context.Context, deployReq *pb.DeployRequest) (
 	*pb.DeployReply, error) {
 
@@ -158,6 +587,15 @@ func (s server) Deploy(cts context.Context, deployReq *pb.DeployRequest) (
 		return &pb.DeployReply{}, err
 	}
 
+	if err := stitch.Validate(); err != nil {
+		return &pb.DeployReply{}, err
+	}
+
+	if deployReq.Stop && len(stitch.Machines) != 0 {
+		return &pb.DeployReply{},
+			errors.New("stopped blueprints can't have machines")
+	}
+
 	for _, c := range stitch.Containers {
 		if _, err := reference.ParseAnyReference(c.Image.Name); err != nil {
 			return &pb.DeployReply{}, fmt.Errorf("could not parse "+
@@ -165,13 +603,23 @@ func (s server) Deploy(cts context.Context, deployReq *pb.DeployRequest) (
 		}
 	}
 
+	var deploymentID string
 	err = s.conn.Txn(db.ClusterTable).Run(func(view db.Database) error {
-		cluster, err := view.GetCluster()
+		cluster, err := view.GetCluster(stitch.Namespace)
 		if err != nil {
 			cluster = view.InsertCluster()
+			cluster.Namespace = stitch.Namespace
 		}
 
-		cluster.Blueprint = stitch.String()
+		// Redeploying an unchanged blueprint continues the existing deployment.
+		blueprint := stitch.String()
+		if cluster.Blueprint != blueprint || cluster.Stopped != deployReq.Stop ||
+			cluster.DeploymentID == "" {
+			cluster.Blueprint = blueprint
+			cluster.Stopped = deployReq.Stop
+			cluster.DeploymentID = uuid.NewV4().String()
+		}
+		deploymentID = cluster.DeploymentID
 		view.Commit(cluster)
 		return nil
 	})
@@ -189,7 +637,60 @@ func (s server) Deploy(cts context.Context, deployReq *pb.DeployRequest) (
 		}
 	}
 
-	return &pb.DeployReply{}, nil
+	return &pb.DeployReply{ID: deploymentID}, nil
+}
+
+// SetSecret sets the sealed value of a secret.  The daemon forwards the request to the
+// leader of the cluster of the request's namespace, which syncs the secret to the
+// workers through Etcd.
+func (s server) SetSecret(ctx context.Context, req *pb.SecretRequest) (
+	*pb.SecretReply, error) {
+
+	if req.Name == "" {
+		return nil, errors.New("missing secret name")
+	}
+
+	if s.runningOnDaemon {
+		machines, err := s.selectMachines(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		leaderClient, err := newLeaderClient(machines, s.creds)
+		if err != nil {
+			return nil, err
+		}
+		defer leaderClient.Close()
+
+		if err := leaderClient.SetSecret(req.Name, req.Value); err != nil {
+			return nil, err
+		}
+		return &pb.SecretReply{}, nil
+	}
+
+	// Only the leader writes secrets to Etcd, so those set on other minions would
+	// be overwritten.
+	if !s.conn.EtcdLeader() {
+		return nil, errors.New("secrets can only be set on the leader")
+	}
+
+	s.conn.Txn(db.SecretTable).Run(func(view db.Database) error {
+		var secret db.Secret
+		secrets := view.SelectFromSecret(func(secret db.Secret) bool {
+			return secret.Name == req.Name
+		})
+		if len(secrets) == 0 {
+			secret = view.InsertSecret()
+			secret.Name = req.Name
+		} else {
+			secret = secrets[0]
+		}
+
+		secret.Value = req.Value
+		view.Commit(secret)
+		return nil
+	})
+	return &pb.SecretReply{}, nil
 }
 
 func (s server) Version(_ context.Context, _ *pb.VersionRequest) (
@@ -197,13 +698,70 @@ func (s server) Version(_ context.Context, _ *pb.VersionRequest) (
 	return &pb.VersionReply{Version: version.Version}, nil
 }
 
-func getClusterContainers(conn db.Conn, leaderClient client.Client) (interface{}, error) {
-	leaderContainers, err := leaderClient.QueryContainers()
+// workerContainerFields are the container fields that are only known to the worker
+// running the container.  They're copied into the leader's rows by
+// updateLeaderContainerAttrs.
+var workerContainerFields = map[string]struct{}{
+	"Created":  {},
+	"DockerID": {},
+	"Status":   {},
+	"Health":   {},
+	"Restarts": {},
+	"ExitCode": {},
+}
+
+// getClusterContainers queries the leader for the containers matching 'filters',
+// and merges in the attributes known only to the workers if any were asked for.
+// Filters on worker attributes are left for the caller to apply.
+func getClusterContainers(machines []db.Machine, leaderClient client.Client,
+	creds certs.Credentials, filters []*pb.Filter, fields []string) (
+	interface{}, error) {
+
+	// If every field is requested, the worker attributes always are.
+	needWorkers := len(fields) == 0
+	var leaderFilters, workerFilters []*pb.Filter
+	for _, f := range filters {
+		if _, ok := workerContainerFields[f.Field]; ok {
+			needWorkers = true
+			continue
+		}
+
+		leaderFilters = append(leaderFilters, f)
+
+		// The leader rewrites the images of containers built in the cluster,
+		// so the workers' images may not match.
+		if f.Field != "Image" {
+			workerFilters = append(workerFilters, f)
+		}
+	}
+
+	var leaderFields, workerFields []string
+	if len(fields) != 0 {
+		// The StitchID is needed to match the workers' rows to the leader's.
+		workerFields = []string{"StitchID"}
+		fields = append([]string{"StitchID"}, fields...)
+		for _, field := range forwardedFields(fields, filters) {
+			if _, ok := workerContainerFields[field]; ok {
+				needWorkers = true
+				workerFields = append(workerFields, field)
+			} else {
+				leaderFields = append(leaderFields, field)
+			}
+		}
+	}
+
+	leaderContainers, err := queryContainerRows(leaderClient,
+		apiFilters(leaderFilters), leaderFields)
 	if err != nil {
 		return nil, err
 	}
 
-	workerContainers, err := queryWorkers(conn.SelectFromMachine(nil))
+	if !needWorkers {
+		return leaderContainers, nil
+	}
+
+	workerContainers, err := queryWorkers(machines, creds, workerFilters,
+		workerFields)
 	if err != nil {
 		return nil, err
 	}
@@ -211,14 +769,39 @@ func getClusterContainers(conn db.Conn, leaderClient client.Client) (interface{}
 	return updateLeaderContainerAttrs(leaderContainers, workerContainers), nil
 }
 
+func queryContainerRows(c client.Client, filters []api.Filter, fields []string) (
+	[]db.Container, error) {
+
+	rows, err := c.Query(db.ContainerTable, filters, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	containers, ok := rows.([]db.Container)
+	if !ok {
+		return nil, fmt.Errorf("unexpected container rows: %v", rows)
+	}
+	return containers, nil
+}
+
 type queryContainersResponse struct {
 	containers []db.Container
 	err        error
 }
 
 // queryWorkers gets a client for all worker machines and returns a list of
-// `db.Container`s on these machines.
-func queryWorkers(machines []db.Machine) ([]db.Container, error) {
+// `db.Container`s on these machines that match 'filters'.  Workers that can't be
+// running a container matching the filters aren't queried at all.
+func queryWorkers(machines []db.Machine, creds certs.Credentials,
+	filters []*pb.Filter, fields []string) ([]db.Container, error) {
+
+	minion := ""
+	for _, f := range filters {
+		if f.Field == "Minion" && f.Op == pb.Filter_EQUAL {
+			minion = f.Value
+		}
+	}
+
 	var wg sync.WaitGroup
 	queryResponses := make(chan queryContainersResponse, len(machines))
 	for _, m := range machines {
@@ -226,14 +809,19 @@ func queryWorkers(machines []db.Machine) ([]db.Container, error) {
 			continue
 		}
 
+		if minion != "" && m.PrivateIP != minion {
+			continue
+		}
+
 		wg.Add(1)
 		go func(m db.Machine) {
 			defer wg.Done()
 			var qContainers []db.Container
-			client, err := newClient(api.RemoteAddress(m.PublicIP))
+			client, err := newClient(api.RemoteAddress(m.PublicIP), creds)
 			if err == nil {
 				defer client.Close()
-				qContainers, err = client.QueryContainers()
+				qContainers, err = queryContainerRows(client,
+					apiFilters(filters), fields)
 			}
 			queryResponses <- queryContainersResponse{qContainers, err}
 		}(m)
@@ -270,6 +858,9 @@ func updateLeaderContainerAttrs(lContainers []db.Container, wContainers []db.Con
 			lc.Created = wc.Created
 			lc.DockerID = wc.DockerID
 			lc.Status = wc.Status
+			lc.Health = wc.Health
+			lc.Restarts = wc.Restarts
+			lc.ExitCode = wc.ExitCode
 		}
 		allContainers = append(allContainers, lc)
 	}
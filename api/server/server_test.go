@@ -1,20 +1,24 @@
 package server
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
 
 	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
 	"github.com/quilt/quilt/api/client/mocks"
 	"github.com/quilt/quilt/api/pb"
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/stitch"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func checkQuery(t *testing.T, s server, table db.TableType, exp string) {
@@ -27,15 +31,20 @@ func checkQuery(t *testing.T, s server, table db.TableType, exp string) {
 
 func TestQueryErrors(t *testing.T) {
 	// Invalid table type.
-	_, err := server{}.Query(context.Background(),
-		&pb.DBQuery{Table: string(db.HostnameTable)})
-	assert.EqualError(t, err, "unrecognized table: db.Hostname")
+	_, err := server{}.Query(context.Background(), &pb.DBQuery{Table: "foo"})
+	assert.EqualError(t, err, "unrecognized table: foo")
+
+	_, err = server{runningOnDaemon: true}.Query(context.Background(),
+		&pb.DBQuery{Table: "foo"})
+	assert.EqualError(t, err, "unrecognized table: foo")
 
 	// Error getting the leader client.
-	newLeaderClient = func(_ []db.Machine) (client.Client, error) {
+	newLeaderClient = func(_ []db.Machine, _ certs.Credentials) (
+		client.Client, error) {
+
 		return nil, errors.New("get leader error")
 	}
-	s := server{db.New(), true}
+	s := server{conn: db.New(), runningOnDaemon: true}
 	_, err = s.Query(context.Background(),
 		&pb.DBQuery{Table: string(db.ContainerTable)})
 	assert.EqualError(t, err, "get leader error")
@@ -57,12 +66,71 @@ func TestQueryMachinesDaemon(t *testing.T) {
 		return nil
 	})
 
-	exp := `[{"ID":1,"StitchID":"","Role":"Master","Provider":"Amazon","Region":"",` +
-		`"Size":"size","DiskSize":0,"SSHKeys":null,"FloatingIP":"",` +
+	exp := `[{"ID":1,"Namespace":"","StitchID":"","Role":"Master",` +
+		`"Provider":"Amazon","Region":"","Size":"size","DiskSize":0,` +
+		`"SSHKeys":null,"FloatingIP":"",` +
 		`"Preemptible":false,"CloudID":"","PublicIP":"8.8.8.8",` +
 		`"PrivateIP":"9.9.9.9","Connected":false}]`
 
-	checkQuery(t, server{conn, true}, db.MachineTable, exp)
+	checkQuery(t, server{conn: conn, runningOnDaemon: true}, db.MachineTable, exp)
+}
+
+func TestQueryNamespaces(t *testing.T) {
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		for _, ns := range []string{"ns1", "ns2"} {
+			clst := view.InsertCluster()
+			clst.Namespace = ns
+			view.Commit(clst)
+
+			m := view.InsertMachine()
+			m.Namespace = ns
+			m.PublicIP = ns + "-ip"
+			view.Commit(m)
+		}
+		return nil
+	})
+	s := server{conn: conn, runningOnDaemon: true}
+
+	// The daemon's own tables are filtered by the namespace of the request.
+	ctx := withNamespace(context.Background(), "ns1")
+	reply, err := s.Query(ctx, &pb.DBQuery{
+		Table:  string(db.MachineTable),
+		Fields: []string{"PublicIP"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"PublicIP":"ns1-ip"}]`, reply.TableContents)
+
+	reply, err = s.Query(context.Background(), &pb.DBQuery{
+		Table:  string(db.MachineTable),
+		Fields: []string{"PublicIP"}})
+	assert.NoError(t, err)
+	assert.Contains(t, []string{
+		`[{"PublicIP":"ns1-ip"},{"PublicIP":"ns2-ip"}]`,
+		`[{"PublicIP":"ns2-ip"},{"PublicIP":"ns1-ip"}]`,
+	}, reply.TableContents)
+
+	// Queries proxied to a cluster must choose a namespace.
+	_, err = s.Query(context.Background(),
+		&pb.DBQuery{Table: string(db.ImageTable)})
+	assert.Equal(t, errAmbiguousNamespace, err)
+
+	newLeaderClient = func(machines []db.Machine, _ certs.Credentials) (
+		client.Client, error) {
+
+		assert.Len(t, machines, 1)
+		assert.Equal(t, "ns2-ip", machines[0].PublicIP)
+
+		mc := new(mocks.Client)
+		mc.On("Query", db.ImageTable, []api.Filter(nil), []string{"Name"}).Return(
+			[]db.Image{{Name: "image"}}, nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	reply, err = s.Query(withNamespace(context.Background(), "ns2"),
+		&pb.DBQuery{Table: string(db.ImageTable), Fields: []string{"Name"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"Name":"image"}]`, reply.TableContents)
 }
 
 func TestQueryContainersCluster(t *testing.T) {
@@ -84,15 +152,56 @@ func TestQueryContainersCluster(t *testing.T) {
 		`"Labels":["labelA","labelB"],"Created":"0001-01-01T00:00:00Z",` +
 		`"Image":"image"}]`
 
-	checkQuery(t, server{conn, false}, db.ContainerTable, exp)
+	checkQuery(t, server{conn: conn, runningOnDaemon: false}, db.ContainerTable, exp)
+}
+
+func TestQueryContainersIndexed(t *testing.T) {
+	t.Parallel()
+
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		m := view.InsertMinion()
+		m.PrivateIP = "1.2.3.4"
+		view.Commit(m)
+
+		for _, id := range []string{"a", "b"} {
+			c := view.InsertContainer()
+			c.StitchID = id
+			c.Minion = "1.2.3.4"
+			view.Commit(c)
+		}
+		return nil
+	})
+	s := server{conn: conn}
+
+	reply, err := s.Query(context.Background(), &pb.DBQuery{
+		Table: string(db.ContainerTable),
+		Filters: []*pb.Filter{
+			{Field: "Minion", Op: pb.Filter_EQUAL, Value: "1.2.3.4"},
+			{Field: "StitchID", Op: pb.Filter_EQUAL, Value: "b"},
+		},
+		Fields: []string{"StitchID"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"StitchID":"b"}]`, reply.TableContents)
+
+	reply, err = s.Query(context.Background(), &pb.DBQuery{
+		Table: string(db.ContainerTable),
+		Filters: []*pb.Filter{
+			{Field: "Minion", Op: pb.Filter_EQUAL, Value: "5.6.7.8"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, reply.TableContents)
 }
 
 func TestQueryContainersDaemon(t *testing.T) {
-	newClient = func(host string) (client.Client, error) {
+	newClient = func(host string, _ certs.Credentials) (client.Client, error) {
 		switch host {
 		case api.RemoteAddress("9.9.9.9"):
 			mc := new(mocks.Client)
-			mc.On("QueryContainers").Return([]db.Container{{
+			mc.On("Query", db.ContainerTable, []api.Filter(nil),
+				[]string(nil)).Return([]db.Container{{
 				StitchID: "onWorker",
 				Image:    "shouldIgnore",
 				DockerID: "dockerID",
@@ -105,9 +214,12 @@ func TestQueryContainersDaemon(t *testing.T) {
 		panic("unreached")
 	}
 
-	newLeaderClient = func(_ []db.Machine) (client.Client, error) {
+	newLeaderClient = func(_ []db.Machine, _ certs.Credentials) (
+		client.Client, error) {
+
 		mc := new(mocks.Client)
-		mc.On("QueryContainers").Return([]db.Container{{
+		mc.On("Query", db.ContainerTable, []api.Filter(nil),
+			[]string(nil)).Return([]db.Container{{
 			StitchID: "notScheduled",
 			Image:    "notScheduled",
 		}, {
@@ -132,7 +244,348 @@ func TestQueryContainersDaemon(t *testing.T) {
 		`"Image":"notScheduled"},{"StitchID":"onWorker",` +
 		`"DockerID":"dockerID","Created":"0001-01-01T00:00:00Z",` +
 		`"Image":"onWorker"}]`
-	checkQuery(t, server{conn, true}, db.ContainerTable, exp)
+	checkQuery(t, server{conn: conn, runningOnDaemon: true}, db.ContainerTable, exp)
+}
+
+func TestQueryContainersDaemonForwarded(t *testing.T) {
+	var workers []string
+	newClient = func(host string, _ certs.Credentials) (client.Client, error) {
+		workers = append(workers, host)
+		mc := new(mocks.Client)
+		mc.On("Query", db.ContainerTable,
+			[]api.Filter{api.Equal("Minion", "10.0.0.1")},
+			[]string{"StitchID", "Status"}).Return([]db.Container{{
+			StitchID: "onWorker",
+			Status:   "running",
+		}}, nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	var leader *mocks.Client
+	newLeaderClient = func(_ []db.Machine, _ certs.Credentials) (
+		client.Client, error) {
+
+		leader = new(mocks.Client)
+		leader.On("Query", db.ContainerTable, []api.Filter{
+			api.Equal("Minion", "10.0.0.1"),
+			api.Prefix("Image", "nginx"),
+		}, []string{"StitchID", "Minion", "Image"}).Return([]db.Container{{
+			StitchID: "onWorker",
+			Minion:   "10.0.0.1",
+			Image:    "nginx",
+		}}, nil)
+		leader.On("Close").Return(nil)
+		return leader, nil
+	}
+
+	conn := db.New()
+	conn.Txn(db.MachineTable).Run(func(view db.Database) error {
+		for _, ip := range []string{"1.1.1.1", "2.2.2.2"} {
+			m := view.InsertMachine()
+			m.PublicIP = ip
+			m.PrivateIP = "10.0.0." + ip[:1]
+			m.Role = db.Worker
+			view.Commit(m)
+		}
+		return nil
+	})
+
+	s := server{conn: conn, runningOnDaemon: true}
+	reply, err := s.Query(context.Background(), &pb.DBQuery{
+		Table:  string(db.ContainerTable),
+		Fields: []string{"StitchID", "Status"},
+		Filters: []*pb.Filter{
+			{Field: "Minion", Op: pb.Filter_EQUAL, Value: "10.0.0.1"},
+			{Field: "Image", Op: pb.Filter_PREFIX, Value: "nginx"},
+			{Field: "Status", Op: pb.Filter_EQUAL, Value: "running"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"Status":"running","StitchID":"onWorker"}]`,
+		reply.TableContents)
+	leader.AssertExpectations(t)
+
+	// Only the worker the containers are filtered to is queried.
+	assert.Equal(t, []string{api.RemoteAddress("1.1.1.1")}, workers)
+}
+
+func TestQueryAllTables(t *testing.T) {
+	t.Parallel()
+
+	s := server{conn: db.New(), runningOnDaemon: false}
+	for _, table := range db.AllTables {
+		_, err := s.Query(context.Background(), &pb.DBQuery{Table: string(table)})
+		assert.NoError(t, err, "failed to query %s", table)
+	}
+}
+
+func TestQueryProxiedTables(t *testing.T) {
+	newLeaderClient = func(_ []db.Machine, _ certs.Credentials) (
+		client.Client, error) {
+
+		mc := new(mocks.Client)
+		mc.On("Query", db.ImageTable, []api.Filter(nil), []string(nil)).Return(
+			[]db.Image{{Name: "image", DockerID: "built"}}, nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		acl := view.InsertACL()
+		acl.Admin = []string{"local"}
+		view.Commit(acl)
+		return nil
+	})
+	s := server{conn: conn, runningOnDaemon: true}
+
+	checkQuery(t, s, db.ImageTable,
+		`[{"ID":0,"Name":"image","Dockerfile":"","DockerID":"built"}]`)
+	checkQuery(t, s, db.ACLTable,
+		`[{"ID":1,"Namespace":"","Admin":["local"],"ApplicationPorts":null}]`)
+}
+
+func TestQueryHistory(t *testing.T) {
+	conn := db.New()
+	conn.EnableHistory(10)
+	conn.WithTag("engine").Txn(db.AllTables...).Run(func(view db.Database) error {
+		view.InsertMachine()
+		view.InsertLabel()
+		return nil
+	})
+
+	// The daemon answers queries about its own tables itself.
+	s := server{conn: conn, runningOnDaemon: true}
+	reply, err := s.QueryHistory(context.Background(),
+		&pb.HistoryQuery{Table: string(db.MachineTable)})
+	assert.NoError(t, err)
+
+	var revs []db.Revision
+	assert.NoError(t, json.Unmarshal([]byte(reply.TableContents), &revs))
+	assert.Len(t, revs, 1)
+	assert.Equal(t, "engine", revs[0].Tag)
+	assert.Equal(t, db.MachineTable, revs[0].Table)
+
+	// Whereas queries about tables tracked by the minions are proxied.
+	exp := []db.Revision{{Tag: "scheduler", Table: db.ContainerTable, ID: 3}}
+	newLeaderClient = func(_ []db.Machine, _ certs.Credentials) (
+		client.Client, error) {
+
+		mc := new(mocks.Client)
+		mc.On("QueryHistory", db.ContainerTable, 3).Return(exp, nil)
+		mc.On("QueryHistory", db.TableType(""), 3).Return(exp, nil)
+		mc.On("QueryHistory", db.TableType(""), 100).Return(nil, nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+	reply, err = s.QueryHistory(context.Background(),
+		&pb.HistoryQuery{Table: string(db.ContainerTable), ID: 3})
+	assert.NoError(t, err)
+
+	revs = nil
+	assert.NoError(t, json.Unmarshal([]byte(reply.TableContents), &revs))
+	assert.Equal(t, exp, revs)
+
+	// As are queries about rows that the daemon doesn't have.
+	reply, err = s.QueryHistory(context.Background(), &pb.HistoryQuery{ID: 3})
+	assert.NoError(t, err)
+
+	revs = nil
+	assert.NoError(t, json.Unmarshal([]byte(reply.TableContents), &revs))
+	assert.Equal(t, exp, revs)
+
+	// Nothing matches a row ID that was never used.
+	reply, err = s.QueryHistory(context.Background(), &pb.HistoryQuery{ID: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, "null", reply.TableContents)
+}
+
+func TestSetSecret(t *testing.T) {
+	conn := db.New()
+	conn.EnableHistory(10)
+	s := server{conn: conn}
+	ctx := context.Background()
+
+	_, err := s.SetSecret(ctx, &pb.SecretRequest{Name: "pass", Value: "sealed"})
+	assert.EqualError(t, err, "secrets can only be set on the leader")
+
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		etcd := view.InsertEtcd()
+		etcd.Leader = true
+		view.Commit(etcd)
+		return nil
+	})
+
+	_, err = s.SetSecret(ctx, &pb.SecretRequest{Value: "sealed"})
+	assert.EqualError(t, err, "missing secret name")
+
+	_, err = s.SetSecret(ctx, &pb.SecretRequest{Name: "pass", Value: "sealed"})
+	assert.NoError(t, err)
+	_, err = s.SetSecret(ctx, &pb.SecretRequest{Name: "pass", Value: "resealed"})
+	assert.NoError(t, err)
+
+	secrets := conn.SelectFromSecret(nil)
+	assert.Len(t, secrets, 1)
+	assert.Equal(t, "resealed", secrets[0].Value)
+
+	// The values of secrets are redacted from queries.
+	checkQuery(t, s, db.SecretTable, `[{"Name":"pass","Value":""}]`)
+
+	reply, err := s.QueryHistory(ctx,
+		&pb.HistoryQuery{Table: string(db.SecretTable)})
+	assert.NoError(t, err)
+	assert.NotContains(t, reply.TableContents, "sealed")
+
+	// The daemon forwards secrets to the leader.
+	mc := new(mocks.Client)
+	mc.On("SetSecret", "pass", "sealed").Return(nil)
+	mc.On("Close").Return(nil)
+	newLeaderClient = func(_ []db.Machine, _ certs.Credentials) (
+		client.Client, error) {
+		return mc, nil
+	}
+
+	conn = db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		acl := view.InsertACL()
+		acl.Admin = []string{"local"}
+		view.Commit(acl)
+		return nil
+	})
+	s = server{conn: conn, runningOnDaemon: true}
+	_, err = s.SetSecret(ctx, &pb.SecretRequest{Name: "pass", Value: "sealed"})
+	assert.NoError(t, err)
+	mc.AssertExpectations(t)
+	assert.Empty(t, conn.SelectFromSecret(nil))
+}
+
+type mockWatchServer struct {
+	ctx     context.Context
+	replies chan string
+
+	grpc.ServerStream
+}
+
+func (s mockWatchServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s mockWatchServer) Send(reply *pb.QueryReply) error {
+	s.replies <- reply.TableContents
+	return nil
+}
+
+func TestWatch(t *testing.T) {
+	conn := db.New()
+	s := server{conn: conn, runningOnDaemon: false}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := mockWatchServer{ctx: ctx, replies: make(chan string)}
+
+	err := s.Watch(&pb.DBQuery{Table: "foo"}, stream)
+	assert.EqualError(t, err, "unrecognized table: foo")
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- s.Watch(&pb.DBQuery{Table: string(db.LabelTable)}, stream)
+	}()
+	assert.Equal(t, "null", <-stream.replies)
+
+	conn.Txn(db.LabelTable).Run(func(view db.Database) error {
+		label := view.InsertLabel()
+		label.Label = "foo"
+		view.Commit(label)
+		return nil
+	})
+	assert.Equal(t, `[{"ID":1,"Label":"foo","IP":"","ContainerIPs":null,`+
+		`"UnhealthyIPs":null}]`, <-stream.replies)
+
+	cancel()
+	assert.NoError(t, <-errChan)
+}
+
+func TestWatchCluster(t *testing.T) {
+	// Each time the cluster is connected to, the worker sends the status of its
+	// container before the leader sends the container.
+	var workerSent chan struct{}
+	connected := make(chan struct{}, 2)
+	newLeaderClient = func(_ []db.Machine, _ certs.Credentials) (
+		client.Client, error) {
+
+		sent := make(chan struct{})
+		workerSent = sent
+		connected <- struct{}{}
+
+		mc := new(mocks.Client)
+		mc.On("Watch", db.ContainerTable, mock.Anything, mock.Anything).Return(
+			nil).Run(func(args mock.Arguments) {
+			<-sent
+			args.Get(2).(func(interface{}))(
+				[]db.Container{{StitchID: "a", Minion: "1.2.3.4"}})
+			<-args.Get(1).(<-chan struct{})
+		})
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	newClient = func(host string, _ certs.Credentials) (client.Client, error) {
+		assert.Equal(t, api.RemoteAddress("9.9.9.9"), host)
+
+		sent := workerSent
+		mc := new(mocks.Client)
+		mc.On("Watch", db.ContainerTable, mock.Anything, mock.Anything).Return(
+			nil).Run(func(args mock.Arguments) {
+			args.Get(2).(func(interface{}))(
+				[]db.Container{{StitchID: "a", Status: "running"}})
+			close(sent)
+			<-args.Get(1).(<-chan struct{})
+		})
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	conn := db.New()
+	conn.Txn(db.MachineTable).Run(func(view db.Database) error {
+		m := view.InsertMachine()
+		m.PublicIP = "9.9.9.9"
+		m.Role = db.Worker
+		view.Commit(m)
+		return nil
+	})
+	s := server{conn: conn, runningOnDaemon: true}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := mockWatchServer{ctx: ctx, replies: make(chan string)}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- s.Watch(&pb.DBQuery{
+			Table:  string(db.ContainerTable),
+			Fields: []string{"StitchID", "Minion", "Status"},
+		}, stream)
+	}()
+	<-connected
+	assert.Equal(t, `[{"Minion":"1.2.3.4","Status":"running","StitchID":"a"}]`,
+		<-stream.replies)
+
+	// The cluster is reconnected to when the machines change, but unchanged
+	// contents aren't resent.
+	conn.Txn(db.MachineTable).Run(func(view db.Database) error {
+		m := view.InsertMachine()
+		m.Role = db.Master
+		view.Commit(m)
+		return nil
+	})
+	<-connected
+
+	cancel()
+	assert.NoError(t, <-errChan)
+	select {
+	case reply := <-stream.replies:
+		t.Fatalf("Unexpected reply: %s", reply)
+	default:
+	}
 }
 
 func TestBadDeployment(t *testing.T) {
@@ -146,6 +599,23 @@ func TestBadDeployment(t *testing.T) {
 
 	assert.EqualError(t, err, "unexpected end of JSON input")
 }
+
+func TestInvalidDeployment(t *testing.T) {
+	conn := db.New()
+	s := server{conn: conn}
+
+	deployment := `{"Labels": [{"Name": "web", "IDs": ["missing"]}],
+		"Connections": [{"From": "web", "To": "db",
+			"MinPort": 80, "MaxPort": 80}]}`
+	_, err := s.Deploy(context.Background(),
+		&pb.DeployRequest{Deployment: deployment})
+	assert.EqualError(t, err, "invalid blueprint:\n"+
+		"  label \"web\": undefined container \"missing\"\n"+
+		"  connection web->db: undefined label \"db\"")
+
+	assert.Empty(t, conn.GetClusterNamespaces())
+}
+
 func TestInvalidImage(t *testing.T) {
 	conn := db.New()
 	s := server{conn: conn}
@@ -194,14 +664,21 @@ func TestDeploy(t *testing.T) {
 		"Size":"m4.large"
 	}]}`
 
-	_, err := s.Deploy(context.Background(),
+	reply, err := s.Deploy(context.Background(),
 		&pb.DeployRequest{Deployment: createMachineDeployment})
 
 	assert.NoError(t, err)
+	assert.NotEmpty(t, reply.ID)
+
+	// Redeploying the same blueprint continues the same deployment.
+	sameReply, err := s.Deploy(context.Background(),
+		&pb.DeployRequest{Deployment: createMachineDeployment})
+	assert.NoError(t, err)
+	assert.Equal(t, reply.ID, sameReply.ID)
 
 	var blueprint string
 	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
-		clst, err := view.GetCluster()
+		clst, err := view.GetCluster("")
 		assert.NoError(t, err)
 		blueprint = clst.Blueprint
 		return nil
@@ -214,6 +691,54 @@ func TestDeploy(t *testing.T) {
 	assert.NoError(t, err)
 
 	assert.Equal(t, exp, actual)
+
+	// Deploying to another namespace leaves the first one alone.
+	newReply, err := s.Deploy(context.Background(),
+		&pb.DeployRequest{Deployment: `{"Namespace": "new"}`})
+	assert.NoError(t, err)
+	assert.NotEqual(t, reply.ID, newReply.ID)
+	assert.Equal(t, []string{"", "new"}, conn.GetClusterNamespaces())
+
+	sameReply, err = s.Deploy(context.Background(),
+		&pb.DeployRequest{Deployment: createMachineDeployment})
+	assert.NoError(t, err)
+	assert.Equal(t, reply.ID, sameReply.ID)
+}
+
+func TestDeployStop(t *testing.T) {
+	conn := db.New()
+	s := server{conn: conn}
+
+	isStopped := func() bool {
+		clsts := conn.SelectFromCluster(nil)
+		assert.Len(t, clsts, 1)
+		return clsts[0].Stopped
+	}
+
+	// A blueprint without machines isn't a stop on its own.
+	reply, err := s.Deploy(context.Background(),
+		&pb.DeployRequest{Deployment: `{"Namespace": "ns"}`})
+	assert.NoError(t, err)
+	assert.False(t, isStopped())
+
+	stopReply, err := s.Deploy(context.Background(),
+		&pb.DeployRequest{Deployment: `{"Namespace": "ns"}`, Stop: true})
+	assert.NoError(t, err)
+	assert.NotEqual(t, reply.ID, stopReply.ID)
+	assert.True(t, isStopped())
+
+	_, err = s.Deploy(context.Background(), &pb.DeployRequest{
+		Deployment: `{"Namespace": "ns", "Machines": [{"Provider": "Amazon"}]}`,
+		Stop:       true,
+	})
+	assert.EqualError(t, err, "stopped blueprints can't have machines")
+	assert.True(t, isStopped())
+
+	// Redeploying clears the stop.
+	_, err = s.Deploy(context.Background(),
+		&pb.DeployRequest{Deployment: `{"Namespace": "ns"}`})
+	assert.NoError(t, err)
+	assert.False(t, isStopped())
 }
 
 func TestVagrantDeployment(t *testing.T) {
@@ -240,7 +765,7 @@ func TestVagrantDeployment(t *testing.T) {
 
 	var blueprint string
 	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
-		clst, err := view.GetCluster()
+		clst, err := view.GetCluster("")
 		assert.NoError(t, err)
 		blueprint = clst.Blueprint
 		return nil
@@ -271,6 +796,9 @@ func TestUpdateLeaderContainerAttrs(t *testing.T) {
 			StitchID: "1",
 			Created:  created,
 			Status:   "running",
+			Health:   db.ContainerHealthy,
+			Restarts: 2,
+			ExitCode: 1,
 		},
 	}
 
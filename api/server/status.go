@@ -0,0 +1,199 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/quilt/quilt/api/pb"
+	"github.com/quilt/quilt/db"
+	"github.com/quilt/quilt/stitch"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// DeploymentStatus reports how far a cluster has converged towards the blueprint of
+// its current deployment.  If an ID is given, the status of that deployment is
+// reported, and a NotFound error is returned if it was replaced, as it will never
+// converge.  Otherwise, the status of the namespace targeted by the request is
+// reported.
+func (s server) DeploymentStatus(cts context.Context,
+	req *pb.DeploymentStatusRequest) (*pb.DeploymentStatusReply, error) {
+
+	cluster, err := s.deploymentCluster(cts, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	blueprint, err := stitch.FromJSON(cluster.Blueprint)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cluster may not be reachable yet, in which case nothing has been placed
+	// or built, and the deployment is stuck on the cluster itself.
+	ctx := withNamespace(cts, cluster.Namespace)
+	var clusterErrs []string
+	var containers []db.Container
+	var images []db.Image
+	if rows, err := s.selectTable(ctx, db.ContainerTable); err != nil {
+		clusterErrs = append(clusterErrs,
+			fmt.Sprintf("failed to query containers: %s", err))
+	} else {
+		containers = rows.([]db.Container)
+	}
+
+	if rows, err := s.selectTable(ctx, db.ImageTable); err != nil {
+		clusterErrs = append(clusterErrs,
+			fmt.Sprintf("failed to query images: %s", err))
+	} else {
+		images = rows.([]db.Image)
+	}
+
+	machines := s.conn.SelectFromMachine(func(m db.Machine) bool {
+		return m.Namespace == cluster.Namespace
+	})
+	status := deploymentStatus(blueprint, machines, containers, images)
+	status.ID = cluster.DeploymentID
+	status.Stuck = append(clusterErrs, status.Stuck...)
+	status.Converged = status.Converged && len(clusterErrs) == 0
+	return status, nil
+}
+
+// deploymentCluster returns the cluster implementing the deployment with the given
+// ID, or if no ID is given, the cluster of the namespace targeted by the request in
+// 'ctx'.
+func (s server) deploymentCluster(ctx context.Context, id string) (db.Cluster, error) {
+	var clusters []db.Cluster
+	if id != "" {
+		clusters = s.conn.SelectFromCluster(func(c db.Cluster) bool {
+			return c.DeploymentID == id
+		})
+		if len(clusters) == 0 {
+			return db.Cluster{}, grpc.Errorf(codes.NotFound,
+				"deployment %s was replaced", id)
+		}
+		return clusters[0], nil
+	}
+
+	namespace, err := s.resolveNamespace(ctx)
+	if err != nil {
+		return db.Cluster{}, err
+	}
+
+	clusters = s.conn.SelectFromCluster(func(c db.Cluster) bool {
+		return c.Namespace == namespace
+	})
+	if len(clusters) == 0 {
+		return db.Cluster{}, errors.New("no deployment")
+	}
+	return clusters[0], nil
+}
+
+// selectTable returns the contents of `table`, proxying the query to the cluster
+// of the namespace targeted by the request in 'ctx' when running on the daemon.
+func (s server) selectTable(ctx context.Context, table db.TableType) (
+	interface{}, error) {
+
+	if s.runningOnDaemon {
+		return s.queryFromDaemon(ctx, table, nil, nil)
+	}
+	return queryLocal(table, s.conn)
+}
+
+// deploymentStatus compares the machines, containers and images implementing a
+// blueprint to those it specifies.
+func deploymentStatus(blueprint stitch.Stitch, machines []db.Machine,
+	containers []db.Container, images []db.Image) *pb.DeploymentStatusReply {
+
+	status := &pb.DeploymentStatusReply{}
+
+	machineMap := map[string]db.Machine{}
+	for _, m := range machines {
+		machineMap[m.StitchID] = m
+	}
+
+	for _, bm := range blueprint.Machines {
+		status.Machines++
+
+		m, ok := machineMap[bm.ID]
+		switch {
+		case !ok || m.PublicIP == "":
+			status.Stuck = append(status.Stuck, fmt.Sprintf(
+				"machine %s: waiting to boot", bm.ID))
+		case !m.Connected:
+			status.MachinesBooted++
+			status.Stuck = append(status.Stuck, fmt.Sprintf(
+				"machine %s (%s): waiting to connect", bm.ID, m.PublicIP))
+		default:
+			status.MachinesBooted++
+			status.MachinesConnected++
+		}
+	}
+
+	containerMap := map[string]db.Container{}
+	for _, c := range containers {
+		containerMap[c.StitchID] = c
+	}
+
+	for _, bc := range blueprint.Containers {
+		status.Containers++
+
+		c, ok := containerMap[bc.ID]
+		switch {
+		case ok && c.Minion == "" && c.Status != "":
+			// The scheduler explains why it can't place the container.
+			status.Stuck = append(status.Stuck, fmt.Sprintf(
+				"container %s (%s): %s", bc.ID, bc.Image.Name, c.Status))
+		case !ok || c.Minion == "":
+			status.Stuck = append(status.Stuck, fmt.Sprintf(
+				"container %s (%s): waiting to be placed", bc.ID,
+				bc.Image.Name))
+		case c.Status != "running":
+			status.ContainersPlaced++
+			status.Stuck = append(status.Stuck, fmt.Sprintf(
+				"container %s (%s): waiting to run on %s", bc.ID,
+				bc.Image.Name, c.Minion))
+		default:
+			status.ContainersPlaced++
+			status.ContainersRunning++
+		}
+	}
+
+	builtImages := map[string]struct{}{}
+	for _, img := range images {
+		if img.DockerID != "" {
+			builtImages[img.Name] = struct{}{}
+		}
+	}
+
+	// Only images with a Dockerfile are built by the cluster.  The rest are
+	// pulled by the workers when their containers boot.
+	blueprintImages := map[string]struct{}{}
+	for _, bc := range blueprint.Containers {
+		if bc.Image.Dockerfile != "" {
+			blueprintImages[bc.Image.Name] = struct{}{}
+		}
+	}
+
+	var imageNames []string
+	for name := range blueprintImages {
+		imageNames = append(imageNames, name)
+	}
+	sort.Strings(imageNames)
+
+	for _, name := range imageNames {
+		status.Images++
+		if _, ok := builtImages[name]; ok {
+			status.ImagesBuilt++
+		} else {
+			status.Stuck = append(status.Stuck, fmt.Sprintf(
+				"image %s: waiting to be built", name))
+		}
+	}
+
+	status.Converged = len(status.Stuck) == 0
+	return status
+}
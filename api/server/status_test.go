@@ -0,0 +1,188 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quilt/quilt/api/client"
+	"github.com/quilt/quilt/api/pb"
+	"github.com/quilt/quilt/certs"
+	"github.com/quilt/quilt/db"
+)
+
+const statusBlueprint = `{
+	"Machines": [{"ID": "m1", "Role": "Master"}, {"ID": "m2", "Role": "Worker"}],
+	"Containers": [
+		{"ID": "c1", "Image": {"Name": "nginx"}},
+		{"ID": "c2", "Image": {"Name": "custom", "Dockerfile": "FROM nginx"}},
+		{"ID": "c3", "Image": {"Name": "custom", "Dockerfile": "FROM nginx"}}
+	]}`
+
+func TestDeploymentStatus(t *testing.T) {
+	t.Parallel()
+
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		clst := view.InsertCluster()
+		clst.Blueprint = statusBlueprint
+		clst.DeploymentID = "id"
+		view.Commit(clst)
+
+		m := view.InsertMachine()
+		m.StitchID = "m1"
+		m.PublicIP = "1.1.1.1"
+		m.Connected = true
+		view.Commit(m)
+
+		m = view.InsertMachine()
+		m.StitchID = "m2"
+		m.PublicIP = "2.2.2.2"
+		view.Commit(m)
+
+		for _, ip := range []string{"10.0.0.2", "10.0.0.3"} {
+			minion := view.InsertMinion()
+			minion.PrivateIP = ip
+			view.Commit(minion)
+		}
+
+		c := view.InsertContainer()
+		c.StitchID = "c1"
+		c.Minion = "10.0.0.2"
+		c.Status = "running"
+		view.Commit(c)
+
+		c = view.InsertContainer()
+		c.StitchID = "c2"
+		c.Minion = "10.0.0.2"
+		view.Commit(c)
+
+		c = view.InsertContainer()
+		c.StitchID = "c3"
+		c.Status = "unschedulable: insufficient CPU"
+		view.Commit(c)
+
+		img := view.InsertImage()
+		img.Name = "custom"
+		view.Commit(img)
+		return nil
+	})
+
+	s := server{conn: conn}
+	status, err := s.DeploymentStatus(context.Background(),
+		&pb.DeploymentStatusRequest{ID: "id"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.DeploymentStatusReply{
+		ID:                "id",
+		Machines:          2,
+		MachinesBooted:    2,
+		MachinesConnected: 1,
+		Containers:        3,
+		ContainersPlaced:  2,
+		ContainersRunning: 1,
+		Images:            1,
+		Stuck: []string{
+			"machine m2 (2.2.2.2): waiting to connect",
+			"container c2 (custom): waiting to run on 10.0.0.2",
+			"container c3 (custom): unschedulable: insufficient CPU",
+			"image custom: waiting to be built",
+		},
+	}, status)
+
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		for _, m := range view.SelectFromMachine(nil) {
+			m.Connected = true
+			view.Commit(m)
+		}
+
+		for _, c := range view.SelectFromContainer(nil) {
+			if c.Minion == "" {
+				c.Minion = "10.0.0.3"
+			}
+			c.Status = "running"
+			view.Commit(c)
+		}
+
+		img := view.SelectFromImage(nil)[0]
+		img.DockerID = "built"
+		view.Commit(img)
+		return nil
+	})
+
+	// An empty ID refers to the current deployment.
+	status, err = s.DeploymentStatus(context.Background(),
+		&pb.DeploymentStatusRequest{})
+	assert.NoError(t, err)
+	assert.True(t, status.Converged)
+	assert.Empty(t, status.Stuck)
+	assert.Equal(t, "id", status.ID)
+
+	_, err = s.DeploymentStatus(context.Background(),
+		&pb.DeploymentStatusRequest{ID: "old"})
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+	assert.Equal(t, "deployment old was replaced", grpc.ErrorDesc(err))
+
+	// Once there are several namespaces, requests without an ID must choose one.
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		clst := view.InsertCluster()
+		clst.Namespace = "other"
+		clst.Blueprint = "{}"
+		clst.DeploymentID = "other-id"
+		view.Commit(clst)
+		return nil
+	})
+
+	_, err = s.DeploymentStatus(context.Background(),
+		&pb.DeploymentStatusRequest{})
+	assert.Equal(t, errAmbiguousNamespace, err)
+
+	status, err = s.DeploymentStatus(withNamespace(context.Background(), "other"),
+		&pb.DeploymentStatusRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "other-id", status.ID)
+	assert.Zero(t, status.Machines)
+
+	status, err = s.DeploymentStatus(context.Background(),
+		&pb.DeploymentStatusRequest{ID: "id"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), status.Machines)
+}
+
+func TestDeploymentStatusErrors(t *testing.T) {
+	s := server{conn: db.New(), runningOnDaemon: true}
+	_, err := s.DeploymentStatus(context.Background(),
+		&pb.DeploymentStatusRequest{})
+	assert.EqualError(t, err, "no deployment")
+
+	// If the cluster is unreachable, the deployment is reported as stuck on it.
+	origNewLeaderClient := newLeaderClient
+	defer func() { newLeaderClient = origNewLeaderClient }()
+	newLeaderClient = func(_ []db.Machine, _ certs.Credentials) (
+		client.Client, error) {
+
+		return nil, errors.New("no leader")
+	}
+
+	s.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		clst := view.InsertCluster()
+		clst.Blueprint = `{"Containers": [{"ID": "c1"}]}`
+		clst.DeploymentID = "id"
+		view.Commit(clst)
+		return nil
+	})
+
+	status, err := s.DeploymentStatus(context.Background(),
+		&pb.DeploymentStatusRequest{ID: "id"})
+	assert.NoError(t, err)
+	assert.False(t, status.Converged)
+	assert.Equal(t, []string{
+		"failed to query containers: no leader",
+		"failed to query images: no leader",
+		"container c1 (): waiting to be placed",
+	}, status.Stuck)
+}
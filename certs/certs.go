@@ -0,0 +1,300 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"time"
+
+	"github.com/quilt/quilt/util"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// The names of the key pairs issued by the daemon.  The daemon's key pair is used by
+// every client, including the `quilt` command line tool, while the minion's key pair
+// may only be used by servers.
+const (
+	Daemon = "daemon"
+	Minion = "minion"
+)
+
+// ServerName is the name for which every Quilt certificate is issued.  Peers are
+// authenticated by the certificate authority that signed them, rather than by
+// address, as the addresses of machines aren't known until after they boot.
+const ServerName = "quilt"
+
+// MinionDir is the directory in which minions keep their credentials.
+const MinionDir = "/etc/quilt/tls"
+
+// DefaultDir is the directory in which the daemon keeps its credentials by default.
+var DefaultDir = defaultDir()
+
+const (
+	caName       = "ca"
+	certValidity = 10 * 365 * 24 * time.Hour
+)
+
+// A KeyPair is a PEM encoded certificate, and its PEM encoded private key.
+type KeyPair struct {
+	Cert string
+	Key  string
+}
+
+// Credentials are the certificate authority that a Quilt process trusts, and the key
+// pair it identifies itself with.
+type Credentials struct {
+	CA string
+	KeyPair
+}
+
+// Setup creates a certificate authority in 'dir', along with the key pairs it
+// issues for the daemon and minions.  Existing files are left untouched so that
+// running clusters continue to trust the daemon.
+func Setup(dir string) error {
+	if err := util.AppFs.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	exists, err := util.FileExists(keyPath(dir, caName))
+	if err != nil || exists {
+		return err
+	}
+
+	ca, err := newCA()
+	if err != nil {
+		return err
+	}
+
+	daemon, err := ca.issue(Daemon, x509.ExtKeyUsageServerAuth,
+		x509.ExtKeyUsageClientAuth)
+	if err != nil {
+		return err
+	}
+
+	minion, err := ca.issue(Minion, x509.ExtKeyUsageServerAuth)
+	if err != nil {
+		return err
+	}
+
+	// The CA's key is written last, as its presence marks the setup as complete.
+	for _, kp := range []struct {
+		name string
+		KeyPair
+	}{{Daemon, daemon}, {Minion, minion}, {caName, ca}} {
+		err := util.WriteFile(certPath(dir, kp.name), []byte(kp.Cert), 0644)
+		if err != nil {
+			return err
+		}
+
+		err = util.WriteFile(keyPath(dir, kp.name), []byte(kp.Key), 0600)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads the Credentials for the key pair called 'name' from 'dir'.
+func Load(dir, name string) (Credentials, error) {
+	var creds Credentials
+	var err error
+	for _, file := range []struct {
+		path string
+		dst  *string
+	}{
+		{certPath(dir, caName), &creds.CA},
+		{certPath(dir, name), &creds.Cert},
+		{keyPath(dir, name), &creds.Key},
+	} {
+		if *file.dst, err = util.ReadFile(file.path); err != nil {
+			return Credentials{}, fmt.Errorf(
+				"failed to read TLS credentials: %s", err)
+		}
+	}
+	return creds, nil
+}
+
+// Save writes 'creds' to 'dir' such that they may be read by Load using 'name'.
+func (creds Credentials) Save(dir, name string) error {
+	if err := util.AppFs.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	if err := util.WriteFile(certPath(dir, caName), []byte(creds.CA),
+		0644); err != nil {
+		return err
+	}
+
+	err := util.WriteFile(certPath(dir, name), []byte(creds.Cert), 0644)
+	if err != nil {
+		return err
+	}
+	return util.WriteFile(keyPath(dir, name), []byte(creds.Key), 0600)
+}
+
+// ServerOption returns a gRPC option that requires clients to authenticate with a
+// certificate signed by the Credentials' CA, and permitted to act as a client.
+func (creds Credentials) ServerOption() (grpc.ServerOption, error) {
+	config, err := creds.ServerConfig()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.Creds(credentials.NewTLS(config)), nil
+}
+
+// ServerConfig returns the TLS configuration underlying ServerOption, for servers
+// that don't speak gRPC.
+func (creds Credentials) ServerConfig() (*tls.Config, error) {
+	config, err := creds.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	config.ClientCAs = config.RootCAs
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	return config, nil
+}
+
+// DialOption returns a gRPC option that authenticates with the Credentials' key pair,
+// and requires servers to have a certificate signed by the Credentials' CA.
+func (creds Credentials) DialOption() (grpc.DialOption, error) {
+	config, err := creds.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	config.ServerName = ServerName
+	return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
+}
+
+func (creds Credentials) tlsConfig() (*tls.Config, error) {
+	cert, err := tls.X509KeyPair([]byte(creds.Cert), []byte(creds.Key))
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(creds.CA)) {
+		return nil, errors.New("malformed certificate authority")
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func newCA() (KeyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return KeyPair{}, err
+	}
+
+	template, err := newTemplate("Quilt CA")
+	if err != nil {
+		return KeyPair{}, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template,
+		&key.PublicKey, key)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	return encode(der, key)
+}
+
+// issue creates a new key pair signed by 'ca', and permitted to be used for 'usage'.
+func (ca KeyPair) issue(name string, usage ...x509.ExtKeyUsage) (KeyPair, error) {
+	caCert, err := tls.X509KeyPair([]byte(ca.Cert), []byte(ca.Key))
+	if err != nil {
+		return KeyPair{}, err
+	}
+
+	parent, err := x509.ParseCertificate(caCert.Certificate[0])
+	if err != nil {
+		return KeyPair{}, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return KeyPair{}, err
+	}
+
+	template, err := newTemplate(name)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	template.DNSNames = []string{ServerName}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = usage
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent,
+		&key.PublicKey, caCert.PrivateKey)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	return encode(der, key)
+}
+
+func newTemplate(name string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   name,
+			Organization: []string{"Quilt"},
+		},
+		NotBefore: now.Add(-time.Hour),
+		NotAfter:  now.Add(certValidity),
+	}, nil
+}
+
+func encode(der []byte, key *ecdsa.PrivateKey) (KeyPair, error) {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return KeyPair{}, err
+	}
+
+	return KeyPair{
+		Cert: string(pem.EncodeToMemory(&pem.Block{
+			Type: "CERTIFICATE", Bytes: der})),
+		Key: string(pem.EncodeToMemory(&pem.Block{
+			Type: "EC PRIVATE KEY", Bytes: keyDER})),
+	}, nil
+}
+
+func certPath(dir, name string) string {
+	return filepath.Join(dir, name+".crt")
+}
+
+func keyPath(dir, name string) string {
+	return filepath.Join(dir, name+".key")
+}
+
+func defaultDir() string {
+	dir, err := homedir.Expand("~/.quilt/tls")
+	if err != nil {
+		return ".quilt/tls"
+	}
+	return dir
+}
@@ -0,0 +1,137 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quilt/quilt/util"
+)
+
+func TestSetup(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+
+	assert.NoError(t, Setup("/tls"))
+	daemon, err := Load("/tls", Daemon)
+	assert.NoError(t, err)
+
+	minion, err := Load("/tls", Minion)
+	assert.NoError(t, err)
+	assert.Equal(t, daemon.CA, minion.CA)
+
+	// Setting up again leaves the existing credentials untouched.
+	assert.NoError(t, Setup("/tls"))
+	again, err := Load("/tls", Daemon)
+	assert.NoError(t, err)
+	assert.Equal(t, daemon, again)
+
+	assert.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth,
+		x509.ExtKeyUsageClientAuth}, parseCert(t, daemon.Cert).ExtKeyUsage)
+	assert.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		parseCert(t, minion.Cert).ExtKeyUsage)
+
+	_, err = Load("/tls", "foo")
+	assert.EqualError(t, err, "failed to read TLS credentials: "+
+		"open /tls/foo.crt: file does not exist")
+}
+
+func TestSave(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+
+	creds := Credentials{CA: "ca", KeyPair: KeyPair{Cert: "cert", Key: "key"}}
+	assert.NoError(t, creds.Save("/etc/quilt/tls", Minion))
+
+	loaded, err := Load("/etc/quilt/tls", Minion)
+	assert.NoError(t, err)
+	assert.Equal(t, creds, loaded)
+
+	_, err = creds.ServerOption()
+	assert.Error(t, err)
+}
+
+func TestHandshake(t *testing.T) {
+	ca, err := newCA()
+	assert.NoError(t, err)
+
+	daemon := issueCreds(t, ca, x509.ExtKeyUsageServerAuth,
+		x509.ExtKeyUsageClientAuth)
+	minion := issueCreds(t, ca, x509.ExtKeyUsageServerAuth)
+
+	// The daemon may connect to minions.
+	assert.NoError(t, handshake(t, minion, daemon))
+
+	// Minions may not connect to anyone, as their certificates may only be used
+	// by servers.
+	assert.Error(t, handshake(t, daemon, minion))
+
+	// Nor may anyone with a certificate from another authority.
+	otherCA, err := newCA()
+	assert.NoError(t, err)
+	other := issueCreds(t, otherCA, x509.ExtKeyUsageServerAuth,
+		x509.ExtKeyUsageClientAuth)
+	other.CA = ca.Cert
+	assert.Error(t, handshake(t, minion, other))
+}
+
+func issueCreds(t *testing.T, ca KeyPair, usage ...x509.ExtKeyUsage) Credentials {
+	kp, err := ca.issue("test", usage...)
+	assert.NoError(t, err)
+	return Credentials{CA: ca.Cert, KeyPair: kp}
+}
+
+// handshake returns the error, if any, with which the client fails to complete a TLS
+// handshake with the server.
+func handshake(t *testing.T, server, client Credentials) error {
+	serverConfig, err := server.ServerConfig()
+	assert.NoError(t, err)
+
+	clientConfig, err := client.tlsConfig()
+	assert.NoError(t, err)
+	clientConfig.ServerName = ServerName
+
+	sock, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	assert.NoError(t, err)
+	defer sock.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := sock.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+
+		err = conn.(*tls.Conn).Handshake()
+		if err == nil {
+			_, err = conn.Write([]byte{0})
+		}
+		conn.Close()
+		serverErr <- err
+	}()
+
+	conn, err := tls.Dial("tcp", sock.Addr().String(), clientConfig)
+	if err != nil {
+		return err
+	}
+
+	// The client may finish its handshake before the server has verified its
+	// certificate, so wait to hear back from the server.
+	_, err = conn.Read(make([]byte, 1))
+	conn.Close()
+
+	if sErr := <-serverErr; sErr != nil {
+		return sErr
+	}
+	return err
+}
+
+func parseCert(t *testing.T, cert string) *x509.Certificate {
+	block, _ := pem.Decode([]byte(cert))
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+	return parsed
+}
@@ -0,0 +1,72 @@
+package certs
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io"
+)
+
+// Secrets are encrypted with a key derived from a private key, rather than with the
+// private key itself, so that the derived key is only ever used for secrets.
+const secretKeyLabel = "quilt secrets"
+
+// Seal encrypts 'plaintext' such that it can only be decrypted by Open with the same
+// key pair.  Blueprint secrets are sealed with the minions' key pair, which is held
+// only by the minions and the host of the daemon that issued it.
+func (kp KeyPair) Seal(plaintext string) (string, error) {
+	aead, err := kp.secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value encrypted by Seal.
+func (kp KeyPair) Open(sealed string) (string, error) {
+	aead, err := kp.secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return "", errors.New("malformed sealed value")
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func (kp KeyPair) secretCipher() (cipher.AEAD, error) {
+	block, _ := pem.Decode([]byte(kp.Key))
+	if block == nil {
+		return nil, errors.New("malformed private key")
+	}
+
+	key := sha256.Sum256(append([]byte(secretKeyLabel), block.Bytes...))
+	aesCipher, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(aesCipher)
+}
@@ -0,0 +1,39 @@
+package certs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSealOpen(t *testing.T) {
+	kp, err := newCA()
+	assert.NoError(t, err)
+
+	sealed, err := kp.Seal("password")
+	assert.NoError(t, err)
+	assert.NotContains(t, sealed, "password")
+
+	opened, err := kp.Open(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "password", opened)
+
+	// Values are sealed with a random nonce, so equal secrets look different.
+	again, err := kp.Seal("password")
+	assert.NoError(t, err)
+	assert.NotEqual(t, sealed, again)
+
+	other, err := newCA()
+	assert.NoError(t, err)
+	_, err = other.Open(sealed)
+	assert.Error(t, err)
+
+	_, err = kp.Open("c2hvcnQ=")
+	assert.EqualError(t, err, "malformed sealed value")
+
+	_, err = kp.Open("not base64")
+	assert.Error(t, err)
+
+	_, err = KeyPair{Key: "garbage"}.Seal("password")
+	assert.EqualError(t, err, "malformed private key")
+}
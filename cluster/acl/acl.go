@@ -5,6 +5,10 @@ type ACL struct {
 	CidrIP  string
 	MinPort int
 	MaxPort int
+
+	// Protocol restricts the ACL to "tcp", "udp" or "icmp" traffic.  If empty,
+	// traffic of any of these protocols is allowed.
+	Protocol string
 }
 
 // Slice is an alias for []ACL to allow for joins
@@ -7,7 +7,7 @@ import (
 )
 
 func TestSlice(t *testing.T) {
-	acl := ACL{"1.2.3.4", 1, 2}
+	acl := ACL{"1.2.3.4", 1, 2, "tcp"}
 	slice := Slice([]ACL{acl})
 
 	assert.Equal(t, slice.Len(), 1)
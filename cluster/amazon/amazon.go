@@ -590,34 +590,7 @@ func syncACLs(desiredACLs []acl.ACL, desiredGroupID string,
 
 	var desiredRangeRules []*ec2.IpPermission
 	for _, acl := range desiredACLs {
-		desiredRangeRules = append(desiredRangeRules, &ec2.IpPermission{
-			FromPort: aws.Int64(int64(acl.MinPort)),
-			ToPort:   aws.Int64(int64(acl.MaxPort)),
-			IpRanges: []*ec2.IpRange{
-				{
-					CidrIp: aws.String(acl.CidrIP),
-				},
-			},
-			IpProtocol: aws.String("tcp"),
-		}, &ec2.IpPermission{
-			FromPort: aws.Int64(int64(acl.MinPort)),
-			ToPort:   aws.Int64(int64(acl.MaxPort)),
-			IpRanges: []*ec2.IpRange{
-				{
-					CidrIp: aws.String(acl.CidrIP),
-				},
-			},
-			IpProtocol: aws.String("udp"),
-		}, &ec2.IpPermission{
-			FromPort: aws.Int64(-1),
-			ToPort:   aws.Int64(-1),
-			IpRanges: []*ec2.IpRange{
-				{
-					CidrIp: aws.String(acl.CidrIP),
-				},
-			},
-			IpProtocol: aws.String("icmp"),
-		})
+		desiredRangeRules = append(desiredRangeRules, aclPermissions(acl)...)
 	}
 
 	_, toAdd, rangesToRemove := join.HashJoin(ipPermSlice(desiredRangeRules),
@@ -632,6 +605,35 @@ func syncACLs(desiredACLs []acl.ACL, desiredGroupID string,
 	return rangesToAdd, foundGroup, toRemove
 }
 
+// aclPermissions returns the permissions that allow the traffic described by
+// `acl`.  An ACL without a protocol allows TCP, UDP, and ICMP.
+func aclPermissions(acl acl.ACL) []*ec2.IpPermission {
+	var perms []*ec2.IpPermission
+	for _, protocol := range []string{"tcp", "udp", "icmp"} {
+		if acl.Protocol != "" && acl.Protocol != protocol {
+			continue
+		}
+
+		// ICMP has no ports, so all ICMP traffic is allowed.
+		minPort, maxPort := int64(acl.MinPort), int64(acl.MaxPort)
+		if protocol == "icmp" {
+			minPort, maxPort = -1, -1
+		}
+
+		perms = append(perms, &ec2.IpPermission{
+			FromPort: aws.Int64(minPort),
+			ToPort:   aws.Int64(maxPort),
+			IpRanges: []*ec2.IpRange{
+				{
+					CidrIp: aws.String(acl.CidrIP),
+				},
+			},
+			IpProtocol: aws.String(protocol),
+		})
+	}
+	return perms
+}
+
 func logACLs(add bool, perms []*ec2.IpPermission) {
 	action := "Remove"
 	if add {
@@ -640,20 +642,16 @@ func logACLs(add bool, perms []*ec2.IpPermission) {
 
 	for _, perm := range perms {
 		if len(perm.IpRanges) != 0 {
-			// Each rule has three variants (TCP, UDP, and ICMP), but
-			// we only want to log once.
-			protocol := *perm.IpProtocol
-			if protocol != "tcp" {
-				continue
-			}
-
+			// Rules that allow all protocols don't have ports.
+			fromPort := aws.Int64Value(perm.FromPort)
+			toPort := aws.Int64Value(perm.ToPort)
 			cidrIP := *perm.IpRanges[0].CidrIp
-			ports := fmt.Sprintf("%d", *perm.FromPort)
-			if *perm.FromPort != *perm.ToPort {
-				ports += fmt.Sprintf("-%d", *perm.ToPort)
+			ports := fmt.Sprintf("%d", fromPort)
+			if fromPort != toPort {
+				ports += fmt.Sprintf("-%d", toPort)
 			}
-			log.WithField("ACL",
-				fmt.Sprintf("%s:%s", cidrIP, ports)).
+			log.WithField("ACL", fmt.Sprintf("%s:%s/%s", cidrIP, ports,
+				aws.StringValue(perm.IpProtocol))).
 				Debugf("Amazon: %s ACL", action)
 		} else {
 			log.WithField("Group",
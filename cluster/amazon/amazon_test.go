@@ -267,6 +267,30 @@ func TestNewACLs(t *testing.T) {
 	}
 }
 
+func TestACLPermissions(t *testing.T) {
+	t.Parallel()
+
+	perm := func(protocol string, min, max int64) *ec2.IpPermission {
+		return &ec2.IpPermission{
+			IpRanges:   []*ec2.IpRange{{CidrIp: aws.String("foo")}},
+			FromPort:   aws.Int64(min),
+			ToPort:     aws.Int64(max),
+			IpProtocol: aws.String(protocol),
+		}
+	}
+
+	assert.Equal(t, []*ec2.IpPermission{
+		perm("tcp", 80, 81), perm("udp", 80, 81), perm("icmp", -1, -1),
+	}, aclPermissions(acl.ACL{CidrIP: "foo", MinPort: 80, MaxPort: 81}))
+
+	assert.Equal(t, []*ec2.IpPermission{perm("udp", 53, 53)},
+		aclPermissions(acl.ACL{CidrIP: "foo", MinPort: 53, MaxPort: 53,
+			Protocol: "udp"}))
+
+	assert.Equal(t, []*ec2.IpPermission{perm("icmp", -1, -1)},
+		aclPermissions(acl.ACL{CidrIP: "foo", Protocol: "icmp"}))
+}
+
 func TestBoot(t *testing.T) {
 	t.Parallel()
 
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/version"
 
@@ -33,12 +34,16 @@ func Ubuntu(opts Options) string {
 		SSHKeys       string
 		LogLevel      string
 		MinionOpts    string
+		TLSDir        string
+		TLS           certs.Credentials
 	}{
 		QuiltImage:    img,
 		UbuntuVersion: "xenial",
 		SSHKeys:       strings.Join(opts.SSHKeys, "\n"),
 		LogLevel:      log.GetLevel().String(),
 		MinionOpts:    opts.MinionOpts.String(),
+		TLSDir:        certs.MinionDir,
+		TLS:           opts.TLS,
 	})
 	if err != nil {
 		panic(err)
@@ -51,6 +56,9 @@ func Ubuntu(opts Options) string {
 type Options struct {
 	SSHKeys    []string
 	MinionOpts MinionOptions
+
+	// The credentials with which the minion authenticates itself to the daemon.
+	TLS certs.Credentials
 }
 
 // MinionOptions defines the command line flags the minion should be invoked with.
@@ -3,6 +3,7 @@ package cloudcfg
 import (
 	"testing"
 
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 
 	log "github.com/Sirupsen/logrus"
@@ -33,4 +34,14 @@ func TestCloudConfig(t *testing.T) {
 	if res != exp {
 		t.Errorf("res: %s\nexp: %s", res, exp)
 	}
+
+	cfgTemplate = "({{.TLSDir}}) ({{.TLS.CA}}) ({{.TLS.Cert}}) ({{.TLS.Key}})"
+	res = Ubuntu(Options{TLS: certs.Credentials{
+		CA:      "ca",
+		KeyPair: certs.KeyPair{Cert: "cert", Key: "key"},
+	}})
+	exp = "(/etc/quilt/tls) (ca) (cert) (key)"
+	if res != exp {
+		t.Errorf("res: %s\nexp: %s", res, exp)
+	}
 }
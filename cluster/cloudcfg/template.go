@@ -50,6 +50,23 @@ initialize_docker() {
 	EOF
 }
 
+initialize_tls() {
+	install -d -m 700 {{.TLSDir}}
+
+	cat <<- 'EOF' > {{.TLSDir}}/ca.crt
+	{{.TLS.CA -}}
+	EOF
+
+	cat <<- 'EOF' > {{.TLSDir}}/minion.crt
+	{{.TLS.Cert -}}
+	EOF
+
+	install -m 600 /dev/null {{.TLSDir}}/minion.key
+	cat <<- 'EOF' > {{.TLSDir}}/minion.key
+	{{.TLS.Key -}}
+	EOF
+}
+
 initialize_minion() {
 	cat <<- EOF > /etc/systemd/system/minion.service
 	[Unit]
@@ -66,6 +83,7 @@ initialize_minion() {
 	-v /var/run/docker.sock:/var/run/docker.sock \
 	-v /etc/ssl/certs/ca-certificates.crt:/etc/ssl/certs/ca-certificates.crt \
 	-v /home/quilt/.ssh:/home/quilt/.ssh:rw \
+	-v {{.TLSDir}}:{{.TLSDir}}:ro \
 	-v /run/docker:/run/docker:rw {{.QuiltImage}} \
 	quilt -l {{.LogLevel}} minion {{.MinionOpts}}
 	Restart=on-failure
@@ -113,6 +131,7 @@ sudo chmod -R /run/docker/plugins 0755
 install_docker
 initialize_ovs
 initialize_docker
+initialize_tls
 initialize_minion
 
 # Allow the user to use docker without sudo
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/cluster/acl"
 	"github.com/quilt/quilt/cluster/amazon"
 	"github.com/quilt/quilt/cluster/cloudcfg"
@@ -17,6 +18,7 @@ import (
 	"github.com/quilt/quilt/cluster/vagrant"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/join"
+	"github.com/quilt/quilt/metrics"
 	"github.com/quilt/quilt/util"
 )
 
@@ -51,11 +53,17 @@ type cluster struct {
 	namespace string
 	conn      db.Conn
 	providers map[launchLoc]provider
+	foreman   *foreman.Foreman
+
+	// The credentials distributed to newly booted minions.
+	minionCreds certs.Credentials
 }
 
 var myIP = util.MyIP
 var sleep = time.Sleep
 
+var errNamespaceStopped = errors.New("namespace stopped")
+
 // action is an enum for provider actions.
 type action int
 
@@ -65,42 +73,84 @@ const (
 	updateIPs
 )
 
-// Run continually checks 'conn' for cluster changes and recreates the cluster as
-// needed.
-func Run(conn db.Conn) {
-	var clst *cluster
-	for range conn.TriggerTick(30, db.ClusterTable, db.MachineTable, db.ACLTable).C {
-		clst = updateCluster(conn, clst)
-
-		// Somewhat of a crude rate-limit of once every five seconds to avoid
-		// stressing out the cloud providers with too many API calls.
-		sleep(5 * time.Second)
+// Run continually checks 'conn' for new namespaces, and runs a cluster for each of
+// them until its namespace is removed.  A namespace is removed once it's stopped
+// and all of its machines have been terminated.  Minions are booted with
+// 'minionCreds', and the foreman authenticates to them with 'creds'.
+func Run(conn db.Conn, creds, minionCreds certs.Credentials) {
+	running := map[string]chan struct{}{}
+	for range conn.TriggerTick(30, db.ClusterTable).C {
+		updateNamespaces(conn, running, creds, minionCreds)
 	}
 }
 
-func updateCluster(conn db.Conn, clst *cluster) *cluster {
-	namespace, err := conn.GetClusterNamespace()
-	if err != nil {
-		return clst
+// updateNamespaces starts a cluster for each namespace in the cluster table without
+// one in 'running', and stops those whose namespace is gone.
+func updateNamespaces(conn db.Conn, running map[string]chan struct{},
+	creds, minionCreds certs.Credentials) {
+
+	namespaces := map[string]struct{}{}
+	for _, namespace := range conn.GetClusterNamespaces() {
+		namespaces[namespace] = struct{}{}
+		if _, ok := running[namespace]; ok {
+			continue
+		}
+
+		log.WithField("namespace", namespace).Info("Starting cluster.")
+		stop := make(chan struct{})
+		running[namespace] = stop
+		go runCluster(conn, namespace, creds, minionCreds, stop)
 	}
 
-	if clst == nil || clst.namespace != namespace {
-		clst = newCluster(conn, namespace)
-		clst.runOnce()
-		foreman.Init(clst.conn)
+	for namespace, stop := range running {
+		if _, ok := namespaces[namespace]; !ok {
+			log.WithField("namespace", namespace).Info("Stopping cluster.")
+			close(stop)
+			delete(running, namespace)
+		}
 	}
+}
+
+// runClusterImpl manages the machines of 'namespace' until 'stop' is closed.
+func runClusterImpl(conn db.Conn, namespace string,
+	creds, minionCreds certs.Credentials, stop <-chan struct{}) {
+
+	clst := newCluster(conn, namespace, creds, minionCreds)
+	defer clst.foreman.Stop()
 
 	clst.runOnce()
-	foreman.RunOnce(clst.conn)
+	clst.foreman.Init()
+
+	loopLog := util.NewEventTimer("Cluster")
+	trigger := conn.TriggerTick(30, db.ClusterTable, db.MachineTable, db.ACLTable)
+	defer trigger.Stop()
+	for {
+		select {
+		case <-trigger.C:
+		case <-stop:
+			return
+		}
 
-	return clst
+		loopLog.LogStart()
+		clst.runOnce()
+		clst.foreman.RunOnce()
+		loopLog.LogEnd()
+
+		// Somewhat of a crude rate-limit of once every five seconds to avoid
+		// stressing out the cloud providers with too many API calls.
+		sleep(5 * time.Second)
+	}
 }
 
-func newCluster(conn db.Conn, namespace string) *cluster {
+func newCluster(conn db.Conn, namespace string,
+	creds, minionCreds certs.Credentials) *cluster {
+
 	clst := &cluster{
-		namespace: namespace,
-		conn:      conn,
-		providers: make(map[launchLoc]provider),
+		namespace:   namespace,
+		conn:        conn,
+		providers:   make(map[launchLoc]provider),
+		foreman:     foreman.New(conn, namespace, creds),
+		minionCreds: minionCreds,
 	}
 
 	for _, p := range allProviders {
@@ -180,14 +230,21 @@ func (clst cluster) updateCloud(machines []joinMachine, act action) {
 			continue
 		}
 
+		var call string
+		start := time.Now()
 		switch act {
 		case boot:
+			call = "boot"
 			err = providerInst.Boot(providerMachines)
 		case stop:
+			call = "stop"
 			err = providerInst.Stop(providerMachines)
 		case updateIPs:
+			call = "update_floating_ips"
 			err = providerInst.UpdateFloatingIPs(providerMachines)
 		}
+		metrics.ObserveProviderCall(string(loc.provider), loc.region, call,
+			start, err)
 
 		if err != nil {
 			noFailures = false
@@ -249,28 +306,41 @@ func (clst cluster) join() (joinResult, error) {
 
 	err = clst.conn.Txn(db.ACLTable, db.ClusterTable,
 		db.MachineTable).Run(func(view db.Database) error {
-		namespace, err := view.GetClusterNamespace()
+		dbCluster, err := view.GetCluster(clst.namespace)
 		if err != nil {
-			log.WithError(err).Error("Failed to get namespace")
-			return err
-		}
-
-		if clst.namespace != namespace {
-			err := errors.New("namespace change during a cluster run")
 			log.WithError(err).Debug("Cluster run abort")
 			return err
 		}
 
-		res.acl, err = view.GetACL()
+		res.acl, err = view.GetACL(clst.namespace)
+		hasACL := err == nil
 		if err != nil {
 			log.WithError(err).Error("Failed to get ACLs")
 		}
 
-		res.machines = view.SelectFromMachine(nil)
-		cloudMachines = getMachineRoles(cloudMachines)
+		res.machines = view.SelectFromMachine(func(m db.Machine) bool {
+			return m.Namespace == clst.namespace
+		})
+
+		// Once a stopped namespace has no machines left, nothing remains to
+		// be managed, so its rows are removed and its cluster stops.
+		if len(cloudMachines) == 0 && len(res.machines) == 0 &&
+			dbCluster.Stopped {
+			log.WithField("namespace", clst.namespace).Info(
+				"Removing stopped namespace.")
+			view.Remove(dbCluster)
+			if hasACL {
+				view.Remove(res.acl)
+			}
+			return errNamespaceStopped
+		}
+		cloudMachines = clst.getMachineRoles(cloudMachines)
 
 		dbResult := syncDB(cloudMachines, res.machines)
 		res.boot = dbResult.boot
+		for i := range res.boot {
+			res.boot[i].CloudCfgOpts.TLS = clst.minionCreds
+		}
 		res.terminate = dbResult.stop
 		res.updateIPs = dbResult.updateIPs
 
@@ -318,9 +388,10 @@ func (clst cluster) syncACLs(adminACLs []string, appACLs []db.PortRange,
 	}
 	for _, appACL := range appACLs {
 		acls = append(acls, acl.ACL{
-			CidrIP:  "0.0.0.0/0",
-			MinPort: appACL.MinPort,
-			MaxPort: appACL.MaxPort,
+			CidrIP:   "0.0.0.0/0",
+			MinPort:  appACL.MinPort,
+			MaxPort:  appACL.MaxPort,
+			Protocol: appACL.Protocol,
 		})
 	}
 
@@ -346,7 +417,11 @@ func (clst cluster) syncACLs(adminACLs []string, appACLs []db.PortRange,
 			setACLs = acls
 		}
 
-		if err := prvdr.SetACLs(setACLs); err != nil {
+		start := time.Now()
+		err := prvdr.SetACLs(setACLs)
+		metrics.ObserveProviderCall(string(loc.provider), loc.region,
+			"set_acls", start, err)
+		if err != nil {
 			log.WithError(err).Warnf("Could not update ACLs on %s in %s.",
 				loc.provider, loc.region)
 		}
@@ -455,7 +530,10 @@ func (clst cluster) get() ([]joinMachine, error) {
 		wg.Add(1)
 		go func(loc launchLoc, p provider) {
 			defer wg.Done()
+			start := time.Now()
 			machines, err := p.List()
+			metrics.ObserveProviderCall(string(loc.provider), loc.region,
+				"list", start, err)
 			cloudMachinesChan <- listResponse{loc, machines, err}
 		}(loc, p)
 	}
@@ -491,9 +569,9 @@ func (clst cluster) getProvider(loc launchLoc) (provider, error) {
 	return p, err
 }
 
-func getMachineRoles(machines []joinMachine) (withRoles []joinMachine) {
+func (clst cluster) getMachineRoles(machines []joinMachine) (withRoles []joinMachine) {
 	for _, m := range machines {
-		m.role = getMachineRole(m.PublicIP)
+		m.role = getMachineRole(clst.foreman, m.PublicIP)
 		withRoles = append(withRoles, m)
 	}
 	return withRoles
@@ -542,4 +620,5 @@ func validRegionsImpl(p db.Provider) []string {
 // Stored in variables so they may be mocked out
 var newProvider = newProviderImpl
 var validRegions = validRegionsImpl
-var getMachineRole = foreman.GetMachineRole
+var getMachineRole = (*foreman.Foreman).GetMachineRole
+var runCluster = runClusterImpl
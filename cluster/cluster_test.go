@@ -7,11 +7,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/cluster/acl"
 	"github.com/quilt/quilt/cluster/cloudcfg"
+	"github.com/quilt/quilt/cluster/foreman"
 	"github.com/quilt/quilt/cluster/machine"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/join"
+	"github.com/quilt/quilt/stitch"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -130,7 +133,8 @@ func (p *fakeProvider) UpdateFloatingIPs(machines []machine.Machine) error {
 func newTestCluster(namespace string) *cluster {
 	sleep = func(t time.Duration) {}
 	mock()
-	return newCluster(db.New(), namespace)
+	return newCluster(db.New(), namespace, certs.Credentials{},
+		certs.Credentials{})
 }
 
 func TestPanicBadProvider(t *testing.T) {
@@ -142,7 +146,7 @@ func TestPanicBadProvider(t *testing.T) {
 	}()
 	allProviders = []db.Provider{FakeAmazon}
 	conn := db.New()
-	newCluster(conn, "test")
+	newCluster(conn, "test", certs.Credentials{}, certs.Credentials{})
 }
 
 func TestSyncDB(t *testing.T) {
@@ -456,9 +460,10 @@ func TestSync(t *testing.T) {
 
 	// Test initial boot
 	clst := newTestCluster("ns")
-	setNamespace(clst.conn, "ns")
+	insertCluster(clst.conn, "ns")
 	clst.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		m := view.InsertMachine()
+		m.Namespace = "ns"
 		m.Role = db.Master
 		m.Provider = FakeAmazon
 		m.Region = testRegion
@@ -473,6 +478,7 @@ func TestSync(t *testing.T) {
 	// Test adding a machine with the same provider
 	clst.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		m := view.InsertMachine()
+		m.Namespace = "ns"
 		m.Role = db.Master
 		m.Provider = FakeAmazon
 		m.Region = testRegion
@@ -487,6 +493,7 @@ func TestSync(t *testing.T) {
 	// Test adding a machine with a different provider
 	clst.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		m := view.InsertMachine()
+		m.Namespace = "ns"
 		m.Role = db.Master
 		m.Provider = FakeVagrant
 		m.Region = testRegion
@@ -514,6 +521,7 @@ func TestSync(t *testing.T) {
 	// Test booting a machine with floating IP - shouldn't update FloatingIP yet
 	clst.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		m := view.InsertMachine()
+		m.Namespace = "ns"
 		m.Role = db.Master
 		m.Provider = FakeAmazon
 		m.Size = "m4.large"
@@ -587,6 +595,7 @@ func TestSync(t *testing.T) {
 		view.Remove(toRemove)
 
 		m := view.InsertMachine()
+		m.Namespace = "ns"
 		m.Role = db.Worker
 		m.Provider = FakeAmazon
 		m.Size = "m4.xlarge"
@@ -603,6 +612,7 @@ func TestSync(t *testing.T) {
 	// Test adding machine with different role
 	clst.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		m := view.InsertMachine()
+		m.Namespace = "ns"
 		m.Role = db.Master
 		m.Provider = FakeAmazon
 		m.Size = "m4.xlarge"
@@ -623,6 +633,7 @@ func TestSync(t *testing.T) {
 		})[0]
 		view.Remove(toRemove)
 		m := view.InsertMachine()
+		m.Namespace = "ns"
 		m.Role = db.Worker
 		m.Provider = FakeAmazon
 		m.Size = "m4.xlarge"
@@ -688,79 +699,178 @@ func TestACLs(t *testing.T) {
 	assert.Equal(t, exp, actual)
 }
 
-func TestUpdateCluster(t *testing.T) {
-	conn := db.New()
+func TestUpdateNamespaces(t *testing.T) {
+	started := make(chan string, 8)
+	runCluster = func(_ db.Conn, namespace string, _, _ certs.Credentials,
+		_ <-chan struct{}) {
+		started <- namespace
+	}
+	defer func() { runCluster = runClusterImpl }()
 
-	clst := updateCluster(conn, nil)
-	assert.Nil(t, clst)
+	conn := db.New()
+	running := map[string]chan struct{}{}
+	updateNamespaces(conn, running, certs.Credentials{}, certs.Credentials{})
+	assert.Empty(t, running)
+
+	insertCluster(conn, "ns1")
+	insertCluster(conn, "ns2")
+	updateNamespaces(conn, running, certs.Credentials{}, certs.Credentials{})
+	assert.Len(t, running, 2)
+	assert.Equal(t, map[string]bool{"ns1": true, "ns2": true},
+		map[string]bool{<-started: true, <-started: true})
+	stop1, stop2 := running["ns1"], running["ns2"]
+
+	// Running clusters aren't restarted.
+	updateNamespaces(conn, running, certs.Credentials{}, certs.Credentials{})
+	assert.Len(t, running, 2)
+
+	conn.Txn(db.ClusterTable).Run(func(view db.Database) error {
+		clst, err := view.GetCluster("ns1")
+		assert.NoError(t, err)
+		view.Remove(clst)
+		return nil
+	})
+	updateNamespaces(conn, running, certs.Credentials{}, certs.Credentials{})
+	assert.Len(t, running, 1)
 
-	setNamespace(conn, "ns1")
-	clst = updateCluster(conn, clst)
-	assert.NotNil(t, clst)
-	assert.Equal(t, "ns1", clst.namespace)
+	select {
+	case <-stop1:
+	default:
+		t.Error("Expected the cluster of ns1 to stop")
+	}
 
-	inst := launchLoc{FakeAmazon, testRegion}
-	amzn := clst.providers[inst].(*fakeProvider)
-	assert.Empty(t, amzn.bootRequests)
-	assert.Empty(t, amzn.stopRequests)
-	assert.Equal(t, "ns1", amzn.namespace)
+	select {
+	case <-stop2:
+		t.Error("Unexpected stop of the cluster of ns2")
+	default:
+	}
+	assert.Empty(t, started)
+}
 
+func TestStoppedNamespace(t *testing.T) {
+	clst := newTestCluster("ns")
+	conn := clst.conn
 	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
-		m := view.InsertMachine()
-		m.Provider = FakeAmazon
-		m.Size = "size1"
-		m.Region = testRegion
-		view.Commit(m)
+		dbc := view.InsertCluster()
+		dbc.Namespace = "ns"
+		dbc.Blueprint = stitch.Stitch{Namespace: "ns"}.String()
+		view.Commit(dbc)
+
+		acl := view.InsertACL()
+		acl.Namespace = "ns"
+		view.Commit(acl)
 		return nil
 	})
 
-	oldClst := clst
-	oldAmzn := amzn
+	// A namespace without machines is only removed once it's stopped.
+	_, err := clst.join()
+	assert.NoError(t, err)
+	assert.Len(t, conn.SelectFromCluster(nil), 1)
+	assert.Len(t, conn.SelectFromACL(nil), 1)
 
-	clst = updateCluster(conn, clst)
-	assert.NotNil(t, clst)
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		dbc, err := view.GetCluster("ns")
+		assert.NoError(t, err)
+		dbc.Stopped = true
+		view.Commit(dbc)
+		return nil
+	})
 
-	// Pointers shouldn't have changed
-	amzn = clst.providers[inst].(*fakeProvider)
-	assert.True(t, oldClst == clst)
-	assert.True(t, oldAmzn == amzn)
+	// The namespace isn't removed while it still has machines in the cloud.
+	inst := launchLoc{FakeAmazon, testRegion}
+	provider := clst.providers[inst].(*fakeProvider)
+	provider.machines["1"] = machine.Machine{ID: "1", PublicIP: "1.1.1.1",
+		Size: "m4.large"}
+	_, err = clst.join()
+	assert.NoError(t, err)
+	assert.Len(t, conn.SelectFromCluster(nil), 1)
+	assert.Len(t, conn.SelectFromACL(nil), 1)
 
-	assert.Empty(t, amzn.stopRequests)
-	assert.Equal(t, []bootRequest{{
-		size:        "size1",
-		cloudConfig: amazonCloudConfig,
-	}}, amzn.bootRequests)
-	assert.Equal(t, "ns1", amzn.namespace)
-	amzn.clearLogs()
+	clst.runOnce()
+	assert.Equal(t, []string{"1"}, provider.stopRequests)
+	assert.Len(t, conn.SelectFromCluster(nil), 0)
+	assert.Len(t, conn.SelectFromACL(nil), 0)
+
+	// Once the namespace is removed, its cluster is stopped.
+	done := make(chan struct{})
+	runCluster = func(conn db.Conn, namespace string,
+		creds, minionCreds certs.Credentials, stop <-chan struct{}) {
+		runClusterImpl(conn, namespace, creds, minionCreds, stop)
+		close(done)
+	}
+	defer func() { runCluster = runClusterImpl }()
 
 	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
-		dbms := view.SelectFromMachine(nil)
-		dbms[0].Size = "size2"
-		view.Commit(dbms[0])
+		dbc := view.InsertCluster()
+		dbc.Namespace = "ns2"
+		dbc.Blueprint = stitch.Stitch{Namespace: "ns2"}.String()
+		dbc.Stopped = true
+		view.Commit(dbc)
 		return nil
 	})
 
-	oldClst = clst
-	oldAmzn = amzn
-	setNamespace(conn, "ns2")
-	clst = updateCluster(conn, clst)
-	assert.NotNil(t, clst)
+	running := map[string]chan struct{}{}
+	updateNamespaces(conn, running, certs.Credentials{}, certs.Credentials{})
+	assert.Len(t, running, 1)
 
-	// Pointers should have changed
-	amzn = clst.providers[inst].(*fakeProvider)
-	assert.True(t, oldClst != clst)
-	assert.True(t, oldAmzn != amzn)
+	timeout := time.After(10 * time.Second)
+	trigger := conn.Trigger(db.ClusterTable)
+	defer trigger.Stop()
+	for len(conn.SelectFromCluster(nil)) != 0 {
+		select {
+		case <-trigger.C:
+		case <-timeout:
+			t.Fatal("Stopped namespace wasn't removed")
+		}
+	}
 
-	assert.Equal(t, "ns1", oldAmzn.namespace)
-	assert.Empty(t, oldAmzn.bootRequests)
-	assert.Empty(t, oldAmzn.stopRequests)
+	updateNamespaces(conn, running, certs.Credentials{}, certs.Credentials{})
+	assert.Empty(t, running)
 
-	assert.Equal(t, "ns2", amzn.namespace)
-	assert.Equal(t, []bootRequest{{
-		size:        "size2",
-		cloudConfig: amazonCloudConfig,
-	}}, amzn.bootRequests)
-	assert.Empty(t, amzn.stopRequests)
+	select {
+	case <-done:
+	case <-timeout:
+		t.Fatal("Cluster of the stopped namespace didn't stop")
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	sleep = func(t time.Duration) {}
+	mock()
+
+	conn := db.New()
+	clusters := map[string]*cluster{}
+	for _, ns := range []string{"ns1", "ns2"} {
+		insertCluster(conn, ns)
+		clusters[ns] = newCluster(conn, ns, certs.Credentials{},
+			certs.Credentials{})
+	}
+
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		for _, size := range []string{"size1", "size2"} {
+			m := view.InsertMachine()
+			m.Namespace = "ns1"
+			m.Provider = FakeAmazon
+			m.Size = size
+			m.Region = testRegion
+			view.Commit(m)
+		}
+		return nil
+	})
+
+	for _, clst := range clusters {
+		clst.runOnce()
+	}
+
+	// Each cluster only boots the machines of its own namespace.
+	inst := launchLoc{FakeAmazon, testRegion}
+	ns1 := clusters["ns1"].providers[inst].(*fakeProvider)
+	ns2 := clusters["ns2"].providers[inst].(*fakeProvider)
+	assert.Equal(t, "ns1", ns1.namespace)
+	assert.Len(t, ns1.bootRequests, 2)
+	assert.Equal(t, "ns2", ns2.namespace)
+	assert.Empty(t, ns2.bootRequests)
+	assert.Empty(t, ns2.stopRequests)
 }
 
 func TestMultiRegionDeploy(t *testing.T) {
@@ -771,6 +881,7 @@ func TestMultiRegionDeploy(t *testing.T) {
 		for _, p := range allProviders {
 			for _, r := range validRegions(p) {
 				m := view.InsertMachine()
+				m.Namespace = "ns"
 				m.Provider = p
 				m.Region = r
 				m.Size = "size1"
@@ -849,13 +960,9 @@ func TestGetError(t *testing.T) {
 	assert.EqualError(t, err, "list Vagrant: err")
 }
 
-func setNamespace(conn db.Conn, ns string) {
+func insertCluster(conn db.Conn, ns string) {
 	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
-		clst, err := view.GetCluster()
-		if err != nil {
-			clst = view.InsertCluster()
-		}
-
+		clst := view.InsertCluster()
 		clst.Namespace = ns
 		view.Commit(clst)
 		return nil
@@ -887,7 +994,7 @@ func mock() {
 
 	validRegions = fakeValidRegions
 	allProviders = []db.Provider{FakeAmazon, FakeVagrant}
-	getMachineRole = func(ip string) db.Role {
+	getMachineRole = func(_ *foreman.Foreman, ip string) db.Role {
 		for _, prvdr := range instantiatedProviders {
 			if role, ok := prvdr.roles[ip]; ok {
 				return role
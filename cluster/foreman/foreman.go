@@ -9,13 +9,23 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/minion/pb"
 
 	log "github.com/Sirupsen/logrus"
 )
 
-var minions map[string]*minion
+// A Foreman configures the minions running on the machines of a single namespace.
+type Foreman struct {
+	conn      db.Conn
+	namespace string
+
+	// The credentials with which the foreman authenticates to minions.
+	creds certs.Credentials
+
+	minions map[string]*minion
+}
 
 type client interface {
 	setMinion(pb.MinionConfig) error
@@ -38,22 +48,31 @@ type minion struct {
 	mark bool /* Mark and sweep garbage collection. */
 }
 
-// Init the first time the foreman operates on a new namespace.  It queries the currently
-// running VMs for their previously assigned roles, and writes them to the database.
-func Init(conn db.Conn) {
-	for _, m := range minions {
-		m.client.Close()
+// New creates a Foreman for the machines of `namespace`.  Minions are connected to
+// using mutual TLS authenticated with 'creds'.
+func New(conn db.Conn, namespace string, creds certs.Credentials) *Foreman {
+	return &Foreman{
+		conn:      conn,
+		namespace: namespace,
+		creds:     creds,
+		minions:   map[string]*minion{},
 	}
-	minions = map[string]*minion{}
+}
 
-	conn.Txn(db.MachineTable).Run(func(view db.Database) error {
+// Init the first time the foreman operates on its namespace.  It queries the
+// currently running VMs for their previously assigned roles, and writes them to the
+// database.
+func (fm *Foreman) Init() {
+	fm.Stop()
+	fm.conn.Txn(db.MachineTable).Run(func(view db.Database) error {
 		machines := view.SelectFromMachine(func(m db.Machine) bool {
-			return m.PublicIP != "" && m.PrivateIP != "" && m.CloudID != ""
+			return m.Namespace == fm.namespace && m.PublicIP != "" &&
+				m.PrivateIP != "" && m.CloudID != ""
 		})
 
-		updateMinionMap(machines)
-		forEachMinion(updateConfig)
-		for _, m := range minions {
+		fm.updateMinionMap(machines)
+		fm.forEachMinion(updateConfig)
+		for _, m := range fm.minions {
 			role := db.PBToRole(m.config.Role)
 			if m.connected && role != db.None {
 				m.machine.Role = role
@@ -67,45 +86,55 @@ func Init(conn db.Conn) {
 }
 
 // RunOnce should be called regularly to allow the foreman to update minion cfg.
-func RunOnce(conn db.Conn) {
+func (fm *Foreman) RunOnce() {
 	var blueprint string
 	var machines []db.Machine
-	conn.Txn(db.ClusterTable,
+	fm.conn.Txn(db.ClusterTable,
 		db.MachineTable).Run(func(view db.Database) error {
 
 		machines = view.SelectFromMachine(func(m db.Machine) bool {
-			return m.PublicIP != "" && m.PrivateIP != ""
+			return m.Namespace == fm.namespace && m.PublicIP != "" &&
+				m.PrivateIP != ""
 		})
 
-		clst, _ := view.GetCluster()
+		clst, _ := view.GetCluster(fm.namespace)
 		blueprint = clst.Blueprint
 
 		return nil
 	})
 
-	updateMinionMap(machines)
+	fm.updateMinionMap(machines)
 
-	forEachMinion(updateConfig)
-	forEachMinion(func(m *minion) {
+	fm.forEachMinion(updateConfig)
+	fm.forEachMinion(func(m *minion) {
 		if m.connected != m.machine.Connected {
-			tr := conn.Txn(db.MachineTable)
+			tr := fm.conn.Txn(db.MachineTable)
 			tr.Run(func(view db.Database) error {
-				m.machine.Connected = m.connected
-				view.Commit(m.machine)
+				// Reread the machine, as it may have changed since the
+				// foreman queried it.
+				ip := m.machine.PublicIP
+				for _, dbm := range view.SelectFromMachineByPublicIP(ip) {
+					if dbm.Namespace != fm.namespace {
+						continue
+					}
+					dbm.Connected = m.connected
+					view.Commit(dbm)
+					m.machine = dbm
+				}
 				return nil
 			})
 		}
 	})
 
 	var etcdIPs []string
-	for _, m := range minions {
+	for _, m := range fm.minions {
 		if m.machine.Role == db.Master && m.machine.PrivateIP != "" {
 			etcdIPs = append(etcdIPs, m.machine.PrivateIP)
 		}
 	}
 
 	// Assign all of the minions their new configs
-	forEachMinion(func(m *minion) {
+	fm.forEachMinion(func(m *minion) {
 		if !m.connected {
 			return
 		}
@@ -134,43 +163,51 @@ func RunOnce(conn db.Conn) {
 
 // GetMachineRole uses the minion map to find the associated minion with
 // the IP, according to the foreman's last update cycle.
-func GetMachineRole(pubIP string) db.Role {
-	if min, ok := minions[pubIP]; ok {
+func (fm *Foreman) GetMachineRole(pubIP string) db.Role {
+	if min, ok := fm.minions[pubIP]; ok {
 		return db.PBToRole(min.config.Role)
 	}
 	return db.None
 }
 
-func updateMinionMap(machines []db.Machine) {
+// Stop closes the foreman's connections to its minions.
+func (fm *Foreman) Stop() {
+	for _, m := range fm.minions {
+		m.client.Close()
+	}
+	fm.minions = map[string]*minion{}
+}
+
+func (fm *Foreman) updateMinionMap(machines []db.Machine) {
 	for _, m := range machines {
-		min, ok := minions[m.PublicIP]
+		min, ok := fm.minions[m.PublicIP]
 		if !ok {
-			client, err := newClient(m.PublicIP)
+			client, err := newClient(m.PublicIP, fm.creds)
 			if err != nil {
 				continue
 			}
 			min = &minion{client: client}
-			minions[m.PublicIP] = min
+			fm.minions[m.PublicIP] = min
 		}
 
 		min.machine = m
 		min.mark = true
 	}
 
-	for k, minion := range minions {
+	for k, minion := range fm.minions {
 		if minion.mark {
 			minion.mark = false
 		} else {
 			minion.client.Close()
-			delete(minions, k)
+			delete(fm.minions, k)
 		}
 	}
 }
 
-func forEachMinion(do func(minion *minion)) {
+func (fm *Foreman) forEachMinion(do func(minion *minion)) {
 	var wg sync.WaitGroup
-	wg.Add(len(minions))
-	for _, m := range minions {
+	wg.Add(len(fm.minions))
+	for _, m := range fm.minions {
 		go func(m *minion) {
 			do(m)
 			wg.Done()
@@ -197,8 +234,13 @@ func updateConfig(m *minion) {
 	m.connected = connected
 }
 
-func newClientImpl(ip string) (client, error) {
-	cc, err := grpc.Dial(ip+":9999", grpc.WithInsecure())
+func newClientImpl(ip string, creds certs.Credentials) (client, error) {
+	tlsOpt, err := creds.DialOption()
+	if err != nil {
+		return nil, err
+	}
+
+	cc, err := grpc.Dial(ip+":9999", tlsOpt)
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/minion/pb"
 )
@@ -15,12 +16,12 @@ type clients struct {
 }
 
 func TestBoot(t *testing.T) {
-	conn, clients := startTest()
-	RunOnce(conn)
+	fm, clients := startTest()
+	fm.RunOnce()
 
 	assert.Zero(t, clients.newCalls)
 
-	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+	fm.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		m := view.InsertMachine()
 		m.PublicIP = "1.1.1.1"
 		m.PrivateIP = "1.1.1.1."
@@ -29,17 +30,17 @@ func TestBoot(t *testing.T) {
 		return nil
 	})
 
-	RunOnce(conn)
+	fm.RunOnce()
 	assert.Equal(t, 1, clients.newCalls)
 	_, ok := clients.clients["1.1.1.1"]
 	assert.True(t, ok)
 
-	RunOnce(conn)
+	fm.RunOnce()
 	assert.Equal(t, 1, clients.newCalls)
 	_, ok = clients.clients["1.1.1.1"]
 	assert.True(t, ok)
 
-	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+	fm.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		m := view.InsertMachine()
 		m.PublicIP = "2.2.2.2"
 		m.PrivateIP = "2.2.2.2"
@@ -48,7 +49,7 @@ func TestBoot(t *testing.T) {
 		return nil
 	})
 
-	RunOnce(conn)
+	fm.RunOnce()
 	assert.Equal(t, 2, clients.newCalls)
 
 	_, ok = clients.clients["2.2.2.2"]
@@ -57,10 +58,10 @@ func TestBoot(t *testing.T) {
 	_, ok = clients.clients["1.1.1.1"]
 	assert.True(t, ok)
 
-	RunOnce(conn)
-	RunOnce(conn)
-	RunOnce(conn)
-	RunOnce(conn)
+	fm.RunOnce()
+	fm.RunOnce()
+	fm.RunOnce()
+	fm.RunOnce()
 	assert.Equal(t, 2, clients.newCalls)
 
 	_, ok = clients.clients["2.2.2.2"]
@@ -69,7 +70,7 @@ func TestBoot(t *testing.T) {
 	_, ok = clients.clients["1.1.1.1"]
 	assert.True(t, ok)
 
-	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+	fm.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		machines := view.SelectFromMachine(func(m db.Machine) bool {
 			return m.PublicIP == "1.1.1.1"
 		})
@@ -77,7 +78,7 @@ func TestBoot(t *testing.T) {
 		return nil
 	})
 
-	RunOnce(conn)
+	fm.RunOnce()
 	assert.Equal(t, 2, clients.newCalls)
 
 	_, ok = clients.clients["2.2.2.2"]
@@ -86,10 +87,10 @@ func TestBoot(t *testing.T) {
 	_, ok = clients.clients["1.1.1.1"]
 	assert.False(t, ok)
 
-	RunOnce(conn)
-	RunOnce(conn)
-	RunOnce(conn)
-	RunOnce(conn)
+	fm.RunOnce()
+	fm.RunOnce()
+	fm.RunOnce()
+	fm.RunOnce()
 	assert.Equal(t, 2, clients.newCalls)
 
 	_, ok = clients.clients["2.2.2.2"]
@@ -100,8 +101,8 @@ func TestBoot(t *testing.T) {
 }
 
 func TestBootEtcd(t *testing.T) {
-	conn, clients := startTest()
-	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+	fm, clients := startTest()
+	fm.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		m := view.InsertMachine()
 		m.Role = db.Master
 		m.PublicIP = "m1-pub"
@@ -117,10 +118,10 @@ func TestBootEtcd(t *testing.T) {
 		view.Commit(m)
 		return nil
 	})
-	RunOnce(conn)
+	fm.RunOnce()
 	assert.Equal(t, []string{"m1-priv"}, clients.clients["w1-pub"].mc.EtcdMembers)
 
-	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+	fm.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		m := view.InsertMachine()
 		m.Role = db.Master
 		m.PublicIP = "m2-pub"
@@ -129,43 +130,72 @@ func TestBootEtcd(t *testing.T) {
 		view.Commit(m)
 		return nil
 	})
-	RunOnce(conn)
+	fm.RunOnce()
 	etcdMembers := clients.clients["w1-pub"].mc.EtcdMembers
 	assert.Len(t, etcdMembers, 2)
 	assert.Contains(t, etcdMembers, "m1-priv")
 	assert.Contains(t, etcdMembers, "m2-priv")
 
-	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+	fm.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		var toDelete = view.SelectFromMachine(func(m db.Machine) bool {
 			return m.PrivateIP == "m1-priv"
 		})[0]
 		view.Remove(toDelete)
 		return nil
 	})
-	RunOnce(conn)
+	fm.RunOnce()
 	assert.Equal(t, []string{"m2-priv"},
 		clients.clients["w1-pub"].mc.EtcdMembers)
 }
 
+func TestNamespace(t *testing.T) {
+	fm, clients := startTest()
+	fm.namespace = "ns"
+	fm.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		for _, ns := range []string{"ns", "other"} {
+			clst := view.InsertCluster()
+			clst.Namespace = ns
+			clst.Blueprint = ns + "-blueprint"
+			view.Commit(clst)
+
+			m := view.InsertMachine()
+			m.Namespace = ns
+			m.PublicIP = ns + "-pub"
+			m.PrivateIP = ns + "-priv"
+			view.Commit(m)
+		}
+		return nil
+	})
+
+	// The foreman only configures the minions in its namespace.
+	fm.RunOnce()
+	assert.Equal(t, 1, clients.newCalls)
+	assert.Equal(t, "ns-blueprint", clients.clients["ns-pub"].mc.Blueprint)
+	assert.NotContains(t, clients.clients, "other-pub")
+
+	fm.Stop()
+	assert.Empty(t, fm.minions)
+	assert.Empty(t, clients.clients)
+}
+
 func TestGetMachineRole(t *testing.T) {
 	workerMinion := minion{
 		config: pb.MinionConfig{
 			Role: pb.MinionConfig_WORKER,
 		},
 	}
-	minions = map[string]*minion{
+	fm := New(db.New(), "", certs.Credentials{})
+	fm.minions = map[string]*minion{
 		"1.1.1.1": &workerMinion,
 	}
 
-	assert.Equal(t, db.Role(db.Worker), GetMachineRole("1.1.1.1"))
-	assert.Equal(t, db.Role(db.None), GetMachineRole("none"))
-
-	minions = map[string]*minion{}
+	assert.Equal(t, db.Role(db.Worker), fm.GetMachineRole("1.1.1.1"))
+	assert.Equal(t, db.Role(db.None), fm.GetMachineRole("none"))
 }
 
 func TestInitForeman(t *testing.T) {
-	conn := startTestWithRole(pb.MinionConfig_WORKER)
-	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+	fm := startTestWithRole(pb.MinionConfig_WORKER)
+	fm.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		m := view.InsertMachine()
 		m.PublicIP = "2.2.2.2"
 		m.PrivateIP = "2.2.2.2"
@@ -174,23 +204,44 @@ func TestInitForeman(t *testing.T) {
 		return nil
 	})
 
-	Init(conn)
-	for _, m := range minions {
+	fm.Init()
+	for _, m := range fm.minions {
 		assert.Equal(t, db.Role(db.Worker), m.machine.Role)
 	}
 
-	conn = startTestWithRole(pb.MinionConfig_Role(-7))
-	Init(conn)
-	for _, m := range minions {
+	fm = startTestWithRole(pb.MinionConfig_Role(-7))
+	fm.Init()
+	for _, m := range fm.minions {
 		assert.Equal(t, db.None, m.machine.Role)
 	}
 }
 
-func startTest() (db.Conn, *clients) {
-	conn := db.New()
-	minions = map[string]*minion{}
+func TestConnectedKeepsChanges(t *testing.T) {
+	fm, _ := startTest()
+	fm.conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		m := view.InsertMachine()
+		m.PublicIP = "1.1.1.1"
+		m.PrivateIP = "1.1.1.1"
+		m.CloudID = "ID"
+		view.Commit(m)
+		return nil
+	})
+
+	// Change the machine while the foreman is querying its minion.
+	newClient = func(ip string, _ certs.Credentials) (client, error) {
+		return changingClient{fm.conn, ip}, nil
+	}
+	fm.RunOnce()
+
+	machines := fm.conn.SelectFromMachine(nil)
+	assert.Len(t, machines, 1)
+	assert.True(t, machines[0].Connected)
+	assert.Equal(t, "floating", machines[0].FloatingIP)
+}
+
+func startTest() (*Foreman, *clients) {
 	clients := &clients{make(map[string]*fakeClient), 0}
-	newClient = func(ip string) (client, error) {
+	newClient = func(ip string, _ certs.Credentials) (client, error) {
 		if fc, ok := clients.clients[ip]; ok {
 			return fc, nil
 		}
@@ -199,18 +250,18 @@ func startTest() (db.Conn, *clients) {
 		clients.newCalls++
 		return fc, nil
 	}
-	return conn, clients
+	return New(db.New(), "", certs.Credentials{}), clients
 }
 
-func startTestWithRole(role pb.MinionConfig_Role) db.Conn {
+func startTestWithRole(role pb.MinionConfig_Role) *Foreman {
 	clientInst := &clients{make(map[string]*fakeClient), 0}
-	newClient = func(ip string) (client, error) {
+	newClient = func(ip string, _ certs.Credentials) (client, error) {
 		fc := &fakeClient{clientInst, ip, pb.MinionConfig{Role: role}}
 		clientInst.clients[ip] = fc
 		clientInst.newCalls++
 		return fc, nil
 	}
-	return db.New()
+	return New(db.New(), "", certs.Credentials{})
 }
 
 type fakeClient struct {
@@ -231,3 +282,25 @@ func (fc *fakeClient) getMinion() (pb.MinionConfig, error) {
 func (fc *fakeClient) Close() {
 	delete(fc.clients.clients, fc.ip)
 }
+
+type changingClient struct {
+	conn db.Conn
+	ip   string
+}
+
+func (cc changingClient) setMinion(mc pb.MinionConfig) error {
+	return nil
+}
+
+func (cc changingClient) getMinion() (pb.MinionConfig, error) {
+	cc.conn.Txn(db.MachineTable).Run(func(view db.Database) error {
+		for _, m := range view.SelectFromMachineByPublicIP(cc.ip) {
+			m.FloatingIP = "floating"
+			view.Commit(m)
+		}
+		return nil
+	})
+	return pb.MinionConfig{}, nil
+}
+
+func (cc changingClient) Close() {}
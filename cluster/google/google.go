@@ -346,6 +346,13 @@ func (clst *Cluster) parseACLs(fws []compute.Firewall) (acls []acl.ACL, err erro
 }
 
 func parsePorts(allowed []*compute.FirewallAllowed) (acls []acl.ACL, err error) {
+	// Firewalls for ACLs that are restricted to a protocol only allow that
+	// protocol.  Otherwise, the firewall allows TCP, UDP, and ICMP.
+	var protocol string
+	if len(allowed) == 1 {
+		protocol = allowed[0].IPProtocol
+	}
+
 	for _, rule := range allowed {
 		for _, portsStr := range rule.Ports {
 			portRange, err := parseInts(strings.Split(portsStr, "-"))
@@ -363,9 +370,15 @@ func parsePorts(allowed []*compute.FirewallAllowed) (acls []acl.ACL, err error)
 				return nil, fmt.Errorf(
 					"unrecognized port format: %s", portsStr)
 			}
-			acls = append(acls, acl.ACL{MinPort: min, MaxPort: max})
+			acls = append(acls, acl.ACL{MinPort: min, MaxPort: max,
+				Protocol: protocol})
 		}
 	}
+
+	// ICMP has no ports.
+	if protocol == "icmp" {
+		acls = append(acls, acl.ACL{Protocol: protocol})
+	}
 	return acls, nil
 }
 
@@ -404,14 +417,15 @@ func (clst *Cluster) SetACLs(acls []acl.ACL) error {
 	}
 	for _, a := range toRemove {
 		toSet = append(toSet, acl.ACL{
-			MinPort: a.(acl.ACL).MinPort,
-			MaxPort: a.(acl.ACL).MaxPort,
-			CidrIP:  "", // Remove all currently allowed IPs.
+			MinPort:  a.(acl.ACL).MinPort,
+			MaxPort:  a.(acl.ACL).MaxPort,
+			Protocol: a.(acl.ACL).Protocol,
+			CidrIP:   "", // Remove all currently allowed IPs.
 		})
 	}
 
 	for acl, cidrIPs := range groupACLsByPorts(toSet) {
-		fw, err := clst.getCreateFirewall(acl.MinPort, acl.MaxPort)
+		fw, err := clst.getCreateFirewall(acl)
 		if err != nil {
 			return err
 		}
@@ -420,18 +434,20 @@ func (clst *Cluster) SetACLs(acls []acl.ACL) error {
 			continue
 		}
 
+		ports := fmt.Sprintf("%d-%d", acl.MinPort, acl.MaxPort)
+		if acl.Protocol != "" {
+			ports += "/" + acl.Protocol
+		}
+
 		var op *compute.Operation
 		if len(cidrIPs) == 0 {
-			log.WithField("ports", fmt.Sprintf(
-				"%d-%d", acl.MinPort, acl.MaxPort)).
-				Debug("Google: Deleting firewall")
+			log.WithField("ports", ports).Debug("Google: Deleting firewall")
 			op, err = clst.gce.DeleteFirewall(fw.Name)
 			if err != nil {
 				return err
 			}
 		} else {
-			log.WithField("ports", fmt.Sprintf(
-				"%d-%d", acl.MinPort, acl.MaxPort)).
+			log.WithField("ports", ports).
 				WithField("CidrIPs", cidrIPs).
 				Debug("Google: Setting ACLs")
 			op, err = clst.firewallPatch(fw.Name, cidrIPs)
@@ -498,18 +514,26 @@ func (clst *Cluster) getFirewall(name string) (*compute.Firewall, error) {
 	return nil, nil
 }
 
-func (clst *Cluster) getCreateFirewall(minPort int, maxPort int) (
-	*compute.Firewall, error) {
-
-	ports := fmt.Sprintf("%d-%d", minPort, maxPort)
+// getCreateFirewall returns the firewall for the ports and protocol of `acl`,
+// creating it if it doesn't exist.
+func (clst *Cluster) getCreateFirewall(acl acl.ACL) (*compute.Firewall, error) {
+	ports := fmt.Sprintf("%d-%d", acl.MinPort, acl.MaxPort)
 	fwName := fmt.Sprintf("%s-%s-%s", clst.ns, clst.zone, ports)
+	switch acl.Protocol {
+	case "":
+	case "icmp":
+		fwName = fmt.Sprintf("%s-%s-icmp", clst.ns, clst.zone)
+	default:
+		fwName += "-" + acl.Protocol
+	}
 
 	if fw, _ := clst.getFirewall(fwName); fw != nil {
 		return fw, nil
 	}
 
 	log.WithField("name", fwName).Debug("Creating firewall")
-	op, err := clst.insertFirewall(fwName, ports, []string{"127.0.0.1/32"}, true)
+	op, err := clst.insertFirewall(fwName, acl.Protocol, ports,
+		[]string{"127.0.0.1/32"}, true)
 	if err != nil {
 		return nil, err
 	}
@@ -534,31 +558,33 @@ func (clst *Cluster) networkExists(name string) (bool, error) {
 	return false, nil
 }
 
-// This creates a firewall but does nothing else
-func (clst *Cluster) insertFirewall(name, ports string, sourceRanges []string,
-	restrictToZone bool) (*compute.Operation, error) {
+// This creates a firewall but does nothing else.  If `protocol` is empty, the
+// firewall allows TCP, UDP, and ICMP traffic.
+func (clst *Cluster) insertFirewall(name, protocol, ports string,
+	sourceRanges []string, restrictToZone bool) (*compute.Operation, error) {
 
 	var targetTags []string
 	if restrictToZone {
 		targetTags = []string{clst.zone}
 	}
 
+	var allowed []*compute.FirewallAllowed
+	for _, proto := range []string{"tcp", "udp", "icmp"} {
+		if protocol != "" && protocol != proto {
+			continue
+		}
+
+		rule := &compute.FirewallAllowed{IPProtocol: proto}
+		if proto != "icmp" {
+			rule.Ports = []string{ports}
+		}
+		allowed = append(allowed, rule)
+	}
+
 	firewall := &compute.Firewall{
-		Name:    name,
-		Network: networkURL(clst.networkName),
-		Allowed: []*compute.FirewallAllowed{
-			{
-				IPProtocol: "tcp",
-				Ports:      []string{ports},
-			},
-			{
-				IPProtocol: "udp",
-				Ports:      []string{ports},
-			},
-			{
-				IPProtocol: "icmp",
-			},
-		},
+		Name:         name,
+		Network:      networkURL(clst.networkName),
+		Allowed:      allowed,
 		SourceRanges: sourceRanges,
 		TargetTags:   targetTags,
 	}
@@ -625,7 +651,7 @@ func (clst *Cluster) createInternalFirewall() error {
 	} else {
 		log.Debug("creating internal firewall")
 		op, err := clst.insertFirewall(
-			clst.intFW, "1-65535", []string{clst.ipv4Range}, false)
+			clst.intFW, "", "1-65535", []string{clst.ipv4Range}, false)
 		if err != nil {
 			return err
 		}
@@ -646,8 +672,9 @@ func groupACLsByPorts(acls []acl.ACL) map[acl.ACL][]string {
 	grouped := make(map[acl.ACL][]string)
 	for _, a := range acls {
 		key := acl.ACL{
-			MinPort: a.MinPort,
-			MaxPort: a.MaxPort,
+			MinPort:  a.MinPort,
+			MaxPort:  a.MaxPort,
+			Protocol: a.Protocol,
 		}
 		if _, ok := grouped[key]; !ok {
 			grouped[key] = nil
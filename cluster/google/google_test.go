@@ -125,6 +125,28 @@ func (s *GoogleTestSuite) TestParseACLs() {
 		{MinPort: 1, MaxPort: 65535, CidrIP: "foo"},
 	}, parsed)
 
+	parsed, err = s.clst.parseACLs([]compute.Firewall{
+		{
+			Name: "dns",
+			Allowed: []*compute.FirewallAllowed{
+				{IPProtocol: "udp", Ports: []string{"53-53"}},
+			},
+			SourceRanges: []string{"foo"},
+		},
+		{
+			Name: "ping",
+			Allowed: []*compute.FirewallAllowed{
+				{IPProtocol: "icmp"},
+			},
+			SourceRanges: []string{"foo"},
+		},
+	})
+	s.NoError(err)
+	s.Equal([]acl.ACL{
+		{MinPort: 53, MaxPort: 53, Protocol: "udp", CidrIP: "foo"},
+		{Protocol: "icmp", CidrIP: "foo"},
+	}, parsed)
+
 	_, err = s.clst.parseACLs([]compute.Firewall{
 		{
 			Name: "firewall",
@@ -49,6 +49,33 @@ func ChooseSize(provider db.Provider, ram, cpu stitch.Range, maxPrice float64) s
 	}
 }
 
+// Describe returns the description of machines of the given provider and size, and
+// whether the size is known.  Vagrant sizes describe only the RAM and CPU.
+func Describe(provider db.Provider, size string) (Description, bool) {
+	var descriptions []Description
+	switch provider {
+	case db.Amazon:
+		descriptions = amazonDescriptions
+	case db.DigitalOcean:
+		descriptions = digitalOceanDescriptions
+	case db.Google:
+		descriptions = googleDescriptions
+	case db.Vagrant:
+		var ram, cpu float64
+		if _, err := fmt.Sscanf(size, "%g,%g", &ram, &cpu); err != nil {
+			return Description{}, false
+		}
+		return Description{Size: size, RAM: ram, CPU: int(cpu)}, true
+	}
+
+	for _, d := range descriptions {
+		if d.Size == size {
+			return d, true
+		}
+	}
+	return Description{}, false
+}
+
 func chooseBestSize(descriptions []Description, ram, cpu stitch.Range,
 	maxPrice float64) string {
 	var best Description
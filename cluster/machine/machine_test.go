@@ -3,7 +3,9 @@ package machine
 import (
 	"testing"
 
+	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/stitch"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestConstraints(t *testing.T) {
@@ -61,3 +63,24 @@ func TestConstraints(t *testing.T) {
 	checkConstraint(testDescriptions, stitch.Range{Min: 3},
 		stitch.Range{}, 0, "size4")
 }
+
+func TestDescribe(t *testing.T) {
+	d, ok := Describe(db.Amazon, "m4.large")
+	assert.True(t, ok)
+	assert.Equal(t, 2, d.CPU)
+	assert.Equal(t, 8.0, d.RAM)
+
+	d, ok = Describe(db.Vagrant, vagrantSize(stitch.Range{Min: 2},
+		stitch.Range{Min: 4}))
+	assert.True(t, ok)
+	assert.Equal(t, Description{Size: "2,4", RAM: 2, CPU: 4}, d)
+
+	_, ok = Describe(db.Google, "m4.large")
+	assert.False(t, ok)
+
+	_, ok = Describe(db.Vagrant, "large")
+	assert.False(t, ok)
+
+	_, ok = Describe("", "m4.large")
+	assert.False(t, ok)
+}
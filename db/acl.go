@@ -1,23 +1,23 @@
 package db
 
-import (
-	"errors"
-	"fmt"
-	"log"
-)
+import "fmt"
 
-// ACL defines access control for Quilt-managed machines.
+// ACL defines access control for the Quilt-managed machines of a namespace.
 type ACL struct {
 	ID int
 
+	Namespace string
+
 	Admin            []string
 	ApplicationPorts []PortRange
 }
 
-// PortRange represents a range of ports for which to allow traffic.
+// PortRange represents a range of ports for which to allow traffic.  If Protocol
+// is set, only traffic of that protocol is allowed.
 type PortRange struct {
-	MinPort int
-	MaxPort int
+	MinPort  int
+	MaxPort  int
+	Protocol string `json:",omitempty"`
 }
 
 func (pr PortRange) String() string {
@@ -25,6 +25,9 @@ func (pr PortRange) String() string {
 	if pr.MaxPort != pr.MinPort {
 		port += fmt.Sprintf("-%d", pr.MaxPort)
 	}
+	if pr.Protocol != "" {
+		port += "/" + pr.Protocol
+	}
 	return port
 }
 
@@ -47,17 +50,26 @@ func (db Database) SelectFromACL(check func(ACL) bool) []ACL {
 	return result
 }
 
-// GetACL gets the ACL row from the database. There should only ever be a single
-// ACL row.
-func (db Database) GetACL() (ACL, error) {
-	aclRows := db.SelectFromACL(nil)
-	numACLs := len(aclRows)
-	if numACLs == 1 {
-		return aclRows[0], nil
-	} else if numACLs > 1 {
-		log.Panicf("Found %d ACL rows, there should be 1", numACLs)
+// SelectFromACL gets all acls in the database connection that satisfy 'check'.
+func (conn Conn) SelectFromACL(check func(ACL) bool) []ACL {
+	var acls []ACL
+	conn.ReadTxn(ACLTable).Run(func(view Database) error {
+		acls = view.SelectFromACL(check)
+		return nil
+	})
+	return acls
+}
+
+// GetACL gets the ACL row of 'namespace' from the database. There is at most one ACL
+// row per namespace, as enforced by a Unique constraint.
+func (db Database) GetACL(namespace string) (ACL, error) {
+	aclRows := db.SelectFromACL(func(acl ACL) bool {
+		return acl.Namespace == namespace
+	})
+	if len(aclRows) == 0 {
+		return ACL{}, fmt.Errorf("no ACL row in namespace %q", namespace)
 	}
-	return ACL{}, errors.New("no ACL rows found")
+	return aclRows[0], nil
 }
 
 func (acl ACL) getID() int {
@@ -1,16 +1,25 @@
 package db
 
 import (
-	"errors"
-	"log"
+	"fmt"
+	"sort"
 )
 
-// A Cluster is a group of Machines which can operate containers.
+// A Cluster is a group of Machines which can operate containers.  Each Cluster
+// implements the blueprint of a single namespace.
 type Cluster struct {
 	ID int
 
 	Namespace string // Cloud Provider Namespace
 	Blueprint string `rowStringer:"omit"`
+
+	// Identifies the deployment of Blueprint, so that clients can track its
+	// progress.
+	DeploymentID string
+
+	// Stopped is set by `quilt stop`, and causes the cluster to be removed once
+	// its machines are gone.
+	Stopped bool
 }
 
 // InsertCluster creates a new Cluster and interts it into 'db'.
@@ -35,41 +44,41 @@ func (db Database) SelectFromCluster(check func(Cluster) bool) []Cluster {
 // SelectFromCluster gets all clusters in the database that satisfy 'check'.
 func (conn Conn) SelectFromCluster(check func(Cluster) bool) []Cluster {
 	var clusters []Cluster
-	conn.Txn(ClusterTable).Run(func(view Database) error {
+	conn.ReadTxn(ClusterTable).Run(func(view Database) error {
 		clusters = view.SelectFromCluster(check)
 		return nil
 	})
 	return clusters
 }
 
-// GetCluster gets the cluster from the database. There should only ever be a single
-// cluster.
-func (db Database) GetCluster() (Cluster, error) {
-	clusters := db.SelectFromCluster(nil)
-	numClusters := len(clusters)
-	if numClusters == 1 {
-		return clusters[0], nil
-	} else if numClusters > 1 {
-		log.Panicf("Found %d clusters, there should be 1", numClusters)
+// GetCluster gets the cluster of 'namespace' from the database.  There is at most one
+// cluster per namespace, as enforced by a Unique constraint.
+func (db Database) GetCluster(namespace string) (Cluster, error) {
+	clusters := db.SelectFromCluster(func(c Cluster) bool {
+		return c.Namespace == namespace
+	})
+	if len(clusters) == 0 {
+		return Cluster{}, fmt.Errorf("no cluster in namespace %q", namespace)
 	}
-	return Cluster{}, errors.New("no clusters found")
+	return clusters[0], nil
 }
 
-// GetClusterNamespace returns the namespace of the single cluster object in the cluster
-// table.  Otherwise it returns an error.
-func (db Database) GetClusterNamespace() (string, error) {
-	clst, err := db.GetCluster()
-	if err != nil {
-		return "", err
+// GetClusterNamespaces returns the namespaces of the clusters in the cluster table,
+// in sorted order.
+func (db Database) GetClusterNamespaces() []string {
+	var namespaces []string
+	for _, clst := range db.SelectFromCluster(nil) {
+		namespaces = append(namespaces, clst.Namespace)
 	}
-	return clst.Namespace, nil
+	sort.Strings(namespaces)
+	return namespaces
 }
 
-// GetClusterNamespace returns the namespace of the single cluster object in the cluster
-// table.  Otherwise it returns an error.
-func (conn Conn) GetClusterNamespace() (namespace string, err error) {
-	conn.Txn(ClusterTable).Run(func(db Database) error {
-		namespace, err = db.GetClusterNamespace()
+// GetClusterNamespaces returns the namespaces of the clusters in the cluster table,
+// in sorted order.
+func (conn Conn) GetClusterNamespaces() (namespaces []string) {
+	conn.ReadTxn(ClusterTable).Run(func(db Database) error {
+		namespaces = db.GetClusterNamespaces()
 		return nil
 	})
 	return
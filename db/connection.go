@@ -5,14 +5,17 @@ import (
 )
 
 // A Connection allows the members of two labels to speak to each other on the port
-// range [MinPort, MaxPort] inclusive.
+// range [MinPort, MaxPort] inclusive.  An empty Protocol allows TCP, UDP and ICMP.
+// If ContainerPort is set, the public port MinPort is forwarded to ContainerPort.
 type Connection struct {
 	ID int `json:"-"`
 
-	From    string
-	To      string
-	MinPort int
-	MaxPort int
+	From          string
+	To            string
+	MinPort       int
+	MaxPort       int
+	Protocol      string `json:",omitempty"`
+	ContainerPort int    `json:",omitempty"`
 }
 
 // InsertConnection creates a new connection row and inserts it into the database.
@@ -43,7 +46,7 @@ func (c Connection) getID() int {
 // the 'check'.
 func (conn Conn) SelectFromConnection(check func(Connection) bool) []Connection {
 	var connections []Connection
-	conn.Txn(ConnectionTable).Run(func(view Database) error {
+	conn.ReadTxn(ConnectionTable).Run(func(view Database) error {
 		connections = view.SelectFromConnection(check)
 		return nil
 	})
@@ -56,6 +59,14 @@ func (c Connection) String() string {
 		port += fmt.Sprintf("-%d", c.MaxPort)
 	}
 
+	if c.ContainerPort != 0 {
+		port += fmt.Sprintf(":%d", c.ContainerPort)
+	}
+
+	if c.Protocol != "" {
+		port += "/" + c.Protocol
+	}
+
 	return fmt.Sprintf("Connection-%d{%s->%s:%s}", c.ID, c.From, c.To, port)
 }
 
@@ -71,6 +82,10 @@ func (c Connection) less(r row) bool {
 		return c.MaxPort < o.MaxPort
 	case c.MinPort != o.MaxPort:
 		return c.MinPort < o.MinPort
+	case c.Protocol != o.Protocol:
+		return c.Protocol < o.Protocol
+	case c.ContainerPort != o.ContainerPort:
+		return c.ContainerPort < o.ContainerPort
 	default:
 		return c.ID < o.ID
 	}
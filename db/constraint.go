@@ -0,0 +1,259 @@
+package db
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A Constraint is an invariant on the rows of the database.  Constraints are checked
+// at the end of every read-write Transaction that changes their tables.  Such
+// Transactions are given access to all of the tables a Constraint examines, even
+// those they weren't created with.  If a Constraint is violated, the Transaction's
+// changes are rolled back, and Run returns an error describing the violation.
+//
+// Constraints only examine the rows changed by the Transaction, so they should be
+// declared before the database is populated.
+type Constraint interface {
+	// The tables the Constraint examines.
+	tables() []TableType
+
+	// check returns an error if the changes made to 'view' violate the Constraint.
+	check(view Database) error
+}
+
+// builtinConstraints are the invariants enforced on every database.
+var builtinConstraints = []Constraint{
+	Unique(ClusterTable, "Namespace"),
+	Unique(ACLTable, "Namespace"),
+	Unique(ContainerTable, "StitchID"),
+	References(ContainerTable, "Minion", MinionTable, "PrivateIP"),
+}
+
+// Unique requires that no two rows in 'tt' share a value of 'field'.  Rows holding
+// the field's zero value are exempt.
+func Unique(tt TableType, field string) Constraint {
+	checkField(tt, field)
+	return uniqueConstraint{tt, field}
+}
+
+// Singleton requires that 'tt' holds at most one row.
+func Singleton(tt TableType) Constraint {
+	return singletonConstraint{tt}
+}
+
+// References requires that the value of 'field' in each row of 'tt' matches the
+// value of 'targetField' in some row of 'target'.  Rows holding the field's zero
+// value refer to nothing, and are exempt.
+func References(tt TableType, field string, target TableType,
+	targetField string) Constraint {
+
+	checkField(tt, field)
+	checkField(target, targetField)
+	return referenceConstraint{tt, field, target, targetField}
+}
+
+// AddConstraint begins enforcing 'c' on the database.
+func (cn Conn) AddConstraint(c Constraint) {
+	cn.Txn(c.tables()...).Run(func(view Database) error {
+		view.addConstraint(c)
+		return nil
+	})
+}
+
+type singletonConstraint struct {
+	table TableType
+}
+
+func (c singletonConstraint) tables() []TableType {
+	return []TableType{c.table}
+}
+
+func (c singletonConstraint) check(view Database) error {
+	rows := view.tables[c.table].rows
+	if len(rows) <= 1 {
+		return nil
+	}
+
+	var ids []int
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	return fmt.Errorf("%s may hold at most one row, but holds %s",
+		c.table, idsStr(ids))
+}
+
+type uniqueConstraint struct {
+	table TableType
+	field string
+}
+
+func (c uniqueConstraint) tables() []TableType {
+	return []TableType{c.table}
+}
+
+func (c uniqueConstraint) check(view Database) error {
+	t := view.tables[c.table]
+	for _, change := range t.changes {
+		if change.new == nil {
+			continue
+		}
+
+		val := fieldValue(change.new, c.field)
+		if isZero(val) {
+			continue
+		}
+
+		if ids := t.find(c.field, val); len(ids) > 1 {
+			return fmt.Errorf("%s.%s must be unique, but rows %s share %#v",
+				c.table, c.field, idsStr(ids), val)
+		}
+	}
+	return nil
+}
+
+type referenceConstraint struct {
+	table       TableType
+	field       string
+	target      TableType
+	targetField string
+}
+
+func (c referenceConstraint) tables() []TableType {
+	return []TableType{c.table, c.target}
+}
+
+func (c referenceConstraint) check(view Database) error {
+	t := view.tables[c.table]
+	target := view.tables[c.target]
+
+	// New references must refer to an existing row.
+	for id, change := range t.changes {
+		if change.new == nil {
+			continue
+		}
+
+		val := fieldValue(change.new, c.field)
+		if isZero(val) || (change.old != nil &&
+			reflect.DeepEqual(val, fieldValue(change.old, c.field))) {
+			continue
+		}
+
+		if len(target.find(c.targetField, val)) == 0 {
+			return fmt.Errorf("%s-%d refers to a %s with %s %#v, "+
+				"but there is none", c.table, id, c.target,
+				c.targetField, val)
+		}
+	}
+
+	// And rows may not be changed out from under existing references.
+	for _, change := range target.changes {
+		if change.old == nil {
+			continue
+		}
+
+		val := fieldValue(change.old, c.targetField)
+		if isZero(val) || (change.new != nil &&
+			reflect.DeepEqual(val, fieldValue(change.new, c.targetField))) {
+			continue
+		}
+
+		if len(target.find(c.targetField, val)) > 0 {
+			continue
+		}
+
+		if ids := t.find(c.field, val); len(ids) > 0 {
+			return fmt.Errorf("%s %s refer to a %s with %s %#v, "+
+				"but there is none", c.table, idsStr(ids), c.target,
+				c.targetField, val)
+		}
+	}
+	return nil
+}
+
+// checkConstraints returns an error describing the first Constraint violated by the
+// running Transaction.
+func (db Database) checkConstraints() error {
+	checked := map[Constraint]struct{}{}
+	for _, tt := range db.sortedTables() {
+		t := db.tables[tt]
+		if len(t.changes) == 0 {
+			continue
+		}
+
+		for _, c := range t.constraints {
+			if _, ok := checked[c]; ok {
+				continue
+			}
+			checked[c] = struct{}{}
+
+			if err := c.check(db); err != nil {
+				return fmt.Errorf("constraint violated: %s", err)
+			}
+		}
+	}
+	return nil
+}
+
+// rollback reverts the changes made by the running Transaction.
+func (db Database) rollback() {
+	for _, t := range db.tables {
+		for id, change := range t.changes {
+			if change.old == nil {
+				t.delete(id)
+			} else {
+				t.put(change.old)
+			}
+		}
+
+		t.changes = nil
+		t.pending = nil
+		t.shouldAlert = false
+	}
+}
+
+// find returns the IDs of the rows whose 'field' equals 'val', using an index if one
+// is available.
+func (t *table) find(field string, val interface{}) []int {
+	var ids []int
+	if idx, ok := t.indexes[field]; ok {
+		for id := range idx.ids[val] {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	for id, r := range t.rows {
+		if reflect.DeepEqual(fieldValue(r, field), val) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// checkField panics if the rows of 'tt' have no 'field', as that can only be a
+// programming error.
+func checkField(tt TableType, field string) {
+	if _, ok := reflect.TypeOf(tableRows[tt]).FieldByName(field); !ok {
+		panic(fmt.Sprintf("no field %s in table %s", field, tt))
+	}
+}
+
+func fieldValue(r row, field string) interface{} {
+	return reflect.ValueOf(r).FieldByName(field).Interface()
+}
+
+func isZero(val interface{}) bool {
+	return reflect.DeepEqual(val, reflect.Zero(reflect.TypeOf(val)).Interface())
+}
+
+func idsStr(ids []int) string {
+	sort.Ints(ids)
+
+	var strs []string
+	for _, id := range ids {
+		strs = append(strs, fmt.Sprintf("%d", id))
+	}
+	return strings.Join(strs, ", ")
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingletonConstraint(t *testing.T) {
+	conn := New()
+	conn.AddConstraint(Singleton(EtcdTable))
+	trig := conn.Trigger(EtcdTable)
+
+	err := conn.Txn(AllTables...).Run(func(view Database) error {
+		view.InsertEtcd()
+		return nil
+	})
+	assert.NoError(t, err)
+	<-trig.C
+
+	err = conn.Txn(AllTables...).Run(func(view Database) error {
+		view.InsertEtcd()
+		return nil
+	})
+	assert.EqualError(t, err, "constraint violated: "+
+		"db.Etcd may hold at most one row, but holds 1, 2")
+	assert.Len(t, conn.SelectFromEtcd(nil), 1)
+
+	// Transactions that are rolled back don't fire triggers.
+	select {
+	case <-trig.C:
+		t.Error("Unexpected trigger")
+	default:
+	}
+}
+
+func TestUniqueConstraint(t *testing.T) {
+	conn := New()
+
+	var dbc Container
+	err := conn.Txn(AllTables...).Run(func(view Database) error {
+		dbc = view.InsertContainer()
+		dbc.StitchID = "a"
+		view.Commit(dbc)
+
+		// Containers without a StitchID are exempt.
+		view.InsertContainer()
+		view.InsertContainer()
+		return nil
+	})
+	assert.NoError(t, err)
+
+	err = conn.Txn(AllTables...).Run(func(view Database) error {
+		dbc.Image = "changed"
+		view.Commit(dbc)
+
+		dup := view.InsertContainer()
+		dup.StitchID = "a"
+		view.Commit(dup)
+		return nil
+	})
+	assert.EqualError(t, err, `constraint violated: `+
+		`db.Container.StitchID must be unique, but rows 1, 4 share "a"`)
+
+	// All changes made by the failed Transaction are reverted, and its indexes
+	// restored.
+	dbcs := conn.SelectFromContainer(func(dbc Container) bool {
+		return dbc.StitchID == "a"
+	})
+	assert.Len(t, dbcs, 1)
+	assert.Empty(t, dbcs[0].Image)
+
+	conn.ReadTxn(ContainerTable).Run(func(view Database) error {
+		assert.Len(t, view.SelectFromContainerByStitchID("a"), 1)
+		return nil
+	})
+}
+
+func TestReferenceConstraint(t *testing.T) {
+	conn := New()
+
+	var m Minion
+	var dbc Container
+	err := conn.Txn(AllTables...).Run(func(view Database) error {
+		m = view.InsertMinion()
+		m.PrivateIP = "1.2.3.4"
+		view.Commit(m)
+
+		dbc = view.InsertContainer()
+		dbc.Minion = "1.2.3.4"
+		view.Commit(dbc)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	err = conn.Txn(AllTables...).Run(func(view Database) error {
+		dbc.Minion = "5.6.7.8"
+		view.Commit(dbc)
+		return nil
+	})
+	assert.EqualError(t, err, `constraint violated: db.Container-2 refers to a `+
+		`db.Minion with PrivateIP "5.6.7.8", but there is none`)
+	dbc.Minion = "1.2.3.4"
+
+	err = conn.Txn(AllTables...).Run(func(view Database) error {
+		view.Remove(m)
+		return nil
+	})
+	assert.EqualError(t, err, `constraint violated: db.Container 2 refer to a `+
+		`db.Minion with PrivateIP "1.2.3.4", but there is none`)
+
+	// Transactions are given the tables they need to check the constraint.
+	err = conn.Txn(MinionTable).Run(func(view Database) error {
+		view.Remove(m)
+		return nil
+	})
+	assert.EqualError(t, err, `constraint violated: db.Container 2 refer to a `+
+		`db.Minion with PrivateIP "1.2.3.4", but there is none`)
+
+	err = conn.Txn(ContainerTable).Run(func(view Database) error {
+		dbc.Minion = "5.6.7.8"
+		view.Commit(dbc)
+		return nil
+	})
+	assert.EqualError(t, err, `constraint violated: db.Container-2 refers to a `+
+		`db.Minion with PrivateIP "5.6.7.8", but there is none`)
+	dbc.Minion = "1.2.3.4"
+
+	// Changes that leave the reference alone are allowed.
+	err = conn.Txn(ContainerTable).Run(func(view Database) error {
+		dbc.Image = "image"
+		view.Commit(dbc)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// Clearing a reference is always allowed.
+	err = conn.Txn(AllTables...).Run(func(view Database) error {
+		dbc.Minion = ""
+		view.Commit(dbc)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestAddConstraint(t *testing.T) {
+	conn := New()
+	conn.AddConstraint(Unique(MachineTable, "PublicIP"))
+
+	err := conn.Txn(AllTables...).Run(func(view Database) error {
+		for i := 0; i < 2; i++ {
+			m := view.InsertMachine()
+			m.PublicIP = "8.8.8.8"
+			view.Commit(m)
+		}
+		return nil
+	})
+	assert.EqualError(t, err, `constraint violated: `+
+		`db.Machine.PublicIP must be unique, but rows 1, 2 share "8.8.8.8"`)
+	assert.Empty(t, conn.SelectFromMachine(nil))
+
+	assert.Panics(t, func() { Unique(MachineTable, "Foo") })
+}
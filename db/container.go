@@ -15,9 +15,9 @@ type Container struct {
 	ID int `json:"-"`
 
 	IP                string            `json:",omitempty"`
-	Minion            string            `json:",omitempty"`
+	Minion            string            `json:",omitempty" db:"index"`
 	EndpointID        string            `json:",omitempty"`
-	StitchID          string            `json:",omitempty"`
+	StitchID          string            `json:",omitempty" db:"index"`
 	DockerID          string            `json:",omitempty"`
 	Status            string            `json:",omitempty"`
 	Command           []string          `json:",omitempty"`
@@ -30,6 +30,62 @@ type Container struct {
 	Image      string `json:",omitempty"`
 	ImageID    string `json:",omitempty"`
 	Dockerfile string `json:"-"`
+
+	// CPU is measured in cores, and memory in MiB.  Zero values are unrestricted.
+	CPURequest    float64 `json:",omitempty"`
+	CPULimit      float64 `json:",omitempty"`
+	MemoryRequest int     `json:",omitempty"`
+	MemoryLimit   int     `json:",omitempty"`
+
+	// Volumes are written in the Docker bind format, `source:mountPath[:ro]`.
+	// Their data lives on VolumeMinion, to which the container is pinned unless
+	// it's Movable.
+	Volumes      []string `json:",omitempty"`
+	Movable      bool     `json:",omitempty"`
+	VolumeMinion string   `json:",omitempty"`
+
+	// Health is determined by the worker running the container, and is only
+	// meaningful if the container has a HealthCheck.
+	HealthCheck *HealthCheck `json:",omitempty"`
+	Health      string       `json:",omitempty"`
+
+	// The RestartPolicy and MaxRetries are specified by the blueprint, while
+	// Restarts and ExitCode are reported by the worker running the container.
+	RestartPolicy string `json:",omitempty"`
+	MaxRetries    int    `json:",omitempty"`
+	Restarts      int    `json:",omitempty"`
+	ExitCode      int    `json:",omitempty"`
+
+	// SecretEnv and SecretFiles map environment variables and file paths to the
+	// names of the Secrets that hold their values.
+	SecretEnv   map[string]string `json:",omitempty"`
+	SecretFiles map[string]string `json:",omitempty"`
+}
+
+// A HealthCheck determines whether a container is serving.  See stitch.HealthCheck.
+type HealthCheck struct {
+	Command  []string `json:",omitempty"`
+	HTTPPath string   `json:",omitempty"`
+	Port     int      `json:",omitempty"`
+
+	Interval int `json:",omitempty"`
+	Timeout  int `json:",omitempty"`
+	Retries  int `json:",omitempty"`
+}
+
+const (
+	// ContainerHealthy is the health of a container that passed its last check.
+	ContainerHealthy = "healthy"
+
+	// ContainerUnhealthy is the health of a container that failed its last few
+	// checks.
+	ContainerUnhealthy = "unhealthy"
+)
+
+// Serving returns whether the container should receive traffic sent to its labels.
+// Containers with health checks only do so once they're known to be healthy.
+func (c Container) Serving() bool {
+	return c.HealthCheck == nil || c.Health == ContainerHealthy
 }
 
 // ContainerSlice is an alias for []Container to allow for joins
@@ -58,13 +114,56 @@ func (db Database) SelectFromContainer(check func(Container) bool) []Container {
 // SelectFromContainer gets all containers in the database that satisfy the 'check'.
 func (conn Conn) SelectFromContainer(check func(Container) bool) []Container {
 	var containers []Container
-	conn.Txn(ContainerTable).Run(func(view Database) error {
+	conn.ReadTxn(ContainerTable).Run(func(view Database) error {
 		containers = view.SelectFromContainer(check)
 		return nil
 	})
 	return containers
 }
 
+// SelectFromContainerByStitchID gets all containers in the database with the given
+// StitchID.  Unlike SelectFromContainer, it doesn't scan the entire table.
+func (db Database) SelectFromContainerByStitchID(stitchID string) []Container {
+	return toContainers(db.accessTable(ContainerTable).lookup("StitchID", stitchID))
+}
+
+// SelectFromContainerByStitchID gets all containers in the database connection with
+// the given StitchID.
+func (conn Conn) SelectFromContainerByStitchID(stitchID string) []Container {
+	var containers []Container
+	conn.ReadTxn(ContainerTable).Run(func(view Database) error {
+		containers = view.SelectFromContainerByStitchID(stitchID)
+		return nil
+	})
+	return containers
+}
+
+// SelectFromContainerByMinion gets all containers in the database scheduled on the
+// minion with the given private IP.  Unlike SelectFromContainer, it doesn't scan the
+// entire table.
+func (db Database) SelectFromContainerByMinion(minion string) []Container {
+	return toContainers(db.accessTable(ContainerTable).lookup("Minion", minion))
+}
+
+// SelectFromContainerByMinion gets all containers in the database connection
+// scheduled on the minion with the given private IP.
+func (conn Conn) SelectFromContainerByMinion(minion string) []Container {
+	var containers []Container
+	conn.ReadTxn(ContainerTable).Run(func(view Database) error {
+		containers = view.SelectFromContainerByMinion(minion)
+		return nil
+	})
+	return containers
+}
+
+func toContainers(rows []row) []Container {
+	var result []Container
+	for _, r := range rows {
+		result = append(result, r.(Container))
+	}
+	return result
+}
+
 func (c Container) getID() int {
 	return c.ID
 }
@@ -107,10 +206,51 @@ func (c Container) String() string {
 		tags = append(tags, fmt.Sprintf("Env: %s", c.Env))
 	}
 
+	if len(c.SecretEnv) > 0 {
+		tags = append(tags, fmt.Sprintf("SecretEnv: %s", c.SecretEnv))
+	}
+
+	if len(c.SecretFiles) > 0 {
+		tags = append(tags, fmt.Sprintf("SecretFiles: %s", c.SecretFiles))
+	}
+
+	if c.CPURequest != 0 || c.CPULimit != 0 {
+		tags = append(tags, fmt.Sprintf("CPU: %g/%g", c.CPURequest, c.CPULimit))
+	}
+
+	if c.MemoryRequest != 0 || c.MemoryLimit != 0 {
+		tags = append(tags, fmt.Sprintf("Memory: %d/%dMiB", c.MemoryRequest,
+			c.MemoryLimit))
+	}
+
+	if len(c.Volumes) > 0 {
+		tags = append(tags, fmt.Sprintf("Volumes: %s", c.Volumes))
+	}
+
+	if c.VolumeMinion != "" {
+		tags = append(tags, fmt.Sprintf("VolumeMinion: %s", c.VolumeMinion))
+	}
+
+	if c.RestartPolicy != "" {
+		tags = append(tags, fmt.Sprintf("RestartPolicy: %s", c.RestartPolicy))
+	}
+
+	if c.Restarts != 0 {
+		tags = append(tags, fmt.Sprintf("Restarts: %d", c.Restarts))
+	}
+
+	if c.ExitCode != 0 {
+		tags = append(tags, fmt.Sprintf("ExitCode: %d", c.ExitCode))
+	}
+
 	if len(c.Status) > 0 {
 		tags = append(tags, fmt.Sprintf("Status: %s", c.Status))
 	}
 
+	if c.Health != "" {
+		tags = append(tags, fmt.Sprintf("Health: %s", c.Health))
+	}
+
 	if !c.Created.IsZero() {
 		tags = append(tags, fmt.Sprintf("Created: %s", c.Created.String()))
 	}
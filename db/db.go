@@ -13,8 +13,16 @@ import (
 // engine populates the database with a preferred state of the world, while various
 // modules flesh out that policy with actual implementation details.
 type Database struct {
-	tables  map[TableType]*table
-	idAlloc *idCounter
+	tables   map[TableType]*table
+	idAlloc  *idCounter
+	journal  *journal
+	readOnly bool
+
+	// The tables examined by the Constraints on each table.
+	constrained *constrainedTables
+
+	// Identifies the Conn that made the Transaction in the history.
+	tag string
 }
 
 // A Trigger sends notifications when anything in their corresponding table changes.
@@ -39,6 +47,13 @@ type Transaction struct {
 	db Database
 }
 
+// constrainedTables records, for each table, the tables that must be available to
+// check the Constraints on it.
+type constrainedTables struct {
+	sync.Mutex
+	tables map[TableType]map[TableType]struct{}
+}
+
 // An idCounter is a wrapper around the global DB id providing concurrency safe use
 type idCounter struct {
 	sync.Mutex
@@ -47,21 +62,55 @@ type idCounter struct {
 
 // New creates a connection to a brand new database.
 func New() Conn {
-	db := Database{make(map[TableType]*table), &idCounter{}}
+	cn := Conn{db: newDatabase()}
+	cn.runLogger()
+	return cn
+}
+
+func newDatabase() Database {
+	db := Database{
+		tables:  make(map[TableType]*table),
+		idAlloc: &idCounter{},
+		constrained: &constrainedTables{
+			tables: map[TableType]map[TableType]struct{}{},
+		},
+	}
 	for _, t := range AllTables {
-		db.tables[t] = newTable()
+		db.tables[t] = newTable(tableRows[t])
 	}
 
-	cn := Conn{db: db}
-	cn.runLogger()
-	return cn
+	for _, c := range builtinConstraints {
+		db.addConstraint(c)
+	}
+	return db
 }
 
 // Txn creates a new Transaction object connected to the same database, but with
-// restricted access to only the given tables.
+// restricted access to only the given tables, and the tables examined by their
+// Constraints.
 func (cn Conn) Txn(tables ...TableType) Transaction {
+	return cn.txn(cn.db.withConstrainedTables(tables))
+}
+
+// ReadTxn creates a new read-only Transaction with access to only the given tables.
+// Read-only Transactions may run concurrently with each other, even if their tables
+// overlap, but not with read-write Transactions on the same tables.  Attempting to
+// modify the database within a read-only Transaction panics.
+func (cn Conn) ReadTxn(tables ...TableType) Transaction {
+	tr := cn.txn(tables)
+	tr.db.readOnly = true
+	return tr
+}
+
+func (cn Conn) txn(tables []TableType) Transaction {
 	// The Transaction has the same database data, just a subset of the tables.
-	db := Database{make(map[TableType]*table), cn.db.idAlloc}
+	db := Database{
+		tables:      make(map[TableType]*table),
+		idAlloc:     cn.db.idAlloc,
+		journal:     cn.db.journal,
+		constrained: cn.db.constrained,
+		tag:         cn.db.tag,
+	}
 	for _, t := range tables {
 		db.tables[t] = cn.db.accessTable(t)
 	}
@@ -69,21 +118,75 @@ func (cn Conn) Txn(tables ...TableType) Transaction {
 	return Transaction{db: db}
 }
 
+// withConstrainedTables returns 'tables' along with the tables examined by their
+// Constraints, so that a Transaction that changes them can check the Constraints.
+func (db Database) withConstrainedTables(tables []TableType) []TableType {
+	db.constrained.Lock()
+	defer db.constrained.Unlock()
+
+	result := append([]TableType{}, tables...)
+	for _, tt := range tables {
+		for ctt := range db.constrained.tables[tt] {
+			result = append(result, ctt)
+		}
+	}
+	return result
+}
+
+// addConstraint registers 'c' with the tables it examines.  The caller must hold the
+// locks of those tables.
+func (db Database) addConstraint(c Constraint) {
+	db.constrained.Lock()
+	defer db.constrained.Unlock()
+
+	for _, tt := range c.tables() {
+		t := db.tables[tt]
+		t.constraints = append(t.constraints, c)
+
+		if db.constrained.tables[tt] == nil {
+			db.constrained.tables[tt] = map[TableType]struct{}{}
+		}
+		for _, ctt := range c.tables() {
+			db.constrained.tables[tt][ctt] = struct{}{}
+		}
+	}
+}
+
 // Run executes database transactions.  It takes a closure, 'do', which is operates
 // on its 'db' argument.  Transactions may be concurrent, but only if they operate on
-// independent sets of tables. Otherwise, each transaction runs sequentially on it's
-// database without conflicting with other transactions.
+// independent sets of tables, or are all read-only. Otherwise, each transaction runs
+// sequentially on it's database without conflicting with other transactions.  If the
+// changes made by 'do' violate a Constraint, they are rolled back and Run returns
+// the violation.  Otherwise, Run returns the error returned by 'do'.
 func (tr Transaction) Run(do func(db Database) error) error {
 	tr.lockTables()
 	defer tr.unlockTables()
 
 	err := do(tr.db)
+	if tr.db.readOnly {
+		return err
+	}
+
+	if cerr := tr.db.checkConstraints(); cerr != nil {
+		tr.db.rollback()
+		return cerr
+	}
+
 	var alertTables []*table
-	for _, table := range tr.db.tables {
+	var journalEntries []journalEntry
+	for tt, table := range tr.db.tables {
+		table.publish(tt, tr.db.tag)
 		if table.shouldAlert {
 			alertTables = append(alertTables, table)
 			table.shouldAlert = false
 		}
+
+		journalEntries = append(journalEntries, table.pending...)
+		table.pending = nil
+	}
+
+	if len(journalEntries) > 0 {
+		tr.db.journal.append(journalEntries)
 	}
 
 	for _, table := range alertTables {
@@ -139,14 +242,12 @@ func (cn Conn) TriggerTick(seconds int, tt ...TableType) Trigger {
 // sorted order avoids deadlock between two transactionss requesting intersecting sets of
 // tables.
 func (tr Transaction) lockTables() {
-	tables := tableSlice{}
-	for tt := range tr.db.tables {
-		tables = append(tables, tt)
-	}
-	sort.Sort(tables)
-
-	for _, tt := range tables {
-		tr.db.tables[tt].Lock()
+	for _, tt := range tr.db.sortedTables() {
+		if tr.db.readOnly {
+			tr.db.tables[tt].RLock()
+		} else {
+			tr.db.tables[tt].Lock()
+		}
 	}
 }
 
@@ -154,7 +255,11 @@ func (tr Transaction) lockTables() {
 // irrelevant.
 func (tr Transaction) unlockTables() {
 	for _, t := range tr.db.tables {
-		t.Unlock()
+		if tr.db.readOnly {
+			t.RUnlock()
+		} else {
+			t.Unlock()
+		}
 	}
 }
 
@@ -164,15 +269,17 @@ func (t Trigger) Stop() {
 }
 
 func (db Database) insert(r row) {
-	table := db.accessTable(getTableType(r))
+	table := db.accessWritableTable(getTableType(r))
 	table.shouldAlert = true
-	table.rows[r.getID()] = r
+	table.put(r)
+	table.noteChange(r.getID(), nil, r)
+	db.record(table, journalInsert, r)
 }
 
 // Commit updates the database with the data contained in row.
 func (db Database) Commit(r row) {
 	rid := r.getID()
-	table := db.accessTable(getTableType(r))
+	table := db.accessWritableTable(getTableType(r))
 	old := table.rows[rid]
 
 	if reflect.TypeOf(old) != reflect.TypeOf(r) {
@@ -180,16 +287,39 @@ func (db Database) Commit(r row) {
 	}
 
 	if table.shouldAlert || !reflect.DeepEqual(r, old) {
-		table.rows[rid] = r
+		table.put(r)
 		table.shouldAlert = true
+		table.noteChange(rid, old, r)
+		db.record(table, journalCommit, r)
 	}
 }
 
 // Remove deletes row from the database.
 func (db Database) Remove(r row) {
-	table := db.accessTable(getTableType(r))
-	delete(table.rows, r.getID())
+	table := db.accessWritableTable(getTableType(r))
+	if old, ok := table.rows[r.getID()]; ok {
+		table.noteChange(r.getID(), old, nil)
+	}
+	table.delete(r.getID())
 	table.shouldAlert = true
+	db.record(table, journalRemove, r)
+}
+
+// record notes a change to 'table' so that it may be written to the journal once the
+// Transaction completes.  It's a noop for databases that aren't persisted.
+func (db Database) record(table *table, op journalOp, r row) {
+	if db.journal != nil {
+		table.pending = append(table.pending, journalEntry{op, r})
+	}
+}
+
+func (db Database) sortedTables() []TableType {
+	tables := tableSlice{}
+	for tt := range db.tables {
+		tables = append(tables, tt)
+	}
+	sort.Sort(tables)
+	return tables
 }
 
 func (db Database) nextID() int {
@@ -213,6 +343,13 @@ func (db Database) accessTable(tt TableType) *table {
 	return dbTable
 }
 
+func (db Database) accessWritableTable(tt TableType) *table {
+	if db.readOnly {
+		panic("Write in read-only transaction to table: " + tt)
+	}
+	return db.accessTable(tt)
+}
+
 type tableSlice []TableType
 
 func (tables tableSlice) Len() int {
@@ -159,6 +159,59 @@ func TestContainerString(t *testing.T) {
 		"Created: " + fakeTimeString + "}"
 
 	assert.Equal(t, exp, c.String())
+
+	c = Container{ID: 2, Image: "nginx", CPURequest: 0.5, CPULimit: 1,
+		MemoryRequest: 256, MemoryLimit: 512}
+	assert.Equal(t, "Container-2{run nginx, CPU: 0.5/1, Memory: 256/512MiB}",
+		c.String())
+
+	c = Container{ID: 3, Image: "postgres", Volumes: []string{"data:/data"},
+		VolumeMinion: "10.0.0.2"}
+	assert.Equal(t, "Container-3{run postgres, Volumes: [data:/data], "+
+		"VolumeMinion: 10.0.0.2}", c.String())
+
+	c = Container{ID: 4, Image: "nginx", Health: ContainerUnhealthy}
+	assert.Equal(t, "Container-4{run nginx, Health: unhealthy}", c.String())
+
+	c = Container{ID: 5, Image: "nginx", RestartPolicy: "on-failure", Restarts: 2,
+		ExitCode: 1, Status: "exited"}
+	assert.Equal(t, "Container-5{run nginx, RestartPolicy: on-failure, "+
+		"Restarts: 2, ExitCode: 1, Status: exited}", c.String())
+
+	c = Container{ID: 6, Image: "postgres",
+		SecretEnv:   map[string]string{"PGPASSWORD": "pg-password"},
+		SecretFiles: map[string]string{"/etc/key": "key"}}
+	assert.Equal(t, "Container-6{run postgres, "+
+		"SecretEnv: map[PGPASSWORD:pg-password], "+
+		"SecretFiles: map[/etc/key:key]}", c.String())
+}
+
+func TestContainerServing(t *testing.T) {
+	assert.True(t, Container{}.Serving())
+
+	c := Container{HealthCheck: &HealthCheck{Port: 80}}
+	assert.False(t, c.Serving())
+
+	c.Health = ContainerUnhealthy
+	assert.False(t, c.Serving())
+
+	c.Health = ContainerHealthy
+	assert.True(t, c.Serving())
+}
+
+func TestConnectionString(t *testing.T) {
+	c := Connection{ID: 1, From: "a", To: "b", MinPort: 80, MaxPort: 81}
+	assert.Equal(t, "Connection-1{a->b:80-81}", c.String())
+
+	c = Connection{ID: 2, From: "a", To: "b", MinPort: 53, MaxPort: 53,
+		Protocol: "udp"}
+	assert.Equal(t, "Connection-2{a->b:53/udp}", c.String())
+
+	c = Connection{ID: 3, From: "public", To: "b", MinPort: 8080,
+		MaxPort: 8080, ContainerPort: 80}
+	assert.Equal(t, "Connection-3{public->b:8080:80}", c.String())
+	assert.Equal(t, "53/udp", PortRange{MinPort: 53, MaxPort: 53,
+		Protocol: "udp"}.String())
 }
 
 func TestTxnBasic(t *testing.T) {
@@ -318,8 +371,9 @@ func TestTxnSequential(t *testing.T) {
 
 // Fails the test when the transactions run out of order.
 func checkTxnSequential(t *testing.T) {
-	subTxnOne, subTxnTwo := getRandomTransactions(New(),
-		pickTwoTables(map[TableType]struct{}{})...)
+	conn := New()
+	subTxnOne, subTxnTwo := getRandomTransactions(conn,
+		pickTwoTables(conn, map[TableType]struct{}{})...)
 
 	done := make(chan struct{})
 	defer close(done)
@@ -375,10 +429,75 @@ func checkTxnSequential(t *testing.T) {
 	}
 }
 
+// Read-only Transactions should be able to run concurrently, even if their tables
+// overlap.
+func TestReadTxnConcurrent(t *testing.T) {
+	// Skip the logger, as a read-write Transaction waiting to register its
+	// triggers would hold up the second reader.
+	conn := Conn{db: newDatabase()}
+	oneStarted := make(chan struct{})
+	twoDone := make(chan struct{})
+	go conn.ReadTxn(AllTables...).Run(func(view Database) error {
+		close(oneStarted)
+		<-twoDone
+		return nil
+	})
+
+	<-oneStarted
+	go func() {
+		conn.ReadTxn(MachineTable).Run(func(view Database) error {
+			return nil
+		})
+		close(twoDone)
+	}()
+
+	select {
+	case <-twoDone:
+	case <-time.After(time.Second):
+		t.Fatal("Read-only transactions ran sequentially")
+	}
+}
+
+// Read-write Transactions should wait for overlapping read-only Transactions.
+func TestReadTxnSequential(t *testing.T) {
+	conn := New()
+	results := make(chan int, 2)
+	oneStarted := make(chan struct{})
+	go conn.ReadTxn(MachineTable).Run(func(view Database) error {
+		close(oneStarted)
+		time.Sleep(100 * time.Millisecond)
+		results <- 1
+		return nil
+	})
+
+	<-oneStarted
+	conn.Txn(MachineTable).Run(func(view Database) error {
+		results <- 2
+		return nil
+	})
+
+	assert.Equal(t, 1, <-results)
+	assert.Equal(t, 2, <-results)
+}
+
+// Read-only Transactions should panic when modifying the database.
+func TestReadTxnPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("Read-only transaction didn't panic on write")
+		}
+	}()
+
+	New().ReadTxn(MachineTable).Run(func(view Database) error {
+		view.InsertMachine()
+		return nil
+	})
+}
+
 func getRandomTransactions(conn Conn, tables ...TableType) (Transaction, Transaction) {
 	taken := map[TableType]struct{}{}
-	firstTables := pickTwoTables(taken)
-	secondTables := pickTwoTables(taken)
+	firstTables := pickTwoTables(conn, taken)
+	secondTables := pickTwoTables(conn, taken)
 
 	firstTables = append(firstTables, tables...)
 	secondTables = append(secondTables, tables...)
@@ -386,16 +505,24 @@ func getRandomTransactions(conn Conn, tables ...TableType) (Transaction, Transac
 	return conn.Txn(firstTables...), conn.Txn(secondTables...)
 }
 
-func pickTwoTables(taken map[TableType]struct{}) []TableType {
+// pickTwoTables picks two tables that, along with the tables their constraints
+// examine, aren't 'taken'.
+func pickTwoTables(conn Conn, taken map[TableType]struct{}) []TableType {
 	tableCount := int32(len(AllTables))
 	chosen := []TableType{}
+Outer:
 	for len(chosen) < 2 {
 		tt := AllTables[rand.Int31n(tableCount)]
-		if _, ok := taken[tt]; ok {
-			continue
+		needed := conn.db.withConstrainedTables([]TableType{tt})
+		for _, ntt := range needed {
+			if _, ok := taken[ntt]; ok {
+				continue Outer
+			}
 		}
 
-		taken[tt] = struct{}{}
+		for _, ntt := range needed {
+			taken[ntt] = struct{}{}
+		}
 		chosen = append(chosen, tt)
 	}
 
@@ -594,23 +721,41 @@ func TestSliceHelpers(t *testing.T) {
 	assert.Equal(t, conns[0], ConnectionSlice(conns).Get(0))
 }
 
-func TestGetClusterNamespace(t *testing.T) {
+func TestGetCluster(t *testing.T) {
 	conn := New()
+	assert.Empty(t, conn.GetClusterNamespaces())
 
-	ns, err := conn.GetClusterNamespace()
-	assert.NotNil(t, err)
-	assert.Exactly(t, ns, "")
+	err := conn.Txn(AllTables...).Run(func(view Database) error {
+		_, err := view.GetCluster("test")
+		assert.EqualError(t, err, `no cluster in namespace "test"`)
 
+		for _, ns := range []string{"test", "prod"} {
+			clst := view.InsertCluster()
+			clst.Namespace = ns
+			clst.Blueprint = ns + "-blueprint"
+			view.Commit(clst)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"prod", "test"}, conn.GetClusterNamespaces())
 	conn.Txn(AllTables...).Run(func(view Database) error {
+		clst, err := view.GetCluster("test")
+		assert.NoError(t, err)
+		assert.Equal(t, "test-blueprint", clst.Blueprint)
+		return nil
+	})
+
+	// Each namespace has at most one cluster.
+	err = conn.Txn(AllTables...).Run(func(view Database) error {
 		clst := view.InsertCluster()
 		clst.Namespace = "test"
 		view.Commit(clst)
 		return nil
 	})
-
-	ns, err = conn.GetClusterNamespace()
-	assert.NoError(t, err)
-	assert.Exactly(t, ns, "test")
+	assert.Error(t, err)
+	assert.Len(t, conn.SelectFromCluster(nil), 2)
 }
 
 type mSort []Machine
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"sort"
+	"time"
+)
+
+// A Revision records a single change to a row made by a committed Transaction.
+type Revision struct {
+	Time  time.Time
+	Tag   string // The tag of the Conn that made the change.
+	Table TableType
+	ID    int
+
+	// The row before and after the Transaction.  Old is nil if the row was
+	// inserted, and New is nil if it was removed.
+	Old, New interface{}
+}
+
+// WithTag returns a Conn to the same database whose Transactions are recorded in the
+// history as being made by 'tag'.  Modules use this to identify themselves.
+func (cn Conn) WithTag(tag string) Conn {
+	cn.db.tag = tag
+	return cn
+}
+
+// EnableHistory causes the database to record the last 'limit' Revisions of every
+// table.  A limit of zero disables the history.
+func (cn Conn) EnableHistory(limit int) {
+	cn.Txn(AllTables...).Run(func(view Database) error {
+		for _, t := range view.tables {
+			t.historyLimit = limit
+			t.trimHistory()
+		}
+		return nil
+	})
+}
+
+// SelectFromHistory gets all recorded Revisions that satisfy 'check', ordered from
+// oldest to newest.
+func (cn Conn) SelectFromHistory(check func(Revision) bool) []Revision {
+	var result []Revision
+	cn.ReadTxn(AllTables...).Run(func(view Database) error {
+		for _, t := range view.tables {
+			for _, rev := range t.history {
+				if check == nil || check(rev) {
+					result = append(result, rev)
+				}
+			}
+		}
+		return nil
+	})
+
+	sort.Sort(revisionSlice(result))
+	return result
+}
+
+// addHistory appends the changes made by a Transaction to the table's history.
+func (t *table) addHistory(tt TableType, tag string, changes map[int]*rowChange) {
+	if t.historyLimit == 0 {
+		return
+	}
+
+	now := time.Now()
+	var revs []Revision
+	for id, change := range changes {
+		if change.unchanged() {
+			continue
+		}
+
+		revs = append(revs, Revision{Time: now, Tag: tag, Table: tt, ID: id,
+			Old: change.old, New: change.new})
+	}
+
+	sort.Sort(revisionSlice(revs))
+	t.history = append(t.history, revs...)
+	t.trimHistory()
+}
+
+func (t *table) trimHistory() {
+	if extra := len(t.history) - t.historyLimit; extra > 0 {
+		t.history = append([]Revision(nil), t.history[extra:]...)
+	}
+}
+
+type revisionSlice []Revision
+
+func (revs revisionSlice) Len() int {
+	return len(revs)
+}
+
+func (revs revisionSlice) Swap(i, j int) {
+	revs[i], revs[j] = revs[j], revs[i]
+}
+
+func (revs revisionSlice) Less(i, j int) bool {
+	if !revs[i].Time.Equal(revs[j].Time) {
+		return revs[i].Time.Before(revs[j].Time)
+	}
+	return revs[i].ID < revs[j].ID
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	conn := New()
+
+	// Nothing is recorded until the history is enabled.
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		view.InsertMachine()
+		return nil
+	})
+	assert.Empty(t, conn.SelectFromHistory(nil))
+
+	conn.EnableHistory(3)
+	engine := conn.WithTag("engine")
+	cluster := conn.WithTag("cluster")
+
+	var m Machine
+	engine.Txn(AllTables...).Run(func(view Database) error {
+		m = view.InsertMachine()
+		view.Remove(view.InsertContainer())
+		return nil
+	})
+
+	old := m
+	cluster.Txn(AllTables...).Run(func(view Database) error {
+		m.PublicIP = "1.2.3.4"
+		view.Commit(m)
+		return nil
+	})
+
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		view.Remove(m)
+		return nil
+	})
+
+	revs := conn.SelectFromHistory(func(rev Revision) bool {
+		return rev.ID == m.ID
+	})
+	assert.Len(t, revs, 3)
+	for i := range revs {
+		revs[i].Time = revs[0].Time
+	}
+
+	now := revs[0].Time
+	assert.Equal(t, []Revision{
+		{Time: now, Tag: "engine", Table: MachineTable, ID: m.ID, New: old},
+		{Time: now, Tag: "cluster", Table: MachineTable, ID: m.ID, Old: old, New: m},
+		{Time: now, Table: MachineTable, ID: m.ID, Old: m},
+	}, revs)
+
+	// Only the most recent revisions are kept.
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		view.InsertMachine()
+		return nil
+	})
+	revs = conn.SelectFromHistory(nil)
+	assert.Len(t, revs, 3)
+	assert.Equal(t, "cluster", revs[0].Tag)
+
+	conn.EnableHistory(0)
+	assert.Empty(t, conn.SelectFromHistory(nil))
+}
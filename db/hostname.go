@@ -32,7 +32,7 @@ func (db Database) SelectFromHostname(check func(Hostname) bool) []Hostname {
 // SelectFromHostname gets all hostnames in the database that satisfy the 'check'.
 func (conn Conn) SelectFromHostname(check func(Hostname) bool) []Hostname {
 	var hostnames []Hostname
-	conn.Txn(HostnameTable).Run(func(view Database) error {
+	conn.ReadTxn(HostnameTable).Run(func(view Database) error {
 		hostnames = view.SelectFromHostname(check)
 		return nil
 	})
@@ -34,6 +34,16 @@ func (db Database) SelectFromImage(check func(Image) bool) []Image {
 	return result
 }
 
+// SelectFromImage gets all images in the database connection that satisfy 'check'.
+func (conn Conn) SelectFromImage(check func(Image) bool) []Image {
+	var images []Image
+	conn.ReadTxn(ImageTable).Run(func(view Database) error {
+		images = view.SelectFromImage(check)
+		return nil
+	})
+	return images
+}
+
 func (image Image) getID() int {
 	return image.ID
 }
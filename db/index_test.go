@@ -0,0 +1,131 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex(t *testing.T) {
+	conn := New()
+
+	var a, b Container
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		for _, ip := range []string{"1.1.1.1", "2.2.2.2"} {
+			m := view.InsertMinion()
+			m.PrivateIP = ip
+			view.Commit(m)
+		}
+
+		a = view.InsertContainer()
+		a.StitchID = "a"
+		a.Minion = "1.1.1.1"
+		view.Commit(a)
+
+		b = view.InsertContainer()
+		b.StitchID = "b"
+		b.Minion = "1.1.1.1"
+		view.Commit(b)
+
+		assert.Equal(t, []Container{a}, view.SelectFromContainerByStitchID("a"))
+		assert.Equal(t, []Container{b}, view.SelectFromContainerByStitchID("b"))
+		assert.Len(t, view.SelectFromContainerByMinion("1.1.1.1"), 2)
+		assert.Empty(t, view.SelectFromContainerByMinion(""))
+		assert.Empty(t, view.SelectFromContainerByStitchID(""))
+		return nil
+	})
+
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		b.Minion = "2.2.2.2"
+		view.Commit(b)
+		view.Remove(a)
+		return nil
+	})
+
+	assert.Empty(t, conn.SelectFromContainerByMinion("1.1.1.1"))
+	assert.Equal(t, []Container{b}, conn.SelectFromContainerByMinion("2.2.2.2"))
+
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		assert.Empty(t, view.SelectFromContainerByStitchID("a"))
+
+		label := view.InsertLabel()
+		label.Label = "foo"
+		view.Commit(label)
+		assert.Equal(t, []Label{label}, view.SelectFromLabelByLabel("foo"))
+
+		m := view.InsertMachine()
+		m.PublicIP = "8.8.8.8"
+		view.Commit(m)
+		assert.Equal(t, []Machine{m}, view.SelectFromMachineByPublicIP("8.8.8.8"))
+		return nil
+	})
+}
+
+func TestIndexPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("Lookup didn't panic on an unindexed field")
+		}
+	}()
+
+	New().Txn(ContainerTable).Run(func(view Database) error {
+		view.accessTable(ContainerTable).lookup("Image", "foo")
+		return nil
+	})
+}
+
+func BenchmarkSelectByStitchID(b *testing.B) {
+	benchmarkContainers(b, 10000, func(view Database, i int) {
+		id := fmt.Sprintf("%d", i%10000)
+		view.SelectFromContainer(func(dbc Container) bool {
+			return dbc.StitchID == id
+		})
+	})
+}
+
+func BenchmarkIndexedSelectByStitchID(b *testing.B) {
+	benchmarkContainers(b, 10000, func(view Database, i int) {
+		view.SelectFromContainerByStitchID(fmt.Sprintf("%d", i%10000))
+	})
+}
+
+func BenchmarkSelectByMinion(b *testing.B) {
+	benchmarkContainers(b, 10000, func(view Database, i int) {
+		minion := fmt.Sprintf("10.0.0.%d", i%100)
+		view.SelectFromContainer(func(dbc Container) bool {
+			return dbc.Minion == minion
+		})
+	})
+}
+
+func BenchmarkIndexedSelectByMinion(b *testing.B) {
+	benchmarkContainers(b, 10000, func(view Database, i int) {
+		view.SelectFromContainerByMinion(fmt.Sprintf("10.0.0.%d", i%100))
+	})
+}
+
+// benchmarkContainers times 'do' against a database populated with 'n' containers
+// spread evenly across 100 minions.
+func benchmarkContainers(b *testing.B, n int, do func(Database, int)) {
+	New().Txn(AllTables...).Run(func(view Database) error {
+		for i := 0; i < 100; i++ {
+			m := view.InsertMinion()
+			m.PrivateIP = fmt.Sprintf("10.0.0.%d", i)
+			view.Commit(m)
+		}
+
+		for i := 0; i < n; i++ {
+			dbc := view.InsertContainer()
+			dbc.StitchID = fmt.Sprintf("%d", i)
+			dbc.Minion = fmt.Sprintf("10.0.0.%d", i%100)
+			view.Commit(dbc)
+		}
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			do(view, i)
+		}
+		return nil
+	})
+}
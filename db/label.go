@@ -4,9 +4,14 @@ package db
 type Label struct {
 	ID int
 
-	Label        string
+	Label        string `db:"index"`
 	IP           string
 	ContainerIPs []string
+
+	// UnhealthyIPs are the IPs of the label's containers that aren't serving.
+	// They're subject to the label's ACLs, but don't receive traffic sent to the
+	// label.
+	UnhealthyIPs []string
 }
 
 // LabelSlice is an alias for []Label to allow for joins
@@ -35,13 +40,23 @@ func (db Database) SelectFromLabel(check func(Label) bool) []Label {
 // SelectFromLabel gets all labels in the database connection that satisfy 'check'.
 func (conn Conn) SelectFromLabel(check func(Label) bool) []Label {
 	var result []Label
-	conn.Txn(LabelTable).Run(func(view Database) error {
+	conn.ReadTxn(LabelTable).Run(func(view Database) error {
 		result = view.SelectFromLabel(check)
 		return nil
 	})
 	return result
 }
 
+// SelectFromLabelByLabel gets all labels in the database with the given name.  Unlike
+// SelectFromLabel, it doesn't scan the entire table.
+func (db Database) SelectFromLabelByLabel(label string) []Label {
+	var result []Label
+	for _, r := range db.accessTable(LabelTable).lookup("Label", label) {
+		result = append(result, r.(Label))
+	}
+	return result
+}
+
 func (r Label) getID() int {
 	return r.ID
 }
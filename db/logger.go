@@ -21,7 +21,7 @@ func (conn Conn) runLogger() {
 func (conn Conn) logTable(t TableType) {
 	var truncated bool
 	var strs []string
-	conn.Txn(AllTables...).Run(func(view Database) error {
+	conn.ReadTxn(t).Run(func(view Database) error {
 		var rows []row
 		for _, v := range view.tables[t].rows {
 			if len(rows) > 50 {
@@ -12,6 +12,7 @@ type Machine struct {
 	ID int //Database ID
 
 	/* Populated by the policy engine. */
+	Namespace   string
 	StitchID    string
 	Role        Role
 	Provider    Provider
@@ -24,7 +25,7 @@ type Machine struct {
 
 	/* Populated by the cloud provider. */
 	CloudID   string //Cloud Provider ID
-	PublicIP  string
+	PublicIP  string `db:"index"`
 	PrivateIP string
 
 	/* Populated by the foreman. */
@@ -53,13 +54,23 @@ func (db Database) SelectFromMachine(check func(Machine) bool) []Machine {
 // SelectFromMachine gets all machines in the database that satisfy 'check'.
 func (cn Conn) SelectFromMachine(check func(Machine) bool) []Machine {
 	var machines []Machine
-	cn.Txn(MachineTable).Run(func(view Database) error {
+	cn.ReadTxn(MachineTable).Run(func(view Database) error {
 		machines = view.SelectFromMachine(check)
 		return nil
 	})
 	return machines
 }
 
+// SelectFromMachineByPublicIP gets all machines in the database with the given public
+// IP.  Unlike SelectFromMachine, it doesn't scan the entire table.
+func (db Database) SelectFromMachineByPublicIP(ip string) []Machine {
+	var result []Machine
+	for _, r := range db.accessTable(MachineTable).lookup("PublicIP", ip) {
+		result = append(result, r.(Machine))
+	}
+	return result
+}
+
 func (m Machine) getID() int {
 	return m.ID
 }
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	tableRowsDesc = prometheus.NewDesc("quilt_table_rows",
+		"The number of rows in each database table.", []string{"table"}, nil)
+	machineConnectedDesc = prometheus.NewDesc("quilt_machine_connected",
+		"Whether the foreman is connected to the minion on each machine.",
+		[]string{"stitch_id", "role", "provider", "public_ip"}, nil)
+	containersDesc = prometheus.NewDesc("quilt_containers",
+		"The number of containers in each state.", []string{"status"}, nil)
+)
+
+// Collector returns a Prometheus collector that exports the size of each table, the
+// connection state of each machine, and the states of the containers, as stored in
+// the database when it's scraped.
+func (cn Conn) Collector() prometheus.Collector {
+	return collector{cn}
+}
+
+type collector struct {
+	conn Conn
+}
+
+func (c collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- tableRowsDesc
+	descs <- machineConnectedDesc
+	descs <- containersDesc
+}
+
+func (c collector) Collect(metrics chan<- prometheus.Metric) {
+	c.conn.ReadTxn(AllTables...).Run(func(view Database) error {
+		for _, t := range AllTables {
+			metrics <- prometheus.MustNewConstMetric(tableRowsDesc,
+				prometheus.GaugeValue,
+				float64(len(view.accessTable(t).rows)), string(t))
+		}
+
+		for _, m := range view.SelectFromMachine(nil) {
+			connected := 0.0
+			if m.Connected {
+				connected = 1
+			}
+			metrics <- prometheus.MustNewConstMetric(machineConnectedDesc,
+				prometheus.GaugeValue, connected, m.StitchID,
+				string(m.Role), string(m.Provider), m.PublicIP)
+		}
+
+		statusCounts := map[string]int{}
+		for _, dbc := range view.SelectFromContainer(nil) {
+			status := dbc.Status
+			if status == "" {
+				status = "unknown"
+			}
+			statusCounts[status]++
+		}
+
+		for status, count := range statusCounts {
+			metrics <- prometheus.MustNewConstMetric(containersDesc,
+				prometheus.GaugeValue, float64(count), status)
+		}
+		return nil
+	})
+}
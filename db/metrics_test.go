@@ -0,0 +1,74 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	conn := New()
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		m := view.InsertMachine()
+		m.StitchID = "1"
+		m.Role = Master
+		m.Provider = Amazon
+		m.PublicIP = "1.2.3.4"
+		m.Connected = true
+		view.Commit(m)
+
+		m = view.InsertMachine()
+		m.StitchID = "2"
+		m.Role = Worker
+		m.Provider = Amazon
+		view.Commit(m)
+
+		for _, status := range []string{"running", "running", ""} {
+			dbc := view.InsertContainer()
+			dbc.Status = status
+			view.Commit(dbc)
+		}
+		return nil
+	})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(conn.Collector())
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	values := map[string]map[string]float64{}
+	for _, family := range families {
+		byLabel := map[string]float64{}
+		for _, metric := range family.Metric {
+			byLabel[labelString(metric.Label)] = metric.Gauge.GetValue()
+		}
+		values[family.GetName()] = byLabel
+	}
+
+	assert.Equal(t, 2.0, values["quilt_table_rows"]["table=db.Machine"])
+	assert.Equal(t, 3.0, values["quilt_table_rows"]["table=db.Container"])
+	assert.Equal(t, 0.0, values["quilt_table_rows"]["table=db.Etcd"])
+
+	assert.Equal(t, map[string]float64{
+		"provider=Amazon,public_ip=1.2.3.4,role=Master,stitch_id=1": 1,
+		"provider=Amazon,public_ip=,role=Worker,stitch_id=2":        0,
+	}, values["quilt_machine_connected"])
+
+	assert.Equal(t, map[string]float64{
+		"status=running": 2,
+		"status=unknown": 1,
+	}, values["quilt_containers"])
+}
+
+func labelString(labels []*dto.LabelPair) string {
+	var str string
+	for i, label := range labels {
+		if i > 0 {
+			str += ","
+		}
+		str += label.GetName() + "=" + label.GetValue()
+	}
+	return str
+}
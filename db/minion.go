@@ -16,6 +16,11 @@ type Minion struct {
 	Size       string
 	Region     string
 	FloatingIP string
+
+	// The capacity of the minion available to containers, in cores and MiB.
+	// Zero values are unknown, and so aren't enforced by the scheduler.
+	CPU    float64 `json:",omitempty"`
+	Memory int     `json:",omitempty"`
 }
 
 // InsertMinion creates a new Minion and inserts it into 'db'.
@@ -60,7 +65,7 @@ func (db Database) MinionSelf() Minion {
 func (conn Conn) MinionSelf() Minion {
 	var m Minion
 
-	conn.Txn(MinionTable).Run(func(view Database) error {
+	conn.ReadTxn(MinionTable).Run(func(view Database) error {
 		m = view.MinionSelf()
 		return nil
 	})
@@ -71,7 +76,7 @@ func (conn Conn) MinionSelf() Minion {
 // SelectFromMinion gets all minions in the database that satisfy the 'check'.
 func (conn Conn) SelectFromMinion(check func(Minion) bool) []Minion {
 	var minions []Minion
-	conn.Txn(MinionTable).Run(func(view Database) error {
+	conn.ReadTxn(MinionTable).Run(func(view Database) error {
 		minions = view.SelectFromMinion(check)
 		return nil
 	})
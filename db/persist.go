@@ -0,0 +1,241 @@
+package db
+
+import (
+	"encoding/gob"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/quilt/quilt/util"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	snapshotName = "snapshot"
+	logName      = "log"
+
+	// The number of Transactions appended to the log before it's folded into a
+	// fresh snapshot.
+	compactThreshold = 1024
+)
+
+// A journal persists the database to disk so that it survives restarts.  It consists
+// of a snapshot of every row, and an append-only log of the changes committed by each
+// Transaction since that snapshot was taken.
+type journal struct {
+	dir string
+
+	log     afero.File
+	enc     *gob.Encoder
+	batches int
+
+	compact chan struct{}
+	sync.Mutex
+}
+
+type journalOp int
+
+const (
+	journalInsert journalOp = iota
+	journalCommit
+	journalRemove
+)
+
+// A journalEntry records a single insert, Commit, or Remove.
+type journalEntry struct {
+	Op  journalOp
+	Row row
+}
+
+type snapshot struct {
+	NextID int
+	Rows   []row
+}
+
+func init() {
+	for _, r := range tableRows {
+		gob.Register(r)
+	}
+}
+
+// NewPersistent creates a connection to a database that is backed by the directory
+// 'dir'.  Any state previously persisted to 'dir' is replayed before returning, and
+// all subsequent changes are logged so that they're recovered by the next call to
+// NewPersistent.
+func NewPersistent(dir string) (Conn, error) {
+	if err := util.AppFs.MkdirAll(dir, 0700); err != nil {
+		return Conn{}, err
+	}
+
+	db := newDatabase()
+	j := &journal{dir: dir, compact: make(chan struct{}, 1)}
+	if err := j.replay(db); err != nil {
+		return Conn{}, err
+	}
+
+	if err := j.snapshot(db); err != nil {
+		return Conn{}, err
+	}
+	db.journal = j
+
+	cn := Conn{db: db}
+	go j.runCompactor(cn)
+	cn.runLogger()
+	return cn, nil
+}
+
+// replay loads the snapshot, and then applies every complete Transaction in the log.
+// A partially written Transaction at the tail of the log (e.g. due to a crash in the
+// middle of a write) is discarded.
+func (j *journal) replay(db Database) error {
+	snapFile, err := util.AppFs.Open(j.path(snapshotName))
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return err
+	default:
+		var snap snapshot
+		err := gob.NewDecoder(snapFile).Decode(&snap)
+		snapFile.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, r := range snap.Rows {
+			db.accessTable(getTableType(r)).put(r)
+		}
+		db.idAlloc.curID = snap.NextID
+	}
+
+	logFile, err := util.AppFs.Open(j.path(logName))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	dec := gob.NewDecoder(logFile)
+	for {
+		var batch []journalEntry
+		err := dec.Decode(&batch)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			log.WithError(err).Warn("Discarding incomplete database log entry.")
+			return nil
+		}
+
+		for _, entry := range batch {
+			db.apply(entry)
+		}
+	}
+}
+
+// snapshot writes every row of 'db' to a new snapshot, and then truncates the log.
+// The caller must ensure that no Transactions are running on 'db'.
+func (j *journal) snapshot(db Database) error {
+	snap := snapshot{NextID: db.idAlloc.curID}
+	for _, t := range db.tables {
+		for _, r := range t.rows {
+			snap.Rows = append(snap.Rows, r)
+		}
+	}
+
+	tmpPath := j.path(snapshotName + ".tmp")
+	tmp, err := util.AppFs.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
+		0600)
+	if err != nil {
+		return err
+	}
+
+	if err := gob.NewEncoder(tmp).Encode(snap); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+
+	if err := util.AppFs.Rename(tmpPath, j.path(snapshotName)); err != nil {
+		return err
+	}
+
+	if j.log != nil {
+		j.log.Close()
+	}
+
+	// The log is always started from scratch alongside a new snapshot so that it
+	// contains a single gob stream.
+	j.log, err = util.AppFs.OpenFile(j.path(logName),
+		os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	j.enc = gob.NewEncoder(j.log)
+	j.batches = 0
+	return nil
+}
+
+// append durably writes the changes made by a single Transaction to the log.
+func (j *journal) append(batch []journalEntry) {
+	j.Lock()
+	defer j.Unlock()
+
+	if err := j.enc.Encode(batch); err != nil {
+		log.WithError(err).Error("Failed to write database log.")
+		return
+	}
+
+	if err := j.log.Sync(); err != nil {
+		log.WithError(err).Error("Failed to sync database log.")
+		return
+	}
+
+	j.batches++
+	if j.batches >= compactThreshold {
+		select {
+		case j.compact <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (j *journal) runCompactor(conn Conn) {
+	for range j.compact {
+		conn.ReadTxn(AllTables...).Run(func(view Database) error {
+			j.Lock()
+			defer j.Unlock()
+
+			if err := j.snapshot(view); err != nil {
+				log.WithError(err).Error("Failed to compact database log.")
+			}
+			return nil
+		})
+	}
+}
+
+func (j *journal) path(name string) string {
+	return filepath.Join(j.dir, name)
+}
+
+func (db Database) apply(entry journalEntry) {
+	r := entry.Row
+	table := db.accessTable(getTableType(r))
+	switch entry.Op {
+	case journalInsert, journalCommit:
+		table.put(r)
+	case journalRemove:
+		table.delete(r.getID())
+	}
+
+	if id := r.getID(); id > db.idAlloc.curID {
+		db.idAlloc.curID = id
+	}
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quilt/quilt/util"
+)
+
+func TestPersistReplay(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+
+	conn, err := NewPersistent("/db")
+	assert.NoError(t, err)
+
+	var m Machine
+	var dbc Container
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		clst := view.InsertCluster()
+		clst.Namespace = "ns"
+		view.Commit(clst)
+
+		m = view.InsertMachine()
+		m.Role = Master
+		m.PublicIP = "1.2.3.4"
+		view.Commit(m)
+
+		dbc = view.InsertContainer()
+		dbc.StitchID = "1"
+		view.Commit(dbc)
+
+		view.Remove(view.InsertLabel())
+		return nil
+	})
+
+	conn.Txn(ContainerTable).Run(func(view Database) error {
+		view.Remove(dbc)
+		return nil
+	})
+
+	conn, err = NewPersistent("/db")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"ns"}, conn.GetClusterNamespaces())
+
+	assert.Equal(t, []Machine{m}, conn.SelectFromMachine(nil))
+	assert.Empty(t, conn.SelectFromContainer(nil))
+	assert.Empty(t, conn.SelectFromLabel(nil))
+
+	// IDs must not be reused, even those of rows that were removed.
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		assert.Equal(t, 5, view.InsertEtcd().ID)
+		return nil
+	})
+}
+
+func TestPersistTornLog(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+
+	conn, err := NewPersistent("/db")
+	assert.NoError(t, err)
+
+	var m Machine
+	conn.Txn(MachineTable).Run(func(view Database) error {
+		m = view.InsertMachine()
+		return nil
+	})
+
+	f, err := util.AppFs.OpenFile("/db/log", os.O_WRONLY|os.O_APPEND, 0600)
+	assert.NoError(t, err)
+	f.Write([]byte{0x42, 0x13, 0x37})
+	f.Close()
+
+	conn, err = NewPersistent("/db")
+	assert.NoError(t, err)
+	assert.Equal(t, []Machine{m}, conn.SelectFromMachine(nil))
+}
+
+func TestPersistCompact(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+
+	conn, err := NewPersistent("/db")
+	assert.NoError(t, err)
+
+	for i := 0; i < compactThreshold; i++ {
+		conn.Txn(MachineTable).Run(func(view Database) error {
+			view.InsertMachine()
+			return nil
+		})
+	}
+
+	// The compactor runs in the background, so wait for it to truncate the log.
+	j := conn.db.journal
+	err = util.WaitFor(func() bool {
+		j.Lock()
+		defer j.Unlock()
+		return j.batches == 0
+	}, 10*time.Millisecond, 5*time.Second)
+	assert.NoError(t, err)
+
+	info, err := util.AppFs.Stat("/db/log")
+	assert.NoError(t, err)
+	assert.Zero(t, info.Size())
+
+	conn, err = NewPersistent("/db")
+	assert.NoError(t, err)
+	assert.Len(t, conn.SelectFromMachine(nil), compactThreshold)
+}
@@ -0,0 +1,82 @@
+package db
+
+// A Secret is a value, such as a password, that's set out-of-band rather than in the
+// blueprint.  Its Value is sealed with the minions' credentials, and is only opened
+// by the workers running the containers that use it.
+type Secret struct {
+	ID int `json:"-"`
+
+	Name  string
+	Value string `rowStringer:"omit"`
+}
+
+// SecretSlice is an alias for []Secret to allow for joins
+type SecretSlice []Secret
+
+// InsertSecret creates a new Secret row and inserts it into 'db'.
+func (db Database) InsertSecret() Secret {
+	result := Secret{ID: db.nextID()}
+	db.insert(result)
+	return result
+}
+
+// SelectFromSecret gets all secrets in the database that satisfy 'check'.
+func (db Database) SelectFromSecret(check func(Secret) bool) []Secret {
+	secretTable := db.accessTable(SecretTable)
+	result := []Secret{}
+	for _, row := range secretTable.rows {
+		if check == nil || check(row.(Secret)) {
+			result = append(result, row.(Secret))
+		}
+	}
+	return result
+}
+
+// SelectFromSecret gets all secrets in the database that satisfy the 'check'.
+func (conn Conn) SelectFromSecret(check func(Secret) bool) []Secret {
+	var secrets []Secret
+	conn.ReadTxn(SecretTable).Run(func(view Database) error {
+		secrets = view.SelectFromSecret(check)
+		return nil
+	})
+	return secrets
+}
+
+func (r Secret) getID() int {
+	return r.ID
+}
+
+func (r Secret) String() string {
+	return defaultString(r)
+}
+
+func (r Secret) less(row row) bool {
+	r2 := row.(Secret)
+
+	switch {
+	case r.Name != r2.Name:
+		return r.Name < r2.Name
+	default:
+		return r.ID < r2.ID
+	}
+}
+
+// Get returns the value contained at the given index
+func (ss SecretSlice) Get(i int) interface{} {
+	return ss[i]
+}
+
+// Len returns the number of items in the slice
+func (ss SecretSlice) Len() int {
+	return len(ss)
+}
+
+// Less implements less than for sort.Interface.
+func (ss SecretSlice) Less(i, j int) bool {
+	return ss[i].less(ss[j])
+}
+
+// Swap implements swapping for sort.Interface.
+func (ss SecretSlice) Swap(i, j int) {
+	ss[i], ss[j] = ss[j], ss[i]
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretSelect(t *testing.T) {
+	conn := New()
+	err := conn.Txn(SecretTable).Run(func(view Database) error {
+		s := view.InsertSecret()
+		s.Name = "a"
+		s.Value = "sealed-a"
+		view.Commit(s)
+
+		s = view.InsertSecret()
+		s.Name = "b"
+		s.Value = "sealed-b"
+		view.Commit(s)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	actual := conn.SelectFromSecret(func(s Secret) bool {
+		return s.Name == "a"
+	})
+	assert.Equal(t, []Secret{{ID: 1, Name: "a", Value: "sealed-a"}}, actual)
+	assert.Len(t, conn.SelectFromSecret(nil), 2)
+}
+
+func TestSecretSlice(t *testing.T) {
+	secrets := []Secret{{ID: 3, Name: "b"}, {ID: 2, Name: "b"}, {ID: 1, Name: "a"}}
+	sort.Sort(SecretSlice(secrets))
+	assert.Equal(t, []Secret{{ID: 1, Name: "a"}, {ID: 2, Name: "b"},
+		{ID: 3, Name: "b"}}, secrets)
+	assert.Equal(t, secrets[0], SecretSlice(secrets).Get(0))
+}
+
+func TestSecretString(t *testing.T) {
+	// Secret values are never logged, even though they're sealed.
+	assert.Equal(t, "Secret-1{Name=password}",
+		Secret{ID: 1, Name: "password", Value: "sealed"}.String())
+}
@@ -0,0 +1,190 @@
+package db
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// A ChangeSet describes the rows of a table that were inserted, removed, or modified
+// by a committed Transaction.  The rows have the concrete type stored in 'Table', e.g.
+// a ChangeSet for the ContainerTable holds Containers.
+type ChangeSet struct {
+	Table TableType
+
+	Inserted []interface{}
+	Removed  []interface{}
+	Modified []Modification
+}
+
+// A Modification holds the value of a row before and after it was committed.
+type Modification struct {
+	Old, New interface{}
+}
+
+// A Subscription delivers a ChangeSet on 'Subscription.C' for every Transaction that
+// changes its tables.  Unlike a Trigger, notifications are never dropped.  Instead,
+// if the receiver falls behind, the changes made by consecutive Transactions are
+// merged into a single ChangeSet per table.
+type Subscription struct {
+	C <-chan ChangeSet
+
+	sub *subscriber
+}
+
+type subscriber struct {
+	c     chan ChangeSet
+	ready chan struct{}
+	stop  chan struct{}
+
+	sync.Mutex
+	pending map[TableType]map[int]*rowChange
+}
+
+// A rowChange is the value of a row before and after some number of changes.  'old'
+// is nil if the row was inserted, and 'new' is nil if it was removed.
+type rowChange struct {
+	old, new row
+}
+
+// Subscribe registers a new Subscription to changes in the tables 'tt'.  So that
+// clients may build their initial state, the first ChangeSet for each table reports
+// all of its existing rows as inserted.
+func (cn Conn) Subscribe(tt ...TableType) Subscription {
+	sub := &subscriber{
+		c:       make(chan ChangeSet),
+		ready:   make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		pending: map[TableType]map[int]*rowChange{},
+	}
+
+	cn.Txn(tt...).Run(func(db Database) error {
+		for _, t := range tt {
+			dbTable := db.accessTable(t)
+			dbTable.subscribers[sub] = struct{}{}
+
+			changes := map[int]*rowChange{}
+			for id, r := range dbTable.rows {
+				changes[id] = &rowChange{new: r}
+			}
+			sub.push(t, changes)
+		}
+		return nil
+	})
+
+	go sub.run()
+	return Subscription{C: sub.c, sub: sub}
+}
+
+// Stop a running Subscription thus allowing resources to be deallocated.
+func (s Subscription) Stop() {
+	close(s.sub.stop)
+}
+
+// push merges 'changes' into the ChangeSets waiting to be delivered.
+func (sub *subscriber) push(tt TableType, changes map[int]*rowChange) {
+	sub.Lock()
+	defer sub.Unlock()
+
+	pending := sub.pending[tt]
+	if pending == nil {
+		pending = map[int]*rowChange{}
+		sub.pending[tt] = pending
+	}
+
+	for id, change := range changes {
+		if prev, ok := pending[id]; ok {
+			pending[id] = &rowChange{old: prev.old, new: change.new}
+		} else {
+			pending[id] = &rowChange{old: change.old, new: change.new}
+		}
+
+		if pending[id].unchanged() {
+			delete(pending, id)
+		}
+	}
+
+	if len(pending) == 0 {
+		delete(sub.pending, tt)
+		return
+	}
+
+	select {
+	case sub.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (sub *subscriber) run() {
+	for {
+		select {
+		case <-sub.ready:
+		case <-sub.stop:
+			return
+		}
+
+		sub.Lock()
+		pending := sub.pending
+		sub.pending = map[TableType]map[int]*rowChange{}
+		sub.Unlock()
+
+		var tables tableSlice
+		for tt := range pending {
+			tables = append(tables, tt)
+		}
+		sort.Sort(tables)
+
+		for _, tt := range tables {
+			select {
+			case sub.c <- newChangeSet(tt, pending[tt]):
+			case <-sub.stop:
+				return
+			}
+		}
+	}
+}
+
+func (sub *subscriber) stopped() bool {
+	select {
+	case <-sub.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (rc rowChange) unchanged() bool {
+	return (rc.old == nil && rc.new == nil) || reflect.DeepEqual(rc.old, rc.new)
+}
+
+func newChangeSet(tt TableType, changes map[int]*rowChange) ChangeSet {
+	var inserted, removed, modified rowSlice
+	olds := map[int]row{}
+	for id, change := range changes {
+		switch {
+		case change.old == nil:
+			inserted = append(inserted, change.new)
+		case change.new == nil:
+			removed = append(removed, change.old)
+		default:
+			modified = append(modified, change.new)
+			olds[id] = change.old
+		}
+	}
+
+	sort.Sort(inserted)
+	sort.Sort(removed)
+	sort.Sort(modified)
+
+	cs := ChangeSet{Table: tt}
+	for _, r := range inserted {
+		cs.Inserted = append(cs.Inserted, r)
+	}
+	for _, r := range removed {
+		cs.Removed = append(cs.Removed, r)
+	}
+	for _, r := range modified {
+		cs.Modified = append(cs.Modified, Modification{olds[r.getID()], r})
+	}
+	return cs
+}
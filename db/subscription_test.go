@@ -0,0 +1,137 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribe(t *testing.T) {
+	conn := New()
+
+	var m Machine
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		m = view.InsertMachine()
+		return nil
+	})
+
+	sub := conn.Subscribe(MachineTable, ContainerTable)
+	defer sub.Stop()
+
+	// The initial ChangeSet should include the existing machine.
+	assert.Equal(t, ChangeSet{Table: MachineTable, Inserted: []interface{}{m}},
+		changeRecv(t, sub))
+	changeNoRecv(t, sub)
+
+	var dbc Container
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		dbc = view.InsertContainer()
+		dbc.StitchID = "1"
+		view.Commit(dbc)
+
+		// Changes to rows that are inserted and removed within the same
+		// Transaction shouldn't be reported.
+		view.Remove(view.InsertContainer())
+
+		// Neither should changes to tables without a subscription.
+		view.InsertLabel()
+		return nil
+	})
+	assert.Equal(t, ChangeSet{Table: ContainerTable, Inserted: []interface{}{dbc}},
+		changeRecv(t, sub))
+	changeNoRecv(t, sub)
+
+	old := m
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		m.PublicIP = "1.2.3.4"
+		view.Commit(m)
+		view.Remove(dbc)
+		return nil
+	})
+
+	// The ChangeSets for each table may arrive in either order.
+	changes := map[TableType]ChangeSet{}
+	for i := 0; i < 2; i++ {
+		cs := changeRecv(t, sub)
+		changes[cs.Table] = cs
+	}
+	assert.Equal(t, map[TableType]ChangeSet{
+		ContainerTable: {Table: ContainerTable, Removed: []interface{}{dbc}},
+		MachineTable: {Table: MachineTable,
+			Modified: []Modification{{old, m}}},
+	}, changes)
+	changeNoRecv(t, sub)
+
+	// Committing a row without modifying it isn't a change.
+	conn.Txn(AllTables...).Run(func(view Database) error {
+		view.Commit(m)
+		return nil
+	})
+	changeNoRecv(t, sub)
+}
+
+func TestSubscriberMerge(t *testing.T) {
+	sub := &subscriber{
+		ready:   make(chan struct{}, 1),
+		pending: map[TableType]map[int]*rowChange{},
+	}
+
+	m1 := Machine{ID: 1}
+	m2 := Machine{ID: 2}
+	sub.push(MachineTable, map[int]*rowChange{
+		1: {new: m1},
+		2: {new: m2},
+	})
+
+	// The receiver hasn't caught up, so these changes should be merged with the
+	// insertions above.
+	newM1 := Machine{ID: 1, Region: "here"}
+	sub.push(MachineTable, map[int]*rowChange{
+		1: {old: m1, new: newM1},
+		2: {old: m2},
+	})
+
+	assert.Equal(t, ChangeSet{Table: MachineTable, Inserted: []interface{}{newM1}},
+		newChangeSet(MachineTable, sub.pending[MachineTable]))
+
+	// Reverting a modification cancels it out entirely.
+	sub.pending = map[TableType]map[int]*rowChange{}
+	sub.push(MachineTable, map[int]*rowChange{1: {old: m1, new: newM1}})
+	sub.push(MachineTable, map[int]*rowChange{1: {old: newM1, new: m1}})
+	assert.Empty(t, sub.pending)
+}
+
+func TestSubscribeStop(t *testing.T) {
+	conn := New()
+	sub := conn.Subscribe(MachineTable)
+	sub.Stop()
+
+	conn.Txn(MachineTable).Run(func(view Database) error {
+		view.InsertMachine()
+		return nil
+	})
+
+	conn.Txn(MachineTable).Run(func(view Database) error {
+		assert.Empty(t, view.accessTable(MachineTable).subscribers)
+		return nil
+	})
+}
+
+func changeRecv(t *testing.T, sub Subscription) ChangeSet {
+	select {
+	case cs := <-sub.C:
+		return cs
+	case <-time.Tick(5 * time.Second):
+		t.Error("Expected Receive")
+		return ChangeSet{}
+	}
+}
+
+func changeNoRecv(t *testing.T, sub Subscription) {
+	select {
+	case cs := <-sub.C:
+		t.Errorf("Unexpected Receive: %v", cs)
+	case <-time.Tick(25 * time.Millisecond):
+	}
+}
@@ -41,27 +41,142 @@ var ImageTable = TableType(reflect.TypeOf(Image{}).String())
 // HostnameTable is the type of the Hostname table.
 var HostnameTable = TableType(reflect.TypeOf(Hostname{}).String())
 
+// SecretTable is the type of the Secret table.
+var SecretTable = TableType(reflect.TypeOf(Secret{}).String())
+
 // AllTables is a slice of all the db TableTypes. It is used primarily for tests,
 // where there is no reason to put lots of thought into which tables a Transaction
 // should use.
 var AllTables = []TableType{ClusterTable, MachineTable, ContainerTable, MinionTable,
 	ConnectionTable, LabelTable, EtcdTable, PlacementTable, ACLTable, ImageTable,
-	HostnameTable}
+	HostnameTable, SecretTable}
+
+// tableRows maps each TableType to an empty row of the type it stores.
+var tableRows = map[TableType]row{
+	ClusterTable:    Cluster{},
+	MachineTable:    Machine{},
+	ContainerTable:  Container{},
+	MinionTable:     Minion{},
+	ConnectionTable: Connection{},
+	LabelTable:      Label{},
+	EtcdTable:       Etcd{},
+	PlacementTable:  Placement{},
+	ACLTable:        ACL{},
+	ImageTable:      Image{},
+	HostnameTable:   Hostname{},
+	SecretTable:     Secret{},
+}
 
 type table struct {
 	rows map[int]row
 
+	// Secondary indexes keyed by the name of the field they index.  A field is
+	// indexed by tagging it with `db:"index"`.
+	indexes map[string]*index
+
 	triggers    map[Trigger]struct{}
 	shouldAlert bool
-	sync.Mutex
+
+	subscribers map[*subscriber]struct{}
+
+	// Rows changed by the running Transaction, keyed by ID.
+	changes map[int]*rowChange
+
+	// The Constraints that examine this table.
+	constraints []Constraint
+
+	// The most recent Revisions of the table, oldest first.
+	history      []Revision
+	historyLimit int
+
+	// Changes made by the running Transaction that have yet to be journaled.
+	pending []journalEntry
+	sync.RWMutex
+}
+
+// An index maps each value of a field to the IDs of the rows that hold it.
+type index struct {
+	field int
+	ids   map[interface{}]map[int]struct{}
 }
 
-func newTable() *table {
-	return &table{
+func newTable(prototype row) *table {
+	t := &table{
 		rows:        make(map[int]row),
+		indexes:     make(map[string]*index),
 		triggers:    make(map[Trigger]struct{}),
+		subscribers: make(map[*subscriber]struct{}),
 		shouldAlert: false,
 	}
+
+	rowType := reflect.TypeOf(prototype)
+	for i := 0; i < rowType.NumField(); i++ {
+		field := rowType.Field(i)
+		if field.Tag.Get("db") != "index" {
+			continue
+		}
+
+		if !field.Type.Comparable() {
+			panic("cannot index field: " + field.Name)
+		}
+		t.indexes[field.Name] = &index{i, make(map[interface{}]map[int]struct{})}
+	}
+
+	return t
+}
+
+// put stores 'r' in the table, replacing any existing row with the same ID.
+func (t *table) put(r row) {
+	id := r.getID()
+	if old, ok := t.rows[id]; ok {
+		t.unindex(old)
+	}
+
+	t.rows[id] = r
+	for _, idx := range t.indexes {
+		val := idx.value(r)
+		if idx.ids[val] == nil {
+			idx.ids[val] = make(map[int]struct{})
+		}
+		idx.ids[val][id] = struct{}{}
+	}
+}
+
+// delete removes the row with the given ID from the table.
+func (t *table) delete(id int) {
+	if old, ok := t.rows[id]; ok {
+		t.unindex(old)
+		delete(t.rows, id)
+	}
+}
+
+func (t *table) unindex(r row) {
+	for _, idx := range t.indexes {
+		val := idx.value(r)
+		delete(idx.ids[val], r.getID())
+		if len(idx.ids[val]) == 0 {
+			delete(idx.ids, val)
+		}
+	}
+}
+
+// lookup returns the rows whose 'field' equals 'value'.  It panics if 'field' isn't
+// indexed.
+func (t *table) lookup(field string, value interface{}) []row {
+	idx, ok := t.indexes[field]
+	if !ok {
+		panic("no index on field: " + field)
+	}
+
+	var result []row
+	for id := range idx.ids[value] {
+		result = append(result, t.rows[id])
+	}
+	return result
+}
+
+func (idx *index) value(r row) interface{} {
+	return reflect.ValueOf(r).Field(idx.field).Interface()
 }
 
 func (t *table) alert() {
@@ -79,3 +194,35 @@ func (t *table) alert() {
 		}
 	}
 }
+
+// noteChange records that the row with the given 'id' changed from 'old' to 'new'
+// within the running Transaction.
+func (t *table) noteChange(id int, old, new row) {
+	if t.changes == nil {
+		t.changes = map[int]*rowChange{}
+	}
+
+	if change, ok := t.changes[id]; ok {
+		change.new = new
+	} else {
+		t.changes[id] = &rowChange{old, new}
+	}
+}
+
+// publish delivers the changes made by the completed Transaction to subscribers, and
+// records them in the history on behalf of 'tag'.
+func (t *table) publish(tt TableType, tag string) {
+	changes := t.changes
+	t.changes = nil
+	t.addHistory(tt, tag, changes)
+	for sub := range t.subscribers {
+		if sub.stopped() {
+			delete(t.subscribers, sub)
+			continue
+		}
+
+		if changes != nil {
+			sub.push(tt, changes)
+		}
+	}
+}
@@ -13,49 +13,60 @@ import (
 var myIP = util.MyIP
 var defaultDiskSize = 32
 
-// Run updates the database in response to stitch changes in the cluster table.
+// Run updates the database in response to stitch changes in the cluster table.  The
+// machines and ACLs of each namespace are derived from the blueprint of its cluster.
 func Run(conn db.Conn) {
+	loopLog := util.NewEventTimer("Engine")
 	for range conn.TriggerTick(30, db.ClusterTable, db.MachineTable, db.ACLTable).C {
-		conn.Txn(db.ACLTable, db.ClusterTable,
+		loopLog.LogStart()
+		err := conn.Txn(db.ACLTable, db.ClusterTable,
 			db.MachineTable).Run(updateTxn)
+		if err != nil {
+			log.WithError(err).Error("Failed to update machines and ACLs.")
+		}
+		loopLog.LogEnd()
 	}
 }
 
 func updateTxn(view db.Database) error {
-	cluster, err := view.GetCluster()
-	if err != nil {
-		return err
-	}
+	for _, cluster := range view.SelectFromCluster(nil) {
+		stitch, err := stitch.FromJSON(cluster.Blueprint)
+		if err != nil {
+			log.WithError(err).WithField("namespace", cluster.Namespace).
+				Warn("Failed to parse blueprint.")
+			continue
+		}
 
-	stitch, err := stitch.FromJSON(cluster.Blueprint)
-	if err != nil {
-		return err
+		machineTxn(view, cluster.Namespace, stitch)
+		aclTxn(view, cluster.Namespace, stitch)
 	}
-
-	cluster.Namespace = stitch.Namespace
-	view.Commit(cluster)
-
-	machineTxn(view, stitch)
-	aclTxn(view, stitch)
 	return nil
 }
 
-func aclTxn(view db.Database, blueprintHandle stitch.Stitch) {
-	aclRow, err := view.GetACL()
+func aclTxn(view db.Database, namespace string, blueprintHandle stitch.Stitch) {
+	aclRow, err := view.GetACL(namespace)
 	if err != nil {
 		aclRow = view.InsertACL()
+		aclRow.Namespace = namespace
 	}
 
 	aclRow.Admin = resolveACLs(blueprintHandle.AdminACL)
 
 	var applicationPorts []db.PortRange
 	for _, conn := range blueprintHandle.Connections {
-		if conn.From == stitch.PublicInternetLabel {
-			applicationPorts = append(applicationPorts, db.PortRange{
-				MinPort: conn.MinPort,
-				MaxPort: conn.MaxPort,
-			})
+		if conn.From != stitch.PublicInternetLabel {
+			continue
+		}
+
+		// The cloud ACLs allow traffic to the public ports, which the minions
+		// forward to the container ports.  ICMP has no ports, so all ICMP
+		// traffic is allowed.
+		ports := db.PortRange{MinPort: conn.MinPort, MaxPort: conn.MaxPort,
+			Protocol: conn.Protocol}
+		if conn.Protocol == stitch.ICMP {
+			ports.MinPort, ports.MaxPort = 0, 0
 		}
+		applicationPorts = append(applicationPorts, ports)
 	}
 	aclRow.ApplicationPorts = applicationPorts
 
@@ -124,12 +135,14 @@ func toDBMachine(machines []stitch.Machine, maxPrice float64) []db.Machine {
 	return dbMachines
 }
 
-func machineTxn(view db.Database, stitch stitch.Stitch) {
+func machineTxn(view db.Database, namespace string, stitch stitch.Stitch) {
 	// XXX: How best to deal with machines that don't specify enough information?
 	maxPrice := stitch.MaxPrice
 	stitchMachines := toDBMachine(stitch.Machines, maxPrice)
 
-	dbMachines := view.SelectFromMachine(nil)
+	dbMachines := view.SelectFromMachine(func(m db.Machine) bool {
+		return m.Namespace == namespace
+	})
 
 	scoreFun := func(left, right interface{}) int {
 		stitchMachine := left.(db.Machine)
@@ -180,6 +193,7 @@ func machineTxn(view db.Database, stitch stitch.Stitch) {
 		stitchMachine := pair.L.(db.Machine)
 		dbMachine := pair.R.(db.Machine)
 
+		dbMachine.Namespace = namespace
 		dbMachine.StitchID = stitchMachine.StitchID
 		dbMachine.Role = stitchMachine.Role
 		dbMachine.Size = stitchMachine.Size
@@ -25,7 +25,7 @@ func TestEngine(t *testing.T) {
 		},
 	}
 	updateStitch(t, conn, stc)
-	acl, err := selectACL(conn)
+	acl, err := selectACL(conn, "namespace")
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(acl.Admin))
 
@@ -95,9 +95,8 @@ func TestEngine(t *testing.T) {
 	assert.Equal(t, "2", workers[0].PublicIP)
 	assert.Equal(t, "3", workers[0].PrivateIP)
 
-	/* Empty Namespace does nothing. */
-	stc.Namespace = ""
-	updateStitch(t, conn, stc)
+	/* An empty blueprint in the empty namespace doesn't touch this one. */
+	updateStitch(t, conn, stitch.Stitch{})
 	masters, workers = selectMachines(conn)
 
 	assert.Equal(t, 1, len(masters))
@@ -112,6 +111,7 @@ func TestEngine(t *testing.T) {
 
 	/* Verify things go to zero. */
 	updateStitch(t, conn, stitch.Stitch{
+		Namespace: "namespace",
 		Machines: []stitch.Machine{
 			{Provider: "Amazon", Size: "m4.large", Role: "Worker"},
 		},
@@ -226,7 +226,7 @@ func TestACLs(t *testing.T) {
 		return "5.6.7.8", nil
 	}
 	updateStitch(t, conn, stc)
-	acl, err := selectACL(conn)
+	acl, err := selectACL(conn, "")
 	assert.Nil(t, err)
 	assert.Equal(t, []string{"1.2.3.4/32", "5.6.7.8/32"}, acl.Admin)
 
@@ -234,9 +234,76 @@ func TestACLs(t *testing.T) {
 		return "", errors.New("")
 	}
 	updateStitch(t, conn, stc)
-	acl, err = selectACL(conn)
+	acl, err = selectACL(conn, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.2.3.4/32"}, acl.Admin)
+
+	stc.Connections = []stitch.Connection{
+		{From: "public", To: "web", MinPort: 80, MaxPort: 80},
+		{From: "public", To: "dns", MinPort: 53, MaxPort: 53,
+			Protocol: stitch.UDP},
+		{From: "public", To: "web", MinPort: 80, MaxPort: 80,
+			Protocol: stitch.ICMP},
+		{From: "web", To: "dns", MinPort: 53, MaxPort: 53},
+		{From: "public", To: "game", MinPort: 27015, MaxPort: 27030},
+		{From: "public", To: "web", MinPort: 8443, MaxPort: 8443,
+			ContainerPort: 443},
+	}
+	updateStitch(t, conn, stc)
+	acl, err = selectACL(conn, "")
 	assert.Nil(t, err)
+	assert.Equal(t, []db.PortRange{
+		{MinPort: 80, MaxPort: 80},
+		{MinPort: 53, MaxPort: 53, Protocol: "udp"},
+		{Protocol: "icmp"},
+		{MinPort: 27015, MaxPort: 27030},
+		{MinPort: 8443, MaxPort: 8443},
+	}, acl.ApplicationPorts)
+}
+
+func TestNamespaces(t *testing.T) {
+	conn := db.New()
+
+	staging := stitch.Stitch{
+		Namespace: "staging",
+		AdminACL:  []string{"1.2.3.4/32"},
+		Machines: []stitch.Machine{
+			{Provider: "Amazon", Size: "m4.large", Role: "Master"},
+			{Provider: "Amazon", Size: "m4.large", Role: "Worker"},
+		},
+	}
+	prod := stitch.Stitch{
+		Namespace: "prod",
+		AdminACL:  []string{"5.6.7.8/32"},
+		Machines: []stitch.Machine{
+			{Provider: "Amazon", Size: "m4.large", Role: "Master"},
+			{Provider: "Amazon", Size: "m4.large", Role: "Worker"},
+			{Provider: "Amazon", Size: "m4.large", Role: "Worker"},
+		},
+	}
+	updateStitch(t, conn, staging)
+	updateStitch(t, conn, prod)
+
+	countMachines := func(namespace string) int {
+		return len(conn.SelectFromMachine(func(m db.Machine) bool {
+			return m.Namespace == namespace
+		}))
+	}
+	assert.Equal(t, 2, countMachines("staging"))
+	assert.Equal(t, 3, countMachines("prod"))
+
+	acl, err := selectACL(conn, "staging")
+	assert.NoError(t, err)
 	assert.Equal(t, []string{"1.2.3.4/32"}, acl.Admin)
+
+	acl, err = selectACL(conn, "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"5.6.7.8/32"}, acl.Admin)
+
+	// Stopping one namespace leaves the others alone.
+	updateStitch(t, conn, stitch.Stitch{Namespace: "staging"})
+	assert.Equal(t, 0, countMachines("staging"))
+	assert.Equal(t, 3, countMachines("prod"))
 }
 
 func selectMachines(conn db.Conn) (masters, workers []db.Machine) {
@@ -252,9 +319,9 @@ func selectMachines(conn db.Conn) (masters, workers []db.Machine) {
 	return
 }
 
-func selectACL(conn db.Conn) (acl db.ACL, err error) {
+func selectACL(conn db.Conn, namespace string) (acl db.ACL, err error) {
 	err = conn.Txn(db.AllTables...).Run(func(view db.Database) error {
-		acl, err = view.GetACL()
+		acl, err = view.GetACL(namespace)
 		return err
 	})
 	return
@@ -262,9 +329,10 @@ func selectACL(conn db.Conn) (acl db.ACL, err error) {
 
 func updateStitch(t *testing.T, conn db.Conn, stitch stitch.Stitch) {
 	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
-		cluster, err := view.GetCluster()
+		cluster, err := view.GetCluster(stitch.Namespace)
 		if err != nil {
 			cluster = view.InsertCluster()
+			cluster.Namespace = stitch.Namespace
 		}
 		cluster.Blueprint = stitch.String()
 		view.Commit(cluster)
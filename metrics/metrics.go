@@ -0,0 +1,102 @@
+// Package metrics exports the internal state of the daemon and the minions to
+// Prometheus.  Modules record events in the collectors defined here, which are
+// served, along with any collectors passed to Run, over mutually authenticated TLS.
+package metrics
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MinionPort is the port on which the minions serve metrics.
+const MinionPort = 9002
+
+const namespace = "quilt"
+
+var (
+	// LoopDuration records how long each iteration of Quilt's control loops
+	// takes, labelled by the name of the loop.
+	LoopDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "loop_duration_seconds",
+		Help:      "The time taken by each iteration of a control loop.",
+	}, []string{"loop"})
+
+	// ProviderCallDuration records the latency of calls to the cloud providers.
+	ProviderCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_call_duration_seconds",
+		Help:      "The latency of calls to the cloud provider APIs.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	}, []string{"provider", "region", "call"})
+
+	// ProviderErrors counts the calls to the cloud providers that failed.
+	ProviderErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_errors_total",
+		Help:      "The number of failed calls to the cloud provider APIs.",
+	}, []string{"provider", "region", "call"})
+
+	// PlacementFailures counts the containers the scheduler failed to place.
+	PlacementFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scheduler_placement_failures_total",
+		Help:      "The number of times a container couldn't be placed.",
+	})
+
+	// EtcdSyncErrors counts the failed attempts to synchronize the database with
+	// Etcd, labelled by the table being synchronized.
+	EtcdSyncErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "etcd_sync_errors_total",
+		Help:      "The number of failed database synchronizations with Etcd.",
+	}, []string{"table"})
+)
+
+func init() {
+	prometheus.MustRegister(LoopDuration, ProviderCallDuration, ProviderErrors,
+		PlacementFailures, EtcdSyncErrors)
+}
+
+// ObserveProviderCall records the latency and outcome of a call to a cloud
+// provider that began at `start`.
+func ObserveProviderCall(provider, region, call string, start time.Time,
+	err error) {
+
+	ProviderCallDuration.WithLabelValues(provider, region, call).Observe(
+		time.Since(start).Seconds())
+	if err != nil {
+		ProviderErrors.WithLabelValues(provider, region, call).Inc()
+	}
+}
+
+// Run serves the metrics at `/metrics` on `listenAddr`, along with those of
+// `collectors`.  Scrapers must present a certificate trusted by `tlsConfig`.
+func Run(listenAddr string, tlsConfig *tls.Config,
+	collectors ...prometheus.Collector) error {
+
+	sock, err := tls.Listen("tcp", listenAddr, tlsConfig)
+	if err != nil {
+		return err
+	}
+	defer sock.Close()
+
+	return http.Serve(sock, handler(collectors...))
+}
+
+func handler(collectors ...prometheus.Collector) http.Handler {
+	registry := prometheus.NewRegistry()
+	for _, c := range collectors {
+		registry.MustRegister(c)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(
+		prometheus.Gatherers{prometheus.DefaultGatherer, registry},
+		promhttp.HandlerOpts{}))
+	return mux
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	ObserveProviderCall("Amazon", "us-west-1", "boot", time.Now(), nil)
+	ObserveProviderCall("Amazon", "us-west-1", "boot", time.Now(),
+		errors.New("boot failed"))
+	LoopDuration.WithLabelValues("Test").Observe(1)
+
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "quilt_test_gauge",
+		Help: "A gauge registered by the test.",
+	})
+	gauge.Set(3)
+
+	resp := httptest.NewRecorder()
+	handler(gauge).ServeHTTP(resp, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, string(body), `quilt_provider_call_duration_seconds_count`+
+		`{call="boot",provider="Amazon",region="us-west-1"} 2`)
+	assert.Contains(t, string(body), `quilt_provider_errors_total`+
+		`{call="boot",provider="Amazon",region="us-west-1"} 1`)
+	assert.Contains(t, string(body),
+		`quilt_loop_duration_seconds_sum{loop="Test"} 1`)
+	assert.Contains(t, string(body), "quilt_test_gauge 3")
+
+	// Go runtime metrics are exported by the default registry.
+	assert.Contains(t, string(body), "go_goroutines")
+
+	resp = httptest.NewRecorder()
+	handler().ServeHTTP(resp, httptest.NewRequest("GET", "/foo", nil))
+	assert.Equal(t, 404, resp.Code)
+}
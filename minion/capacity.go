@@ -0,0 +1,51 @@
+package minion
+
+import (
+	"bufio"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/quilt/quilt/cluster/machine"
+	"github.com/quilt/quilt/db"
+	"github.com/quilt/quilt/util"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const meminfoFile = "/proc/meminfo"
+
+var numCPU = runtime.NumCPU
+
+// capacity returns the CPU cores and MiB of memory of a minion with the given
+// provider and size.  The host is inspected if the size isn't a known one, and
+// zero values are returned for whatever can't be determined.
+func capacity(provider, size string) (float64, int) {
+	if d, ok := machine.Describe(db.Provider(provider), size); ok {
+		return float64(d.CPU), int(d.RAM * 1024)
+	}
+
+	memory, err := hostMemory()
+	if err != nil {
+		log.WithError(err).Warn("Failed to get the memory of the host.")
+	}
+	return float64(numCPU()), memory
+}
+
+// hostMemory returns the total memory of the host in MiB.
+func hostMemory() (int, error) {
+	meminfo, err := util.ReadFile(meminfoFile)
+	if err != nil {
+		return 0, err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(meminfo))
+	for scanner.Scan() {
+		var kb int
+		_, err := fmt.Sscanf(scanner.Text(), "MemTotal: %d kB", &kb)
+		if err == nil {
+			return kb / 1024, nil
+		}
+	}
+	return 0, fmt.Errorf("no MemTotal in %s", meminfoFile)
+}
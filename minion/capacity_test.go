@@ -0,0 +1,40 @@
+package minion
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quilt/quilt/util"
+)
+
+func TestCapacity(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+	numCPU = func() int { return 3 }
+
+	cpu, memory := capacity("Amazon", "m4.large")
+	assert.Equal(t, 2.0, cpu)
+	assert.Equal(t, 8192, memory)
+
+	cpu, memory = capacity("Vagrant", "1.5,1")
+	assert.Equal(t, 1.0, cpu)
+	assert.Equal(t, 1536, memory)
+
+	// The host's memory can't be read.
+	cpu, memory = capacity("Amazon", "unknown")
+	assert.Equal(t, 3.0, cpu)
+	assert.Zero(t, memory)
+
+	err := util.WriteFile(meminfoFile, []byte("MemTotal:        4045032 kB\n"+
+		"MemFree:          367980 kB\n"), 0644)
+	assert.NoError(t, err)
+	cpu, memory = capacity("", "")
+	assert.Equal(t, 3.0, cpu)
+	assert.Equal(t, 3950, memory)
+
+	err = util.WriteFile(meminfoFile, []byte("MemFree: 367980 kB\n"), 0644)
+	assert.NoError(t, err)
+	_, err = hostMemory()
+	assert.EqualError(t, err, "no MemTotal in /proc/meminfo")
+}
@@ -2,6 +2,7 @@ package docker
 
 import (
 	"errors"
+	"io"
 	"io/ioutil"
 	"path/filepath"
 	"strings"
@@ -13,6 +14,7 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	dkc "github.com/fsouza/go-dockerclient"
+	"golang.org/x/net/context"
 )
 
 var pullCacheTimeout = time.Minute
@@ -37,7 +39,13 @@ type Container struct {
 	Pid     int
 	Env     map[string]string
 	Labels  map[string]string
+	Binds   []string
 	Created time.Time
+
+	// The exit code of the container's last run, and the number of times Docker
+	// has restarted it.
+	ExitCode     int
+	RestartCount int
 }
 
 // ContainerSlice is an alias for []Container to allow for joins
@@ -72,6 +80,44 @@ type RunOptions struct {
 	PidMode     string
 	Privileged  bool
 	VolumesFrom []string
+
+	// Binds mount volumes into the container.  Docker creates the named volumes
+	// and host directories that don't exist yet.
+	Binds []string
+
+	// CPU is measured in cores, and memory in MiB.  Zero values are unrestricted.
+	CPURequest    float64
+	CPULimit      float64
+	MemoryRequest int
+	MemoryLimit   int
+
+	// RestartPolicy is "always", "on-failure" or "no", and defaults to "no".
+	// Docker waits exponentially longer between restarts of a container that
+	// keeps crashing, and gives up on failed containers after MaxRetries
+	// restarts if it's nonzero.
+	RestartPolicy string
+	MaxRetries    int
+}
+
+// LogsOptions changes the behavior of the Logs function.
+type LogsOptions struct {
+	Follow     bool
+	Timestamps bool
+
+	// Only show logs written after this time, if it's set.
+	Since time.Time
+
+	Stdout, Stderr io.Writer
+}
+
+// ExecOptions changes the behavior of the Exec function.
+type ExecOptions struct {
+	Cmd []string
+	TTY bool
+
+	// The command's standard input, or nil if it has none.
+	Stdin          io.Reader
+	Stdout, Stderr io.Writer
 }
 
 type client interface {
@@ -87,6 +133,10 @@ type client interface {
 	CreateContainer(dkc.CreateContainerOptions) (*dkc.Container, error)
 	CreateNetwork(dkc.CreateNetworkOptions) (*dkc.Network, error)
 	ListNetworks() ([]dkc.Network, error)
+	Logs(opts dkc.LogsOptions) error
+	CreateExec(opts dkc.CreateExecOptions) (*dkc.Exec, error)
+	StartExec(id string, opts dkc.StartExecOptions) error
+	InspectExec(id string) (*dkc.ExecInspect, error)
 }
 
 // New creates client to the docker daemon.
@@ -118,9 +168,18 @@ func (dk Client) Run(opts RunOptions) (string, error) {
 		PidMode:     opts.PidMode,
 		Privileged:  opts.Privileged,
 		VolumesFrom: opts.VolumesFrom,
+		Binds:       opts.Binds,
 		DNS:         opts.DNS,
 		DNSSearch:   opts.DNSSearch,
 	}
+	setResources(hc, opts)
+
+	if opts.RestartPolicy != "" {
+		hc.RestartPolicy = dkc.RestartPolicy{
+			Name:              opts.RestartPolicy,
+			MaximumRetryCount: opts.MaxRetries,
+		}
+	}
 
 	var nc *dkc.NetworkingConfig
 	if opts.IP != "" {
@@ -149,6 +208,26 @@ func (dk Client) Run(opts RunOptions) (string, error) {
 	return id, nil
 }
 
+// The CFS period over which CPU limits are enforced, in microseconds.
+const cpuPeriod = 100000
+
+// setResources restricts the resources available to a container.  CPU requests
+// are weighted shares of the host's CPU that only matter when it's contended,
+// while CPU limits are hard quotas.
+func setResources(hc *dkc.HostConfig, opts RunOptions) {
+	if opts.CPURequest != 0 {
+		hc.CPUShares = int64(opts.CPURequest * 1024)
+	}
+
+	if opts.CPULimit != 0 {
+		hc.CPUPeriod = cpuPeriod
+		hc.CPUQuota = int64(opts.CPULimit * cpuPeriod)
+	}
+
+	hc.MemoryReservation = int64(opts.MemoryRequest) << 20
+	hc.Memory = int64(opts.MemoryLimit) << 20
+}
+
 // ConfigureNetwork makes a request to docker to create a network running on driver.
 func (dk Client) ConfigureNetwork(driver string) error {
 	networks, err := dk.ListNetworks()
@@ -276,12 +355,74 @@ func (dk Client) Push(registry, image string) error {
 	}, dkc.AuthConfiguration{})
 }
 
+// Logs writes the logs of the container with the given ID to the writers in 'opts'.
+// If 'opts.Follow' is set, Logs blocks writing new logs until the container stops or
+// 'ctx' is cancelled.
+func (dk Client) Logs(ctx context.Context, id string, opts LogsOptions) error {
+	var since int64
+	if !opts.Since.IsZero() {
+		since = opts.Since.Unix()
+	}
+
+	return dk.client.Logs(dkc.LogsOptions{
+		Context:      ctx,
+		Container:    id,
+		OutputStream: opts.Stdout,
+		ErrorStream:  opts.Stderr,
+		Stdout:       true,
+		Stderr:       true,
+		Follow:       opts.Follow,
+		Timestamps:   opts.Timestamps,
+		Since:        since,
+	})
+}
+
+// Exec runs a command in the container with the given ID, and returns its exit code
+// once it completes.
+func (dk Client) Exec(ctx context.Context, id string, opts ExecOptions) (int, error) {
+	exec, err := dk.CreateExec(dkc.CreateExecOptions{
+		Context:      ctx,
+		Container:    id,
+		Cmd:          opts.Cmd,
+		Tty:          opts.TTY,
+		AttachStdin:  opts.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = dk.StartExec(exec.ID, dkc.StartExecOptions{
+		Context:      ctx,
+		InputStream:  opts.Stdin,
+		OutputStream: opts.Stdout,
+		ErrorStream:  opts.Stderr,
+		Tty:          opts.TTY,
+		RawTerminal:  opts.TTY,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	inspect, err := dk.InspectExec(exec.ID)
+	if err != nil {
+		return 0, err
+	}
+	return inspect.ExitCode, nil
+}
+
 // List returns a slice of all running containers.  The List can be be filtered with the
 // supplied `filters` map.
 func (dk Client) List(filters map[string][]string) ([]Container, error) {
 	return dk.list(filters, false)
 }
 
+// ListAll returns a slice of all containers, including those that aren't running.
+func (dk Client) ListAll(filters map[string][]string) ([]Container, error) {
+	return dk.list(filters, true)
+}
+
 func (dk Client) list(filters map[string][]string, all bool) ([]Container, error) {
 	opts := dkc.ListContainersOptions{All: all, Filters: filters}
 	apics, err := dk.ListContainers(opts)
@@ -334,6 +475,13 @@ func (dk Client) Get(id string) (Container, error) {
 		Labels:  dkc.Config.Labels,
 		Status:  dkc.State.Status,
 		Created: dkc.Created,
+
+		ExitCode:     dkc.State.ExitCode,
+		RestartCount: dkc.RestartCount,
+	}
+
+	if dkc.HostConfig != nil {
+		c.Binds = dkc.HostConfig.Binds
 	}
 
 	networks := keys(dkc.NetworkSettings.Networks)
@@ -266,6 +266,85 @@ func TestRunFilepathToContent(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestRunResources(t *testing.T) {
+	t.Parallel()
+	md, dk := NewMock()
+
+	id, err := dk.Run(RunOptions{Name: "name1", CPURequest: 0.5, CPULimit: 2,
+		MemoryRequest: 256, MemoryLimit: 512})
+	assert.NoError(t, err)
+
+	hc := md.Containers[id].HostConfig
+	assert.Equal(t, int64(512), hc.CPUShares)
+	assert.Equal(t, int64(100000), hc.CPUPeriod)
+	assert.Equal(t, int64(200000), hc.CPUQuota)
+	assert.Equal(t, int64(256<<20), hc.MemoryReservation)
+	assert.Equal(t, int64(512<<20), hc.Memory)
+
+	id, err = dk.Run(RunOptions{Name: "name2"})
+	assert.NoError(t, err)
+
+	hc = md.Containers[id].HostConfig
+	assert.Zero(t, hc.CPUShares)
+	assert.Zero(t, hc.CPUQuota)
+	assert.Zero(t, hc.Memory)
+}
+
+func TestRunBinds(t *testing.T) {
+	t.Parallel()
+	_, dk := NewMock()
+
+	binds := []string{"data:/data", "/etc/ssl:/ssl:ro"}
+	id, err := dk.Run(RunOptions{Name: "name1", Binds: binds})
+	assert.NoError(t, err)
+
+	c, err := dk.Get(id)
+	assert.NoError(t, err)
+	assert.Equal(t, binds, c.Binds)
+}
+
+func TestRunRestartPolicy(t *testing.T) {
+	t.Parallel()
+	md, dk := NewMock()
+
+	id, err := dk.Run(RunOptions{Name: "name1", RestartPolicy: "on-failure",
+		MaxRetries: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, dkc.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3},
+		md.Containers[id].HostConfig.RestartPolicy)
+
+	md.Containers[id].State.ExitCode = 1
+	md.Containers[id].RestartCount = 2
+	c, err := dk.Get(id)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.ExitCode)
+	assert.Equal(t, 2, c.RestartCount)
+
+	id, err = dk.Run(RunOptions{Name: "name2"})
+	assert.NoError(t, err)
+	assert.Zero(t, md.Containers[id].HostConfig.RestartPolicy)
+}
+
+func TestListAll(t *testing.T) {
+	t.Parallel()
+	md, dk := NewMock()
+
+	id1, err := dk.Run(RunOptions{Name: "name1"})
+	assert.NoError(t, err)
+	id2, err := dk.Run(RunOptions{Name: "name2"})
+	assert.NoError(t, err)
+	md.StopContainer(id2)
+
+	containers, err := dk.List(nil)
+	assert.NoError(t, err)
+	assert.Len(t, containers, 1)
+	assert.Equal(t, id1, containers[0].ID)
+
+	containers, err = dk.ListAll(nil)
+	assert.NoError(t, err)
+	assert.Len(t, containers, 2)
+}
+
 func TestConfigureNetwork(t *testing.T) {
 	md, dk := NewMock()
 
@@ -43,6 +43,12 @@ type MockClient struct {
 	createdExecs map[string]dkc.CreateExecOptions
 	Executions   map[string][]string
 
+	// The logs written by Logs, keyed by container ID.
+	ContainerLogs map[string]string
+
+	// The exit code of executions.  Executions echo their input to their output.
+	ExecExitCode int
+
 	CreateError           bool
 	CreateNetworkError    bool
 	ListNetworksError     bool
@@ -57,22 +63,24 @@ type MockClient struct {
 	StartError            bool
 	StartExecError        bool
 	UploadError           bool
+	LogsError             bool
 }
 
 // NewMock creates a mock docker client suitable for use in unit tests, and a MockClient
 // that allows testers to manipulate it's behavior.
 func NewMock() (*MockClient, Client) {
 	md := &MockClient{
-		Mutex:        &sync.Mutex{},
-		Built:        map[BuildImageOptions]struct{}{},
-		Pulled:       map[string]struct{}{},
-		Pushed:       map[dkc.PushImageOptions]struct{}{},
-		Containers:   map[string]mockContainer{},
-		Networks:     map[string]*dkc.Network{},
-		Uploads:      map[UploadToContainerOptions]struct{}{},
-		Images:       map[string]*dkc.Image{},
-		createdExecs: map[string]dkc.CreateExecOptions{},
-		Executions:   map[string][]string{},
+		Mutex:         &sync.Mutex{},
+		Built:         map[BuildImageOptions]struct{}{},
+		Pulled:        map[string]struct{}{},
+		Pushed:        map[dkc.PushImageOptions]struct{}{},
+		Containers:    map[string]mockContainer{},
+		Networks:      map[string]*dkc.Network{},
+		Uploads:       map[UploadToContainerOptions]struct{}{},
+		Images:        map[string]*dkc.Image{},
+		createdExecs:  map[string]dkc.CreateExecOptions{},
+		Executions:    map[string][]string{},
+		ContainerLogs: map[string]string{},
 	}
 	return md, Client{md, &sync.Mutex{}, map[string]*cacheEntry{}}
 }
@@ -347,9 +355,43 @@ func (dk MockClient) StartExec(id string, opts dkc.StartExecOptions) error {
 	exec, _ := dk.createdExecs[id]
 	dk.Executions[exec.Container] = append(dk.Executions[exec.Container],
 		strings.Join(exec.Cmd, " "))
+
+	if opts.InputStream != nil && opts.OutputStream != nil {
+		if _, err := io.Copy(opts.OutputStream, opts.InputStream); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// InspectExec returns the status of the supplied execution object.
+func (dk MockClient) InspectExec(id string) (*dkc.ExecInspect, error) {
+	dk.Lock()
+	defer dk.Unlock()
+
+	if _, ok := dk.createdExecs[id]; !ok {
+		return nil, errors.New("unknown exec")
+	}
+	return &dkc.ExecInspect{ID: id, ExitCode: dk.ExecExitCode}, nil
+}
+
+// Logs writes the ContainerLogs of the requested container.
+func (dk MockClient) Logs(opts dkc.LogsOptions) error {
+	dk.Lock()
+	defer dk.Unlock()
+
+	if dk.LogsError {
+		return errors.New("logs error")
+	}
+
+	if _, ok := dk.Containers[opts.Container]; !ok {
+		return errors.New("unknown container")
+	}
+
+	_, err := io.WriteString(opts.OutputStream, dk.ContainerLogs[opts.Container])
+	return err
+}
+
 // ResetExec clears the list of created and started executions, for use by the unit
 // tests.
 func (dk *MockClient) ResetExec() {
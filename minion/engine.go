@@ -24,36 +24,55 @@ func updatePolicy(view db.Database, blueprint string) {
 }
 
 // `portPlacements` creates exclusive placement rules such that no two containers
-// listening on the same public port get placed on the same machine.
+// listening on overlapping public ports get placed on the same machine.
 func portPlacements(connections []db.Connection) (placements []db.Placement) {
-	ports := make(map[int][]string)
+	var public []db.Connection
 	for _, c := range connections {
-		if c.From != stitch.PublicInternetLabel {
-			continue
+		// ICMP has no ports, so it can't conflict.
+		if c.From == stitch.PublicInternetLabel && c.Protocol != stitch.ICMP {
+			public = append(public, c)
 		}
+	}
 
-		// XXX: Public connections do not currently support ranges, so we can
-		// safely consider just the MinPort.
-		ports[c.MinPort] = append(ports[c.MinPort], c.To)
-	}
-
-	for _, labels := range ports {
-		for _, tgt := range labels {
-			for _, other := range labels {
-				placements = append(placements,
-					db.Placement{
-						Exclusive:   true,
-						TargetLabel: tgt,
-						OtherLabel:  other,
-					},
-				)
+	placementSet := map[db.Placement]struct{}{}
+	for _, tgt := range public {
+		for _, other := range public {
+			if !publicPortsOverlap(tgt, other) {
+				continue
 			}
+
+			placementSet[db.Placement{
+				Exclusive:   true,
+				TargetLabel: tgt.To,
+				OtherLabel:  other.To,
+			}] = struct{}{}
 		}
 	}
 
+	for placement := range placementSet {
+		placements = append(placements, placement)
+	}
 	return placements
 }
 
+// publicPortsOverlap returns whether the public connections `a` and `b` listen on
+// a common port of a common protocol.
+func publicPortsOverlap(a, b db.Connection) bool {
+	if a.Protocol != "" && b.Protocol != "" && a.Protocol != b.Protocol {
+		return false
+	}
+	return a.MinPort <= maxPort(b) && b.MinPort <= maxPort(a)
+}
+
+// maxPort returns the last public port of `c`.  Connections with a single port
+// may leave MaxPort unset.
+func maxPort(c db.Connection) int {
+	if c.MaxPort < c.MinPort {
+		return c.MinPort
+	}
+	return c.MaxPort
+}
+
 func updatePlacements(view db.Database, blueprint stitch.Stitch) {
 	placements := db.PlacementSlice(portPlacements(view.SelectFromConnection(nil)))
 	for _, sp := range blueprint.Placements {
@@ -97,10 +116,12 @@ func updateConnections(view db.Database, blueprint stitch.Stitch) {
 	dbcKey := func(val interface{}) interface{} {
 		c := val.(db.Connection)
 		return stitch.Connection{
-			From:    c.From,
-			To:      c.To,
-			MinPort: c.MinPort,
-			MaxPort: c.MaxPort,
+			From:          c.From,
+			To:            c.To,
+			MinPort:       c.MinPort,
+			MaxPort:       c.MaxPort,
+			Protocol:      c.Protocol,
+			ContainerPort: c.ContainerPort,
 		}
 	}
 
@@ -122,6 +143,8 @@ func updateConnections(view db.Database, blueprint stitch.Stitch) {
 		dbc.To = stitchc.To
 		dbc.MinPort = stitchc.MinPort
 		dbc.MaxPort = stitchc.MaxPort
+		dbc.Protocol = stitchc.Protocol
+		dbc.ContainerPort = stitchc.ContainerPort
 		view.Commit(dbc)
 	}
 }
@@ -129,7 +152,7 @@ func updateConnections(view db.Database, blueprint stitch.Stitch) {
 func queryContainers(blueprint stitch.Stitch) []db.Container {
 	containers := map[string]*db.Container{}
 	for _, c := range blueprint.Containers {
-		containers[c.ID] = &db.Container{
+		dbc := &db.Container{
 			StitchID:          c.ID,
 			Command:           c.Command,
 			Env:               c.Env,
@@ -137,7 +160,34 @@ func queryContainers(blueprint stitch.Stitch) []db.Container {
 			Image:             c.Image.Name,
 			Dockerfile:        c.Image.Dockerfile,
 			Hostname:          c.Hostname,
+			CPURequest:        c.CPURequest,
+			CPULimit:          c.CPULimit,
+			MemoryRequest:     c.MemoryRequest,
+			MemoryLimit:       c.MemoryLimit,
+			Movable:           c.Movable,
+			RestartPolicy:     c.RestartPolicy,
+			MaxRetries:        c.MaxRetries,
+			SecretEnv:         c.SecretEnv,
+			SecretFiles:       c.SecretFiles,
+		}
+
+		for _, v := range c.Volumes {
+			dbc.Volumes = append(dbc.Volumes, volumeBind(v))
+		}
+
+		if c.HealthCheck != nil {
+			check := db.HealthCheck(*c.HealthCheck)
+			dbc.HealthCheck = &check
+		}
+
+		// Containers without a request reserve their limit.
+		if dbc.CPURequest == 0 {
+			dbc.CPURequest = dbc.CPULimit
+		}
+		if dbc.MemoryRequest == 0 {
+			dbc.MemoryRequest = dbc.MemoryLimit
 		}
+		containers[c.ID] = dbc
 	}
 
 	for _, label := range blueprint.Labels {
@@ -186,10 +236,35 @@ func updateContainers(view db.Database, blueprint stitch.Stitch) {
 		dbc.FilepathToContent = newc.FilepathToContent
 		dbc.StitchID = newc.StitchID
 		dbc.Hostname = newc.Hostname
+		dbc.CPURequest = newc.CPURequest
+		dbc.CPULimit = newc.CPULimit
+		dbc.MemoryRequest = newc.MemoryRequest
+		dbc.MemoryLimit = newc.MemoryLimit
+		dbc.Volumes = newc.Volumes
+		dbc.Movable = newc.Movable
+		dbc.HealthCheck = newc.HealthCheck
+		dbc.RestartPolicy = newc.RestartPolicy
+		dbc.MaxRetries = newc.MaxRetries
+		dbc.SecretEnv = newc.SecretEnv
+		dbc.SecretFiles = newc.SecretFiles
 		view.Commit(dbc)
 	}
 }
 
+// volumeBind returns the Docker bind that mounts `v`.
+func volumeBind(v stitch.Volume) string {
+	source := v.Name
+	if v.HostPath != "" {
+		source = v.HostPath
+	}
+
+	bind := source + ":" + v.MountPath
+	if v.ReadOnly {
+		bind += ":ro"
+	}
+	return bind
+}
+
 func updateImages(view db.Database, blueprint stitch.Stitch) {
 	dbImageKey := func(intf interface{}) interface{} {
 		return stitch.Image{
@@ -242,6 +242,71 @@ func testContainerTxn(t *testing.T, conn db.Conn, stc stitch.Stitch) {
 	assert.Empty(t, containers)
 }
 
+func TestQueryContainersResources(t *testing.T) {
+	containers := queryContainers(stitch.Stitch{
+		Containers: []stitch.Container{
+			{ID: "a", Image: stitch.Image{Name: "alpine"},
+				CPURequest: 0.5, CPULimit: 2, MemoryLimit: 512},
+		},
+	})
+	assert.Equal(t, []db.Container{{StitchID: "a", Image: "alpine",
+		CPURequest: 0.5, CPULimit: 2, MemoryRequest: 512, MemoryLimit: 512}},
+		containers)
+}
+
+func TestQueryContainersVolumes(t *testing.T) {
+	containers := queryContainers(stitch.Stitch{
+		Containers: []stitch.Container{
+			{ID: "a", Image: stitch.Image{Name: "postgres"}, Movable: true,
+				Volumes: []stitch.Volume{
+					{Name: "data", MountPath: "/data"},
+					{HostPath: "/etc/ssl", MountPath: "/ssl",
+						ReadOnly: true},
+				}},
+		},
+	})
+	assert.Equal(t, []db.Container{{StitchID: "a", Image: "postgres",
+		Volumes: []string{"data:/data", "/etc/ssl:/ssl:ro"}, Movable: true}},
+		containers)
+}
+
+func TestQueryContainersHealthCheck(t *testing.T) {
+	containers := queryContainers(stitch.Stitch{
+		Containers: []stitch.Container{
+			{ID: "a", Image: stitch.Image{Name: "nginx"},
+				HealthCheck: &stitch.HealthCheck{HTTPPath: "/", Port: 80,
+					Retries: 5}},
+		},
+	})
+	assert.Equal(t, []db.Container{{StitchID: "a", Image: "nginx",
+		HealthCheck: &db.HealthCheck{HTTPPath: "/", Port: 80, Retries: 5}}},
+		containers)
+}
+
+func TestQueryContainersRestartPolicy(t *testing.T) {
+	containers := queryContainers(stitch.Stitch{
+		Containers: []stitch.Container{
+			{ID: "a", Image: stitch.Image{Name: "nginx"},
+				RestartPolicy: stitch.RestartOnFailure, MaxRetries: 3},
+		},
+	})
+	assert.Equal(t, []db.Container{{StitchID: "a", Image: "nginx",
+		RestartPolicy: stitch.RestartOnFailure, MaxRetries: 3}}, containers)
+}
+
+func TestQueryContainersSecrets(t *testing.T) {
+	env := map[string]string{"PGPASSWORD": "pg-password"}
+	files := map[string]string{"/etc/ssl/key": "key"}
+	containers := queryContainers(stitch.Stitch{
+		Containers: []stitch.Container{
+			{ID: "a", Image: stitch.Image{Name: "postgres"},
+				SecretEnv: env, SecretFiles: files},
+		},
+	})
+	assert.Equal(t, []db.Container{{StitchID: "a", Image: "postgres",
+		SecretEnv: env, SecretFiles: files}}, containers)
+}
+
 func TestConnectionTxn(t *testing.T) {
 	conn := db.New()
 	trigg := conn.Trigger(db.ConnectionTable).C
@@ -543,6 +608,29 @@ func TestPlacementTxn(t *testing.T) {
 			OtherLabel:  "bar",
 		},
 	)
+
+	// Public port ranges conflict with the ports they contain, but not with
+	// ports of other protocols.
+	stc.Connections = []stitch.Connection{
+		{From: stitch.PublicInternetLabel, To: "foo", MinPort: 80, MaxPort: 90},
+		{From: stitch.PublicInternetLabel, To: "bar", MinPort: 85, MaxPort: 85,
+			Protocol: stitch.TCP, ContainerPort: 8080},
+		{From: stitch.PublicInternetLabel, To: "baz", MinPort: 80, MaxPort: 90,
+			Protocol: stitch.UDP},
+		{From: stitch.PublicInternetLabel, To: "bar", MinPort: 100,
+			MaxPort: 100, Protocol: stitch.TCP},
+		{From: stitch.PublicInternetLabel, To: "baz", MinPort: 100,
+			MaxPort: 100, Protocol: stitch.UDP},
+	}
+	checkPlacement(stc,
+		db.Placement{TargetLabel: "foo", Exclusive: true, OtherLabel: "foo"},
+		db.Placement{TargetLabel: "bar", Exclusive: true, OtherLabel: "bar"},
+		db.Placement{TargetLabel: "baz", Exclusive: true, OtherLabel: "baz"},
+		db.Placement{TargetLabel: "foo", Exclusive: true, OtherLabel: "bar"},
+		db.Placement{TargetLabel: "bar", Exclusive: true, OtherLabel: "foo"},
+		db.Placement{TargetLabel: "foo", Exclusive: true, OtherLabel: "baz"},
+		db.Placement{TargetLabel: "baz", Exclusive: true, OtherLabel: "foo"},
+	)
 }
 
 func checkImage(t *testing.T, conn db.Conn, stc stitch.Stitch, exp ...db.Image) {
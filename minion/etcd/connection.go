@@ -7,6 +7,7 @@ import (
 
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/join"
+	"github.com/quilt/quilt/metrics"
 
 	log "github.com/Sirupsen/logrus"
 )
@@ -19,6 +20,7 @@ func runConnection(conn db.Conn, store Store) {
 	for range joinNotifiers(trigg.C, etcdWatch) {
 		if err := runConnectionOnce(conn, store); err != nil {
 			log.WithError(err).Warn("Failed to sync connections with Etcd.")
+			metrics.EtcdSyncErrors.WithLabelValues("connection").Inc()
 		}
 	}
 }
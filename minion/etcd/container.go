@@ -7,6 +7,7 @@ import (
 
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/join"
+	"github.com/quilt/quilt/metrics"
 	"github.com/quilt/quilt/util"
 
 	log "github.com/Sirupsen/logrus"
@@ -20,6 +21,7 @@ func runContainer(conn db.Conn, store Store) {
 	for range joinNotifiers(trigg.C, etcdWatch) {
 		if err := runContainerOnce(conn, store); err != nil {
 			log.WithError(err).Warn("Failed to sync containers with Etcd.")
+			metrics.EtcdSyncErrors.WithLabelValues("container").Inc()
 		}
 	}
 }
@@ -52,6 +54,12 @@ func updateLeader(conn db.Conn, store Store, etcdStr string) error {
 		dbcs[i].Image = myIP + ":5000/" + dbcs[i].Image
 	}
 
+	// Health is determined by the workers, and synced through the health
+	// directory instead.
+	for i := range dbcs {
+		dbcs[i].Health = ""
+	}
+
 	err := writeEtcdSlice(store, containerPath, etcdStr, db.ContainerSlice(dbcs))
 	if err != nil {
 		return fmt.Errorf("etcd write error: %s", err)
@@ -71,10 +79,13 @@ func updateNonLeader(conn db.Conn, etcdStr string) {
 		}
 	}
 
-	conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+	err := conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
 		joinContainers(view, etcdDBCs)
 		return nil
 	})
+	if err != nil {
+		log.WithError(err).Error("Failed to update containers from Etcd.")
+	}
 }
 
 func joinContainers(view db.Database, etcdDBCs []db.Container) {
@@ -90,6 +101,15 @@ func joinContainers(view db.Database, etcdDBCs []db.Container) {
 			Command           string
 			Env               string
 			FilepathToContent string
+			CPURequest        float64
+			CPULimit          float64
+			MemoryRequest     int
+			MemoryLimit       int
+			Volumes           string
+			RestartPolicy     string
+			MaxRetries        int
+			SecretEnv         string
+			SecretFiles       string
 		}{
 			IP:                dbc.IP,
 			StitchID:          dbc.StitchID,
@@ -98,6 +118,15 @@ func joinContainers(view db.Database, etcdDBCs []db.Container) {
 			Command:           fmt.Sprintf("%v", dbc.Command),
 			Env:               util.MapAsString(dbc.Env),
 			FilepathToContent: util.MapAsString(dbc.FilepathToContent),
+			CPURequest:        dbc.CPURequest,
+			CPULimit:          dbc.CPULimit,
+			MemoryRequest:     dbc.MemoryRequest,
+			MemoryLimit:       dbc.MemoryLimit,
+			Volumes:           fmt.Sprintf("%v", dbc.Volumes),
+			RestartPolicy:     dbc.RestartPolicy,
+			MaxRetries:        dbc.MaxRetries,
+			SecretEnv:         util.MapAsString(dbc.SecretEnv),
+			SecretFiles:       util.MapAsString(dbc.SecretFiles),
 		}
 	}
 
@@ -127,6 +156,18 @@ func joinContainers(view db.Database, etcdDBCs []db.Container) {
 		dbc.Labels = edbc.Labels
 		dbc.Env = edbc.Env
 		dbc.FilepathToContent = edbc.FilepathToContent
+		dbc.CPURequest = edbc.CPURequest
+		dbc.CPULimit = edbc.CPULimit
+		dbc.MemoryRequest = edbc.MemoryRequest
+		dbc.MemoryLimit = edbc.MemoryLimit
+		dbc.Volumes = edbc.Volumes
+		dbc.Movable = edbc.Movable
+		dbc.VolumeMinion = edbc.VolumeMinion
+		dbc.HealthCheck = edbc.HealthCheck
+		dbc.RestartPolicy = edbc.RestartPolicy
+		dbc.MaxRetries = edbc.MaxRetries
+		dbc.SecretEnv = edbc.SecretEnv
+		dbc.SecretFiles = edbc.SecretFiles
 		view.Commit(dbc)
 	}
 }
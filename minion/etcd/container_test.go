@@ -25,6 +25,11 @@ func TestRunContainerOnce(t *testing.T) {
 		self.Role = db.Master
 		view.Commit(self)
 
+		worker := view.InsertMinion()
+		worker.Role = db.Worker
+		worker.PrivateIP = "1.2.3.4"
+		view.Commit(worker)
+
 		etcd := view.InsertEtcd()
 		etcd.Leader = true
 		view.Commit(etcd)
@@ -37,6 +42,11 @@ func TestRunContainerOnce(t *testing.T) {
 		dbc.Command = []string{"1", "2", "3"}
 		dbc.Env = map[string]string{"red": "pill", "blue": "pill"}
 		dbc.FilepathToContent = map[string]string{"foo": "bar"}
+		dbc.Volumes = []string{"data:/data"}
+		dbc.VolumeMinion = "1.2.3.4"
+		dbc.RestartPolicy = "on-failure"
+		dbc.MaxRetries = 3
+		dbc.SecretEnv = map[string]string{"PASSWORD": "password"}
 		view.Commit(dbc)
 		return nil
 	})
@@ -65,7 +75,16 @@ func TestRunContainerOnce(t *testing.T) {
             "foo": "bar"
         },
         "Created": "0001-01-01T00:00:00Z",
-        "Image": "ubuntu"
+        "Image": "ubuntu",
+        "Volumes": [
+            "data:/data"
+        ],
+        "VolumeMinion": "1.2.3.4",
+        "RestartPolicy": "on-failure",
+        "MaxRetries": 3,
+        "SecretEnv": {
+            "PASSWORD": "password"
+        }
     }
 ]`
 	assert.Equal(t, expStr, str)
@@ -93,6 +112,11 @@ func TestRunContainerOnce(t *testing.T) {
 		Command:           []string{"1", "2", "3"},
 		Env:               map[string]string{"red": "pill", "blue": "pill"},
 		FilepathToContent: map[string]string{"foo": "bar"},
+		Volumes:           []string{"data:/data"},
+		VolumeMinion:      "1.2.3.4",
+		RestartPolicy:     "on-failure",
+		MaxRetries:        3,
+		SecretEnv:         map[string]string{"PASSWORD": "password"},
 	}
 	dbcs := conn.SelectFromContainer(nil)
 	assert.Len(t, dbcs, 1)
@@ -153,6 +177,11 @@ func TestRunContainerOnceWithDockerfile(t *testing.T) {
 		self.PrivateIP = "leader"
 		view.Commit(self)
 
+		worker := view.InsertMinion()
+		worker.Role = db.Worker
+		worker.PrivateIP = "1.2.3.4"
+		view.Commit(worker)
+
 		etcd := view.InsertEtcd()
 		etcd.Leader = true
 		view.Commit(etcd)
@@ -0,0 +1,100 @@
+package etcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/quilt/quilt/db"
+	"github.com/quilt/quilt/metrics"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const healthPath = "/health"
+
+// runHealth syncs the health of containers from the workers that check it to the
+// masters.  Each worker writes a map from StitchID to health at
+// `healthPath/<privateIP>`, which the masters read into their container tables.
+func runHealth(conn db.Conn, store Store) {
+	etcdWatch := store.Watch(healthPath, 1*time.Second)
+	trigg := conn.TriggerTick(60, db.ContainerTable, db.MinionTable)
+	for range joinNotifiers(trigg.C, etcdWatch) {
+		if err := runHealthOnce(conn, store); err != nil {
+			log.WithError(err).Warn("Failed to sync health with Etcd.")
+			metrics.EtcdSyncErrors.WithLabelValues("health").Inc()
+		}
+	}
+}
+
+func runHealthOnce(conn db.Conn, store Store) error {
+	self := conn.MinionSelf()
+	switch {
+	case self.Role == db.Worker && self.PrivateIP != "":
+		return writeHealth(conn, store, self.PrivateIP)
+	case self.Role == db.Master:
+		return readHealth(conn, store)
+	}
+	return nil
+}
+
+func writeHealth(conn db.Conn, store Store, myIP string) error {
+	health := map[string]string{}
+	for _, dbc := range conn.SelectFromContainer(nil) {
+		if dbc.Health != "" {
+			health[dbc.StitchID] = dbc.Health
+		}
+	}
+
+	js, err := jsonMarshal(health)
+	if err != nil {
+		panic("Failed to convert health to JSON")
+	}
+
+	key := path.Join(healthPath, myIP)
+	etcdStr, err := readEtcdNode(store, key)
+	if err != nil {
+		return fmt.Errorf("etcd read error: %s", err)
+	}
+
+	// Only write changes, so that the watchers aren't woken needlessly.
+	if etcdStr == string(js) {
+		return nil
+	}
+
+	if err := store.Set(key, string(js), 0); err != nil {
+		return fmt.Errorf("etcd write error: %s", err)
+	}
+	return nil
+}
+
+func readHealth(conn db.Conn, store Store) error {
+	tree, err := store.GetTree(healthPath)
+	if err != nil {
+		return fmt.Errorf("etcd read error: %s", err)
+	}
+
+	minionHealth := map[string]map[string]string{}
+	for ip, t := range tree.Children {
+		var health map[string]string
+		if err := json.Unmarshal([]byte(t.Value), &health); err != nil {
+			log.WithField("json", t.Value).Warning("Failed to parse health.")
+			continue
+		}
+		minionHealth[ip] = health
+	}
+
+	return conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+		for _, dbc := range view.SelectFromContainer(nil) {
+			// The health reported by minions other than the container's
+			// current one is stale.
+			health := minionHealth[dbc.Minion][dbc.StitchID]
+			if dbc.Health != health {
+				dbc.Health = health
+				view.Commit(dbc)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+package etcd
+
+import (
+	"testing"
+
+	"github.com/quilt/quilt/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteHealth(t *testing.T) {
+	t.Parallel()
+
+	store := newTestMock()
+	conn := db.New()
+	key := healthPath + "/1.2.3.4"
+
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		self := view.InsertMinion()
+		self.Self = true
+		self.Role = db.Worker
+		self.PrivateIP = "1.2.3.4"
+		view.Commit(self)
+
+		dbc := view.InsertContainer()
+		dbc.StitchID = "1"
+		dbc.Health = db.ContainerHealthy
+		view.Commit(dbc)
+
+		dbc = view.InsertContainer()
+		dbc.StitchID = "2"
+		dbc.Health = db.ContainerUnhealthy
+		view.Commit(dbc)
+
+		dbc = view.InsertContainer()
+		dbc.StitchID = "3"
+		view.Commit(dbc)
+		return nil
+	})
+
+	err := runHealthOnce(conn, store)
+	assert.Error(t, err)
+
+	store.Set(key, "", 0)
+	assert.NoError(t, runHealthOnce(conn, store))
+
+	val, err := store.Get(key)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+    "1": "healthy",
+    "2": "unhealthy"
+}`, val)
+
+	// Unchanged health isn't rewritten.
+	writes := *store.writes
+	assert.NoError(t, runHealthOnce(conn, store))
+	assert.Equal(t, writes, *store.writes)
+}
+
+func TestReadHealth(t *testing.T) {
+	t.Parallel()
+
+	store := newTestMock()
+	conn := db.New()
+
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		self := view.InsertMinion()
+		self.Self = true
+		self.Role = db.Master
+		view.Commit(self)
+
+		for _, ip := range []string{"1.2.3.4", "5.6.7.8"} {
+			worker := view.InsertMinion()
+			worker.Role = db.Worker
+			worker.PrivateIP = ip
+			view.Commit(worker)
+		}
+
+		dbc := view.InsertContainer()
+		dbc.StitchID = "1"
+		dbc.Minion = "1.2.3.4"
+		view.Commit(dbc)
+
+		dbc = view.InsertContainer()
+		dbc.StitchID = "2"
+		dbc.Minion = "5.6.7.8"
+		dbc.Health = db.ContainerHealthy
+		view.Commit(dbc)
+
+		dbc = view.InsertContainer()
+		dbc.StitchID = "3"
+		dbc.Minion = "1.2.3.4"
+		dbc.Health = db.ContainerHealthy
+		view.Commit(dbc)
+		return nil
+	})
+
+	err := runHealthOnce(conn, store)
+	assert.Error(t, err)
+
+	store.Set(healthPath+"/1.2.3.4", `{"1": "healthy", "2": "unhealthy"}`, 0)
+	store.Set(healthPath+"/9.9.9.9", "garbage", 0)
+	assert.NoError(t, runHealthOnce(conn, store))
+
+	health := map[string]string{}
+	for _, dbc := range conn.SelectFromContainer(nil) {
+		health[dbc.StitchID] = dbc.Health
+	}
+
+	// Container 2 moved to a minion that hasn't reported its health, and
+	// container 3 is no longer reported.
+	assert.Equal(t, map[string]string{
+		"1": db.ContainerHealthy,
+		"2": "",
+		"3": "",
+	}, health)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/join"
+	"github.com/quilt/quilt/metrics"
 	"github.com/quilt/quilt/util"
 
 	log "github.com/Sirupsen/logrus"
@@ -31,6 +32,7 @@ func readMinion(conn db.Conn, store Store) {
 	tree, err := store.GetTree(minionPath)
 	if err != nil {
 		log.WithError(err).Warning("Failed to get minions from Etcd.")
+		metrics.EtcdSyncErrors.WithLabelValues("minion").Inc()
 		return
 	}
 
@@ -44,7 +46,7 @@ func readMinion(conn db.Conn, store Store) {
 		storeMinions = append(storeMinions, m)
 	}
 
-	conn.Txn(db.MinionTable).Run(func(view db.Database) error {
+	err = conn.Txn(db.MinionTable).Run(func(view db.Database) error {
 		dbms, sms := filterSelf(view.SelectFromMinion(nil), storeMinions)
 		del, add := diffMinion(dbms, sms)
 
@@ -59,8 +61,31 @@ func readMinion(conn db.Conn, store Store) {
 			minion.ID = id
 			view.Commit(minion)
 		}
+
+		unassignContainers(view, del)
 		return nil
 	})
+	if err != nil {
+		log.WithError(err).Error("Failed to update minions.")
+	}
+}
+
+// unassignContainers unassigns the containers placed on the 'removed' minions that
+// are gone, as containers may only refer to existing minions.  The scheduler places
+// them elsewhere.
+func unassignContainers(view db.Database, removed []db.Minion) {
+	for _, m := range removed {
+		if len(view.SelectFromMinion(func(dbm db.Minion) bool {
+			return dbm.PrivateIP == m.PrivateIP
+		})) > 0 {
+			continue
+		}
+
+		for _, dbc := range view.SelectFromContainerByMinion(m.PrivateIP) {
+			dbc.Minion = ""
+			view.Commit(dbc)
+		}
+	}
 }
 
 func filterSelf(dbMinions, storeMinions []db.Minion) ([]db.Minion, []db.Minion) {
@@ -122,6 +147,7 @@ func writeMinion(conn db.Conn, store Store) {
 	key := path.Join(minionPath, minion.PrivateIP)
 	if err := store.Set(key, string(js), minionTimeout*time.Second); err != nil {
 		log.Warningf("Failed to create: %s", key)
+		metrics.EtcdSyncErrors.WithLabelValues("minion").Inc()
 		return
 	}
 }
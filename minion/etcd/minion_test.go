@@ -73,11 +73,23 @@ func TestReadMinion(t *testing.T) {
 	minions[0].ID = m.ID
 	assert.Equal(t, m, minions[0])
 
+	// Containers placed on a minion that's gone are unassigned.
+	conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+		dbc := view.InsertContainer()
+		dbc.Minion = m.PrivateIP
+		view.Commit(dbc)
+		return nil
+	})
+
 	store = NewMock()
 	store.Mkdir(minionPath, 0)
 	readMinion(conn, store)
 	minions = conn.SelectFromMinion(nil)
 	assert.Empty(t, minions)
+
+	dbcs := conn.SelectFromContainer(nil)
+	assert.Len(t, dbcs, 1)
+	assert.Empty(t, dbcs[0].Minion)
 }
 
 func TestReadDiff(t *testing.T) {
@@ -13,11 +13,14 @@ import (
 func Run(conn db.Conn) {
 	store := NewStore()
 	makeEtcdDir(minionPath, store, 0)
+	makeEtcdDir(healthPath, store, 0)
 
 	go runElection(conn, store)
 	go runConnection(conn, store)
 	go runContainer(conn, store)
 	go runHostname(conn, store)
+	go runHealth(conn, store)
+	go runSecret(conn, store)
 	runMinionSync(conn, store)
 }
 
@@ -0,0 +1,88 @@
+package etcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/quilt/quilt/db"
+	"github.com/quilt/quilt/join"
+	"github.com/quilt/quilt/metrics"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const secretPath = "/secrets"
+
+// runSecret syncs the sealed secrets set through the leader's API server to the rest
+// of the cluster.  The secrets are never opened here.
+func runSecret(conn db.Conn, store Store) {
+	etcdWatch := store.Watch(secretPath, 1*time.Second)
+	trigg := conn.TriggerTick(60, db.SecretTable)
+	for range joinNotifiers(trigg.C, etcdWatch) {
+		if err := runSecretOnce(conn, store); err != nil {
+			log.WithError(err).Warn("Failed to sync secrets with Etcd.")
+			metrics.EtcdSyncErrors.WithLabelValues("secret").Inc()
+		}
+	}
+}
+
+func runSecretOnce(conn db.Conn, store Store) error {
+	etcdStr, err := readEtcdNode(store, secretPath)
+	if err != nil {
+		return fmt.Errorf("etcd read error: %s", err)
+	}
+
+	var etcdSecrets []db.Secret
+	json.Unmarshal([]byte(etcdStr), &etcdSecrets)
+
+	leader := conn.EtcdLeader()
+	var secrets []db.Secret
+	conn.Txn(db.SecretTable).Run(func(view db.Database) error {
+		joinSecrets(view, etcdSecrets, leader)
+		secrets = view.SelectFromSecret(nil)
+		return nil
+	})
+
+	if !leader {
+		return nil
+	}
+
+	err = writeEtcdSlice(store, secretPath, etcdStr, db.SecretSlice(secrets))
+	if err != nil {
+		return fmt.Errorf("etcd write error: %s", err)
+	}
+	return nil
+}
+
+// joinSecrets updates the secrets in `view` to match those in Etcd.  Secrets are only
+// ever set through the leader, so its values take precedence, and it keeps the
+// secrets that it hasn't written to Etcd yet.  Secrets aren't removed from Etcd, so
+// a newly elected leader doesn't lose those it hasn't read.
+func joinSecrets(view db.Database, etcdSecrets []db.Secret, leader bool) {
+	key := func(iface interface{}) interface{} {
+		return iface.(db.Secret).Name
+	}
+	pairs, dbIfaces, etcdIfaces := join.HashJoin(
+		db.SecretSlice(view.SelectFromSecret(nil)),
+		db.SecretSlice(etcdSecrets), key, key)
+
+	if !leader {
+		for _, iface := range dbIfaces {
+			view.Remove(iface.(db.Secret))
+		}
+
+		for _, pair := range pairs {
+			dbSecret := pair.L.(db.Secret)
+			dbSecret.Value = pair.R.(db.Secret).Value
+			view.Commit(dbSecret)
+		}
+	}
+
+	for _, iface := range etcdIfaces {
+		etcdSecret := iface.(db.Secret)
+		dbSecret := view.InsertSecret()
+		etcdSecret.ID = dbSecret.ID
+		view.Commit(etcdSecret)
+	}
+}
@@ -0,0 +1,86 @@
+package etcd
+
+import (
+	"testing"
+
+	"github.com/quilt/quilt/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretLeader(t *testing.T) {
+	t.Parallel()
+
+	store := newTestMock()
+	conn := db.New()
+
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		etcd := view.InsertEtcd()
+		etcd.Leader = true
+		view.Commit(etcd)
+
+		secret := view.InsertSecret()
+		secret.Name = "a"
+		secret.Value = "new"
+		view.Commit(secret)
+		return nil
+	})
+
+	err := runSecretOnce(conn, store)
+	assert.Error(t, err)
+
+	store.Set(secretPath, `[{"Name": "a", "Value": "old"},
+		{"Name": "b", "Value": "sealed"}]`, 0)
+	assert.NoError(t, runSecretOnce(conn, store))
+
+	// The leader's values win, and secrets it hasn't seen are kept.
+	val, err := store.Get(secretPath)
+	assert.NoError(t, err)
+	assert.Equal(t, `[
+    {
+        "Name": "a",
+        "Value": "new"
+    },
+    {
+        "Name": "b",
+        "Value": "sealed"
+    }
+]`, val)
+	assert.Equal(t, map[string]string{"a": "new", "b": "sealed"},
+		secretValues(conn))
+}
+
+func TestSecretWorker(t *testing.T) {
+	t.Parallel()
+
+	store := newTestMock()
+	conn := db.New()
+
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		for _, name := range []string{"a", "stale"} {
+			secret := view.InsertSecret()
+			secret.Name = name
+			secret.Value = "old"
+			view.Commit(secret)
+		}
+		return nil
+	})
+
+	store.Set(secretPath, `[{"Name": "a", "Value": "new"},
+		{"Name": "b", "Value": "sealed"}]`, 0)
+	assert.NoError(t, runSecretOnce(conn, store))
+	assert.Equal(t, map[string]string{"a": "new", "b": "sealed"},
+		secretValues(conn))
+
+	// Only the leader writes to Etcd.
+	writes := *store.writes
+	assert.NoError(t, runSecretOnce(conn, store))
+	assert.Equal(t, writes, *store.writes)
+}
+
+func secretValues(conn db.Conn) map[string]string {
+	values := map[string]string{}
+	for _, secret := range conn.SelectFromSecret(nil) {
+		values[secret.Name] = secret.Value
+	}
+	return values
+}
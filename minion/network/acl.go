@@ -50,10 +50,15 @@ func syncAddressSets(ovsdbClient ovsdb.Client, labels []db.Label) {
 		if l.Label == stitch.PublicInternetLabel {
 			continue
 		}
+
+		// Unhealthy containers are excluded from load balancing, but they're
+		// still subject to the label's ACLs.
+		addresses := append([]string{l.IP}, l.ContainerIPs...)
+		addresses = append(addresses, l.UnhealthyIPs...)
 		expAddressSets = append(expAddressSets,
 			ovsdb.AddressSet{
 				Name:      addressSetName(l.Label),
-				Addresses: unique(append(l.ContainerIPs, l.IP)),
+				Addresses: unique(addresses),
 			},
 		)
 	}
@@ -160,15 +165,23 @@ func matchString(c db.Connection) string {
 	return or(
 		and(
 			and(from(c.From), to(c.To)),
-			portConstraint(c.MinPort, c.MaxPort, "dst")),
+			portConstraint(c, "dst")),
 		and(
 			and(from(c.To), to(c.From)),
-			portConstraint(c.MinPort, c.MaxPort, "src")))
-}
-
-func portConstraint(minPort, maxPort int, direction string) string {
-	return fmt.Sprintf("(icmp || %[1]d <= udp.%[2]s <= %[3]d || "+
-		"%[1]d <= tcp.%[2]s <= %[3]d)", minPort, direction, maxPort)
+			portConstraint(c, "src")))
+}
+
+func portConstraint(c db.Connection, direction string) string {
+	switch c.Protocol {
+	case stitch.ICMP:
+		return "icmp"
+	case stitch.TCP, stitch.UDP:
+		return fmt.Sprintf("%[1]d <= %[2]s.%[3]s <= %[4]d",
+			c.MinPort, c.Protocol, direction, c.MaxPort)
+	default:
+		return fmt.Sprintf("(icmp || %[1]d <= udp.%[2]s <= %[3]d || "+
+			"%[1]d <= tcp.%[2]s <= %[3]d)", c.MinPort, direction, c.MaxPort)
+	}
 }
 
 func from(label string) string {
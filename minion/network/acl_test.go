@@ -2,12 +2,15 @@ package network
 
 import (
 	"errors"
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/minion/ovsdb"
 	"github.com/quilt/quilt/minion/ovsdb/mocks"
 	"github.com/quilt/quilt/stitch"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -41,6 +44,16 @@ func TestSyncAddressSets(t *testing.T) {
 	client.AssertCalled(t, "ListAddressSets")
 	client.AssertCalled(t, "DeleteAddressSet", mock.Anything)
 	client.AssertCalled(t, "CreateAddressSet", mock.Anything, mock.Anything)
+
+	// Unhealthy containers remain subject to the label's ACLs.
+	client.On("DeleteAddressSet", "a").Return(nil).Once()
+	client.On("CreateAddressSet", "c", mock.MatchedBy(func(addrs []string) bool {
+		sort.Strings(addrs)
+		return reflect.DeepEqual(addrs, []string{"1.2.3.6", "1.2.3.7"})
+	})).Return(nil).Once()
+	syncAddressSets(client, []db.Label{
+		{Label: "c", IP: "1.2.3.6", UnhealthyIPs: []string{"1.2.3.7"}}})
+	client.AssertExpectations(t)
 }
 
 func TestSyncACLs(t *testing.T) {
@@ -78,3 +91,17 @@ func TestSyncACLs(t *testing.T) {
 	client.AssertCalled(t, "CreateACL", mock.Anything, mock.Anything, mock.Anything,
 		mock.Anything, mock.Anything)
 }
+
+func TestPortConstraint(t *testing.T) {
+	t.Parallel()
+
+	conn := db.Connection{From: "a", To: "b", MinPort: 53, MaxPort: 54}
+	assert.Equal(t, "(icmp || 53 <= udp.dst <= 54 || 53 <= tcp.dst <= 54)",
+		portConstraint(conn, "dst"))
+
+	conn.Protocol = stitch.UDP
+	assert.Equal(t, "53 <= udp.src <= 54", portConstraint(conn, "src"))
+
+	conn.Protocol = stitch.ICMP
+	assert.Equal(t, "icmp", portConstraint(conn, "dst"))
+}
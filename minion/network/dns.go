@@ -3,6 +3,7 @@ package network
 import (
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 	"sync"
 
@@ -51,27 +52,47 @@ func syncHostnamesOnce(conn db.Conn) {
 }
 
 func joinHostnames(view db.Database) error {
+	// The containers are sorted by StitchID, and every replica of a label is
+	// counted whether or not it's serving, so that each replica keeps its number
+	// when the others change health.  Blueprints rely on these numbers being stable.
+	dbcs := view.SelectFromContainer(nil)
+	sort.Sort(db.ContainerSlice(dbcs))
+
 	var target []db.Hostname
-	for _, label := range view.SelectFromLabel(nil) {
-		if label.IP != "" {
+	replicas := map[string]int{}
+	serving := map[string]bool{}
+	for _, dbc := range dbcs {
+		if dbc.Hostname != "" && dbc.IP != "" {
 			target = append(target, db.Hostname{
-				Hostname: label.Label,
-				IP:       label.IP,
+				Hostname: dbc.Hostname,
+				IP:       dbc.IP,
 			})
 		}
-		for i, containerIP := range label.ContainerIPs {
+
+		for _, label := range dbc.Labels {
+			replicas[label]++
+			if dbc.IP == "" || !dbc.Serving() {
+				continue
+			}
+
+			serving[label] = true
 			target = append(target, db.Hostname{
-				Hostname: fmt.Sprintf("%d.%s", i+1, label.Label),
-				IP:       containerIP,
+				Hostname: fmt.Sprintf("%d.%s", replicas[label], label),
+				IP:       dbc.IP,
 			})
 		}
 	}
-	for _, c := range view.SelectFromContainer(nil) {
-		if c.Hostname != "" && c.IP != "" {
-			target = append(target, db.Hostname{
-				Hostname: c.Hostname,
-				IP:       c.IP,
-			})
+
+	// Traffic sent to a label's IP only reaches its serving containers, so labels
+	// without any aren't published.
+	for label := range serving {
+		for _, dbl := range view.SelectFromLabelByLabel(label) {
+			if dbl.IP != "" {
+				target = append(target, db.Hostname{
+					Hostname: dbl.Label,
+					IP:       dbl.IP,
+				})
+			}
 		}
 	}
 
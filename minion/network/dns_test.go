@@ -146,6 +146,11 @@ func TestSyncHostnamesWorker(t *testing.T) {
 		dbl.Label = "label"
 		dbl.IP = "IP"
 		view.Commit(dbl)
+
+		dbc := view.InsertContainer()
+		dbc.IP = "containerIP"
+		dbc.Labels = []string{"label"}
+		view.Commit(dbc)
 		return nil
 	})
 	syncHostnamesOnce(conn)
@@ -158,8 +163,10 @@ func TestSyncHostnamesWorker(t *testing.T) {
 		return nil
 	})
 	syncHostnamesOnce(conn)
-	assert.Equal(t, []db.Hostname{{ID: 3, Hostname: "label", IP: "IP"}},
-		conn.SelectFromHostname(nil))
+	assertHostnamesEqual(t, []db.Hostname{
+		{Hostname: "label", IP: "IP"},
+		{Hostname: "1.label", IP: "containerIP"},
+	}, conn.SelectFromHostname(nil))
 }
 
 type syncHostnameTest struct {
@@ -169,14 +176,27 @@ type syncHostnameTest struct {
 }
 
 func TestSyncHostnames(t *testing.T) {
+	unhealthy := db.Container{
+		StitchID:    "1",
+		IP:          "unhealthy",
+		Labels:      []string{"foo"},
+		HealthCheck: &db.HealthCheck{Command: []string{"check"}},
+		Health:      db.ContainerUnhealthy,
+	}
+	healthy := db.Container{
+		StitchID:    "2",
+		IP:          "healthy",
+		Labels:      []string{"foo"},
+		HealthCheck: &db.HealthCheck{Command: []string{"check"}},
+		Health:      db.ContainerHealthy,
+	}
+
 	tests := []syncHostnameTest{
 		{
-			labels: []db.Label{
-				{
-					Label:        "foo",
-					IP:           "fooIP",
-					ContainerIPs: []string{"container", "ips"},
-				},
+			labels: []db.Label{{Label: "foo", IP: "fooIP"}},
+			containers: []db.Container{
+				{StitchID: "1", IP: "container", Labels: []string{"foo"}},
+				{StitchID: "2", IP: "ips", Labels: []string{"foo"}},
 			},
 			expHostnames: []db.Hostname{
 				{Hostname: "foo", IP: "fooIP"},
@@ -186,14 +206,16 @@ func TestSyncHostnames(t *testing.T) {
 		},
 		{
 			labels: []db.Label{
+				{Label: "foo", IP: "fooIP"},
+				{Label: "bar", IP: "barIP"},
+			},
+			containers: []db.Container{
+				{StitchID: "1", IP: "container", Labels: []string{"foo"}},
+				{StitchID: "2", IP: "ips", Labels: []string{"foo"}},
 				{
-					Label:        "foo",
-					IP:           "fooIP",
-					ContainerIPs: []string{"container", "ips"},
-				},
-				{
-					Label: "bar",
-					IP:    "barIP",
+					StitchID: "3",
+					IP:       "barContainer",
+					Labels:   []string{"bar"},
 				},
 			},
 			oldHostnames: []db.Hostname{
@@ -206,13 +228,16 @@ func TestSyncHostnames(t *testing.T) {
 				{Hostname: "1.foo", IP: "container"},
 				{Hostname: "2.foo", IP: "ips"},
 				{Hostname: "bar", IP: "barIP"},
+				{Hostname: "1.bar", IP: "barContainer"},
 			},
 		},
 		{
-			labels: []db.Label{
+			labels: []db.Label{{Label: "bar", IP: "barIP"}},
+			containers: []db.Container{
 				{
-					Label: "bar",
-					IP:    "barIP",
+					StitchID: "1",
+					IP:       "barContainer",
+					Labels:   []string{"bar"},
 				},
 			},
 			oldHostnames: []db.Hostname{
@@ -221,19 +246,17 @@ func TestSyncHostnames(t *testing.T) {
 			},
 			expHostnames: []db.Hostname{
 				{Hostname: "bar", IP: "barIP"},
+				{Hostname: "1.bar", IP: "barContainer"},
 			},
 		},
 		{
-			labels: []db.Label{
-				{
-					Label: "foo",
-					IP:    "fooIP",
-				},
-			},
+			labels: []db.Label{{Label: "foo", IP: "fooIP"}},
 			containers: []db.Container{
 				{
+					StitchID: "1",
 					Hostname: "container",
 					IP:       "containerIP",
+					Labels:   []string{"foo"},
 				},
 			},
 			oldHostnames: []db.Hostname{
@@ -241,9 +264,33 @@ func TestSyncHostnames(t *testing.T) {
 			},
 			expHostnames: []db.Hostname{
 				{Hostname: "foo", IP: "fooIP"},
+				{Hostname: "1.foo", IP: "containerIP"},
 				{Hostname: "container", IP: "containerIP"},
 			},
 		},
+		// An unhealthy first replica doesn't renumber the others.
+		{
+			labels:     []db.Label{{Label: "foo", IP: "fooIP"}},
+			containers: []db.Container{unhealthy, healthy},
+			oldHostnames: []db.Hostname{
+				{Hostname: "foo", IP: "fooIP"},
+				{Hostname: "1.foo", IP: "unhealthy"},
+				{Hostname: "2.foo", IP: "healthy"},
+			},
+			expHostnames: []db.Hostname{
+				{Hostname: "foo", IP: "fooIP"},
+				{Hostname: "2.foo", IP: "healthy"},
+			},
+		},
+		// Labels without serving containers aren't published.
+		{
+			labels:     []db.Label{{Label: "foo", IP: "fooIP"}},
+			containers: []db.Container{unhealthy},
+			oldHostnames: []db.Hostname{
+				{Hostname: "foo", IP: "fooIP"},
+				{Hostname: "1.foo", IP: "unhealthy"},
+			},
+		},
 	}
 	for _, test := range tests {
 		conn := db.New()
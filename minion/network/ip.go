@@ -8,7 +8,6 @@ import (
 	"sort"
 
 	"github.com/quilt/quilt/db"
-	"github.com/quilt/quilt/join"
 	"github.com/quilt/quilt/minion/ipdef"
 
 	log "github.com/Sirupsen/logrus"
@@ -85,37 +84,41 @@ func updateLabelIPs(view db.Database, ipSet map[string]struct{}) error {
 	// ordering is consistent between function calls.  This is pretty darn fragile.
 	sort.Sort(db.ContainerSlice(dbcs))
 
+	// Only serving containers receive traffic sent to their labels, but the others
+	// are tracked so that they remain subject to the labels' ACLs.
+	labelSet := map[string]struct{}{}
 	containerIPs := map[string][]string{}
+	unhealthyIPs := map[string][]string{}
 	for _, dbc := range dbcs {
 		for _, l := range dbc.Labels {
-			containerIPs[l] = append(containerIPs[l], dbc.IP)
+			labelSet[l] = struct{}{}
+			if dbc.Serving() {
+				containerIPs[l] = append(containerIPs[l], dbc.IP)
+			} else {
+				unhealthyIPs[l] = append(unhealthyIPs[l], dbc.IP)
+			}
 		}
 	}
 
-	labelKeyFunc := func(val interface{}) interface{} {
-		return val.(db.Label).Label
-	}
-
-	labelKeySlice := join.StringSlice{}
-	for l := range containerIPs {
-		labelKeySlice = append(labelKeySlice, l)
+	// Labels without containers are removed, and the rest are found through the
+	// label index.
+	for _, dbl := range view.SelectFromLabel(func(dbl db.Label) bool {
+		_, ok := labelSet[dbl.Label]
+		return !ok
+	}) {
+		view.Remove(dbl)
 	}
 
-	labels := db.LabelSlice(view.SelectFromLabel(nil))
-	pairs, dbls, dbcLabels := join.HashJoin(labels, labelKeySlice, labelKeyFunc, nil)
-
-	for _, dbl := range dbls {
-		view.Remove(dbl.(db.Label))
-	}
-
-	for _, label := range dbcLabels {
-		pairs = append(pairs, join.Pair{L: view.InsertLabel(), R: label})
-	}
-
-	for _, pair := range pairs {
-		dbl := pair.L.(db.Label)
-		dbl.Label = pair.R.(string)
-		dbl.ContainerIPs = containerIPs[dbl.Label]
+	for label := range labelSet {
+		var dbl db.Label
+		if dbls := view.SelectFromLabelByLabel(label); len(dbls) > 0 {
+			dbl = dbls[0]
+		} else {
+			dbl = view.InsertLabel()
+			dbl.Label = label
+		}
+		dbl.ContainerIPs = containerIPs[label]
+		dbl.UnhealthyIPs = unhealthyIPs[label]
 
 		if dbl.IP == "" {
 			ip, err := allocateIP(ipSet, ipdef.QuiltSubnet)
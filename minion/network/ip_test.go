@@ -61,6 +61,21 @@ func TestUpdateLabelIPs(t *testing.T) {
 		dbc.IP = "2.2.2.2"
 		view.Commit(dbc)
 
+		dbc = view.InsertContainer()
+		dbc.Labels = []string{"red"}
+		dbc.StitchID = "3"
+		dbc.IP = "3.3.3.3"
+		dbc.HealthCheck = &db.HealthCheck{Port: 80}
+		dbc.Health = db.ContainerHealthy
+		view.Commit(dbc)
+
+		dbc = view.InsertContainer()
+		dbc.Labels = []string{"green"}
+		dbc.StitchID = "4"
+		dbc.IP = "4.4.4.4"
+		dbc.HealthCheck = &db.HealthCheck{Port: 80}
+		view.Commit(dbc)
+
 		label := view.InsertLabel()
 		label.Label = "yellow"
 		view.Commit(label)
@@ -79,7 +94,10 @@ func TestUpdateLabelIPs(t *testing.T) {
 			ContainerIPs: []string{"1.1.1.1"},
 		}, {
 			Label:        "red",
-			ContainerIPs: []string{"1.1.1.1", "2.2.2.2"},
+			ContainerIPs: []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"},
+		}, {
+			Label:        "green",
+			UnhealthyIPs: []string{"4.4.4.4"},
 		},
 	}
 
@@ -91,6 +109,7 @@ func TestUpdateLabelIPs(t *testing.T) {
 
 		if exp.Label == actual.Label &&
 			reflect.DeepEqual(exp.ContainerIPs, actual.ContainerIPs) &&
+			reflect.DeepEqual(exp.UnhealthyIPs, actual.UnhealthyIPs) &&
 			ipdef.QuiltSubnet.Contains(net.ParseIP(actual.IP)) {
 			return 0
 		}
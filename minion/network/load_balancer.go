@@ -59,6 +59,11 @@ func updateLoadBalancerIPs(client ovsdb.Client, labels []db.Label) {
 
 	var target []ovsdb.LoadBalancer
 	for _, label := range labels {
+		// A label without serving containers has nowhere to send traffic.
+		if len(label.ContainerIPs) == 0 {
+			continue
+		}
+
 		// Ignore the ContainerIPs order. We must copy the ContainerIPs slice
 		// before sorting it to avoid mutating the value within the Database.
 		ips := make([]string, len(label.ContainerIPs))
@@ -47,6 +47,11 @@ func TestUpdateLoadBalancerIPs(t *testing.T) {
 			IP:           "10.0.0.10",
 			ContainerIPs: []string{"10.0.0.11"},
 		},
+		{
+			Label:        "unhealthy",
+			IP:           "10.0.0.20",
+			UnhealthyIPs: []string{"10.0.0.21"},
+		},
 	})
 	client.AssertExpectations(t)
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/quilt/quilt/db"
@@ -191,30 +192,31 @@ func preroutingRules(publicInterface string, containers []db.Container,
 
 	// Map each label to all ports on which it can receive packets
 	// from the public internet.
-	portsFromWeb := make(map[string]map[int]struct{})
+	portsFromWeb := make(map[string]map[natPort]struct{})
 	for _, conn := range connections {
 		if conn.From != stitch.PublicInternetLabel {
 			continue
 		}
 
 		if _, ok := portsFromWeb[conn.To]; !ok {
-			portsFromWeb[conn.To] = make(map[int]struct{})
+			portsFromWeb[conn.To] = make(map[natPort]struct{})
 		}
 
-		portsFromWeb[conn.To][conn.MinPort] = struct{}{}
+		for _, port := range natPorts(conn) {
+			portsFromWeb[conn.To][port] = struct{}{}
+		}
 	}
 
-	// Map the container's port to the same port of the host.
+	// Map the ports of the host to the container's ports.
 	for _, dbc := range containers {
 		for _, label := range dbc.Labels {
-			for port := range portsFromWeb[label] {
-				for _, protocol := range []string{"tcp", "udp"} {
-					rules = append(rules, fmt.Sprintf(
-						"-i %[1]s -p %[2]s -m %[2]s "+
-							"--dport %[3]d -j DNAT "+
-							"--to-destination %[4]s:%[3]d",
-						publicInterface, protocol, port, dbc.IP))
-				}
+			for _, port := range sortNatPorts(portsFromWeb[label]) {
+				rules = append(rules, fmt.Sprintf(
+					"-i %[1]s -p %[2]s -m %[2]s "+
+						"--dport %[3]s -j DNAT "+
+						"--to-destination %[4]s",
+					publicInterface, port.protocol, port.dport(),
+					port.destination(dbc.IP)))
 			}
 		}
 	}
@@ -227,30 +229,32 @@ func postroutingRules(publicInterface string, containers []db.Container,
 
 	// Map each label to all ports on which it can send packets
 	// to the public internet.
-	portsToWeb := make(map[string]map[int]struct{})
+	portsToWeb := make(map[string]map[natPort]struct{})
 	for _, conn := range connections {
 		if conn.To != stitch.PublicInternetLabel {
 			continue
 		}
 
 		if _, ok := portsToWeb[conn.From]; !ok {
-			portsToWeb[conn.From] = make(map[int]struct{})
+			portsToWeb[conn.From] = make(map[natPort]struct{})
 		}
 
-		portsToWeb[conn.From][conn.MinPort] = struct{}{}
+		for _, port := range natPorts(conn) {
+			port.containerPort = 0
+			portsToWeb[conn.From][port] = struct{}{}
+		}
 	}
 
 	for _, dbc := range containers {
 		for _, label := range dbc.Labels {
-			for port := range portsToWeb[label] {
-				for _, protocol := range []string{"tcp", "udp"} {
-					rules = append(rules, fmt.Sprintf(
-						"-s %[1]s/32 -p %[2]s -m %[2]s "+
-							"--dport %[3]d -o %[4]s "+
-							"-j MASQUERADE",
-						dbc.IP, protocol, port, publicInterface,
-					))
-				}
+			for _, port := range sortNatPorts(portsToWeb[label]) {
+				rules = append(rules, fmt.Sprintf(
+					"-s %[1]s/32 -p %[2]s -m %[2]s "+
+						"--dport %[3]s -o %[4]s "+
+						"-j MASQUERADE",
+					dbc.IP, port.protocol, port.dport(),
+					publicInterface,
+				))
 			}
 		}
 	}
@@ -258,6 +262,90 @@ func postroutingRules(publicInterface string, containers []db.Container,
 	return rules
 }
 
+// A natPort is a range of ports of a transport protocol that is translated between
+// the public internet and a container.  If containerPort is set, the single public
+// port minPort is forwarded to it.  Otherwise, the ports are preserved.
+type natPort struct {
+	protocol      string
+	minPort       int
+	maxPort       int
+	containerPort int
+}
+
+// dport returns the iptables representation of the public ports.
+func (port natPort) dport() string {
+	if port.maxPort > port.minPort {
+		return fmt.Sprintf("%d:%d", port.minPort, port.maxPort)
+	}
+	return fmt.Sprintf("%d", port.minPort)
+}
+
+// destination returns the address to which packets for the public ports are
+// forwarded on the container with the given IP.
+func (port natPort) destination(ip string) string {
+	switch {
+	case port.maxPort > port.minPort:
+		// Without a port, DNAT keeps each packet's destination port.
+		return ip
+	case port.containerPort != 0:
+		return fmt.Sprintf("%s:%d", ip, port.containerPort)
+	default:
+		return fmt.Sprintf("%s:%d", ip, port.minPort)
+	}
+}
+
+// natPorts returns the ports that must be translated for a public connection.
+// ICMP has no ports, and so isn't translated.
+func natPorts(conn db.Connection) (ports []natPort) {
+	protocols := []string{stitch.TCP, stitch.UDP}
+	switch conn.Protocol {
+	case stitch.ICMP:
+		return nil
+	case stitch.TCP, stitch.UDP:
+		protocols = []string{conn.Protocol}
+	}
+
+	for _, protocol := range protocols {
+		ports = append(ports, natPort{protocol, conn.MinPort,
+			conn.MaxPort, conn.ContainerPort})
+	}
+	return ports
+}
+
+// sortNatPorts returns the ports in `set` ordered by port range, then protocol, so
+// that the generated rules are deterministic.
+func sortNatPorts(set map[natPort]struct{}) []natPort {
+	var ports natPortSlice
+	for port := range set {
+		ports = append(ports, port)
+	}
+	sort.Sort(ports)
+	return ports
+}
+
+type natPortSlice []natPort
+
+func (ps natPortSlice) Len() int {
+	return len(ps)
+}
+
+func (ps natPortSlice) Swap(i, j int) {
+	ps[i], ps[j] = ps[j], ps[i]
+}
+
+func (ps natPortSlice) Less(i, j int) bool {
+	switch {
+	case ps[i].minPort != ps[j].minPort:
+		return ps[i].minPort < ps[j].minPort
+	case ps[i].maxPort != ps[j].maxPort:
+		return ps[i].maxPort < ps[j].maxPort
+	case ps[i].protocol != ps[j].protocol:
+		return ps[i].protocol < ps[j].protocol
+	default:
+		return ps[i].containerPort < ps[j].containerPort
+	}
+}
+
 type rule struct {
 	table         string
 	chain         string
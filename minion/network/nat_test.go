@@ -64,6 +64,14 @@ func TestPreroutingRules(t *testing.T) {
 			IP:     "10.10.10.10",
 			Labels: []string{"green"},
 		},
+		{
+			IP:     "11.11.11.11",
+			Labels: []string{"dns"},
+		},
+		{
+			IP:     "12.12.12.12",
+			Labels: []string{"game"},
+		},
 	}
 
 	connections := []db.Connection{
@@ -92,6 +100,32 @@ func TestPreroutingRules(t *testing.T) {
 			To:      stitch.PublicInternetLabel,
 			MinPort: 80,
 		},
+		{
+			From:     stitch.PublicInternetLabel,
+			To:       "dns",
+			MinPort:  53,
+			Protocol: stitch.UDP,
+		},
+		{
+			From:     stitch.PublicInternetLabel,
+			To:       "dns",
+			Protocol: stitch.ICMP,
+		},
+		{
+			From:     stitch.PublicInternetLabel,
+			To:       "game",
+			MinPort:  27015,
+			MaxPort:  27030,
+			Protocol: stitch.UDP,
+		},
+		{
+			From:          stitch.PublicInternetLabel,
+			To:            "game",
+			MinPort:       8080,
+			MaxPort:       8080,
+			Protocol:      stitch.TCP,
+			ContainerPort: 80,
+		},
 	}
 
 	actual := preroutingRules("eth0", containers, connections)
@@ -102,6 +136,12 @@ func TestPreroutingRules(t *testing.T) {
 		"-i eth0 -p udp -m udp --dport 81 -j DNAT --to-destination 8.8.8.8:81",
 		"-i eth0 -p tcp -m tcp --dport 80 -j DNAT --to-destination 9.9.9.9:80",
 		"-i eth0 -p udp -m udp --dport 80 -j DNAT --to-destination 9.9.9.9:80",
+		"-i eth0 -p udp -m udp --dport 53 -j DNAT " +
+			"--to-destination 11.11.11.11:53",
+		"-i eth0 -p tcp -m tcp --dport 8080 -j DNAT " +
+			"--to-destination 12.12.12.12:80",
+		"-i eth0 -p udp -m udp --dport 27015:27030 -j DNAT " +
+			"--to-destination 12.12.12.12",
 	}
 	assert.Equal(t, exp, actual)
 }
@@ -155,9 +195,18 @@ func TestPostroutingRules(t *testing.T) {
 			To:      stitch.PublicInternetLabel,
 			MinPort: 80,
 		},
+		{
+			From:     "green",
+			To:       stitch.PublicInternetLabel,
+			MinPort:  5000,
+			MaxPort:  5010,
+			Protocol: stitch.TCP,
+		},
 	}
 
 	exp := []string{
+		"-s 10.10.10.10/32 -p tcp -m tcp --dport 5000:5010 -o eth0 " +
+			"-j MASQUERADE",
 		"-s 8.8.8.8/32 -p tcp -m tcp --dport 80 -o eth0 -j MASQUERADE",
 		"-s 8.8.8.8/32 -p tcp -m tcp --dport 81 -o eth0 -j MASQUERADE",
 		"-s 8.8.8.8/32 -p udp -m udp --dport 80 -o eth0 -j MASQUERADE",
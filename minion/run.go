@@ -8,7 +8,9 @@ import (
 
 	"github.com/quilt/quilt/api"
 	apiServer "github.com/quilt/quilt/api/server"
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
+	"github.com/quilt/quilt/metrics"
 	"github.com/quilt/quilt/minion/docker"
 	"github.com/quilt/quilt/minion/etcd"
 	"github.com/quilt/quilt/minion/network"
@@ -22,58 +24,90 @@ import (
 	log "github.com/Sirupsen/logrus"
 )
 
-// Run blocks executing the minion.
-func Run(role db.Role, inboundPubIntf, outboundPubIntf string) {
+// The number of changes to remember per database table for `quilt history`.
+const historySize = 100
+
+// Run blocks executing the minion.  The minion's servers require clients to
+// authenticate with a certificate signed by the certificate authority in 'creds'.
+func Run(role db.Role, inboundPubIntf, outboundPubIntf string,
+	creds certs.Credentials) {
+
 	// XXX Uncomment the following line to run the profiler
 	//runProfiler(5 * time.Minute)
 
 	conn := db.New()
+	conn.EnableHistory(historySize)
 	dk := docker.New("unix:///var/run/docker.sock")
 
 	// XXX: As we are developing minion modules to use this passed down role
 	// instead of querying their db independently, we need to do this.
 	// Possibly in the future just pass down role into all of the modules,
 	// but may be simpler to just have it use this entry.
-	conn.Txn(db.MinionTable).Run(func(view db.Database) error {
+	err := conn.Txn(db.MinionTable).Run(func(view db.Database) error {
 		minion := view.InsertMinion()
 		minion.Role = role
 		minion.Self = true
 		view.Commit(minion)
 		return nil
 	})
+	if err != nil {
+		log.WithError(err).Error("Failed to record the minion's role.")
+	}
 
 	// Not in a goroutine, want the plugin to start before the scheduler
 	plugin.Run()
 
-	supervisor.Run(conn, dk, role)
+	supervisor.Run(conn.WithTag("supervisor"), dk, role)
 
-	go minionServerRun(conn)
-	go scheduler.Run(conn, dk)
-	go network.Run(conn, inboundPubIntf, outboundPubIntf)
-	go registry.Run(conn, dk)
-	go etcd.Run(conn)
-	go syncAuthorizedKeys(conn)
+	go minionServerRun(conn.WithTag("minion-server"), creds)
+	go scheduler.Run(conn.WithTag("scheduler"), dk, creds.KeyPair)
+	go network.Run(conn.WithTag("network"), inboundPubIntf, outboundPubIntf)
+	go registry.Run(conn.WithTag("registry"), dk)
+	go etcd.Run(conn.WithTag("etcd"))
+	go syncAuthorizedKeys(conn.WithTag("keys"))
+	go runMetrics(conn.WithTag("metrics"), creds)
 
-	go apiServer.Run(conn, fmt.Sprintf("tcp://0.0.0.0:%d", api.DefaultRemotePort),
-		false)
+	go apiServer.Run(conn.WithTag("api"),
+		fmt.Sprintf("tcp://0.0.0.0:%d", api.DefaultRemotePort), false, creds,
+		&dk)
 
 	loopLog := util.NewEventTimer("Minion-Update")
 
+	conn = conn.WithTag("minion")
 	for range conn.Trigger(db.MinionTable, db.EtcdTable).C {
 		loopLog.LogStart()
 		txn := conn.Txn(db.ConnectionTable, db.ContainerTable, db.MinionTable,
 			db.EtcdTable, db.PlacementTable, db.ImageTable)
-		txn.Run(func(view db.Database) error {
+		err := txn.Run(func(view db.Database) error {
 			minion := view.MinionSelf()
 			if view.EtcdLeader() {
 				updatePolicy(view, minion.Blueprint)
 			}
 			return nil
 		})
+		if err != nil {
+			log.WithError(err).Error("Failed to update the policy.")
+		}
 		loopLog.LogEnd()
 	}
 }
 
+// runMetrics serves the minion's metrics to scrapers authenticated with 'creds'.
+func runMetrics(conn db.Conn, creds certs.Credentials) {
+	tlsConfig, err := creds.ServerConfig()
+	if err != nil {
+		log.WithError(err).Error("Failed to load metrics TLS configuration")
+		return
+	}
+
+	addr := fmt.Sprintf(":%d", metrics.MinionPort)
+	for {
+		err := metrics.Run(addr, tlsConfig, conn.Collector())
+		log.WithError(err).Error("Metrics server stopped")
+		time.Sleep(30 * time.Second)
+	}
+}
+
 func runProfiler(duration time.Duration) {
 	go func() {
 		p := pprofile.New("minion")
@@ -0,0 +1,184 @@
+package scheduler
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/quilt/quilt/db"
+	"github.com/quilt/quilt/minion/docker"
+	netctx "golang.org/x/net/context"
+)
+
+// The defaults for unset fields of health checks.  Intervals and timeouts are in
+// seconds.
+const (
+	defaultHealthInterval = 10
+	defaultHealthTimeout  = 5
+	defaultHealthRetries  = 3
+)
+
+var healthNow = time.Now
+var dialTimeout = net.DialTimeout
+var httpGet = func(url string, timeout time.Duration) (int, error) {
+	client := http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// healthState tracks the checks of a single Docker container.
+type healthState struct {
+	next     time.Time
+	failures int
+}
+
+// runHealthChecks periodically checks the health of the containers running on this
+// worker, and records it in their Health.
+func runHealthChecks(conn db.Conn, dk docker.Client) {
+	states := map[string]*healthState{}
+	for range conn.TriggerTick(1, db.ContainerTable).C {
+		if conn.MinionSelf().Role == db.Worker {
+			checkHealth(conn, dk, states)
+		}
+	}
+}
+
+func checkHealth(conn db.Conn, dk docker.Client, states map[string]*healthState) {
+	now := healthNow()
+	dbcs := conn.SelectFromContainer(func(dbc db.Container) bool {
+		return dbc.HealthCheck != nil && dbc.DockerID != "" && dbc.IP != ""
+	})
+
+	var due []db.Container
+	running := map[string]struct{}{}
+	for _, dbc := range dbcs {
+		running[dbc.DockerID] = struct{}{}
+		if state, ok := states[dbc.DockerID]; !ok || !now.Before(state.next) {
+			due = append(due, dbc)
+		}
+	}
+
+	for id := range states {
+		if _, ok := running[id]; !ok {
+			delete(states, id)
+		}
+	}
+
+	errs := make([]error, len(due))
+	var wg sync.WaitGroup
+	wg.Add(len(due))
+	for i, dbc := range due {
+		go func(i int, dbc db.Container) {
+			errs[i] = runHealthCheck(dk, dbc)
+			wg.Done()
+		}(i, dbc)
+	}
+	wg.Wait()
+
+	health := map[string]string{}
+	for i, dbc := range due {
+		check := healthCheckDefaults(*dbc.HealthCheck)
+		state, ok := states[dbc.DockerID]
+		if !ok {
+			state = &healthState{}
+			states[dbc.DockerID] = state
+		}
+		state.next = now.Add(time.Duration(check.Interval) * time.Second)
+
+		if errs[i] == nil {
+			state.failures = 0
+			health[dbc.DockerID] = db.ContainerHealthy
+			continue
+		}
+
+		// Containers remain in their current state until they've failed enough
+		// consecutive checks.
+		state.failures++
+		log.WithError(errs[i]).WithField("container", dbc.StitchID).Debug(
+			"Failed health check.")
+		if state.failures >= check.Retries {
+			health[dbc.DockerID] = db.ContainerUnhealthy
+		}
+	}
+
+	if len(health) == 0 {
+		return
+	}
+
+	err := conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+		for _, dbc := range view.SelectFromContainer(nil) {
+			h, ok := health[dbc.DockerID]
+			if !ok || dbc.Health == h {
+				continue
+			}
+
+			log.WithField("container", dbc.StitchID).Infof(
+				"Container is %s.", h)
+			dbc.Health = h
+			view.Commit(dbc)
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to update container health.")
+	}
+}
+
+// runHealthCheck returns an error if `dbc` fails its health check.  Commands are run
+// in the container, while HTTP and TCP checks are made from the host.
+func runHealthCheck(dk docker.Client, dbc db.Container) error {
+	check := healthCheckDefaults(*dbc.HealthCheck)
+	timeout := time.Duration(check.Timeout) * time.Second
+	addr := fmt.Sprintf("%s:%d", dbc.IP, check.Port)
+
+	switch {
+	case len(check.Command) != 0:
+		ctx, cancel := netctx.WithTimeout(netctx.Background(), timeout)
+		defer cancel()
+
+		code, err := dk.Exec(ctx, dbc.DockerID,
+			docker.ExecOptions{Cmd: check.Command})
+		if err != nil {
+			return err
+		}
+		if code != 0 {
+			return fmt.Errorf("exit status %d", code)
+		}
+		return nil
+	case check.HTTPPath != "":
+		status, err := httpGet("http://"+addr+check.HTTPPath, timeout)
+		if err != nil {
+			return err
+		}
+		if status < 200 || status >= 400 {
+			return fmt.Errorf("HTTP status %d", status)
+		}
+		return nil
+	default:
+		c, err := dialTimeout("tcp", addr, timeout)
+		if err != nil {
+			return err
+		}
+		return c.Close()
+	}
+}
+
+func healthCheckDefaults(check db.HealthCheck) db.HealthCheck {
+	if check.Interval == 0 {
+		check.Interval = defaultHealthInterval
+	}
+	if check.Timeout == 0 {
+		check.Timeout = defaultHealthTimeout
+	}
+	if check.Retries == 0 {
+		check.Retries = defaultHealthRetries
+	}
+	return check
+}
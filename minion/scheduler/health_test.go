@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/quilt/quilt/db"
+	"github.com/quilt/quilt/minion/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHealth(t *testing.T) {
+	md, dk := docker.NewMock()
+	id, err := dk.Run(docker.RunOptions{Image: "postgres"})
+	assert.NoError(t, err)
+
+	now := time.Now()
+	healthNow = func() time.Time { return now }
+
+	var httpURL string
+	httpStatus := 200
+	httpGet = func(url string, timeout time.Duration) (int, error) {
+		httpURL = url
+		return httpStatus, nil
+	}
+
+	var dialErr error
+	dialTimeout = func(network, addr string, timeout time.Duration) (net.Conn,
+		error) {
+		if dialErr != nil {
+			return nil, dialErr
+		}
+		c, _ := net.Pipe()
+		return c, nil
+	}
+
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		dbc := view.InsertContainer()
+		dbc.StitchID = "command"
+		dbc.IP = "10.0.0.2"
+		dbc.DockerID = id
+		dbc.HealthCheck = &db.HealthCheck{Command: []string{"pg_isready"},
+			Retries: 2}
+		view.Commit(dbc)
+
+		dbc = view.InsertContainer()
+		dbc.StitchID = "http"
+		dbc.IP = "10.0.0.3"
+		dbc.DockerID = "http"
+		dbc.HealthCheck = &db.HealthCheck{HTTPPath: "/healthz", Port: 80,
+			Interval: 5, Retries: 1}
+		view.Commit(dbc)
+
+		dbc = view.InsertContainer()
+		dbc.StitchID = "tcp"
+		dbc.IP = "10.0.0.4"
+		dbc.DockerID = "tcp"
+		dbc.HealthCheck = &db.HealthCheck{Port: 6379}
+		view.Commit(dbc)
+
+		dbc = view.InsertContainer()
+		dbc.StitchID = "unchecked"
+		dbc.IP = "10.0.0.5"
+		dbc.DockerID = "unchecked"
+		view.Commit(dbc)
+		return nil
+	})
+
+	health := func() map[string]string {
+		res := map[string]string{}
+		for _, dbc := range conn.SelectFromContainer(nil) {
+			res[dbc.StitchID] = dbc.Health
+		}
+		return res
+	}
+
+	states := map[string]*healthState{}
+	checkHealth(conn, dk, states)
+	assert.Equal(t, map[string]string{
+		"command":   db.ContainerHealthy,
+		"http":      db.ContainerHealthy,
+		"tcp":       db.ContainerHealthy,
+		"unchecked": "",
+	}, health())
+	assert.Equal(t, "http://10.0.0.3:80/healthz", httpURL)
+	assert.Equal(t, []string{"pg_isready"}, md.Executions[id])
+
+	// Checks aren't repeated before their interval has passed.
+	httpURL = ""
+	now = now.Add(time.Second)
+	checkHealth(conn, dk, states)
+	assert.Empty(t, httpURL)
+	assert.Len(t, md.Executions[id], 1)
+
+	// Containers become unhealthy after failing their retries.
+	md.ExecExitCode = 1
+	httpStatus = 500
+	dialErr = errors.New("connection refused")
+	now = now.Add(10 * time.Second)
+	checkHealth(conn, dk, states)
+	assert.Equal(t, map[string]string{
+		"command":   db.ContainerHealthy,
+		"http":      db.ContainerUnhealthy,
+		"tcp":       db.ContainerHealthy,
+		"unchecked": "",
+	}, health())
+
+	now = now.Add(10 * time.Second)
+	checkHealth(conn, dk, states)
+	assert.Equal(t, db.ContainerUnhealthy, health()["command"])
+	assert.Equal(t, db.ContainerHealthy, health()["tcp"])
+
+	now = now.Add(10 * time.Second)
+	checkHealth(conn, dk, states)
+	assert.Equal(t, db.ContainerUnhealthy, health()["tcp"])
+
+	// A single success makes a container healthy again.
+	md.ExecExitCode = 0
+	now = now.Add(10 * time.Second)
+	checkHealth(conn, dk, states)
+	assert.Equal(t, db.ContainerHealthy, health()["command"])
+	assert.Equal(t, 0, states[id].failures)
+
+	// The states of removed containers are forgotten.
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		for _, dbc := range view.SelectFromContainer(nil) {
+			if dbc.StitchID == "tcp" {
+				view.Remove(dbc)
+			}
+		}
+		return nil
+	})
+	checkHealth(conn, dk, states)
+	assert.NotContains(t, states, "tcp")
+}
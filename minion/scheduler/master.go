@@ -7,6 +7,7 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/quilt/quilt/db"
+	"github.com/quilt/quilt/metrics"
 	"github.com/quilt/quilt/util"
 )
 
@@ -27,19 +28,35 @@ func runMaster(conn db.Conn) {
 		return
 	}
 
-	conn.Txn(db.ContainerTable, db.MinionTable, db.ImageTable,
+	err := conn.Txn(db.ContainerTable, db.MinionTable, db.ImageTable,
 		db.PlacementTable).Run(func(view db.Database) error {
 		placeContainers(view)
 		return nil
 	})
+	if err != nil {
+		log.WithError(err).Error("Failed to place containers.")
+	}
 }
 
 func placeContainers(view db.Database) {
 	constraints := view.SelectFromPlacement(nil)
-	containers := view.SelectFromContainer(nil)
 	minions := view.SelectFromMinion(nil)
 	images := view.SelectFromImage(nil)
 
+	// Containers may only refer to existing minions, so those that are unassigned
+	// and those on each minion are all of them.  They're found through the Minion
+	// index rather than by scanning the table.
+	containers := view.SelectFromContainerByMinion("")
+	minionIPs := map[string]struct{}{}
+	for _, m := range minions {
+		if _, ok := minionIPs[m.PrivateIP]; ok || m.PrivateIP == "" {
+			continue
+		}
+		minionIPs[m.PrivateIP] = struct{}{}
+		containers = append(containers,
+			view.SelectFromContainerByMinion(m.PrivateIP)...)
+	}
+
 	ctx := makeContext(minions, constraints, containers, images)
 	cleanupPlacements(ctx)
 	placeUnassigned(ctx)
@@ -49,12 +66,16 @@ func placeContainers(view db.Database) {
 	}
 }
 
-// Unassign all containers that are placed incorrectly.
+// Unassign all containers that are placed incorrectly.  Containers pinned to their
+// volumes are left in place, as moving them would lose their data.
 func cleanupPlacements(ctx *context) {
 	for _, m := range ctx.minions {
 		var valid []*db.Container
 		for _, dbc := range m.containers {
-			if validPlacement(ctx.constraints, *m, valid, dbc) {
+			keep := pinned(dbc) && dbc.VolumeMinion == m.PrivateIP ||
+				validPlacement(ctx.constraints, *m, valid, dbc) &&
+					insufficientResources(m.Minion, valid, dbc) == ""
+			if keep {
 				valid = append(valid, dbc)
 				continue
 			}
@@ -72,18 +93,89 @@ func placeUnassigned(ctx *context) {
 
 Outer:
 	for _, dbc := range ctx.unassigned {
+		var insufficient string
 		for i, m := range minions {
-			if validPlacement(ctx.constraints, *m, m.containers, dbc) {
-				dbc.Minion = m.PrivateIP
-				ctx.changed = append(ctx.changed, dbc)
-				m.containers = append(m.containers, dbc)
-				heap.Fix(&minions, i)
-				log.WithField("container", dbc).Info("Placed container.")
-				continue Outer
+			if pinned(dbc) && dbc.VolumeMinion != m.PrivateIP {
+				continue
+			}
+
+			if !validPlacement(ctx.constraints, *m, m.containers, dbc) {
+				continue
+			}
+
+			if resource := insufficientResources(m.Minion, m.containers,
+				dbc); resource != "" {
+				insufficient = resource
+				continue
+			}
+
+			dbc.Minion = m.PrivateIP
+			dbc.Status = ""
+			dbc.VolumeMinion = ""
+			if stateful(dbc) {
+				dbc.VolumeMinion = m.PrivateIP
 			}
+			ctx.changed = append(ctx.changed, dbc)
+			m.containers = append(m.containers, dbc)
+			heap.Fix(&minions, i)
+			log.WithField("container", dbc).Info("Placed container.")
+			continue Outer
 		}
 
 		log.WithField("container", dbc).Warning("Failed to place container.")
+		metrics.PlacementFailures.Inc()
+
+		// Before any workers have connected, there's nothing to report.
+		if len(minions) == 0 {
+			continue
+		}
+
+		status := unschedulable
+		if insufficient != "" {
+			status += ": insufficient " + insufficient
+		} else if pinned(dbc) {
+			status += ": its volumes are on " + dbc.VolumeMinion
+		}
+		if dbc.Status != status {
+			dbc.Status = status
+			ctx.changed = append(ctx.changed, dbc)
+		}
+	}
+}
+
+// The status of containers that can't be placed on any of the minions.
+const unschedulable = "unschedulable"
+
+// stateful returns whether `dbc` must stay with the data in its volumes.
+func stateful(dbc *db.Container) bool {
+	return len(dbc.Volumes) > 0 && !dbc.Movable
+}
+
+// pinned returns whether `dbc` must stay on the minion that holds the data in its
+// volumes.
+func pinned(dbc *db.Container) bool {
+	return stateful(dbc) && dbc.VolumeMinion != ""
+}
+
+// insufficientResources returns the resource that `m` lacks to run `dbc` alongside
+// `peers`, or the empty string if it has enough.  The requests of the containers are
+// reserved out of the capacity of the minion, so that it's never overcommitted.
+func insufficientResources(m db.Minion, peers []*db.Container,
+	dbc *db.Container) string {
+
+	cpu, memory := dbc.CPURequest, dbc.MemoryRequest
+	for _, peer := range peers {
+		cpu += peer.CPURequest
+		memory += peer.MemoryRequest
+	}
+
+	switch {
+	case m.CPU != 0 && cpu > m.CPU:
+		return "CPU"
+	case m.Memory != 0 && memory > m.Memory:
+		return "memory"
+	default:
+		return ""
 	}
 }
 
@@ -227,6 +319,12 @@ func makeContext(minions []db.Minion, constraints []db.Placement,
 			ctx.changed = append(ctx.changed, dbc)
 		}
 
+		// Containers placed before they had volumes are pinned where they run.
+		if dbc.Minion != "" && stateful(dbc) && dbc.VolumeMinion == "" {
+			dbc.VolumeMinion = dbc.Minion
+			ctx.changed = append(ctx.changed, dbc)
+		}
+
 		// If the container is built by Quilt, only schedule it if the image
 		// has been built.
 		if dbc.Dockerfile != "" {
@@ -259,8 +357,9 @@ func makeContext(minions []db.Minion, constraints []db.Placement,
 	return &ctx
 }
 
-// Minion Heap.  Minions are sorted based on the number of containers scheduled on them
-// with fewer containers being higher priority.
+// Minion Heap.  Minions are sorted based on the share of their capacity requested by
+// the containers scheduled on them, and then on the number of those containers, with
+// less loaded minions being higher priority.
 type minionHeap []*minion
 
 func (mh minionHeap) Len() int      { return len(mh) }
@@ -271,9 +370,32 @@ func (mh *minionHeap) Push(x interface{}) { panic("Not Reached") }
 func (mh *minionHeap) Pop() interface{}   { panic("Not Reached") }
 
 func (mh minionHeap) Less(i, j int) bool {
+	if li, lj := mh[i].load(), mh[j].load(); li != lj {
+		return li < lj
+	}
 	return len(mh[i].containers) < len(mh[j].containers)
 }
 
+// load returns the largest share of the known capacity of the minion requested by
+// its containers.
+func (m minion) load() float64 {
+	var cpu float64
+	var memory int
+	for _, dbc := range m.containers {
+		cpu += dbc.CPURequest
+		memory += dbc.MemoryRequest
+	}
+
+	var load float64
+	if m.CPU != 0 {
+		load = cpu / m.CPU
+	}
+	if m.Memory != 0 && float64(memory)/float64(m.Memory) > load {
+		load = float64(memory) / float64(m.Memory)
+	}
+	return load
+}
+
 type dbcSlice []*db.Container
 
 func (s dbcSlice) Less(i, j int) bool {
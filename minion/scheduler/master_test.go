@@ -41,6 +41,44 @@ func TestPlaceContainers(t *testing.T) {
 	})
 }
 
+func TestPlaceContainersByMinion(t *testing.T) {
+	t.Parallel()
+	conn := db.New()
+
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		worker := view.InsertMinion()
+		worker.PrivateIP = "1"
+		worker.Role = db.Worker
+		view.Commit(worker)
+
+		master := view.InsertMinion()
+		master.PrivateIP = "2"
+		master.Role = db.Master
+		view.Commit(master)
+
+		for _, ip := range []string{"", "1", "2"} {
+			c := view.InsertContainer()
+			c.StitchID = "on" + ip
+			c.Minion = ip
+			view.Commit(c)
+		}
+		return nil
+	})
+
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		placeContainers(view)
+		return nil
+	})
+
+	// Containers that are unassigned, or on minions that aren't workers, are
+	// placed on the worker.
+	dbcs := conn.SelectFromContainer(nil)
+	assert.Len(t, dbcs, 3)
+	for _, dbc := range dbcs {
+		assert.Equal(t, "1", dbc.Minion, dbc.StitchID)
+	}
+}
+
 func TestCleanup(t *testing.T) {
 	t.Parallel()
 
@@ -269,9 +307,129 @@ func TestPlaceUnassigned(t *testing.T) {
 	containers[0].Minion = ""
 	ctx = makeContext(minions, placements, containers, nil)
 	placeUnassigned(ctx)
+	assert.Equal(t, []*db.Container{{ID: 1, Labels: []string{"1"},
+		Status: "unschedulable"}}, ctx.changed)
+
+	// The status of unschedulable containers is only changed once.
+	ctx = makeContext(minions, placements, containers, nil)
+	placeUnassigned(ctx)
 	assert.Nil(t, ctx.changed)
 }
 
+func TestPlaceUnassignedResources(t *testing.T) {
+	t.Parallel()
+
+	minions := []db.Minion{
+		{PrivateIP: "1", Role: db.Worker, CPU: 2, Memory: 1024},
+		{PrivateIP: "2", Role: db.Worker, CPU: 4, Memory: 1024},
+	}
+	containers := []db.Container{
+		{ID: 1, StitchID: "1", CPURequest: 3},
+		{ID: 2, StitchID: "2", CPURequest: 1, MemoryRequest: 512},
+		{ID: 3, StitchID: "3", MemoryRequest: 768},
+		{ID: 4, StitchID: "4", CPURequest: 1, MemoryRequest: 512},
+		{ID: 5, StitchID: "5", CPURequest: 4},
+		{ID: 6, StitchID: "6", MemoryRequest: 2048},
+	}
+
+	ctx := makeContext(minions, nil, containers, nil)
+	placeUnassigned(ctx)
+
+	// Containers are placed on the least loaded minion that has room for them.
+	assert.Equal(t, "2", containers[0].Minion)
+	assert.Equal(t, "1", containers[1].Minion)
+	assert.Equal(t, "2", containers[2].Minion)
+	assert.Equal(t, "1", containers[3].Minion)
+
+	assert.Equal(t, "", containers[4].Minion)
+	assert.Equal(t, "unschedulable: insufficient CPU", containers[4].Status)
+	assert.Equal(t, "", containers[5].Minion)
+	assert.Equal(t, "unschedulable: insufficient memory", containers[5].Status)
+
+	// Once there's room, unschedulable containers are placed.
+	minions[0].CPU = 8
+	ctx = makeContext(minions, nil, containers, nil)
+	placeUnassigned(ctx)
+	assert.Equal(t, "1", containers[4].Minion)
+	assert.Equal(t, "", containers[4].Status)
+
+	// Minions of unknown capacity aren't limited.
+	minions[1].Memory = 0
+	ctx = makeContext(minions, nil, containers, nil)
+	placeUnassigned(ctx)
+	assert.Equal(t, "2", containers[5].Minion)
+}
+
+func TestPlaceVolumes(t *testing.T) {
+	t.Parallel()
+
+	minions := []db.Minion{
+		{PrivateIP: "1", Role: db.Worker},
+		{PrivateIP: "2", Role: db.Worker},
+	}
+	containers := []db.Container{
+		{ID: 1, StitchID: "1", Volumes: []string{"data:/data"}},
+		{ID: 2, StitchID: "2", Volumes: []string{"data:/data"}, Movable: true},
+		{ID: 3, StitchID: "3", Volumes: []string{"data:/data"}, Minion: "2"},
+	}
+
+	ctx := makeContext(minions, nil, containers, nil)
+	cleanupPlacements(ctx)
+	placeUnassigned(ctx)
+	assert.Equal(t, "1", containers[0].Minion)
+	assert.Equal(t, "1", containers[0].VolumeMinion)
+	assert.Equal(t, "1", containers[1].Minion)
+	assert.Equal(t, "", containers[1].VolumeMinion)
+
+	// Containers placed before they had volumes are pinned where they run.
+	assert.Equal(t, "2", containers[2].VolumeMinion)
+
+	// Pinned containers aren't moved by placement constraints.
+	placements := []db.Placement{
+		{TargetLabel: "red", Exclusive: true, Region: "Region1"},
+	}
+	minions[0].Region = "Region1"
+	for i := range containers {
+		containers[i].Labels = []string{"red"}
+	}
+	ctx = makeContext(minions, placements, containers, nil)
+	cleanupPlacements(ctx)
+	placeUnassigned(ctx)
+	assert.Equal(t, "1", containers[0].Minion)
+	assert.Equal(t, "2", containers[1].Minion)
+
+	// When its minion goes away, a pinned container waits for it to come back.
+	ctx = makeContext(minions[1:], nil, containers, nil)
+	cleanupPlacements(ctx)
+	placeUnassigned(ctx)
+	assert.Equal(t, "", containers[0].Minion)
+	assert.Equal(t, "1", containers[0].VolumeMinion)
+	assert.Equal(t, "unschedulable: its volumes are on 1", containers[0].Status)
+
+	ctx = makeContext(minions, nil, containers, nil)
+	cleanupPlacements(ctx)
+	placeUnassigned(ctx)
+	assert.Equal(t, "1", containers[0].Minion)
+	assert.Equal(t, "", containers[0].Status)
+}
+
+func TestCleanupResources(t *testing.T) {
+	t.Parallel()
+
+	minions := []db.Minion{{PrivateIP: "1", Role: db.Worker, CPU: 2}}
+	containers := []db.Container{
+		{ID: 1, Minion: "1", CPURequest: 1},
+		{ID: 2, Minion: "1", CPURequest: 2},
+		{ID: 3, Minion: "1", CPURequest: 1},
+	}
+
+	ctx := makeContext(minions, nil, containers, nil)
+	cleanupPlacements(ctx)
+	assert.Equal(t, []*db.Container{&containers[0], &containers[2]},
+		ctx.minions[0].containers)
+	assert.Equal(t, []*db.Container{{ID: 2, CPURequest: 2}}, ctx.unassigned)
+}
+
 func TestMakeContext(t *testing.T) {
 	t.Parallel()
 
@@ -9,14 +9,16 @@ import (
 	"time"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/minion/docker"
 	"github.com/quilt/quilt/minion/network/plugin"
 	"github.com/quilt/quilt/util"
 )
 
-// Run blocks implementing the scheduler module.
-func Run(conn db.Conn, dk docker.Client) {
+// Run blocks implementing the scheduler module.  Workers open the secrets used by
+// their containers with `keys`.
+func Run(conn db.Conn, dk docker.Client, keys certs.KeyPair) {
 	bootWait(conn)
 
 	err := dk.ConfigureNetwork(plugin.NetworkName)
@@ -24,15 +26,17 @@ func Run(conn db.Conn, dk docker.Client) {
 		log.WithError(err).Fatal("Failed to configure network plugin")
 	}
 
+	go runHealthChecks(conn, dk)
+
 	loopLog := util.NewEventTimer("Scheduler")
 	trig := conn.TriggerTick(60, db.MinionTable, db.ContainerTable,
-		db.PlacementTable, db.EtcdTable).C
+		db.PlacementTable, db.EtcdTable, db.SecretTable).C
 	for range trig {
 		loopLog.LogStart()
 		minion := conn.MinionSelf()
 
 		if minion.Role == db.Worker {
-			runWorker(conn, dk, minion.PrivateIP)
+			runWorker(conn, dk, minion.PrivateIP, keys)
 		} else if minion.Role == db.Master {
 			runMaster(conn)
 		}
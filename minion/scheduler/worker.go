@@ -3,16 +3,19 @@ package scheduler
 import (
 	"crypto/sha1"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/join"
 	"github.com/quilt/quilt/minion/docker"
 	"github.com/quilt/quilt/minion/ipdef"
 	"github.com/quilt/quilt/minion/network/openflow"
 	"github.com/quilt/quilt/minion/network/plugin"
+	"github.com/quilt/quilt/stitch"
 	"github.com/quilt/quilt/util"
 )
 
@@ -20,11 +23,18 @@ const labelKey = "quilt"
 const labelValue = "scheduler"
 const labelPair = labelKey + "=" + labelValue
 const filesKey = "files"
+const resourcesKey = "resources"
+const restartKey = "restart"
+const secretsKey = "secrets"
+const stitchIDKey = "stitchID"
 const concurrencyLimit = 32
 
+// The status of containers that Docker is waiting to restart after they crashed.
+const crashLoopStatus = "CrashLoop"
+
 var once sync.Once
 
-func runWorker(conn db.Conn, dk docker.Client, myIP string) {
+func runWorker(conn db.Conn, dk docker.Client, myIP string, keys certs.KeyPair) {
 	if myIP == "" {
 		return
 	}
@@ -39,33 +49,52 @@ func runWorker(conn db.Conn, dk docker.Client, myIP string) {
 	filter := map[string][]string{"label": {labelPair}}
 
 	var toBoot, toKill []interface{}
+	var secrets map[string]string
 	for i := 0; i < 2; i++ {
-		dkcs, err := dk.List(filter)
+		// Stopped containers are listed so that Docker, rather than the
+		// scheduler, decides whether to restart them.
+		dkcs, err := dk.ListAll(filter)
 		if err != nil {
 			log.WithError(err).Warning("Failed to list docker containers.")
 			return
 		}
 
-		conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
-			dbcs := view.SelectFromContainer(func(dbc db.Container) bool {
-				return dbc.IP != "" && dbc.Minion == myIP
-			})
+		txn := conn.Txn(db.ContainerTable, db.SecretTable)
+		err = txn.Run(func(view db.Database) error {
+			var dbcs []db.Container
+			for _, dbc := range view.SelectFromContainerByMinion(myIP) {
+				if dbc.IP != "" {
+					dbcs = append(dbcs, dbc)
+				}
+			}
 
-			var changed []db.Container
+			var changed, waiting []db.Container
 			changed, toBoot, toKill = syncWorker(dbcs, dkcs)
-			for _, dbc := range changed {
+
+			// Secrets are only opened once they're needed, and their
+			// plaintext is never written to the database.  Containers
+			// whose secrets aren't available report which one they're
+			// waiting for instead of booting.
+			secrets = openSecrets(toBoot, view.SelectFromSecret(nil), keys)
+			waiting, toBoot = waitForSecrets(toBoot, secrets)
+			for _, dbc := range append(changed, waiting...) {
 				view.Commit(dbc)
 			}
 
 			return nil
 		})
+		if err != nil {
+			log.WithError(err).Error("Failed to update container statuses.")
+		}
 
 		if len(toBoot) == 0 && len(toKill) == 0 {
 			break
 		}
 
 		start := time.Now()
-		doContainers(dk, toBoot, dockerRun)
+		doContainers(dk, toBoot, func(dk docker.Client, iface interface{}) {
+			dockerRun(dk, iface, secrets)
+		})
 		doContainers(dk, toKill, dockerKill)
 		log.Infof("Scheduler spent %v starting/stopping containers",
 			time.Since(start))
@@ -87,10 +116,20 @@ func syncWorker(dbcs []db.Container, dkcs []docker.Container) (
 		dbc := pair.L.(db.Container)
 		dkc := pair.R.(docker.Container)
 
+		// A new Docker container hasn't been checked yet.
+		if dbc.DockerID != dkc.ID {
+			dbc.Health = ""
+		}
+
 		dbc.DockerID = dkc.ID
 		dbc.EndpointID = dkc.EID
 		dbc.Status = dkc.Status
 		dbc.Created = dkc.Created
+		dbc.Restarts = dkc.RestartCount
+		dbc.ExitCode = dkc.ExitCode
+		if dkc.Status == "restarting" && dkc.RestartCount > 0 {
+			dbc.Status = crashLoopStatus
+		}
 		changed = append(changed, dbc)
 	}
 
@@ -120,22 +159,47 @@ func doContainers(dk docker.Client, ifaces []interface{},
 	}
 }
 
-func dockerRun(dk docker.Client, iface interface{}) {
+// dockerRun boots the container `iface`, filling in its secrets from `secrets`, a
+// map from secret name to plaintext.  Containers with missing secrets aren't booted.
+func dockerRun(dk docker.Client, iface interface{}, secrets map[string]string) {
 	dbc := iface.(db.Container)
+
+	env, err := withSecrets(dbc.Env, dbc.SecretEnv, secrets)
+	var files map[string]string
+	if err == nil {
+		files, err = withSecrets(dbc.FilepathToContent, dbc.SecretFiles, secrets)
+	}
+	if err != nil {
+		log.WithError(err).WithField("container", dbc).Warning(
+			"Failed to run container")
+		return
+	}
+
 	log.WithField("container", dbc).Info("Start container")
-	_, err := dk.Run(docker.RunOptions{
+	_, err = dk.Run(docker.RunOptions{
 		Image:             dbc.Image,
 		Args:              dbc.Command,
-		Env:               dbc.Env,
-		FilepathToContent: dbc.FilepathToContent,
+		Env:               env,
+		FilepathToContent: files,
 		Labels: map[string]string{
-			labelKey: labelValue,
-			filesKey: filesHash(dbc.FilepathToContent),
+			labelKey:     labelValue,
+			filesKey:     filesHash(dbc.FilepathToContent),
+			resourcesKey: resourcesString(dbc),
+			restartKey:   restartString(dbc),
+			secretsKey:   secretsString(dbc),
+			stitchIDKey:  dbc.StitchID,
 		},
-		IP:          dbc.IP,
-		NetworkMode: plugin.NetworkName,
-		DNS:         []string{ipdef.GatewayIP.String()},
-		DNSSearch:   []string{"q"},
+		IP:            dbc.IP,
+		NetworkMode:   plugin.NetworkName,
+		DNS:           []string{ipdef.GatewayIP.String()},
+		DNSSearch:     []string{"q"},
+		CPURequest:    dbc.CPURequest,
+		CPULimit:      dbc.CPULimit,
+		MemoryRequest: dbc.MemoryRequest,
+		MemoryLimit:   dbc.MemoryLimit,
+		Binds:         dbc.Volumes,
+		RestartPolicy: dockerRestartPolicy(dbc.RestartPolicy),
+		MaxRetries:    dbc.MaxRetries,
 	})
 	if err != nil {
 		log.WithFields(log.Fields{
@@ -160,7 +224,35 @@ func syncJoinScore(left, right interface{}) int {
 	dbc := left.(db.Container)
 	dkc := right.(docker.Container)
 
-	if dbc.IP != dkc.IP || filesHash(dbc.FilepathToContent) != dkc.Labels[filesKey] {
+	// Docker releases the IPs of stopped containers, so they can only be matched
+	// with the blueprint container they were started for.  Containers started
+	// before they were labelled with it are matched by their Docker ID instead.
+	if dkc.Status == "exited" || dkc.Status == "restarting" {
+		stitchID, ok := dkc.Labels[stitchIDKey]
+		if ok && stitchID != dbc.StitchID || !ok && dbc.DockerID != dkc.ID {
+			return -1
+		}
+	} else if dbc.IP != dkc.IP {
+		return -1
+	}
+
+	if filesHash(dbc.FilepathToContent) != dkc.Labels[filesKey] {
+		return -1
+	}
+
+	if secretsString(dbc) != dkc.Labels[secretsKey] {
+		return -1
+	}
+
+	if resourcesString(dbc) != dkc.Labels[resourcesKey] ||
+		!util.StrSliceEqual(dbc.Volumes, dkc.Binds) {
+		return -1
+	}
+
+	// Containers started before restart policies could be set are never restarted
+	// by Docker, so those that have stopped are replaced.
+	if restartString(dbc) != dkc.Labels[restartKey] ||
+		(restartString(dbc) == "" && dkc.Status == "exited") {
 		return -1
 	}
 
@@ -196,10 +288,161 @@ func filesHash(filepathToContent map[string]string) string {
 	return fmt.Sprintf("%x", sha1.Sum([]byte(toHash)))
 }
 
+// secretsString summarizes the secrets a container refers to, so that it's restarted
+// when they change.  Only the names of the secrets are hashed, so changing the value
+// of a secret doesn't restart the containers that use it.
+func secretsString(dbc db.Container) string {
+	if len(dbc.SecretEnv) == 0 && len(dbc.SecretFiles) == 0 {
+		return ""
+	}
+	toHash := util.MapAsString(dbc.SecretEnv) + util.MapAsString(dbc.SecretFiles)
+	return fmt.Sprintf("%x", sha1.Sum([]byte(toHash)))
+}
+
+// openSecrets decrypts the secrets used by the containers in `toBoot`, and returns a
+// map from their names to their plaintext.  Secrets that fail to open are left out,
+// so that only the containers that use them fail to boot.
+func openSecrets(toBoot []interface{}, dbSecrets []db.Secret,
+	keys certs.KeyPair) map[string]string {
+
+	used := map[string]struct{}{}
+	for _, iface := range toBoot {
+		dbc := iface.(db.Container)
+		for _, name := range dbc.SecretEnv {
+			used[name] = struct{}{}
+		}
+		for _, name := range dbc.SecretFiles {
+			used[name] = struct{}{}
+		}
+	}
+
+	secrets := map[string]string{}
+	for _, secret := range dbSecrets {
+		if _, ok := used[secret.Name]; !ok {
+			continue
+		}
+
+		plaintext, err := keys.Open(secret.Value)
+		if err != nil {
+			log.WithError(err).WithField("secret", secret.Name).Warning(
+				"Failed to open secret")
+			continue
+		}
+		secrets[secret.Name] = plaintext
+	}
+	return secrets
+}
+
+// waitForSecrets splits `toBoot` into the containers that refer to secrets missing
+// from `secrets`, with their statuses set to the first such secret, and those that
+// are ready to boot.
+func waitForSecrets(toBoot []interface{}, secrets map[string]string) (
+	waiting []db.Container, ready []interface{}) {
+
+	for _, iface := range toBoot {
+		dbc := iface.(db.Container)
+
+		var names []string
+		for _, name := range dbc.SecretEnv {
+			names = append(names, name)
+		}
+		for _, name := range dbc.SecretFiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var missing string
+		for _, name := range names {
+			if _, ok := secrets[name]; !ok {
+				missing = name
+				break
+			}
+		}
+
+		if missing == "" {
+			ready = append(ready, dbc)
+			continue
+		}
+
+		status := fmt.Sprintf("waiting for secret %q", missing)
+		if dbc.Status != status {
+			dbc.Status = status
+			waiting = append(waiting, dbc)
+		}
+	}
+	return waiting, ready
+}
+
+// withSecrets returns a copy of `values` with the keys in `refs` set to the plaintext
+// of the secrets they refer to.
+func withSecrets(values, refs, secrets map[string]string) (map[string]string,
+	error) {
+
+	if len(refs) == 0 {
+		return values, nil
+	}
+
+	res := map[string]string{}
+	for key, value := range values {
+		res[key] = value
+	}
+
+	for key, name := range refs {
+		plaintext, ok := secrets[name]
+		if !ok {
+			return nil, fmt.Errorf("secret %q isn't set", name)
+		}
+		res[key] = plaintext
+	}
+	return res, nil
+}
+
+// resourcesString summarizes the resources of a container, so that it's restarted
+// when they change.  Containers without resources have no summary, just like those
+// started before resources could be set.
+func resourcesString(dbc db.Container) string {
+	if dbc.CPURequest == 0 && dbc.CPULimit == 0 &&
+		dbc.MemoryRequest == 0 && dbc.MemoryLimit == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%g/%g,%d/%d", dbc.CPURequest, dbc.CPULimit,
+		dbc.MemoryRequest, dbc.MemoryLimit)
+}
+
+// restartString summarizes the restart policy of a container, so that it's restarted
+// when the policy changes.  Containers that are always restarted have no summary,
+// just like those started before restart policies could be set.
+func restartString(dbc db.Container) string {
+	switch dbc.RestartPolicy {
+	case "", stitch.RestartAlways:
+		return ""
+	case stitch.RestartOnFailure:
+		return fmt.Sprintf("%s:%d", dbc.RestartPolicy, dbc.MaxRetries)
+	default:
+		return dbc.RestartPolicy
+	}
+}
+
+// dockerRestartPolicy converts a blueprint restart policy to Docker's.  Containers
+// are always restarted by default.
+func dockerRestartPolicy(policy string) string {
+	switch policy {
+	case stitch.RestartNever:
+		return "no"
+	case stitch.RestartOnFailure:
+		return policy
+	default:
+		return "always"
+	}
+}
+
 func updateOpenflow(conn db.Conn, myIP string) {
-	dbcs := conn.SelectFromContainer(func(dbc db.Container) bool {
-		return dbc.EndpointID != "" && dbc.IP != "" && dbc.Minion == myIP
-	})
+	var dbcs []db.Container
+	for _, dbc := range conn.SelectFromContainerByMinion(myIP) {
+		if dbc.EndpointID != "" && dbc.IP != "" {
+			dbcs = append(dbcs, dbc)
+		}
+	}
 
 	ofcs := openflowContainers(dbcs)
 	if err := replaceFlows(ofcs); err != nil {
@@ -1,10 +1,13 @@
 package scheduler
 
 import (
+	"encoding/pem"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/minion/docker"
 	"github.com/quilt/quilt/minion/network/openflow"
@@ -33,20 +36,20 @@ func TestRunWorker(t *testing.T) {
 	})
 
 	// Wrong Minion IP, should do nothing.
-	runWorker(conn, dk, "1.2.3.5")
+	runWorker(conn, dk, "1.2.3.5", certs.KeyPair{})
 	dkcs, err := dk.List(nil)
 	assert.NoError(t, err)
 	assert.Len(t, dkcs, 0)
 
 	// Run with a list error, should do nothing.
 	md.ListError = true
-	runWorker(conn, dk, "1.2.3.4")
+	runWorker(conn, dk, "1.2.3.4", certs.KeyPair{})
 	md.ListError = false
 	dkcs, err = dk.List(nil)
 	assert.NoError(t, err)
 	assert.Len(t, dkcs, 0)
 
-	runWorker(conn, dk, "1.2.3.4")
+	runWorker(conn, dk, "1.2.3.4", certs.KeyPair{})
 	dkcs, err = dk.List(nil)
 	assert.NoError(t, err)
 	assert.Len(t, dkcs, 1)
@@ -58,7 +61,9 @@ func runSync(dk docker.Client, dbcs []db.Container,
 
 	changes, tdbcs, tdkcs := syncWorker(dbcs, dkcs)
 	doContainers(dk, tdkcs, dockerKill)
-	doContainers(dk, tdbcs, dockerRun)
+	doContainers(dk, tdbcs, func(dk docker.Client, iface interface{}) {
+		dockerRun(dk, iface, nil)
+	})
 	return changes
 }
 
@@ -103,6 +108,16 @@ func TestSyncWorker(t *testing.T) {
 	}
 	assert.Equal(t, dkcsDB, dbcs)
 
+	// Health is kept until the Docker container is replaced.
+	dbcs[0].Health = db.ContainerHealthy
+	changed, _, _ = syncWorker(dbcs, dkcs)
+	assert.Equal(t, db.ContainerHealthy, changed[0].Health)
+
+	dbcs[0].DockerID = "old"
+	changed, _, _ = syncWorker(dbcs, dkcs)
+	assert.Empty(t, changed[0].Health)
+	dbcs[0].Health = ""
+
 	dbcs[0].DockerID = ""
 	changed = runSync(dk, dbcs, dkcs)
 
@@ -230,6 +245,240 @@ func TestSyncJoinScore(t *testing.T) {
 	dbc.ImageID = "wrong"
 	score = syncJoinScore(dbc, dkc)
 	assert.Equal(t, -1, score)
+
+	dbc.ImageID = dkc.ImageID
+	dbc.MemoryLimit = 512
+	score = syncJoinScore(dbc, dkc)
+	assert.Equal(t, -1, score)
+
+	dkc.Labels[resourcesKey] = "0/0,0/512"
+	score = syncJoinScore(dbc, dkc)
+	assert.Zero(t, score)
+
+	dbc.Volumes = []string{"data:/data"}
+	score = syncJoinScore(dbc, dkc)
+	assert.Equal(t, -1, score)
+
+	dkc.Binds = []string{"data:/data"}
+	score = syncJoinScore(dbc, dkc)
+	assert.Zero(t, score)
+
+	dbc.RestartPolicy = "on-failure"
+	score = syncJoinScore(dbc, dkc)
+	assert.Equal(t, -1, score)
+
+	dkc.Labels[restartKey] = "on-failure:0"
+	score = syncJoinScore(dbc, dkc)
+	assert.Zero(t, score)
+
+	// Stopped containers have no IP, so they're matched by their Docker ID
+	// unless they're labelled with their Stitch ID.
+	dkc.Status = "exited"
+	dkc.IP = ""
+	dbc.DockerID = "old"
+	score = syncJoinScore(dbc, dkc)
+	assert.Equal(t, -1, score)
+
+	dbc.DockerID = dkc.ID
+	score = syncJoinScore(dbc, dkc)
+	assert.Zero(t, score)
+
+	dbc.StitchID = "stitchID"
+	dkc.Labels[stitchIDKey] = "other"
+	score = syncJoinScore(dbc, dkc)
+	assert.Equal(t, -1, score)
+
+	// The Docker ID of a database container is lost when its row is recreated.
+	dbc.DockerID = ""
+	dkc.Labels[stitchIDKey] = dbc.StitchID
+	score = syncJoinScore(dbc, dkc)
+	assert.Zero(t, score)
+
+	// Stopped containers that should always be restarted are replaced.
+	dbc.RestartPolicy = "always"
+	dkc.Labels[restartKey] = ""
+	score = syncJoinScore(dbc, dkc)
+	assert.Equal(t, -1, score)
+}
+
+func TestRunsVolumes(t *testing.T) {
+	t.Parallel()
+
+	_, dk := docker.NewMock()
+	dbcs := []db.Container{{ID: 1, Image: "Image1",
+		Volumes: []string{"data:/data"}}}
+
+	runSync(dk, dbcs, nil)
+	dkcs, err := dk.List(nil)
+	assert.NoError(t, err)
+	assert.Len(t, dkcs, 1)
+	assert.Equal(t, []string{"data:/data"}, dkcs[0].Binds)
+
+	// The container isn't restarted once its volumes are mounted.
+	changed, toBoot, toKill := syncWorker(dbcs, dkcs)
+	assert.Len(t, changed, 1)
+	assert.Empty(t, toBoot)
+	assert.Empty(t, toKill)
+}
+
+func TestRunsResources(t *testing.T) {
+	t.Parallel()
+
+	md, dk := docker.NewMock()
+	dbcs := []db.Container{{ID: 1, Image: "Image1", CPURequest: 1, CPULimit: 2,
+		MemoryRequest: 128, MemoryLimit: 256}}
+
+	runSync(dk, dbcs, nil)
+	dkcs, err := dk.List(nil)
+	assert.NoError(t, err)
+	assert.Len(t, dkcs, 1)
+	assert.Equal(t, "1/2,128/256", dkcs[0].Labels[resourcesKey])
+
+	hc := md.Containers[dkcs[0].ID].HostConfig
+	assert.Equal(t, int64(1024), hc.CPUShares)
+	assert.Equal(t, int64(256<<20), hc.Memory)
+}
+
+func TestRunsRestartPolicy(t *testing.T) {
+	t.Parallel()
+
+	md, dk := docker.NewMock()
+	dbcs := []db.Container{
+		{ID: 1, StitchID: "1", Image: "Image1", RestartPolicy: "on-failure",
+			MaxRetries: 3},
+		{ID: 2, StitchID: "2", Image: "Image2", RestartPolicy: "never"},
+		{ID: 3, StitchID: "3", Image: "Image3"},
+	}
+
+	runSync(dk, dbcs, nil)
+	dkcs, err := dk.List(nil)
+	assert.NoError(t, err)
+	assert.Len(t, dkcs, 3)
+
+	policies := map[string]string{}
+	labels := map[string]string{}
+	for _, c := range dkcs {
+		policy := md.Containers[c.ID].HostConfig.RestartPolicy
+		policies[c.Image] = fmt.Sprintf("%s:%d", policy.Name,
+			policy.MaximumRetryCount)
+		labels[c.Image] = c.Labels[restartKey]
+	}
+	assert.Equal(t, map[string]string{
+		"Image1": "on-failure:3",
+		"Image2": "no:0",
+		"Image3": "always:0",
+	}, policies)
+	assert.Equal(t, map[string]string{
+		"Image1": "on-failure:3",
+		"Image2": "never",
+		"Image3": "",
+	}, labels)
+
+	// Crashed containers are left for Docker to restart, and stopped containers
+	// aren't rerun, even if the database rows have lost their Docker IDs.
+	for _, c := range dkcs {
+		switch c.Image {
+		case "Image1":
+			md.Containers[c.ID].State.Status = "restarting"
+			md.Containers[c.ID].State.ExitCode = 2
+			md.Containers[c.ID].RestartCount = 1
+		case "Image2":
+			md.Containers[c.ID].State.Status = "exited"
+		}
+	}
+	dkcs, err = dk.List(nil)
+	assert.NoError(t, err)
+
+	changed, toBoot, toKill := syncWorker(dbcs, dkcs)
+	assert.Empty(t, toBoot)
+	assert.Empty(t, toKill)
+	for _, dbc := range changed {
+		if dbc.ID == 1 {
+			assert.Equal(t, crashLoopStatus, dbc.Status)
+			assert.Equal(t, 1, dbc.Restarts)
+			assert.Equal(t, 2, dbc.ExitCode)
+		}
+	}
+}
+
+func TestRunsSecrets(t *testing.T) {
+	t.Parallel()
+
+	md, dk := docker.NewMock()
+	keys := certs.KeyPair{Key: string(pem.EncodeToMemory(&pem.Block{
+		Type: "EC PRIVATE KEY", Bytes: []byte("key")}))}
+	sealed, err := keys.Seal("password")
+	assert.NoError(t, err)
+
+	dbcs := []db.Container{
+		{ID: 1, Image: "Image1", Env: map[string]string{"USER": "quilt"},
+			SecretEnv:   map[string]string{"PASSWORD": "pass"},
+			SecretFiles: map[string]string{"pass": "pass"}},
+		{ID: 2, Image: "Image2",
+			SecretEnv: map[string]string{"KEY": "missing"}},
+		{ID: 3, Image: "Image3",
+			SecretEnv: map[string]string{"KEY": "garbage"}},
+	}
+	dbSecrets := []db.Secret{
+		{Name: "pass", Value: sealed},
+		{Name: "garbage", Value: "garbage"},
+	}
+
+	_, toBoot, _ := syncWorker(dbcs, nil)
+	secrets := openSecrets(toBoot, dbSecrets, keys)
+	assert.Equal(t, map[string]string{"pass": "password"}, secrets)
+
+	// Containers with secrets that are missing or fail to open report which
+	// secret they're waiting for.
+	waiting, ready := waitForSecrets(toBoot, secrets)
+	assert.Equal(t, []interface{}{dbcs[0]}, ready)
+	statuses := map[int]string{}
+	for _, dbc := range waiting {
+		statuses[dbc.ID] = dbc.Status
+	}
+	assert.Equal(t, map[int]string{
+		2: `waiting for secret "missing"`,
+		3: `waiting for secret "garbage"`,
+	}, statuses)
+
+	// Their statuses are only changed once.
+	waiting, _ = waitForSecrets([]interface{}{waiting[0]}, secrets)
+	assert.Empty(t, waiting)
+
+	// Containers with secrets that are missing or fail to open aren't booted.
+	doContainers(dk, toBoot, func(dk docker.Client, iface interface{}) {
+		dockerRun(dk, iface, secrets)
+	})
+	dkcs, err := dk.List(nil)
+	assert.NoError(t, err)
+	assert.Len(t, dkcs, 1)
+
+	assert.Equal(t, map[string]string{"USER": "quilt", "PASSWORD": "password"},
+		dkcs[0].Env)
+	assert.Equal(t, filesHash(nil), dkcs[0].Labels[filesKey])
+	assert.Equal(t, secretsString(dbcs[0]), dkcs[0].Labels[secretsKey])
+	assert.Equal(t, map[docker.UploadToContainerOptions]struct{}{
+		{
+			ContainerID: dkcs[0].ID,
+			UploadPath:  ".",
+			TarPath:     "pass",
+			Contents:    "password",
+		}: {},
+	}, md.Uploads)
+
+	// The plaintext of the secrets isn't stored in the database containers.
+	assert.Equal(t, map[string]string{"USER": "quilt"}, dbcs[0].Env)
+	assert.Empty(t, dbcs[0].FilepathToContent)
+
+	_, toBoot, toKill := syncWorker(dbcs[:1], dkcs)
+	assert.Empty(t, toBoot)
+	assert.Empty(t, toKill)
+
+	// Containers are restarted when the secrets they refer to change.
+	dbcs[0].SecretEnv = map[string]string{"PASSWORD": "other"}
+	_, toBoot, toKill = syncWorker(dbcs[:1], dkcs)
+	assert.Len(t, toBoot, 1)
+	assert.Len(t, toKill, 1)
 }
 
 func TestOpenFlowContainers(t *testing.T) {
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/minion/pb"
 
@@ -19,7 +20,13 @@ type server struct {
 	db.Conn
 }
 
-func minionServerRun(conn db.Conn) {
+func minionServerRun(conn db.Conn, creds certs.Credentials) {
+	tlsOpt, err := creds.ServerOption()
+	if err != nil {
+		log.WithError(err).Error("Failed to load TLS credentials.")
+		return
+	}
+
 	var sock net.Listener
 	server := server{conn}
 	for {
@@ -34,7 +41,7 @@ func minionServerRun(conn db.Conn) {
 		time.Sleep(30 * time.Second)
 	}
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(tlsOpt)
 	pb.RegisterMinionServer(s, server)
 	s.Serve(sock)
 }
@@ -75,6 +82,7 @@ func (s server) SetMinionConfig(ctx context.Context,
 		minion.Size = msg.Size
 		minion.Region = msg.Region
 		minion.FloatingIP = msg.FloatingIP
+		minion.CPU, minion.Memory = capacity(msg.Provider, msg.Size)
 		minion.AuthorizedKeys = strings.Join(msg.AuthorizedKeys, "\n")
 		minion.Self = true
 		view.Commit(minion)
@@ -26,8 +26,8 @@ func TestSetMinionConfig(t *testing.T) {
 	cfg := pb.MinionConfig{
 		PrivateIP:      "priv",
 		Blueprint:      "blueprint",
-		Provider:       "provider",
-		Size:           "size",
+		Provider:       "Amazon",
+		Size:           "m4.large",
 		Region:         "region",
 		EtcdMembers:    []string{"etcd1", "etcd2"},
 		AuthorizedKeys: []string{"key1", "key2"},
@@ -36,10 +36,12 @@ func TestSetMinionConfig(t *testing.T) {
 		Self:           true,
 		Blueprint:      "blueprint",
 		PrivateIP:      "priv",
-		Provider:       "provider",
+		Provider:       "Amazon",
 		Role:           db.Master,
-		Size:           "size",
+		Size:           "m4.large",
 		Region:         "region",
+		CPU:            2,
+		Memory:         8192,
 		AuthorizedKeys: "key1\nkey2",
 	}
 	_, err := s.SetMinionConfig(nil, &cfg)
@@ -154,7 +154,7 @@ func scp(host string, source string, target string) error {
 }
 
 func queryMachines() ([]db.Machine, error) {
-	c, err := client.New(api.DefaultSocket)
+	c, err := client.Local()
 	if err != nil {
 		return []db.Machine{}, err
 	}
@@ -14,7 +14,6 @@ import (
 
 	"github.com/Sirupsen/logrus"
 
-	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/join"
@@ -306,7 +305,7 @@ func waitForContainers(blueprintPath string) error {
 		return err
 	}
 
-	c, err := client.New(api.DefaultSocket)
+	c, err := client.Local()
 	if err != nil {
 		return err
 	}
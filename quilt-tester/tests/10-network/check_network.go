@@ -11,7 +11,6 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 
-	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/join"
@@ -54,7 +53,7 @@ func (ct commandTime) String() string {
 }
 
 func main() {
-	clnt, err := client.New(api.DefaultSocket)
+	clnt, err := client.Local()
 	if err != nil {
 		log.WithError(err).Fatal("FAILED, couldn't get quiltctl client")
 	}
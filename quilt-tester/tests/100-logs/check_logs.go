@@ -8,7 +8,6 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 
-	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
 )
 
@@ -17,7 +16,7 @@ var machineRegex = regexp.MustCompile(`Machine-(\d+){(.+?), .*, PublicIP=(.*?),`
 func main() {
 	printQuiltPs()
 
-	c, err := client.New(api.DefaultSocket)
+	c, err := client.Local()
 	if err != nil {
 		log.WithError(err).Fatal("FAILED, couldn't get quiltctl client")
 	}
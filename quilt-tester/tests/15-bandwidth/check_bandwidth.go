@@ -11,7 +11,6 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 
-	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
 	"github.com/quilt/quilt/db"
 )
@@ -30,7 +29,7 @@ type testResult struct {
 }
 
 func main() {
-	clnt, err := client.New(api.DefaultSocket)
+	clnt, err := client.Local()
 	if err != nil {
 		log.WithError(err).Fatal("FAILED, couldn't get quiltctl client")
 	}
@@ -8,13 +8,12 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 
-	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
 	"github.com/quilt/quilt/util"
 )
 
 func main() {
-	clnt, err := client.New(api.DefaultSocket)
+	clnt, err := client.Local()
 	if err != nil {
 		log.WithError(err).Fatal("FAILED, couldn't get quiltctl client.")
 	}
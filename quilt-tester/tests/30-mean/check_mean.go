@@ -8,7 +8,6 @@ import (
 	"os/exec"
 	"strings"
 
-	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
 	"github.com/quilt/quilt/db"
 
@@ -23,7 +22,7 @@ type Response struct {
 }
 
 func main() {
-	clnt, err := client.New(api.DefaultSocket)
+	clnt, err := client.Local()
 	if err != nil {
 		log.WithError(err).Fatal("FAILED, couldn't get quiltctl client")
 	}
@@ -6,7 +6,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
 	"github.com/quilt/quilt/minion/supervisor/images"
 
@@ -21,7 +20,7 @@ func main() {
 	log.Info("Sleeping thirty seconds for `quilt stop -containers` to take effect")
 	time.Sleep(30 * time.Second)
 
-	c, err := client.New(api.DefaultSocket)
+	c, err := client.Local()
 	if err != nil {
 		log.WithError(err).Fatal("FAILED, couldn't get quiltctl client")
 	}
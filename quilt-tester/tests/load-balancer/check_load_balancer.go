@@ -6,7 +6,6 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 
-	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
 	"github.com/quilt/quilt/db"
 )
@@ -17,7 +16,7 @@ const (
 )
 
 func main() {
-	c, err := client.New(api.DefaultSocket)
+	c, err := client.Local()
 	if err != nil {
 		log.WithError(err).Fatal("FAILED, couldn't get local client")
 	}
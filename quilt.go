@@ -23,7 +23,8 @@ func main() {
 			"[-log-level=<level> | -l=<level>] [-H=<listen_address>] " +
 			"[log-file=<log_output_file>] " +
 			"[daemon | inspect <stitch> | run <stitch> | minion | " +
-			"stop <namespace> | ps | ssh <id> [command] | " +
+			"stop <namespace> | ps | history <table|id> | " +
+			"ssh <id> [command] | secret set <name> [value] | " +
 			"logs <container> | debug-logs <id...> | version]")
 		fmt.Println("\nWhen provided a stitch, quilt takes responsibility\n" +
 			"for deploying it as specified.  Alternatively, quilt may be\n" +
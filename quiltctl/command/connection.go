@@ -5,31 +5,50 @@ import (
 
 	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
+	"github.com/quilt/quilt/certs"
 )
 
 type connectionFlags struct {
-	host string
+	host   string
+	tlsDir string
 }
 
 func (cf *connectionFlags) InstallFlags(flags *flag.FlagSet) {
 	flags.StringVar(&cf.host, "H", api.DefaultSocket, "the host to connect to")
+	flags.StringVar(&cf.tlsDir, "tls-dir", certs.DefaultDir,
+		"the directory containing the daemon's TLS credentials")
 }
 
 type connectionHelper struct {
 	client client.Client
 
+	// The namespace targeted by the client, or "" to target the daemon's only
+	// namespace.
+	namespace string
+
 	connectionFlags
 }
 
+func (ch *connectionHelper) InstallFlags(flags *flag.FlagSet) {
+	ch.connectionFlags.InstallFlags(flags)
+	flags.StringVar(&ch.namespace, "namespace", "",
+		"the namespace to target, if the daemon manages several")
+}
+
 func (ch *connectionHelper) BeforeRun() error {
-	return ch.setupClient(client.New)
+	creds, err := certs.Load(ch.tlsDir, certs.Daemon)
+	if err != nil {
+		return err
+	}
+	return ch.setupClient(client.WithNamespace(ch.namespace), creds)
 }
 
 func (ch *connectionHelper) AfterRun() error {
 	return ch.client.Close()
 }
 
-func (ch *connectionHelper) setupClient(getter client.Getter) (err error) {
-	ch.client, err = getter(ch.host)
+func (ch *connectionHelper) setupClient(getter client.Getter,
+	creds certs.Credentials) (err error) {
+	ch.client, err = getter(ch.host, creds)
 	return err
 }
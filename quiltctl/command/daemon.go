@@ -5,9 +5,11 @@ import (
 	"fmt"
 
 	"github.com/quilt/quilt/api/server"
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/cluster"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/engine"
+	"github.com/quilt/quilt/metrics"
 	"github.com/quilt/quilt/version"
 
 	log "github.com/Sirupsen/logrus"
@@ -15,6 +17,11 @@ import (
 
 // Daemon contains the options for running the Quilt daemon.
 type Daemon struct {
+	dbDir       string
+	historySize int
+	httpAddr    string
+	metricsAddr string
+
 	*connectionFlags
 }
 
@@ -28,10 +35,27 @@ func NewDaemonCommand() *Daemon {
 // InstallFlags sets up parsing for command line flags
 func (dCmd *Daemon) InstallFlags(flags *flag.FlagSet) {
 	dCmd.connectionFlags.InstallFlags(flags)
+	flags.StringVar(&dCmd.dbDir, "db-dir", "",
+		"the directory in which to persist the database across restarts")
+	flags.IntVar(&dCmd.historySize, "history-size", 100,
+		"the number of changes to remember per database table for "+
+			"`quilt history`, or 0 to disable")
+	flags.StringVar(&dCmd.httpAddr, "http-addr", "",
+		"the address at which to serve the API as JSON over HTTPS, e.g. "+
+			"localhost:9001, or empty to disable")
+	flags.StringVar(&dCmd.metricsAddr, "metrics-addr", "",
+		"the address at which to serve Prometheus metrics over HTTPS, e.g. "+
+			":9002, or empty to disable")
+
 	flags.Usage = func() {
-		fmt.Println("usage: quilt daemon [-H=<daemon_host>]")
+		fmt.Println("usage: quilt daemon [-H=<daemon_host>] [-db-dir=<dir>] " +
+			"[-history-size=<n>] [-tls-dir=<dir>] [-http-addr=<addr>] " +
+			"[-metrics-addr=<addr>]")
 		fmt.Println("`daemon` starts the quilt daemon, which listens for " +
 			"quilt API requests")
+		fmt.Println("The daemon generates a certificate authority in " +
+			"`tls-dir` if there isn't one, and requires all API " +
+			"clients to present a certificate signed by it.")
 
 		flags.PrintDefaults()
 	}
@@ -55,9 +79,59 @@ func (dCmd *Daemon) AfterRun() error {
 // Run starts the daemon.
 func (dCmd *Daemon) Run() int {
 	log.WithField("version", version.Version).Info("Starting Quilt daemon")
-	conn := db.New()
-	go engine.Run(conn)
-	go server.Run(conn, dCmd.host, true)
-	cluster.Run(conn)
+
+	var conn db.Conn
+	if dCmd.dbDir == "" {
+		conn = db.New()
+	} else {
+		var err error
+		conn, err = db.NewPersistent(dCmd.dbDir)
+		if err != nil {
+			log.WithError(err).Error("Failed to open the database.")
+			return 1
+		}
+	}
+
+	conn.EnableHistory(dCmd.historySize)
+
+	if err := certs.Setup(dCmd.tlsDir); err != nil {
+		log.WithError(err).Error("Failed to create TLS credentials.")
+		return 1
+	}
+
+	creds, err := certs.Load(dCmd.tlsDir, certs.Daemon)
+	if err != nil {
+		log.WithError(err).Error("Failed to load TLS credentials.")
+		return 1
+	}
+
+	minionCreds, err := certs.Load(dCmd.tlsDir, certs.Minion)
+	if err != nil {
+		log.WithError(err).Error("Failed to load TLS credentials.")
+		return 1
+	}
+
+	go engine.Run(conn.WithTag("engine"))
+	go server.Run(conn.WithTag("api"), dCmd.host, true, creds, nil)
+	if dCmd.httpAddr != "" {
+		go runGateway(conn.WithTag("api"), dCmd.httpAddr, creds)
+	}
+	if dCmd.metricsAddr != "" {
+		go runMetrics(conn.WithTag("metrics"), dCmd.metricsAddr, creds)
+	}
+	cluster.Run(conn.WithTag("cluster"), creds, minionCreds)
 	return 0
 }
+
+func runGateway(conn db.Conn, addr string, creds certs.Credentials) {
+	err := server.RunGateway(conn, addr, true, creds)
+	log.WithError(err).Error("HTTP gateway stopped")
+}
+
+func runMetrics(conn db.Conn, addr string, creds certs.Credentials) {
+	tlsConfig, err := creds.ServerConfig()
+	if err == nil {
+		err = metrics.Run(addr, tlsConfig, conn.Collector())
+	}
+	log.WithError(err).Error("Metrics server stopped")
+}
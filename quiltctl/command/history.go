@@ -0,0 +1,171 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/quilt/quilt/db"
+)
+
+// History contains the options for querying the history of the database.
+type History struct {
+	table db.TableType
+	id    int
+
+	connectionHelper
+}
+
+// NewHistoryCommand creates a new History command instance.
+func NewHistoryCommand() *History {
+	return &History{}
+}
+
+var historyUsage = `usage: quilt history [-H=<daemon_host>] <table> [id]
+   or: quilt history [-H=<daemon_host>] <id>
+
+Show how the rows of a database table, or a single row, changed over time.
+Each change is attributed to the Quilt module that made it.  A row given only by
+its ID is looked up in the daemon's tables, and then in the cluster's.  As the
+daemon and the cluster number their rows separately, give the table of rows that
+the cluster tracks, such as containers and labels, to avoid ambiguity.
+
+To show the history of all machines:
+quilt history machine
+
+To show the history of the container with database ID 12:
+quilt history container 12
+`
+
+// InstallFlags sets up parsing for command line flags.
+func (hCmd *History) InstallFlags(flags *flag.FlagSet) {
+	hCmd.connectionHelper.InstallFlags(flags)
+	flags.Usage = func() {
+		fmt.Println(historyUsage)
+		flags.PrintDefaults()
+	}
+}
+
+// Parse parses the command line arguments for the history command.
+func (hCmd *History) Parse(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New("must specify a table or row ID")
+	}
+
+	if id, err := strconv.Atoi(args[0]); err == nil && len(args) == 1 {
+		hCmd.id = id
+		return nil
+	}
+
+	table, err := parseTable(args[0])
+	if err != nil {
+		return err
+	}
+	hCmd.table = table
+
+	if len(args) == 2 {
+		if hCmd.id, err = strconv.Atoi(args[1]); err != nil {
+			return fmt.Errorf("malformed row ID: %s", args[1])
+		}
+	}
+	return nil
+}
+
+// Run retrieves and prints the requested history.
+func (hCmd *History) Run() int {
+	revs, err := hCmd.client.QueryHistory(hCmd.table, hCmd.id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to query history: %s\n", err)
+		return 1
+	}
+
+	writeHistory(os.Stdout, revs)
+	return 0
+}
+
+// parseTable matches 'name' against the database tables without regard to case,
+// e.g. "container" matches db.ContainerTable.
+func parseTable(name string) (db.TableType, error) {
+	for _, table := range db.AllTables {
+		tableName := strings.TrimPrefix(string(table), "db.")
+		if strings.EqualFold(tableName, strings.TrimPrefix(name, "db.")) {
+			return table, nil
+		}
+	}
+	return "", fmt.Errorf("unrecognized table: %s", name)
+}
+
+func writeHistory(fd io.Writer, revs []db.Revision) {
+	w := tabwriter.NewWriter(fd, 0, 0, 4, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "TIME\tMODULE\tTABLE\tID\tCHANGE")
+
+	for _, rev := range revs {
+		tag := rev.Tag
+		if tag == "" {
+			tag = "-"
+		}
+
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n",
+			rev.Time.Local().Format(time.Stamp), tag,
+			strings.TrimPrefix(string(rev.Table), "db."), rev.ID,
+			changeStr(rev.Old, rev.New))
+	}
+}
+
+// changeStr summarizes the change from 'old' to 'new'.  Modifications list the
+// fields that changed.
+func changeStr(old, new interface{}) string {
+	switch {
+	case old == nil:
+		return "inserted"
+	case new == nil:
+		return "removed"
+	}
+
+	oldFields, _ := old.(map[string]interface{})
+	newFields, _ := new.(map[string]interface{})
+
+	var names []string
+	for name := range newFields {
+		names = append(names, name)
+	}
+	for name := range oldFields {
+		if _, ok := newFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var diffs []string
+	for _, name := range names {
+		if reflect.DeepEqual(oldFields[name], newFields[name]) {
+			continue
+		}
+
+		diffs = append(diffs, fmt.Sprintf("%s: %s -> %s", name,
+			fieldStr(oldFields[name]), fieldStr(newFields[name])))
+	}
+
+	if len(diffs) == 0 {
+		return "modified"
+	}
+	return strings.Join(diffs, ", ")
+}
+
+func fieldStr(val interface{}) string {
+	str, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return string(str)
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quilt/quilt/api/client/mocks"
+	"github.com/quilt/quilt/db"
+)
+
+func TestHistoryParse(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewHistoryCommand()
+	assert.NoError(t, parseHelper(cmd, []string{"container", "12"}))
+	assert.Equal(t, db.ContainerTable, cmd.table)
+	assert.Equal(t, 12, cmd.id)
+
+	cmd = NewHistoryCommand()
+	assert.NoError(t, parseHelper(cmd, []string{"db.Machine"}))
+	assert.Equal(t, db.MachineTable, cmd.table)
+	assert.Zero(t, cmd.id)
+
+	cmd = NewHistoryCommand()
+	assert.NoError(t, parseHelper(cmd, []string{"7"}))
+	assert.Equal(t, db.TableType(""), cmd.table)
+	assert.Equal(t, 7, cmd.id)
+
+	assert.EqualError(t, parseHelper(NewHistoryCommand(), []string{"foo"}),
+		"unrecognized table: foo")
+	assert.EqualError(t, parseHelper(NewHistoryCommand(), []string{"label", "a"}),
+		"malformed row ID: a")
+	assert.Error(t, parseHelper(NewHistoryCommand(), nil))
+}
+
+func TestHistoryRun(t *testing.T) {
+	t.Parallel()
+
+	mockClient := new(mocks.Client)
+	mockClient.On("QueryHistory", db.MachineTable, 0).Return(nil, nil).Once()
+	mockClient.On("QueryHistory", db.MachineTable, 0).Return(nil, assert.AnError)
+
+	cmd := &History{table: db.MachineTable,
+		connectionHelper: connectionHelper{client: mockClient}}
+	assert.Zero(t, cmd.Run())
+	assert.NotZero(t, cmd.Run())
+}
+
+func TestWriteHistory(t *testing.T) {
+	t.Parallel()
+
+	when := time.Date(2017, time.March, 2, 10, 30, 0, 0, time.Local)
+	old := map[string]interface{}{"PublicIP": "", "Size": "m4.large"}
+	new := map[string]interface{}{"PublicIP": "1.2.3.4", "Size": "m4.large"}
+	revs := []db.Revision{
+		{Time: when, Tag: "engine", Table: db.MachineTable, ID: 3, New: old},
+		{Time: when, Tag: "cluster", Table: db.MachineTable, ID: 3,
+			Old: old, New: new},
+		{Time: when, Table: db.MachineTable, ID: 3, Old: new},
+	}
+
+	var b bytes.Buffer
+	writeHistory(&b, revs)
+
+	exp := "TIME               MODULE     TABLE      ID    CHANGE\n" +
+		"Mar  2 10:30:00    engine     Machine    3     inserted\n" +
+		`Mar  2 10:30:00    cluster    Machine    3     PublicIP: "" -> "1.2.3.4"` +
+		"\n" +
+		"Mar  2 10:30:00    -          Machine    3     removed\n"
+	assert.Equal(t, exp, b.String())
+}
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"os"
 	"strings"
 
+	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/quiltctl/ssh"
 
 	log "github.com/Sirupsen/logrus"
@@ -17,6 +19,7 @@ type Log struct {
 	sinceTimestamp string
 	showTimestamps bool
 	shouldTail     bool
+	useAPI         bool
 
 	target string
 
@@ -40,6 +43,9 @@ quilt logs -i ~/.ssh/quilt 8879fd2dbcee
 
 To follow the logs of the minion on machine 09ed35808a0b:
 quilt logs -f 09ed35808a0b
+
+To get the logs of container 8879fd2dbcee through the Quilt API, without SSH:
+quilt logs -api 8879fd2dbcee
 `
 
 // InstallFlags sets up parsing for command line flags.
@@ -51,6 +57,8 @@ func (lCmd *Log) InstallFlags(flags *flag.FlagSet) {
 	flags.StringVar(&lCmd.sinceTimestamp, "since", "", "show logs since timestamp")
 	flags.BoolVar(&lCmd.shouldTail, "f", false, "follow log output")
 	flags.BoolVar(&lCmd.showTimestamps, "t", false, "show timestamps")
+	flags.BoolVar(&lCmd.useAPI, "api", false,
+		"fetch container logs through the Quilt API rather than SSH")
 
 	flags.Usage = func() {
 		fmt.Println(logsUsage)
@@ -96,6 +104,10 @@ func (lCmd *Log) Run() int {
 		return 1
 	}
 
+	if lCmd.useAPI {
+		return lCmd.apiLogs(resolvedContainer, cont.StitchID)
+	}
+
 	cmd := []string{"docker", "logs"}
 	if lCmd.sinceTimestamp != "" {
 		cmd = append(cmd, fmt.Sprintf("--since=%s", lCmd.sinceTimestamp))
@@ -129,3 +141,25 @@ func (lCmd *Log) Run() int {
 
 	return 0
 }
+
+// apiLogs outputs the logs of the container with the given StitchID, which are
+// fetched through the Quilt API.
+func (lCmd *Log) apiLogs(resolvedContainer bool, stitchID string) int {
+	if !resolvedContainer {
+		log.Error("Only container logs can be fetched through the API")
+		return 1
+	}
+
+	err := lCmd.client.Logs(stitchID, api.LogsOptions{
+		Follow:     lCmd.shouldTail,
+		Timestamps: lCmd.showTimestamps,
+		Since:      lCmd.sinceTimestamp,
+		Stdout:     os.Stdout,
+		Stderr:     os.Stderr,
+	}, nil)
+	if err != nil {
+		log.WithError(err).Error("Error fetching logs")
+		return 1
+	}
+	return 0
+}
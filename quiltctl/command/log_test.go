@@ -5,7 +5,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
+	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client/mocks"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/quiltctl/ssh"
@@ -105,14 +107,16 @@ func TestLog(t *testing.T) {
 	}
 
 	mockLocalClient := new(mocks.Client)
-	mockLocalClient.On("QueryMachines").Return([]db.Machine{{
+	mockLocalClient.On("Query", db.MachineTable, mock.Anything,
+		mock.Anything).Return([]db.Machine{{
 		StitchID: targetMachine,
 		PublicIP: "machine",
 	}, {
 		PublicIP:  "container",
 		PrivateIP: "containerPriv",
 	}}, nil)
-	mockLocalClient.On("QueryContainers").Return([]db.Container{{
+	mockLocalClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return([]db.Container{{
 		StitchID: targetContainer,
 		DockerID: "foo",
 		Minion:   "containerPriv",
@@ -142,10 +146,12 @@ func TestLog(t *testing.T) {
 
 func TestLogAmbiguousID(t *testing.T) {
 	mockClient := new(mocks.Client)
-	mockClient.On("QueryMachines").Return([]db.Machine{{
+	mockClient.On("Query", db.MachineTable, mock.Anything,
+		mock.Anything).Return([]db.Machine{{
 		StitchID: "foo",
 	}}, nil)
-	mockClient.On("QueryContainers").Return([]db.Container{{
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return([]db.Container{{
 		StitchID: "foo",
 	}}, nil)
 	mockClient.On("Close").Return(nil)
@@ -159,10 +165,12 @@ func TestLogAmbiguousID(t *testing.T) {
 
 func TestLogNoMatch(t *testing.T) {
 	mockClient := new(mocks.Client)
-	mockClient.On("QueryMachines").Return([]db.Machine{{
+	mockClient.On("Query", db.MachineTable, mock.Anything,
+		mock.Anything).Return([]db.Machine{{
 		StitchID: "foo",
 	}}, nil)
-	mockClient.On("QueryContainers").Return([]db.Container{{
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return([]db.Container{{
 		StitchID: "foo",
 	}}, nil)
 	mockClient.On("Close").Return(nil)
@@ -176,10 +184,12 @@ func TestLogNoMatch(t *testing.T) {
 
 func TestLogScheduledContainer(t *testing.T) {
 	mockClient := new(mocks.Client)
-	mockClient.On("QueryContainers").Return([]db.Container{{
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return([]db.Container{{
 		StitchID: "foo",
 	}}, nil)
-	mockClient.On("QueryMachines").Return(nil, nil)
+	mockClient.On("Query", db.MachineTable, mock.Anything,
+		mock.Anything).Return(nil, nil)
 	mockClient.On("Close").Return(nil)
 
 	testCmd := Log{
@@ -188,3 +198,48 @@ func TestLogScheduledContainer(t *testing.T) {
 	}
 	assert.Equal(t, 1, testCmd.Run())
 }
+
+func TestLogAPI(t *testing.T) {
+	mockClient := new(mocks.Client)
+	mockClient.On("Query", db.MachineTable, mock.Anything,
+		mock.Anything).Return([]db.Machine{{
+		PrivateIP: "priv",
+		PublicIP:  "host",
+	}}, nil)
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return([]db.Container{{
+		StitchID: "foo",
+		DockerID: "dockerID",
+		Minion:   "priv",
+	}}, nil)
+	mockClient.On("Logs", "foo", mock.Anything, mock.Anything).Return(nil)
+
+	testCmd := Log{
+		connectionHelper: connectionHelper{client: mockClient},
+		target:           "foo",
+		shouldTail:       true,
+		sinceTimestamp:   "10m",
+		useAPI:           true,
+	}
+	assert.Equal(t, 0, testCmd.Run())
+
+	call := mockClient.Calls[len(mockClient.Calls)-1]
+	opts := call.Arguments.Get(1).(api.LogsOptions)
+	assert.True(t, opts.Follow)
+	assert.Equal(t, "10m", opts.Since)
+
+	mockClient = new(mocks.Client)
+	mockClient.On("Query", db.MachineTable, mock.Anything,
+		mock.Anything).Return([]db.Machine{{PrivateIP: "priv"}}, nil)
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return([]db.Container{{
+		StitchID: "foo",
+		DockerID: "dockerID",
+		Minion:   "priv",
+	}}, nil)
+	mockClient.On("Logs", "foo", mock.Anything, mock.Anything).Return(
+		errors.New("logs error"))
+
+	testCmd.connectionHelper = connectionHelper{client: mockClient}
+	assert.Equal(t, 1, testCmd.Run())
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/quilt/quilt/certs"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/minion"
 	"github.com/quilt/quilt/version"
@@ -19,6 +20,7 @@ import (
 type Minion struct {
 	role                            string
 	inboundPubIntf, outboundPubIntf string
+	tlsDir                          string
 }
 
 // NewMinionCommand creates a new Minion command instance.
@@ -33,6 +35,8 @@ func (mCmd *Minion) InstallFlags(flags *flag.FlagSet) {
 		"the interface on which to allow inbound traffic")
 	flags.StringVar(&mCmd.outboundPubIntf, "outbound-pub-intf", "",
 		"the interface on which to allow outbound traffic")
+	flags.StringVar(&mCmd.tlsDir, "tls-dir", certs.MinionDir,
+		"the directory containing the minion's TLS credentials")
 
 	flags.Usage = func() {
 		fmt.Println("usage: quilt minion [-role=<role>]")
@@ -73,6 +77,11 @@ func (mCmd *Minion) run() error {
 		return errors.New("no or improper role specified")
 	}
 
-	minion.Run(role, mCmd.inboundPubIntf, mCmd.outboundPubIntf)
+	creds, err := certs.Load(mCmd.tlsDir, certs.Minion)
+	if err != nil {
+		return err
+	}
+
+	minion.Run(role, mCmd.inboundPubIntf, mCmd.outboundPubIntf, creds)
 	return nil
 }
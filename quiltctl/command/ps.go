@@ -11,6 +11,7 @@ import (
 	"time"
 
 	units "github.com/docker/go-units"
+	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/stitch"
 	"github.com/quilt/quilt/util"
@@ -19,9 +20,17 @@ import (
 // An arbitrary length to truncate container commands to.
 const truncLength = 30
 
+// The ANSI escape sequence that clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// The container fields displayed by `quilt ps`.
+var psContainerFields = []string{"StitchID", "Minion", "Image", "Command", "Labels",
+	"Status", "Health", "Restarts", "ExitCode", "Created"}
+
 // Ps contains the options for querying machines and containers.
 type Ps struct {
 	noTruncate bool
+	watch      bool
 
 	connectionHelper
 }
@@ -36,8 +45,11 @@ func (pCmd *Ps) InstallFlags(flags *flag.FlagSet) {
 	pCmd.connectionHelper.InstallFlags(flags)
 	flags.BoolVar(&pCmd.noTruncate, "no-trunc", false, "do not truncate container"+
 		" command output")
+	flags.BoolVar(&pCmd.watch, "w", false, "continuously update the output as"+
+		" machines and containers change")
 	flags.Usage = func() {
-		fmt.Println("usage: quilt ps [-H=<daemon_host>]")
+		fmt.Println("usage: quilt ps [-H=<daemon_host>] " +
+			"[-namespace=<namespace>] [-w]")
 		fmt.Println("`ps` displays the status of quilt-managed " +
 			"machines and containers.")
 
@@ -52,14 +64,19 @@ func (pCmd *Ps) Parse(args []string) error {
 
 // Run retrieves and prints all machines and containers.
 func (pCmd *Ps) Run() int {
-	if err := pCmd.run(); err != nil {
+	run := pCmd.run
+	if pCmd.watch {
+		run = func() error { return pCmd.runWatch(os.Stdout) }
+	}
+
+	if err := run(); err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
 		return 1
 	}
 	return 0
 }
 
-func (pCmd *Ps) run() (err error) {
+func (pCmd *Ps) run() error {
 	var connections []db.Connection
 	var containers []db.Container
 	var machines []db.Machine
@@ -69,17 +86,24 @@ func (pCmd *Ps) run() (err error) {
 	machineErr := make(chan error)
 
 	go func() {
+		var err error
 		machines, err = pCmd.client.QueryMachines()
 		machineErr <- err
 	}()
 
+	// Only public connections are displayed, and only the container fields
+	// written by writeContainers are needed, so let the daemon drop the rest.
 	go func() {
-		connections, err = pCmd.client.QueryConnections()
+		rows, err := pCmd.client.Query(db.ConnectionTable,
+			[]api.Filter{api.Equal("From", stitch.PublicInternetLabel)},
+			[]string{"From", "To", "MinPort", "MaxPort", "ContainerPort"})
+		connections, _ = rows.([]db.Connection)
 		connectionErr <- err
 	}()
 
 	go func() {
-		containers, err = pCmd.client.QueryContainers()
+		rows, err := pCmd.client.Query(db.ContainerTable, nil, psContainerFields)
+		containers, _ = rows.([]db.Container)
 		containerErr <- err
 	}()
 
@@ -102,6 +126,52 @@ func (pCmd *Ps) run() (err error) {
 	return nil
 }
 
+// runWatch redraws the machines and containers each time they change.
+func (pCmd *Ps) runWatch(fd io.Writer) error {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	updates := make(chan interface{})
+	watchErr := make(chan error, 3)
+	for _, table := range []db.TableType{
+		db.MachineTable, db.ConnectionTable, db.ContainerTable} {
+
+		go func(table db.TableType) {
+			update := func(rows interface{}) {
+				select {
+				case updates <- rows:
+				case <-stop:
+				}
+			}
+			watchErr <- pCmd.client.Watch(table, stop, update)
+		}(table)
+	}
+
+	var connections []db.Connection
+	var containers []db.Container
+	var machines []db.Machine
+	for {
+		select {
+		case rows := <-updates:
+			switch rows := rows.(type) {
+			case []db.Machine:
+				machines = rows
+			case []db.Connection:
+				connections = rows
+			case []db.Container:
+				containers = rows
+			}
+		case err := <-watchErr:
+			return fmt.Errorf("unable to watch the cluster: %s", err)
+		}
+
+		fmt.Fprint(fd, clearScreen)
+		writeMachines(fd, machines)
+		fmt.Fprintln(fd)
+		writeContainers(fd, containers, machines, connections, !pCmd.noTruncate)
+	}
+}
+
 func writeMachines(fd io.Writer, machines []db.Machine) {
 	w := tabwriter.NewWriter(fd, 0, 0, 4, ' ', 0)
 	defer w.Flush()
@@ -136,6 +206,9 @@ func writeContainers(fd io.Writer, containers []db.Container, machines []db.Mach
 		if c.MinPort != c.MaxPort {
 			portStr += fmt.Sprintf("-%d", c.MaxPort)
 		}
+		if c.ContainerPort != 0 {
+			portStr += fmt.Sprintf("->%d", c.ContainerPort)
+		}
 		labelPublicPorts[c.To] = append(labelPublicPorts[c.To], portStr)
 	}
 
@@ -183,6 +256,23 @@ func writeContainers(fd io.Writer, containers []db.Container, machines []db.Mach
 				status = "scheduled"
 			}
 
+			var notes []string
+			if dbc.Health != "" {
+				notes = append(notes, dbc.Health)
+			}
+			if dbc.Restarts > 0 {
+				notes = append(notes, fmt.Sprintf("%d restarts",
+					dbc.Restarts))
+			}
+			if dbc.Status != "running" && dbc.ExitCode != 0 {
+				notes = append(notes, fmt.Sprintf("exit code %d",
+					dbc.ExitCode))
+			}
+			if len(notes) > 0 {
+				status = fmt.Sprintf("%s (%s)", status,
+					strings.Join(notes, ", "))
+			}
+
 			created := ""
 			if !dbc.Created.IsZero() {
 				createdTime := dbc.Created.Local()
@@ -10,6 +10,7 @@ import (
 
 	units "github.com/docker/go-units"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"github.com/quilt/quilt/api/client/mocks"
 	"github.com/quilt/quilt/db"
@@ -40,18 +41,22 @@ func TestPsErrors(t *testing.T) {
 
 	// Error querying containers
 	mockClient := new(mocks.Client)
-	mockClient.On("QueryConnections").Return(nil, nil)
+	mockClient.On("Query", db.ConnectionTable, mock.Anything,
+		mock.Anything).Return(nil, nil)
 	mockClient.On("QueryMachines").Return(nil, nil)
-	mockClient.On("QueryContainers").Return(nil, mockErr)
-	cmd := &Ps{false, connectionHelper{client: mockClient}}
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return(nil, mockErr)
+	cmd := &Ps{connectionHelper: connectionHelper{client: mockClient}}
 	assert.EqualError(t, cmd.run(), "unable to query containers: error")
 
 	// Error querying connections from LeaderClient
 	mockClient = new(mocks.Client)
-	mockClient.On("QueryContainers").Return(nil, nil)
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return(nil, nil)
 	mockClient.On("QueryMachines").Return(nil, nil)
-	mockClient.On("QueryConnections").Return(nil, mockErr)
-	cmd = &Ps{false, connectionHelper{client: mockClient}}
+	mockClient.On("Query", db.ConnectionTable, mock.Anything,
+		mock.Anything).Return(nil, mockErr)
+	cmd = &Ps{connectionHelper: connectionHelper{client: mockClient}}
 	assert.EqualError(t, cmd.run(), "unable to query connections: error")
 }
 
@@ -59,13 +64,38 @@ func TestPsSuccess(t *testing.T) {
 	t.Parallel()
 
 	mockClient := new(mocks.Client)
-	mockClient.On("QueryContainers").Return(nil, nil)
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return(nil, nil)
 	mockClient.On("QueryMachines").Return(nil, nil)
-	mockClient.On("QueryConnections").Return(nil, nil)
-	cmd := &Ps{false, connectionHelper{client: mockClient}}
+	mockClient.On("Query", db.ConnectionTable, mock.Anything,
+		mock.Anything).Return(nil, nil)
+	cmd := &Ps{connectionHelper: connectionHelper{client: mockClient}}
 	assert.Equal(t, 0, cmd.Run())
 }
 
+func TestPsWatch(t *testing.T) {
+	t.Parallel()
+
+	machines := []db.Machine{{StitchID: "1", Role: db.Master, PublicIP: "8.8.8.8"}}
+	sendMachines := func(args mock.Arguments) {
+		args.Get(2).(func(interface{}))(machines)
+	}
+
+	mockClient := new(mocks.Client)
+	mockClient.On("Watch", db.MachineTable, mock.Anything, mock.Anything).
+		Run(sendMachines).Return(assert.AnError)
+	mockClient.On("Watch", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			<-args.Get(1).(<-chan struct{})
+		}).Return(nil)
+
+	var b bytes.Buffer
+	cmd := &Ps{watch: true, connectionHelper: connectionHelper{client: mockClient}}
+	assert.EqualError(t, cmd.runWatch(&b), "unable to watch the cluster: "+
+		assert.AnError.Error())
+	assert.Contains(t, b.String(), "8.8.8.8")
+}
+
 func TestMachineOutput(t *testing.T) {
 	t.Parallel()
 
@@ -149,6 +179,29 @@ ________________________________________________________________________________
 `
 	checkContainerOutput(t, containers, machines, connections, true, expected)
 
+	// Health is shown alongside the status.
+	containers = []db.Container{
+		{ID: 1, StitchID: "3", Minion: "3.3.3.3", Image: "image1",
+			Status: "running", Health: db.ContainerUnhealthy},
+	}
+	expected = `CONTAINER____MACHINE____COMMAND____LABELS____STATUS_____` +
+		`____________CREATED____PUBLIC_IP
+3_______________________image1_______________running_(unhealthy)_______________
+`
+	checkContainerOutput(t, containers, nil, nil, true, expected)
+
+	// Crashing containers show their restarts and last exit code.
+	containers = []db.Container{
+		{ID: 1, StitchID: "3", Minion: "3.3.3.3", Image: "image1",
+			Status: "CrashLoop", Restarts: 4, ExitCode: 1},
+	}
+	expected = `CONTAINER____MACHINE____COMMAND____LABELS____STATUS_____` +
+		`____________________________CREATED____PUBLIC_IP
+3_______________________image1_______________` +
+		`CrashLoop_(4_restarts,_exit_code_1)_______________
+`
+	checkContainerOutput(t, containers, nil, nil, true, expected)
+
 	// Testing writeContainers with created time values.
 	mockTime := time.Now()
 	humanDuration := units.HumanDuration(time.Since(mockTime))
@@ -230,12 +283,14 @@ ________________________________________________________________________________
 	connections = []db.Connection{
 		{ID: 1, From: "public", To: "red", MinPort: 80, MaxPort: 80},
 		{ID: 2, From: "public", To: "red", MinPort: 100, MaxPort: 101},
+		{ID: 3, From: "public", To: "red", MinPort: 8080, MaxPort: 8080,
+			ContainerPort: 80},
 	}
 
 	expected = `CONTAINER____MACHINE____COMMAND____LABELS____STATUS` +
 		`_______CREATED____PUBLIC_IP
 3____________5__________image1_____red_______scheduled` +
-		`_______________7.7.7.7:[80,100-101]
+		`_______________7.7.7.7:[80,100-101,8080->80]
 `
 	checkContainerOutput(t, containers, machines, connections, true, expected)
 }
@@ -10,19 +10,24 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/fatih/color"
 	"github.com/pmezard/go-difflib/difflib"
 
+	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
+	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/stitch"
 )
 
 // Run contains the options for running Stitches.
 type Run struct {
-	stitch string
-	force  bool
+	stitch  string
+	force   bool
+	wait    bool
+	timeout time.Duration
 
 	connectionHelper
 }
@@ -38,15 +43,26 @@ func (rCmd *Run) InstallFlags(flags *flag.FlagSet) {
 
 	flags.StringVar(&rCmd.stitch, "stitch", "", "the stitch to run")
 	flags.BoolVar(&rCmd.force, "f", false, "deploy without confirming changes")
+	flags.BoolVar(&rCmd.wait, "wait", false,
+		"block until the cluster has converged to the stitch")
+	flags.DurationVar(&rCmd.timeout, "timeout", 0,
+		"with -wait, give up after this long (e.g. 10m); 0 waits forever")
 
 	flags.Usage = func() {
-		fmt.Println("usage: quilt run [-H=<daemon_host>] [-f] " +
+		fmt.Println("usage: quilt run [-H=<daemon_host>] " +
+			"[-namespace=<namespace>] [-f] [-wait [-timeout=<duration>]] " +
 			"[-stitch=<stitch>] <stitch>")
 		fmt.Println("`run` compiles the provided stitch, and sends the " +
 			"result to the Quilt daemon to be executed. Confirmation is " +
 			"required if deploying the stitch would cause changes to an " +
 			"existing cluster. Confirmation can be skipped with the " +
-			"`-f` flag.")
+			"`-f` flag. The stitch is deployed to its own namespace, " +
+			"unless `-namespace` overrides it. With `-wait`, `run` " +
+			"blocks until every machine is connected and every " +
+			"container is running, and exits with an error listing " +
+			"what is stuck if `-timeout` elapses first. YAML (.yml, " +
+			".yaml) and JSON (.json) blueprints are loaded without " +
+			"Node.js.")
 		flags.PrintDefaults()
 	}
 }
@@ -74,9 +90,16 @@ func (rCmd *Run) Run() int {
 		log.Error(err)
 		return 1
 	}
+	if err := compiled.Validate(); err != nil {
+		log.Error(err)
+		return 1
+	}
+	if rCmd.namespace != "" {
+		compiled.Namespace = rCmd.namespace
+	}
 	deployment := compiled.String()
 
-	curr, err := getCurrentDeployment(rCmd.client)
+	curr, err := getCurrentDeployment(rCmd.client, compiled.Namespace)
 	if err != nil && err != errNoCluster {
 		log.WithError(err).Error("Unable to get current deployment.")
 		return 1
@@ -106,28 +129,92 @@ func (rCmd *Run) Run() int {
 		}
 	}
 
-	err = rCmd.client.Deploy(deployment)
+	id, err := rCmd.client.Deploy(deployment)
 	if err != nil {
 		log.WithError(err).Error("Error while starting run.")
 		return 1
 	}
 
-	log.Debug("Successfully started run")
+	log.WithField("id", id).Debug("Successfully started run")
+	if rCmd.wait {
+		return rCmd.waitForConvergence(id)
+	}
 	return 0
 }
 
-func getCurrentDeployment(c client.Client) (stitch.Stitch, error) {
+// The interval at which `run -wait` polls the deployment's status.  Saved in a
+// variable so that it can be shortened for unit testing.
+var waitPollInterval = 5 * time.Second
+
+// waitForConvergence blocks until the deployment with the given ID has converged,
+// printing its progress as it changes.
+func (rCmd *Run) waitForConvergence(id string) int {
+	var deadline <-chan time.Time
+	if rCmd.timeout > 0 {
+		deadline = time.After(rCmd.timeout)
+	}
+
+	tick := time.NewTicker(waitPollInterval)
+	defer tick.Stop()
+
+	var lastSummary string
+	var status api.DeploymentStatus
+	for {
+		newStatus, err := rCmd.client.DeploymentStatus(id)
+		switch {
+		case err == client.ErrDeploymentReplaced:
+			log.Error("Deployment was replaced before it converged.")
+			return 1
+		case err != nil:
+			log.WithError(err).Debug("Failed to get deployment status")
+		default:
+			status = newStatus
+			if summary := status.String(); summary != lastSummary {
+				fmt.Println(summary)
+				lastSummary = summary
+			}
+
+			if status.Converged {
+				fmt.Println("Deployment converged.")
+				return 0
+			}
+		}
+
+		select {
+		case <-tick.C:
+		case <-deadline:
+			log.Errorf("Deployment did not converge within %s.", rCmd.timeout)
+			for _, stuck := range status.Stuck {
+				fmt.Println("  " + stuck)
+			}
+			return 1
+		}
+	}
+}
+
+// getCurrentDeployment returns the blueprint deployed to `namespace`, or if it's
+// empty, to the only namespace managed by the daemon.
+func getCurrentDeployment(c client.Client, namespace string) (stitch.Stitch, error) {
 	clusters, err := c.QueryClusters()
 	if err != nil {
 		return stitch.Stitch{}, err
 	}
-	switch len(clusters) {
+
+	var matches []db.Cluster
+	for _, clst := range clusters {
+		if namespace == "" || clst.Namespace == namespace {
+			matches = append(matches, clst)
+		}
+	}
+
+	switch len(matches) {
 	case 0:
 		return stitch.Stitch{}, errNoCluster
 	case 1:
-		return stitch.FromJSON(clusters[0].Blueprint)
+		return stitch.FromJSON(matches[0].Blueprint)
 	default:
-		panic("unreached")
+		return stitch.Stitch{}, errors.New("the daemon manages several " +
+			"namespaces, so one must be chosen with -namespace")
 	}
 }
 
@@ -6,12 +6,15 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/quilt/quilt/api"
+	"github.com/quilt/quilt/api/client"
 	clientMock "github.com/quilt/quilt/api/client/mocks"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/stitch"
@@ -201,7 +204,7 @@ func TestPromptsUser(t *testing.T) {
 		c.On("QueryClusters").Return([]db.Cluster{{
 			Blueprint: `{"old":"blueprint"}`,
 		}}, nil)
-		c.On("Deploy", "{}").Return(nil)
+		c.On("Deploy", "{}").Return("", nil)
 
 		util.WriteFile("test.js", []byte(""), 0644)
 		runCmd := &Run{
@@ -226,9 +229,114 @@ func TestRunFlags(t *testing.T) {
 	checkRunParsing(t, []string{expStitch}, Run{stitch: expStitch}, nil)
 	checkRunParsing(t, []string{"-f", expStitch},
 		Run{force: true, stitch: expStitch}, nil)
+	checkRunParsing(t, []string{"-wait", "-timeout", "10m", expStitch},
+		Run{wait: true, timeout: 10 * time.Minute, stitch: expStitch}, nil)
 	checkRunParsing(t, []string{}, Run{}, errors.New("no blueprint specified"))
 }
 
+func TestRunWait(t *testing.T) {
+	oldInterval := waitPollInterval
+	defer func() {
+		waitPollInterval = oldInterval
+	}()
+	waitPollInterval = time.Millisecond
+
+	compile = func(path string) (stitch.Stitch, error) {
+		return stitch.Stitch{}, nil
+	}
+
+	newRun := func(c *clientMock.Client, timeout time.Duration) *Run {
+		c.On("QueryClusters").Return(nil, nil)
+		c.On("Deploy", "{}").Return("id", nil)
+		return &Run{
+			connectionHelper: connectionHelper{client: c},
+			stitch:           "test.js",
+			force:            true,
+			wait:             true,
+			timeout:          timeout,
+		}
+	}
+
+	stuck := api.DeploymentStatus{ID: "id", Machines: 1,
+		Stuck: []string{"machine 1: waiting to boot"}}
+
+	// The run exits once the deployment converges.
+	c := new(clientMock.Client)
+	c.On("DeploymentStatus", "id").Return(stuck, nil).Once()
+	c.On("DeploymentStatus", "id").Return(api.DeploymentStatus{},
+		errors.New("unreachable")).Once()
+	c.On("DeploymentStatus", "id").Return(api.DeploymentStatus{
+		ID: "id", Converged: true}, nil).Once()
+	assert.Equal(t, 0, newRun(c, 0).Run())
+	c.AssertNumberOfCalls(t, "DeploymentStatus", 3)
+
+	// It fails if the deployment doesn't converge in time.
+	c = new(clientMock.Client)
+	c.On("DeploymentStatus", "id").Return(stuck, nil)
+	assert.Equal(t, 1, newRun(c, 10*time.Millisecond).Run())
+
+	// Or if the deployment is replaced while waiting.
+	c = new(clientMock.Client)
+	c.On("DeploymentStatus", "id").Return(api.DeploymentStatus{},
+		client.ErrDeploymentReplaced)
+	assert.Equal(t, 1, newRun(c, 0).Run())
+}
+
+func TestRunNamespace(t *testing.T) {
+	compile = func(path string) (stitch.Stitch, error) {
+		return stitch.Stitch{Namespace: "compiled"}, nil
+	}
+
+	// The blueprint is compared to the deployment of its own namespace.
+	c := new(clientMock.Client)
+	c.On("QueryClusters").Return([]db.Cluster{
+		{Namespace: "compiled", Blueprint: `{"Namespace":"compiled"}`},
+		{Namespace: "other", Blueprint: `{"Namespace":"other"}`},
+	}, nil)
+	c.On("Deploy", mock.Anything).Return("id", nil)
+
+	runCmd := &Run{
+		connectionHelper: connectionHelper{client: c},
+		stitch:           "test.js",
+	}
+	oldConfirm := confirm
+	confirm = func(in io.Reader, prompt string) (bool, error) {
+		t.Error("Unexpected confirmation of an unchanged deployment")
+		return true, nil
+	}
+	defer func() { confirm = oldConfirm }()
+
+	runCmd.force = true
+	assert.Equal(t, 0, runCmd.Run())
+	c.AssertCalled(t, "Deploy", `{"Namespace":"compiled"}`)
+
+	// -namespace overrides the namespace of the blueprint.
+	runCmd.namespace = "override"
+	assert.Equal(t, 0, runCmd.Run())
+	c.AssertCalled(t, "Deploy", `{"Namespace":"override"}`)
+}
+
+func TestGetCurrentDeployment(t *testing.T) {
+	t.Parallel()
+
+	c := new(clientMock.Client)
+	c.On("QueryClusters").Return([]db.Cluster{
+		{Namespace: "ns1", Blueprint: `{"Namespace":"ns1"}`},
+		{Namespace: "ns2", Blueprint: `{"Namespace":"ns2"}`},
+	}, nil)
+
+	depl, err := getCurrentDeployment(c, "ns2")
+	assert.NoError(t, err)
+	assert.Equal(t, "ns2", depl.Namespace)
+
+	_, err = getCurrentDeployment(c, "ns3")
+	assert.Equal(t, errNoCluster, err)
+
+	_, err = getCurrentDeployment(c, "")
+	assert.EqualError(t, err, "the daemon manages several namespaces, "+
+		"so one must be chosen with -namespace")
+}
+
 func checkRunParsing(t *testing.T, args []string, expFlags Run, expErr error) {
 	runCmd := NewRunCommand()
 	err := parseHelper(runCmd, args)
@@ -244,4 +352,6 @@ func checkRunParsing(t *testing.T, args []string, expFlags Run, expErr error) {
 	assert.Nil(t, err)
 	assert.Equal(t, expFlags.stitch, runCmd.stitch)
 	assert.Equal(t, expFlags.force, runCmd.force)
+	assert.Equal(t, expFlags.wait, runCmd.wait)
+	assert.Equal(t, expFlags.timeout, runCmd.timeout)
 }
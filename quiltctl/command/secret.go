@@ -0,0 +1,102 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/quilt/quilt/certs"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Secret contains the options for setting the values of blueprint secrets.
+type Secret struct {
+	name  string
+	value string
+
+	// The value is read from `stdin` if it isn't given on the command line, so
+	// that it doesn't end up in the shell's history.
+	stdin io.Reader
+
+	// The minions' credentials, with which the value is sealed.
+	keys certs.KeyPair
+
+	connectionHelper
+}
+
+// NewSecretCommand creates a new Secret command instance.
+func NewSecretCommand() *Secret {
+	return &Secret{stdin: os.Stdin}
+}
+
+var secretUsage = `usage: quilt secret set [-H=<daemon_host>] [-tls-dir=<dir>] ` +
+	`[-namespace=<namespace>] <name> [value]
+Set the value of the secret with the given name, which is used by the containers
+that refer to it in the blueprint.  If no value is given, it's read from standard
+input.  The value is encrypted with the minions' credentials in tls-dir before it's
+sent, and is only decrypted on the workers that run containers that use it.`
+
+// InstallFlags sets up parsing for command line flags.
+func (sCmd *Secret) InstallFlags(flags *flag.FlagSet) {
+	sCmd.connectionHelper.InstallFlags(flags)
+	flags.Usage = func() {
+		fmt.Println(secretUsage)
+		flags.PrintDefaults()
+	}
+}
+
+// Parse parses the command line arguments for the secret command.
+func (sCmd *Secret) Parse(args []string) error {
+	if len(args) < 2 || args[0] != "set" {
+		return errors.New("must specify `set` and the name of a secret")
+	}
+
+	if len(args) > 3 {
+		return errors.New("too many arguments")
+	}
+
+	sCmd.name = args[1]
+	if len(args) == 3 {
+		sCmd.value = args[2]
+		return nil
+	}
+
+	value, err := ioutil.ReadAll(sCmd.stdin)
+	if err != nil {
+		return err
+	}
+	sCmd.value = strings.TrimSuffix(string(value), "\n")
+	return nil
+}
+
+// BeforeRun connects to the daemon, and loads the credentials the secret is sealed
+// with.
+func (sCmd *Secret) BeforeRun() error {
+	creds, err := certs.Load(sCmd.tlsDir, certs.Minion)
+	if err != nil {
+		return err
+	}
+	sCmd.keys = creds.KeyPair
+
+	return sCmd.connectionHelper.BeforeRun()
+}
+
+// Run seals the secret and sets it in the cluster.
+func (sCmd *Secret) Run() int {
+	sealed, err := sCmd.keys.Seal(sCmd.value)
+	if err != nil {
+		log.WithError(err).Error("Failed to encrypt secret")
+		return 1
+	}
+
+	if err := sCmd.client.SetSecret(sCmd.name, sealed); err != nil {
+		log.WithError(err).Error("Failed to set secret")
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/quilt/quilt/api/client/mocks"
+	"github.com/quilt/quilt/certs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSecretFlags(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewSecretCommand()
+	err := parseHelper(cmd, []string{"-H", "mockHost", "set", "pass", "value"})
+	assert.NoError(t, err)
+	assert.Equal(t, "mockHost", cmd.host)
+	assert.Equal(t, "pass", cmd.name)
+	assert.Equal(t, "value", cmd.value)
+
+	cmd = &Secret{stdin: strings.NewReader("password\n")}
+	assert.NoError(t, parseHelper(cmd, []string{"set", "pass"}))
+	assert.Equal(t, "password", cmd.value)
+
+	err = parseHelper(NewSecretCommand(), []string{"get", "pass"})
+	assert.EqualError(t, err, "must specify `set` and the name of a secret")
+
+	err = parseHelper(NewSecretCommand(), []string{"set", "pass", "a", "b"})
+	assert.EqualError(t, err, "too many arguments")
+}
+
+func TestSecret(t *testing.T) {
+	t.Parallel()
+
+	keys := certs.KeyPair{Key: string(pem.EncodeToMemory(&pem.Block{
+		Type: "EC PRIVATE KEY", Bytes: []byte("key")}))}
+
+	var sealed string
+	c := new(mocks.Client)
+	c.On("SetSecret", "pass", mock.Anything).Return(nil).Run(
+		func(args mock.Arguments) {
+			sealed = args.String(1)
+		})
+
+	cmd := &Secret{name: "pass", value: "password", keys: keys,
+		connectionHelper: connectionHelper{client: c}}
+	assert.Zero(t, cmd.Run())
+
+	// Only the sealed value is sent to the daemon.
+	assert.NotContains(t, sealed, "password")
+	opened, err := keys.Open(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "password", opened)
+
+	c = new(mocks.Client)
+	c.On("SetSecret", "pass", mock.Anything).Return(assert.AnError)
+	cmd.client = c
+	assert.Equal(t, 1, cmd.Run())
+}
@@ -10,6 +10,7 @@ import (
 	log "github.com/Sirupsen/logrus"
 	"golang.org/x/crypto/ssh/terminal"
 
+	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client"
 	"github.com/quilt/quilt/api/util"
 	"github.com/quilt/quilt/db"
@@ -21,6 +22,7 @@ type SSH struct {
 	target      string
 	privateKey  string
 	allocatePTY bool
+	useAPI      bool
 	args        []string
 
 	sshGetter ssh.Getter
@@ -44,6 +46,9 @@ quilt ssh -i ~/.ssh/quilt 09ed35808a0b
 
 To run a command on container 8879fd2dbcee:
 quilt ssh 8879fd2dbcee echo foo
+
+To run a command on container 8879fd2dbcee through the Quilt API, without SSH:
+quilt ssh -api 8879fd2dbcee echo foo
 `
 
 // InstallFlags sets up parsing for command line flags.
@@ -53,6 +58,8 @@ func (sCmd *SSH) InstallFlags(flags *flag.FlagSet) {
 		"the private key to use to connect to the host")
 	flags.BoolVar(&sCmd.allocatePTY, "t", false,
 		"attempt to allocate a pseudo-terminal")
+	flags.BoolVar(&sCmd.useAPI, "api", false,
+		"connect to the container through the Quilt API rather than SSH")
 
 	flags.Usage = func() {
 		fmt.Println(sshUsage)
@@ -105,6 +112,14 @@ func (sCmd SSH) Run() int {
 		return 1
 	}
 
+	if sCmd.useAPI {
+		if !resolvedContainer {
+			log.Error("Only containers can be accessed through the API")
+			return 1
+		}
+		return sCmd.apiExec(cont.StitchID, allocatePTY)
+	}
+
 	host := contHost
 	if resolvedMachine {
 		host = mach.PublicIP
@@ -145,11 +160,47 @@ func (sCmd SSH) Run() int {
 	return 0
 }
 
+// apiExec runs the command in the container with the given StitchID through the
+// Quilt API, and returns its exit code.
+func (sCmd SSH) apiExec(stitchID string, allocatePTY bool) int {
+	cmd := sCmd.args
+	if len(cmd) == 0 {
+		cmd = []string{"sh"}
+	}
+
+	if allocatePTY {
+		restore, err := makeTerminalRaw()
+		if err != nil {
+			log.WithError(err).Error("Failed to allocate pseudo-terminal")
+			return 1
+		}
+		defer restore()
+	}
+
+	exitCode, err := sCmd.client.Exec(stitchID, api.ExecOptions{
+		Command: cmd,
+		TTY:     allocatePTY,
+		Stdin:   os.Stdin,
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+	}, nil)
+	if err != nil {
+		log.WithError(err).Error("Error running command")
+		return 1
+	}
+	return exitCode
+}
+
+// getMachine retrieves the machine whose StitchID begins with 'id'.  The daemon only
+// returns matching machines, but they are checked again here in case it's too old
+// to support filters.
 func getMachine(c client.Client, id string) (db.Machine, error) {
-	machines, err := c.QueryMachines()
+	rows, err := c.Query(db.MachineTable,
+		[]api.Filter{api.Prefix("StitchID", id)}, nil)
 	if err != nil {
 		return db.Machine{}, err
 	}
+	machines, _ := rows.([]db.Machine)
 
 	var choice *db.Machine
 	for _, m := range machines {
@@ -171,23 +222,31 @@ func getMachine(c client.Client, id string) (db.Machine, error) {
 	return *choice, nil
 }
 
+// getContainer retrieves the container whose StitchID begins with 'id', and the
+// public IP of the machine it's scheduled on.
 func getContainer(c client.Client, id string) (host string, cont db.Container,
 	err error) {
 
-	containers, err := c.QueryContainers()
+	rows, err := c.Query(db.ContainerTable,
+		[]api.Filter{api.Prefix("StitchID", id)},
+		[]string{"StitchID", "DockerID", "Minion"})
 	if err != nil {
 		return "", db.Container{}, err
 	}
+	containers, _ := rows.([]db.Container)
 
-	machines, err := c.QueryMachines()
+	container, err := util.GetContainer(containers, id)
 	if err != nil {
 		return "", db.Container{}, err
 	}
 
-	container, err := util.GetContainer(containers, id)
+	rows, err = c.Query(db.MachineTable,
+		[]api.Filter{api.Equal("PrivateIP", container.Minion)},
+		[]string{"PrivateIP", "PublicIP"})
 	if err != nil {
 		return "", db.Container{}, err
 	}
+	machines, _ := rows.([]db.Machine)
 
 	ip, err := util.GetPublicIP(machines, container.Minion)
 	if err != nil {
@@ -211,6 +270,17 @@ var isTerminal = func() bool {
 	return terminal.IsTerminal(int(os.Stdout.Fd()))
 }
 
+// makeTerminalRaw puts the terminal into raw mode, and returns a function that
+// restores its original state.
+var makeTerminalRaw = func() (func(), error) {
+	fd := int(os.Stdin.Fd())
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return func() { terminal.Restore(fd, state) }, nil
+}
+
 // exitError is an interface to "golang.org/x/crypto/ssh".ExitError that allows for
 // mocking in unit tests.
 type exitError interface {
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/quilt/quilt/api"
 	"github.com/quilt/quilt/api/client/mocks"
 	"github.com/quilt/quilt/db"
 	"github.com/quilt/quilt/quiltctl/ssh"
@@ -140,7 +141,9 @@ func TestGetMachine(t *testing.T) {
 	}
 	for _, test := range tests {
 		mockClient := new(mocks.Client)
-		mockClient.On("QueryMachines").Return(test.machines, nil)
+		mockClient.On("Query", db.MachineTable,
+			[]api.Filter{api.Prefix("StitchID", test.query)},
+			[]string(nil)).Return(test.machines, nil)
 		mockClient.On("Close").Return(nil)
 		m, err := getMachine(mockClient, test.query)
 
@@ -252,8 +255,10 @@ func TestSSH(t *testing.T) {
 		}
 
 		mockClient := new(mocks.Client)
-		mockClient.On("QueryMachines").Return(test.machines, nil)
-		mockClient.On("QueryContainers").Return(test.containers, nil)
+		mockClient.On("Query", db.MachineTable, mock.Anything,
+			mock.Anything).Return(test.machines, nil)
+		mockClient.On("Query", db.ContainerTable, mock.Anything,
+			mock.Anything).Return(test.containers, nil)
 		mockClient.On("Close").Return(nil)
 
 		testCmd.connectionHelper = connectionHelper{client: mockClient}
@@ -265,8 +270,10 @@ func TestSSH(t *testing.T) {
 
 func TestAmbiguousID(t *testing.T) {
 	mockClient := new(mocks.Client)
-	mockClient.On("QueryMachines").Return([]db.Machine{{StitchID: "foo"}}, nil)
-	mockClient.On("QueryContainers").Return([]db.Container{{StitchID: "foo"}}, nil)
+	mockClient.On("Query", db.MachineTable, mock.Anything,
+		mock.Anything).Return([]db.Machine{{StitchID: "foo"}}, nil)
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return([]db.Container{{StitchID: "foo"}}, nil)
 	mockClient.On("Close").Return(nil)
 
 	testCmd := SSH{
@@ -278,8 +285,10 @@ func TestAmbiguousID(t *testing.T) {
 
 func TestNoMatch(t *testing.T) {
 	mockClient := new(mocks.Client)
-	mockClient.On("QueryMachines").Return([]db.Machine{{StitchID: "foo"}}, nil)
-	mockClient.On("QueryContainers").Return([]db.Container{{StitchID: "foo"}}, nil)
+	mockClient.On("Query", db.MachineTable, mock.Anything,
+		mock.Anything).Return([]db.Machine{{StitchID: "foo"}}, nil)
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return([]db.Container{{StitchID: "foo"}}, nil)
 	mockClient.On("Close").Return(nil)
 
 	testCmd := SSH{
@@ -299,8 +308,10 @@ func TestSSHExitError(t *testing.T) {
 	mockSSHClient.On("Run", mock.Anything, mock.Anything).Return(mockExitError(10))
 
 	mockClient := new(mocks.Client)
-	mockClient.On("QueryMachines").Return([]db.Machine{{StitchID: "tgt"}}, nil)
-	mockClient.On("QueryContainers").Return(nil, nil)
+	mockClient.On("Query", db.MachineTable, mock.Anything,
+		mock.Anything).Return([]db.Machine{{StitchID: "tgt"}}, nil)
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return(nil, nil)
 	mockClient.On("Close").Return(nil)
 
 	testCmd := SSH{
@@ -340,8 +351,10 @@ func (err mockExitError) ExitStatus() int {
 
 func TestSSHScheduledContainer(t *testing.T) {
 	mockClient := &mocks.Client{}
-	mockClient.On("QueryContainers").Return([]db.Container{{StitchID: "foo"}}, nil)
-	mockClient.On("QueryMachines").Return(nil, nil)
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return([]db.Container{{StitchID: "foo"}}, nil)
+	mockClient.On("Query", db.MachineTable, mock.Anything,
+		mock.Anything).Return(nil, nil)
 	mockClient.On("Close").Return(nil)
 
 	testCmd := SSH{
@@ -350,3 +363,61 @@ func TestSSHScheduledContainer(t *testing.T) {
 	}
 	assert.Equal(t, 1, testCmd.Run())
 }
+
+func TestSSHAPI(t *testing.T) {
+	isTerminal = func() bool { return true }
+
+	var restored bool
+	makeTerminalRaw = func() (func(), error) {
+		return func() { restored = true }, nil
+	}
+
+	mockClient := &mocks.Client{}
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return([]db.Container{{
+		StitchID: "foo",
+		DockerID: "dockerID",
+		Minion:   "priv",
+	}}, nil)
+	mockClient.On("Query", db.MachineTable, mock.Anything,
+		mock.Anything).Return([]db.Machine{{
+		PrivateIP: "priv",
+		PublicIP:  "host",
+	}}, nil)
+	mockClient.On("Exec", "foo", mock.Anything, mock.Anything).Return(3, nil)
+
+	testCmd := SSH{
+		connectionHelper: connectionHelper{client: mockClient},
+		target:           "foo",
+		useAPI:           true,
+	}
+	assert.Equal(t, 3, testCmd.Run())
+	assert.True(t, restored)
+
+	call := mockClient.Calls[len(mockClient.Calls)-1]
+	opts := call.Arguments.Get(1).(api.ExecOptions)
+	assert.Equal(t, []string{"sh"}, opts.Command)
+	assert.True(t, opts.TTY)
+
+	restored = false
+	testCmd.args = []string{"echo", "foo"}
+	assert.Equal(t, 3, testCmd.Run())
+	assert.False(t, restored)
+
+	call = mockClient.Calls[len(mockClient.Calls)-1]
+	opts = call.Arguments.Get(1).(api.ExecOptions)
+	assert.Equal(t, []string{"echo", "foo"}, opts.Command)
+	assert.False(t, opts.TTY)
+
+	// Machines can't be reached through the API.
+	mockClient = &mocks.Client{}
+	mockClient.On("Query", db.MachineTable, mock.Anything,
+		mock.Anything).Return([]db.Machine{{StitchID: "foo"}}, nil)
+	mockClient.On("Query", db.ContainerTable, mock.Anything,
+		mock.Anything).Return(nil, nil)
+
+	testCmd.connectionHelper = connectionHelper{client: mockClient}
+	assert.Equal(t, 1, testCmd.Run())
+	mockClient.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything,
+		mock.Anything)
+}
@@ -11,7 +11,6 @@ import (
 
 // Stop contains the options for stopping namespaces.
 type Stop struct {
-	namespace      string
 	onlyContainers bool
 
 	connectionHelper
@@ -26,8 +25,6 @@ func NewStopCommand() *Stop {
 func (sCmd *Stop) InstallFlags(flags *flag.FlagSet) {
 	sCmd.connectionHelper.InstallFlags(flags)
 
-	flags.StringVar(&sCmd.namespace, "namespace", "",
-		"the namespace to stop")
 	flags.BoolVar(&sCmd.onlyContainers, "containers", false,
 		"only destroy containers")
 
@@ -36,8 +33,8 @@ func (sCmd *Stop) InstallFlags(flags *flag.FlagSet) {
 			"[-containers] [-namespace=<namespace>] <namespace>]")
 		fmt.Println("`stop` creates an empty Stitch for the given namespace, " +
 			"and sends it to the Quilt daemon to be executed. If no " +
-			"namespace is specified, `stop` attempts to use the namespace " +
-			"currently tracked by the daemon.")
+			"namespace is specified, `stop` attempts to use the only " +
+			"namespace managed by the daemon.")
 		fmt.Println("The result is that resources associated with the " +
 			"namespace, such as VMs, are freed.")
 		flags.PrintDefaults()
@@ -59,31 +56,37 @@ func (sCmd *Stop) Run() int {
 		Namespace: sCmd.namespace,
 	}
 	if sCmd.namespace == "" || sCmd.onlyContainers {
-		currDepl, err := getCurrentDeployment(sCmd.client)
-		if err != nil {
+		currDepl, err := getCurrentDeployment(sCmd.client, sCmd.namespace)
+		if sCmd.onlyContainers && err == errNoCluster {
+			log.Error("Stopping only containers for a namespace " +
+				"not tracked by the remote daemon is not " +
+				"currently supported")
+			return 1
+		} else if err != nil {
 			log.WithError(err).
 				Error("Failed to get current cluster")
 			return 1
 		}
-		if sCmd.namespace == "" {
-			newCluster.Namespace = currDepl.Namespace
-		}
+
+		newCluster.Namespace = currDepl.Namespace
 		if sCmd.onlyContainers {
-			if newCluster.Namespace != currDepl.Namespace {
-				log.Error("Stopping only containers for a namespace " +
-					"not tracked by the remote daemon is not " +
-					"currently supported")
-				return 1
-			}
 			newCluster.Machines = currDepl.Machines
 		}
 	}
 
-	if err := sCmd.client.Deploy(newCluster.String()); err != nil {
+	// Stopping only the containers leaves the machines running, so the namespace
+	// isn't marked as stopped.
+	var err error
+	if sCmd.onlyContainers {
+		_, err = sCmd.client.Deploy(newCluster.String())
+	} else {
+		err = sCmd.client.Stop(newCluster.String())
+	}
+	if err != nil {
 		log.WithError(err).Error("Unable to stop namespace.")
 		return 1
 	}
 
-	log.WithField("namespace", sCmd.namespace).Debug("Stopping namespace")
+	log.WithField("namespace", newCluster.Namespace).Debug("Stopping namespace")
 	return 0
 }
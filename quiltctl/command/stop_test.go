@@ -16,13 +16,13 @@ func TestStopNamespaceDefault(t *testing.T) {
 	c := new(clientMock.Client)
 	c.On("QueryClusters").Once().Return([]db.Cluster{{
 		Blueprint: `{"namespace": "testSpace"}`}}, nil)
-	c.On("Deploy", mock.Anything).Return(nil)
+	c.On("Stop", mock.Anything).Return(nil)
 
 	stopCmd := NewStopCommand()
 	stopCmd.client = c
 	stopCmd.Run()
 
-	c.AssertCalled(t, "Deploy", stitch.Stitch{Namespace: "testSpace"}.String())
+	c.AssertCalled(t, "Stop", stitch.Stitch{Namespace: "testSpace"}.String())
 
 	c.On("QueryClusters").Return(nil, nil)
 	assert.Equal(t, 1, stopCmd.Run(),
@@ -34,14 +34,15 @@ func TestStopNamespace(t *testing.T) {
 
 	c := &clientMock.Client{}
 	c.On("QueryClusters").Return(nil, nil)
-	c.On("Deploy", mock.Anything).Return(nil)
+	c.On("Stop", mock.Anything).Return(nil)
 
 	stopCmd := NewStopCommand()
 	stopCmd.client = c
 	stopCmd.namespace = "namespace"
 	stopCmd.Run()
 
-	c.AssertCalled(t, "Deploy", stitch.Stitch{Namespace: "namespace"}.String())
+	c.AssertCalled(t, "Stop", stitch.Stitch{Namespace: "namespace"}.String())
+	c.AssertNotCalled(t, "Deploy", mock.Anything)
 }
 
 func TestStopContainers(t *testing.T) {
@@ -49,11 +50,12 @@ func TestStopContainers(t *testing.T) {
 
 	c := &clientMock.Client{}
 	c.On("QueryClusters").Return([]db.Cluster{{
+		Namespace: "testSpace",
 		Blueprint: `{"namespace": "testSpace", "machines": ` +
 			`[{"provider": "Amazon"}, {"provider": "Google"}]}`,
 	}}, nil)
 
-	c.On("Deploy", mock.Anything).Return(nil)
+	c.On("Deploy", mock.Anything).Return("", nil)
 
 	stopCmd := NewStopCommand()
 	stopCmd.client = c
@@ -68,6 +70,10 @@ func TestStopContainers(t *testing.T) {
 			Provider: "Google",
 		}}}.String())
 
+	// Only the containers of namespaces tracked by the daemon can be stopped.
+	stopCmd.namespace = "other"
+	assert.Equal(t, 1, stopCmd.Run())
+	c.AssertNumberOfCalls(t, "Deploy", 1)
 }
 
 func TestStopFlags(t *testing.T) {
@@ -12,10 +12,12 @@ import (
 // Note the `minion` command is in quiltclt_posix.go as it only runs on posix systems.
 var commands = map[string]command.SubCommand{
 	"daemon":     command.NewDaemonCommand(),
+	"history":    command.NewHistoryCommand(),
 	"inspect":    &command.Inspect{},
 	"logs":       command.NewLogCommand(),
 	"ps":         command.NewPsCommand(),
 	"run":        command.NewRunCommand(),
+	"secret":     command.NewSecretCommand(),
 	"ssh":        command.NewSSHCommand(),
 	"stop":       command.NewStopCommand(),
 	"version":    command.NewVersionCommand(),
@@ -294,6 +294,44 @@ Service.prototype.neighborOf = function(target) {
     return neighbor(this.name, target.name);
 };
 
+// The service can connect to `target` on `port` over `protocol`, which is 'tcp'
+// if it isn't given.
+Service.prototype.canReachOnPort = function(target, port, protocol) {
+    if (target === publicInternet) {
+        return portInvariant(this.name, publicInternetLabel, port, protocol);
+    }
+    return portInvariant(this.name, target.name, port, protocol);
+};
+
+Service.prototype.reachableFromPublicOnPort = function(port, protocol) {
+    return portInvariant(publicInternetLabel, this.name, port, protocol);
+};
+
+function portInvariant(from, to, port, protocol) {
+    if (protocol === undefined) {
+        return reachablePort(from, to, String(port));
+    }
+    return reachablePort(from, to, String(port), protocol);
+}
+
+// The number of containers in the service is at least `min`, and at most `max` if
+// it's given.
+Service.prototype.hasReplicas = function(min, max) {
+    if (max === undefined) {
+        return replicas(this.name, String(min));
+    }
+    return replicas(this.name, String(min), String(max));
+};
+
+Service.prototype.isolated = function() {
+    return isolated(this.name);
+};
+
+// The containers of the service are guaranteed to run on at least `n` machines.
+Service.prototype.spreadAcross = function(n) {
+    return spread(this.name, String(n));
+};
+
 
 Service.prototype.deploy = function(deployment) {
     deployment.services.push(this);
@@ -311,10 +349,16 @@ Service.prototype.connect = function(range, to) {
     to.allowFrom(this, range);
 }
 
-Service.prototype.allowFrom = function(sourceService, portRange) {
+// Connections from the public internet may forward a single public port to a
+// different `containerPort`.
+Service.prototype.allowFrom = function(sourceService, portRange, containerPort) {
     portRange = boxRange(portRange);
     if (sourceService === publicInternet) {
-        return this.allowFromPublic(portRange);
+        return this.allowFromPublic(portRange, containerPort);
+    }
+    if (containerPort !== undefined) {
+        throw new Error(`only connections from the public internet can be ` +
+            `forwarded to a container port`);
     }
     if (!(sourceService instanceof Service)) {
         throw new Error(`Services can only connect to other services. ` +
@@ -352,10 +396,6 @@ Service.prototype.connectToPublic = function(range) {
 
 Service.prototype.allowOutboundPublic = function(range) {
     range = boxRange(range);
-    if (range.min != range.max) {
-        throw new Error(`public internet can only connect to single ports ` +
-            `and not to port ranges`);
-    }
     this.outgoingPublic.push(range);
 };
 
@@ -366,13 +406,13 @@ Service.prototype.connectFromPublic = function(range) {
     this.allowFromPublic(range);
 }
 
-Service.prototype.allowFromPublic = function(range) {
+Service.prototype.allowFromPublic = function(range, containerPort) {
     range = boxRange(range);
-    if (range.min != range.max) {
-        throw new Error(`public internet can only connect to single ports ` +
-            `and not to port ranges`);
+    if (containerPort !== undefined && range.min != range.max) {
+        throw new Error(`public port ranges can only be forwarded to the ` +
+            `same container ports`);
     }
-    this.incomingPublic.push(range);
+    this.incomingPublic.push({range: range, containerPort: containerPort});
 };
 
 Service.prototype.place = function(rule) {
@@ -384,35 +424,42 @@ Service.prototype.getQuiltConnections = function() {
     var that = this;
 
     this.allowedInboundConnections.forEach(function(conn) {
-        connections.push({
-            from: conn.from.name,
-            to: that.name,
-            minPort: conn.minPort,
-            maxPort: conn.maxPort
-        });
+        connections.push(quiltConnection(conn.from.name, that.name,
+            conn.minPort, conn.maxPort, conn.protocol));
     });
 
     this.outgoingPublic.forEach(function(rng) {
-        connections.push({
-            from: that.name,
-            to: publicInternetLabel,
-            minPort: rng.min,
-            maxPort: rng.max
-        });
+        connections.push(quiltConnection(that.name, publicInternetLabel,
+            rng.min, rng.max, rng.protocol));
     });
 
-    this.incomingPublic.forEach(function(rng) {
-        connections.push({
-            from: publicInternetLabel,
-            to: that.name,
-            minPort: rng.min,
-            maxPort: rng.max
-        });
+    this.incomingPublic.forEach(function(pub) {
+        var conn = quiltConnection(publicInternetLabel, that.name,
+            pub.range.min, pub.range.max, pub.range.protocol);
+        if (pub.containerPort !== undefined) {
+            conn.containerPort = pub.containerPort;
+        }
+        connections.push(conn);
     });
 
     return connections;
 };
 
+// quiltConnection converts a connection to the Quilt representation, which omits
+// the protocol of connections that allow all protocols.
+function quiltConnection(from, to, minPort, maxPort, protocol) {
+    var conn = {
+        from: from,
+        to: to,
+        minPort: minPort,
+        maxPort: maxPort
+    };
+    if (protocol !== undefined) {
+        conn.protocol = protocol;
+    }
+    return conn;
+}
+
 Service.prototype.getQuiltPlacements = function() {
     var placements = [];
     var that = this;
@@ -538,11 +585,43 @@ function Container(image, command) {
     this.filepathToContent = {};
 }
 
+// The CPU (in cores) and memory (in MiB) a container may request and be limited to.
+var containerResources = ['cpuRequest', 'cpuLimit', 'memoryRequest', 'memoryLimit'];
+
 // Create a new Container with the same attributes.
 Container.prototype.clone = function() {
     var cloned = new Container(this.image.clone(), _.clone(this.command));
     cloned.env = _.clone(this.env);
     cloned.filepathToContent = _.clone(this.filepathToContent);
+
+    var that = this;
+    containerResources.forEach(function(resource) {
+        if (that[resource] !== undefined) {
+            cloned[resource] = that[resource];
+        }
+    });
+
+    if (this.volumes !== undefined) {
+        cloned.volumes = this.volumes.map(_.clone);
+    }
+    if (this.movable !== undefined) {
+        cloned.movable = this.movable;
+    }
+    if (this.healthCheck !== undefined) {
+        cloned.healthCheck = cloneHealthCheck(this.healthCheck);
+    }
+    if (this.restartPolicy !== undefined) {
+        cloned.restartPolicy = this.restartPolicy;
+    }
+    if (this.maxRetries !== undefined) {
+        cloned.maxRetries = this.maxRetries;
+    }
+    if (this.secretEnv !== undefined) {
+        cloned.secretEnv = _.clone(this.secretEnv);
+    }
+    if (this.secretFiles !== undefined) {
+        cloned.secretFiles = _.clone(this.secretFiles);
+    }
     return cloned;
 };
 
@@ -556,22 +635,130 @@ Container.prototype.replicate = function(n) {
     return res;
 };
 
+// setEnv sets the environment variable `key` to `val`, which is either a string or
+// a Secret.
 Container.prototype.setEnv = function(key, val) {
+    if (val instanceof Secret) {
+        delete this.env[key];
+        this.secretEnv = this.secretEnv || {};
+        this.secretEnv[key] = val.name;
+        return;
+    }
     this.env[key] = val;
+    if (this.secretEnv !== undefined) {
+        delete this.secretEnv[key];
+    }
 };
 
 Container.prototype.withEnv = function(env) {
     var cloned = this.clone();
-    cloned.env = env;
+    cloned.env = {};
+    delete cloned.secretEnv;
+    Object.keys(env).forEach(function(key) {
+        cloned.setEnv(key, env[key]);
+    });
     return cloned;
 };
 
+// withFiles returns a copy of the container with the given files, whose contents
+// are either strings or Secrets.
 Container.prototype.withFiles = function(fileMap) {
     var cloned = this.clone();
-    cloned.filepathToContent = fileMap;
+    cloned.filepathToContent = {};
+    delete cloned.secretFiles;
+    Object.keys(fileMap).forEach(function(path) {
+        var content = fileMap[path];
+        if (content instanceof Secret) {
+            cloned.secretFiles = cloned.secretFiles || {};
+            cloned.secretFiles[path] = content.name;
+        } else {
+            cloned.filepathToContent[path] = content;
+        }
+    });
+    return cloned;
+};
+
+// withResources returns a copy of the container with the given requests and limits,
+// e.g. {cpuLimit: 2, memoryLimit: 512}.  Requests default to the limits.
+Container.prototype.withResources = function(resources) {
+    var cloned = this.clone();
+    Object.keys(resources).forEach(function(resource) {
+        if (containerResources.indexOf(resource) === -1) {
+            throw new Error(`unknown container resource: ${resource}`);
+        }
+        cloned[resource] = resources[resource];
+    });
+    return cloned;
+};
+
+// withVolumes returns a copy of the container with the given volumes mounted, e.g.
+// [{name: 'data', mountPath: '/data'}, {hostPath: '/etc/ssl', mountPath: '/ssl',
+// readOnly: true}].  The data in the volumes is stored on the container's machine,
+// so the container stays there unless it's movable.
+Container.prototype.withVolumes = function(volumes) {
+    var cloned = this.clone();
+    cloned.volumes = volumes.map(function(volume) {
+        if (volume.mountPath === undefined) {
+            throw new Error('volumes must have a mountPath');
+        }
+        return _.clone(volume);
+    });
     return cloned;
 };
 
+// setMovable allows the container to be moved away from the data in its volumes.
+Container.prototype.setMovable = function(movable) {
+    this.movable = movable;
+};
+
+// withHealthCheck returns a copy of the container with the given health check, e.g.
+// {command: ['pg_isready']}, {port: 80, httpPath: '/healthz'} or {port: 6379}.  The
+// interval, timeout (both in seconds) and retries of the check may also be set.
+Container.prototype.withHealthCheck = function(check) {
+    if (check.command === undefined && check.port === undefined) {
+        throw new Error('health checks must have a command or a port');
+    }
+    var cloned = this.clone();
+    cloned.healthCheck = cloneHealthCheck(check);
+    return cloned;
+};
+
+function cloneHealthCheck(check) {
+    var cloned = _.clone(check);
+    if (check.command !== undefined) {
+        cloned.command = _.clone(check.command);
+    }
+    return cloned;
+}
+
+// The policies for restarting containers that exit.
+var restartPolicies = ['always', 'on-failure', 'never'];
+
+// withRestartPolicy returns a copy of the container that's restarted according to
+// `policy` when it exits.  Containers with the 'on-failure' policy are restarted
+// at most `maxRetries` times, if it's given.
+Container.prototype.withRestartPolicy = function(policy, maxRetries) {
+    if (restartPolicies.indexOf(policy) === -1) {
+        throw new Error(`unknown restart policy: ${policy}`);
+    }
+    var cloned = this.clone();
+    cloned.restartPolicy = policy;
+    if (maxRetries !== undefined) {
+        cloned.maxRetries = maxRetries;
+    }
+    return cloned;
+};
+
+// A Secret is a value, such as a password, that's set with `quilt secret` rather
+// than in the blueprint.  Secrets are used as the values of containers' environment
+// variables and files, and are only decrypted on the machines running them.
+function Secret(name) {
+    if (typeof name !== 'string') {
+        throw new Error('secrets must have a name');
+    }
+    this.name = name;
+}
+
 Container.prototype.setHostname = function(h) {
     this.hostname = h;
 };
@@ -588,6 +775,10 @@ var between = invariantType('between');
 var neighbor = invariantType('reachDirect');
 var reachableACL = invariantType('reachACL');
 var reachable = invariantType('reach');
+var reachablePort = invariantType('reachPort');
+var replicas = invariantType('replicas');
+var isolated = invariantType('isolated');
+var spread = invariantType('spread');
 
 function Assertion(invariant, desired) {
     this.form = invariant.form;
@@ -636,16 +827,20 @@ function MachineRule(exclusive, optionalArgs) {
 function Connection(from, ports) {
     this.minPort = ports.min;
     this.maxPort = ports.max;
+    this.protocol = ports.protocol;
     this.from = from;
 }
 
-function Range(min, max) {
+// A Range of ports may optionally be restricted to a protocol: 'tcp', 'udp' or
+// 'icmp'.  Otherwise, all three are allowed.
+function Range(min, max, protocol) {
     this.min = min;
     this.max = max;
+    this.protocol = protocol;
 }
 
-function Port(p) {
-    return new PortRange(p, p);
+function Port(p, protocol) {
+    return new PortRange(p, p, protocol);
 }
 
 var PortRange = Range;
@@ -671,6 +866,7 @@ module.exports = {
     Port,
     PortRange,
     Range,
+    Secret,
     Service,
     createDeployment,
     getDeployment,
@@ -11,6 +11,7 @@ const {
     Port,
     PortRange,
     Range,
+    Secret,
     Service,
     createDeployment,
     getDeployment,
@@ -200,6 +201,116 @@ describe('Bindings', function () {
                 filepathToContent: { qux: 'quuz' },
             }]);
         });
+        it('resources', function () {
+            const c = new Container('image')
+                .withResources({ cpuLimit: 2, memoryRequest: 256 });
+            deployment.deploy(new Service('foo', c.replicate(2)));
+            checkContainers([
+                {
+                    image: new Image('image'),
+                    cpuLimit: 2,
+                    memoryRequest: 256,
+                },
+                {
+                    image: new Image('image'),
+                    cpuLimit: 2,
+                    memoryRequest: 256,
+                },
+            ]);
+        });
+        it('unknown resource', function () {
+            expect(() => new Container('image').withResources({ disk: 10 }))
+                .to.throw('unknown container resource: disk');
+        });
+        it('volumes', function () {
+            const c = new Container('image').withVolumes([
+                { name: 'data', mountPath: '/data' },
+                { hostPath: '/etc/ssl', mountPath: '/ssl', readOnly: true },
+            ]);
+            c.setMovable(true);
+            deployment.deploy(new Service('foo', c.replicate(2)));
+            checkContainers([
+                {
+                    image: new Image('image'),
+                    volumes: [
+                        { name: 'data', mountPath: '/data' },
+                        { hostPath: '/etc/ssl', mountPath: '/ssl', readOnly: true },
+                    ],
+                    movable: true,
+                },
+                {
+                    image: new Image('image'),
+                    volumes: [
+                        { name: 'data', mountPath: '/data' },
+                        { hostPath: '/etc/ssl', mountPath: '/ssl', readOnly: true },
+                    ],
+                    movable: true,
+                },
+            ]);
+        });
+        it('volume without mount path', function () {
+            expect(() => new Container('image').withVolumes([{ name: 'data' }]))
+                .to.throw('volumes must have a mountPath');
+        });
+        it('health check', function () {
+            const c = new Container('image')
+                .withHealthCheck({ port: 80, httpPath: '/healthz', retries: 5 });
+            deployment.deploy(new Service('foo', c.replicate(2)));
+            checkContainers([
+                {
+                    image: new Image('image'),
+                    healthCheck: { port: 80, httpPath: '/healthz', retries: 5 },
+                },
+                {
+                    image: new Image('image'),
+                    healthCheck: { port: 80, httpPath: '/healthz', retries: 5 },
+                },
+            ]);
+        });
+        it('health check without command or port', function () {
+            expect(() => new Container('image').withHealthCheck({ retries: 5 }))
+                .to.throw('health checks must have a command or a port');
+        });
+        it('restart policy', function () {
+            const c = new Container('image').withRestartPolicy('on-failure', 3);
+            deployment.deploy(new Service('foo', [c]));
+            checkContainers([{
+                image: new Image('image'),
+                restartPolicy: 'on-failure',
+                maxRetries: 3,
+            }]);
+        });
+        it('secrets', function () {
+            const password = new Secret('pg-password');
+            const c = new Container('image')
+                .withEnv({ USER: 'admin', PASSWORD: password })
+                .withFiles({ '/etc/motd': 'hi', '/etc/key': new Secret('key') });
+            deployment.deploy(new Service('foo', [c]));
+            checkContainers([{
+                image: new Image('image'),
+                env: { USER: 'admin' },
+                filepathToContent: { '/etc/motd': 'hi' },
+                secretEnv: { PASSWORD: 'pg-password' },
+                secretFiles: { '/etc/key': 'key' },
+            }]);
+        });
+        it('replace secret env', function () {
+            const c = new Container('image');
+            c.setEnv('PASSWORD', new Secret('pg-password'));
+            c.setEnv('PASSWORD', 'plain');
+            deployment.deploy(new Service('foo', [c]));
+            checkContainers([{
+                env: { PASSWORD: 'plain' },
+                secretEnv: {},
+            }]);
+        });
+        it('unnamed secret', function () {
+            expect(() => new Secret()).to.throw('secrets must have a name');
+        });
+        it('unknown restart policy', function () {
+            expect(() => new Container('image').withRestartPolicy('sometimes'))
+                .to.throw('unknown restart policy: sometimes');
+        });
         it('image dockerfile', function () {
             const c = new Container(new Image('name', 'dockerfile'));
             deployment.deploy(new Service('foo', [c]));
@@ -446,6 +557,23 @@ describe('Bindings', function () {
                 maxPort: 85,
             }]);
         });
+        it('port protocol', function () {
+            bar.allowFrom(foo, new Port(53, 'udp'));
+            foo.allowFrom(publicInternet, new Port(53, 'udp'));
+            checkConnections([{
+                from: 'foo',
+                to: 'bar',
+                minPort: 53,
+                maxPort: 53,
+                protocol: 'udp',
+            }, {
+                from: 'public',
+                to: 'foo',
+                minPort: 53,
+                maxPort: 53,
+                protocol: 'udp',
+            }]);
+        });
         it('connect to invalid port range', function () {
             expect(() => foo.connect(true, bar)).to
                 .throw('Input argument must be a number or a Range');
@@ -469,12 +597,42 @@ describe('Bindings', function () {
             }]);
         });
         it('connect to publicInternet port range', function () {
-            expect(() => publicInternet.allowFrom(foo, new PortRange(80, 81))).to
-                .throw('public internet can only connect to single ports and not to port ranges');
+            publicInternet.allowFrom(foo, new PortRange(80, 81));
+            checkConnections([{
+                from: 'foo',
+                to: 'public',
+                minPort: 80,
+                maxPort: 81,
+            }]);
         });
         it('connect from publicInternet port range', function () {
-            expect(() => foo.allowFrom(publicInternet, new PortRange(80, 81))).to
-                .throw('public internet can only connect to single ports and not to port ranges');
+            foo.allowFrom(publicInternet, new PortRange(80, 81));
+            checkConnections([{
+                from: 'public',
+                to: 'foo',
+                minPort: 80,
+                maxPort: 81,
+            }]);
+        });
+        it('forward public port to container port', function () {
+            foo.allowFrom(publicInternet, 8080, 80);
+            checkConnections([{
+                from: 'public',
+                to: 'foo',
+                minPort: 8080,
+                maxPort: 8080,
+                containerPort: 80,
+            }]);
+        });
+        it('forward public port range to container port', function () {
+            expect(() => foo.allowFrom(publicInternet, new PortRange(80, 81), 80))
+                .to.throw('public port ranges can only be forwarded to the ' +
+                    'same container ports');
+        });
+        it('forward private port to container port', function () {
+            expect(() => foo.allowFrom(bar, 8080, 80)).to
+                .throw('only connections from the public internet can be ' +
+                    'forwarded to a container port');
         });
         it('allowFrom non-service', function () {
             expect(() => foo.allowFrom(10, 10)).to
@@ -541,12 +699,22 @@ describe('Bindings', function () {
             }]);
         });
         it('connect to publicInternet port range', function () {
-            expect(() => foo.connect(new PortRange(80, 81), publicInternet)).to
-                .throw('public internet can only connect to single ports and not to port ranges');
+            foo.connect(new PortRange(80, 81), publicInternet);
+            checkConnections([{
+                from: 'foo',
+                to: 'public',
+                minPort: 80,
+                maxPort: 81,
+            }]);
         });
         it('connect from publicInternet port range', function () {
-            expect(() => publicInternet.connect(new PortRange(80, 81), foo)).to
-                .throw('public internet can only connect to single ports and not to port ranges');
+            publicInternet.connect(new PortRange(80, 81), foo);
+            checkConnections([{
+                from: 'public',
+                to: 'foo',
+                minPort: 80,
+                maxPort: 81,
+            }]);
         });
         it('connect to non-service', function () {
             expect(() => foo.connect(10, 10)).to
@@ -593,6 +761,75 @@ describe('Bindings', function () {
             expect(deploy).to.throw('img has differing Dockerfiles');
         });
     });
+    describe('Invariants', function () {
+        let foo;
+        let bar;
+        const checkInvariants = function (expected) {
+            const { invariants } = deployment.toQuiltRepresentation();
+            expect(invariants).to.have.lengthOf(expected.length)
+                .and.containSubset(expected);
+        };
+        beforeEach(function () {
+            foo = new Service('foo', []);
+            bar = new Service('bar', []);
+            deployment.deploy([foo, bar]);
+        });
+        it('canReachOnPort', function () {
+            deployment.assert(foo.canReachOnPort(bar, 80), true);
+            deployment.assert(foo.canReachOnPort(publicInternet, 53, 'udp'), false);
+            checkInvariants([{
+                form: 'reachPort',
+                nodes: ['foo', 'bar', '80'],
+                target: true,
+            }, {
+                form: 'reachPort',
+                nodes: ['foo', 'public', '53', 'udp'],
+                target: false,
+            }]);
+        });
+        it('reachableFromPublicOnPort', function () {
+            deployment.assert(foo.reachableFromPublicOnPort(443), true);
+            deployment.assert(foo.reachableFromPublicOnPort(53, 'udp'), true);
+            checkInvariants([{
+                form: 'reachPort',
+                nodes: ['public', 'foo', '443'],
+                target: true,
+            }, {
+                form: 'reachPort',
+                nodes: ['public', 'foo', '53', 'udp'],
+                target: true,
+            }]);
+        });
+        it('hasReplicas', function () {
+            deployment.assert(foo.hasReplicas(3), true);
+            deployment.assert(bar.hasReplicas(1, 2), false);
+            checkInvariants([{
+                form: 'replicas',
+                nodes: ['foo', '3'],
+                target: true,
+            }, {
+                form: 'replicas',
+                nodes: ['bar', '1', '2'],
+                target: false,
+            }]);
+        });
+        it('isolated', function () {
+            deployment.assert(foo.isolated(), true);
+            checkInvariants([{
+                form: 'isolated',
+                nodes: ['foo'],
+                target: true,
+            }]);
+        });
+        it('spreadAcross', function () {
+            deployment.assert(foo.spreadAcross(3), true);
+            checkInvariants([{
+                form: 'spread',
+                nodes: ['foo', '3'],
+                target: true,
+            }]);
+        });
+    });
     describe('Custom Deploy', function () {
         it('basic', function () {
             deployment.deploy({
@@ -6,6 +6,27 @@ type Node struct {
 	Label       string
 	Annotations map[string]struct{}
 	Connections map[string]Node
+	// The port ranges on which the Node may initiate each of its Connections.
+	Ports map[string][]PortRange
+}
+
+// A PortRange is the inclusive range of ports [Min, Max] over Protocol.  Ranges
+// without a Protocol accept TCP, UDP and ICMP.
+type PortRange struct {
+	Min      int
+	Max      int
+	Protocol string
+}
+
+// Accepts returns true if `port` is within the range.
+func (pr PortRange) Accepts(port int) bool {
+	return pr.Min <= port && port <= pr.Max
+}
+
+// Allows returns true if the range accepts `port` over `protocol`, which is TCP or
+// UDP.  ICMP ranges have no ports, so they allow neither.
+func (pr PortRange) Allows(protocol string, port int) bool {
+	return (pr.Protocol == "" || pr.Protocol == protocol) && pr.Accepts(port)
 }
 
 // An Edge in the communication Graph.
@@ -44,7 +65,7 @@ func InitializeGraph(blueprint Stitch) (Graph, error) {
 	g.addNode(PublicInternetLabel, PublicInternetLabel, []string{})
 
 	for _, conn := range blueprint.Connections {
-		err := g.addConnection(conn.From, conn.To)
+		err := g.addConnection(conn)
 		if err != nil {
 			return Graph{}, err
 		}
@@ -87,24 +108,28 @@ func (g Graph) copyGraph() Graph {
 	return Graph{Nodes: newNodes, Availability: newAvail}
 }
 
-func (g *Graph) addConnection(from string, to string) error {
-	// from and to are labels
+func (g *Graph) addConnection(conn Connection) error {
+	// conn.From and conn.To are labels
 	var fromContainers []Node
 	var toContainers []Node
 
 	for _, node := range g.Nodes {
-		if node.Label == from {
+		if node.Label == conn.From {
 			fromContainers = append(fromContainers, node)
 		}
-		if node.Label == to {
+		if node.Label == conn.To {
 			toContainers = append(toContainers, node)
 		}
 	}
 
+	ports := PortRange{Min: conn.MinPort, Max: conn.MaxPort,
+		Protocol: conn.Protocol}
 	for _, fromNode := range fromContainers {
 		for _, toNode := range toContainers {
 			if fromNode.Name != toNode.Name {
 				fromNode.Connections[toNode.Name] = toNode
+				fromNode.Ports[toNode.Name] = append(
+					fromNode.Ports[toNode.Name], ports)
 			}
 		}
 	}
@@ -112,6 +137,17 @@ func (g *Graph) addConnection(from string, to string) error {
 	return nil
 }
 
+// labelNodes returns the Nodes that implement `label`.
+func (g Graph) labelNodes(label string) []Node {
+	var res []Node
+	for _, n := range g.Nodes {
+		if n.Label == label {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
 func (g Graph) getNodes() []Node {
 	var res []Node
 	for _, n := range g.Nodes {
@@ -130,6 +166,7 @@ func (g *Graph) addNode(cid string, label string, annotations []string) Node {
 		Label:       label,
 		Annotations: annotationSet,
 		Connections: map[string]Node{},
+		Ports:       map[string][]PortRange{},
 	}
 	g.Nodes[cid] = n
 	g.Availability[0].Insert(cid)
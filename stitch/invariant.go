@@ -2,7 +2,10 @@ package stitch
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+
+	"github.com/quilt/quilt/db"
 )
 
 type invariantType string
@@ -19,6 +22,15 @@ const (
 	betweenInvariant = "between"
 	// Schedulability (enough): zero arguments
 	schedulabilityInvariant = "enough"
+	// Reachability on a port (reachPort): three or four arguments, <from> <to>
+	// <port> [protocol], where the protocol is TCP by default.
+	reachPortInvariant = "reachPort"
+	// Replica count (replicas): two or three arguments, <label> <min> [max]
+	replicasInvariant = "replicas"
+	// Isolation (isolated): one argument, <label>
+	isolationInvariant = "isolated"
+	// Placement spread (spread): two arguments, <label> <machines>
+	spreadInvariant = "spread"
 )
 
 // Annotations.
@@ -60,11 +72,104 @@ func init() {
 		reachACLInvariant:       reachACLImpl,
 		betweenInvariant:        betweenImpl,
 		schedulabilityInvariant: schedulabilityImpl,
+		reachPortInvariant:      reachPortImpl,
+		replicasInvariant:       replicasImpl,
+		isolationInvariant:      isolationImpl,
+		spreadInvariant:         spreadImpl,
+	}
+}
+
+// formNodes is the minimum and maximum number of nodes that each form of invariant
+// operates on.
+var formNodes = map[invariantType]struct{ min, max int }{
+	reachInvariant:          {2, 2},
+	neighborInvariant:       {2, 2},
+	reachACLInvariant:       {2, 2},
+	betweenInvariant:        {3, 3},
+	schedulabilityInvariant: {0, 0},
+	reachPortInvariant:      {3, 4},
+	replicasInvariant:       {2, 3},
+	isolationInvariant:      {1, 1},
+	spreadInvariant:         {2, 2},
+}
+
+// check returns an error if the invariant can't be evaluated.
+func (inv invariant) check() error {
+	nodes, ok := formNodes[inv.Form]
+	if !ok {
+		return fmt.Errorf("unknown invariant form %q", inv.Form)
 	}
+
+	if len(inv.Nodes) < nodes.min || len(inv.Nodes) > nodes.max {
+		if nodes.min == nodes.max {
+			return fmt.Errorf("%s invariants take %d nodes, not %d",
+				inv.Form, nodes.min, len(inv.Nodes))
+		}
+		return fmt.Errorf("%s invariants take %d to %d nodes, not %d",
+			inv.Form, nodes.min, nodes.max, len(inv.Nodes))
+	}
+
+	for _, node := range inv.numbers() {
+		if n, err := strconv.Atoi(node); err != nil || n < 0 {
+			return fmt.Errorf("%q is not a non-negative integer", node)
+		}
+	}
+
+	if inv.Form == reachPortInvariant {
+		if port := inv.number(2); port < 1 || port > 65535 {
+			return fmt.Errorf("%d is not a valid port", port)
+		}
+
+		if protocol := inv.protocol(); protocol != TCP && protocol != UDP {
+			return fmt.Errorf("%s invariants apply to %s or %s, not %q",
+				inv.Form, TCP, UDP, protocol)
+		}
+	}
+	return nil
+}
+
+// labels returns the nodes of the invariant that name labels.
+func (inv invariant) labels() []string {
+	switch inv.Form {
+	case reachPortInvariant:
+		return inv.Nodes[:2]
+	case replicasInvariant, spreadInvariant:
+		return inv.Nodes[:1]
+	default:
+		return inv.Nodes
+	}
+}
+
+// numbers returns the nodes of the invariant that name numbers.  They follow its
+// labels, and only reachPort invariants have nodes after them.
+func (inv invariant) numbers() []string {
+	nodes := inv.Nodes[len(inv.labels()):]
+	if inv.Form == reachPortInvariant {
+		return nodes[:1]
+	}
+	return nodes
+}
+
+// protocol returns the protocol of a reachPort invariant.
+func (inv invariant) protocol() string {
+	if len(inv.Nodes) > 3 {
+		return inv.Nodes[3]
+	}
+	return TCP
+}
+
+// number returns the numeric node at index `i`, which `check` has verified.
+func (inv invariant) number(i int) int {
+	n, _ := strconv.Atoi(inv.Nodes[i])
+	return n
 }
 
 func checkInvariants(graph Graph, invs []invariant) error {
 	for _, asrt := range invs {
+		if err := asrt.check(); err != nil {
+			return err
+		}
+
 		if val := formImpls[asrt.Form](graph, asrt); !val {
 			return invariantError{asrt}
 		}
@@ -218,3 +323,68 @@ func schedulabilityImpl(graph Graph, inv invariant) bool {
 	}
 	return len(machines) >= len(avSets)
 }
+
+func reachPortImpl(graph Graph, inv invariant) bool {
+	port, protocol := inv.number(2), inv.protocol()
+	for _, from := range graph.labelNodes(inv.Nodes[0]) {
+		for _, to := range graph.labelNodes(inv.Nodes[1]) {
+			reachable := false
+			for _, ports := range from.Ports[to.Name] {
+				reachable = reachable || ports.Allows(protocol, port)
+			}
+			if reachable != inv.Target {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func replicasImpl(graph Graph, inv invariant) bool {
+	count := len(graph.labelNodes(inv.Nodes[0]))
+	inBounds := count >= inv.number(1) &&
+		(len(inv.Nodes) < 3 || count <= inv.number(2))
+	return inBounds == inv.Target
+}
+
+// isolationImpl checks that no container in the label may connect to, or accept
+// connections from, anything outside the label, including the public internet.
+func isolationImpl(graph Graph, inv invariant) bool {
+	label := inv.Nodes[0]
+	isolated := true
+	for _, node := range graph.Nodes {
+		for _, peer := range node.Connections {
+			if (node.Label == label) != (peer.Label == label) {
+				isolated = false
+			}
+		}
+	}
+	return isolated == inv.Target
+}
+
+// spreadImpl checks that the containers of the label are guaranteed to run on at
+// least the given number of distinct machines.  That is, there must be enough
+// containers and workers, and exclusive placement rules must keep every pair of
+// the label's containers apart.
+func spreadImpl(graph Graph, inv invariant) bool {
+	machines := inv.number(1)
+	nodes := graph.labelNodes(inv.Nodes[0])
+
+	var workers int
+	for _, m := range graph.Machines {
+		if m.Role == string(db.Worker) {
+			workers++
+		}
+	}
+
+	spread := len(nodes) >= machines && workers >= machines
+	for _, a := range nodes {
+		for _, b := range nodes {
+			apart := contains(graph.Placement[a.Name], b.Name)
+			if a.Name != b.Name && !apart {
+				spread = false
+			}
+		}
+	}
+	return spread == inv.Target
+}
@@ -1,7 +1,10 @@
 package stitch
 
 import (
+	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestReach(t *testing.T) {
@@ -438,3 +441,130 @@ func TestPlacementInvs(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestReachPort(t *testing.T) {
+	stc := invariantStitch(map[string]int{"proxy": 1, "web": 2}, []Connection{
+		{From: "public", To: "proxy", MinPort: 443, MaxPort: 443},
+		{From: "proxy", To: "web", MinPort: 8000, MaxPort: 8080},
+	})
+
+	checkInvariant(t, stc, true, reachPortInvariant, "public", "proxy", "443")
+	checkInvariant(t, stc, true, reachPortInvariant, "public", "proxy", "443", UDP)
+	checkInvariant(t, stc, false, reachPortInvariant, "public", "proxy", "80")
+	checkInvariant(t, stc, false, reachPortInvariant, "public", "web", "443")
+	checkInvariant(t, stc, true, reachPortInvariant, "proxy", "web", "8000")
+	checkInvariant(t, stc, true, reachPortInvariant, "proxy", "web", "8080")
+	checkInvariant(t, stc, false, reachPortInvariant, "proxy", "web", "8081")
+	checkInvariant(t, stc, false, reachPortInvariant, "web", "proxy", "8000")
+
+	// Connections restricted to a protocol only satisfy invariants on it, and
+	// ICMP connections have no ports to satisfy them with.
+	stc = invariantStitch(map[string]int{"dns": 1, "ping": 1}, []Connection{
+		{From: "public", To: "dns", MinPort: 53, MaxPort: 53, Protocol: UDP},
+		{From: "public", To: "ping", Protocol: ICMP},
+	})
+
+	checkInvariant(t, stc, false, reachPortInvariant, "public", "dns", "53")
+	checkInvariant(t, stc, false, reachPortInvariant, "public", "dns", "53", TCP)
+	checkInvariant(t, stc, true, reachPortInvariant, "public", "dns", "53", UDP)
+	checkInvariant(t, stc, false, reachPortInvariant, "public", "ping", "53", UDP)
+	checkInvariant(t, stc, false, reachPortInvariant, "public", "ping", "1")
+}
+
+func TestReplicas(t *testing.T) {
+	stc := invariantStitch(map[string]int{"mongo": 3}, nil)
+
+	checkInvariant(t, stc, true, replicasInvariant, "mongo", "3")
+	checkInvariant(t, stc, true, replicasInvariant, "mongo", "2", "3")
+	checkInvariant(t, stc, false, replicasInvariant, "mongo", "4")
+	checkInvariant(t, stc, false, replicasInvariant, "mongo", "1", "2")
+	checkInvariant(t, stc, false, replicasInvariant, "missing", "1")
+}
+
+func TestIsolated(t *testing.T) {
+	stc := invariantStitch(map[string]int{"a": 2, "b": 1, "c": 1}, []Connection{
+		{From: "a", To: "a", MinPort: 22, MaxPort: 22},
+		{From: "b", To: "public", MinPort: 80, MaxPort: 80},
+		{From: "public", To: "c", MinPort: 80, MaxPort: 80},
+	})
+
+	checkInvariant(t, stc, true, isolationInvariant, "a")
+	checkInvariant(t, stc, false, isolationInvariant, "b")
+	checkInvariant(t, stc, false, isolationInvariant, "c")
+}
+
+func TestSpread(t *testing.T) {
+	stc := invariantStitch(map[string]int{"mongo": 3, "web": 2}, nil)
+	stc.Placements = []Placement{
+		{TargetLabel: "mongo", OtherLabel: "mongo", Exclusive: true},
+	}
+	stc.Machines = []Machine{{Role: "Master"}, {Role: "Worker"},
+		{Role: "Worker"}, {Role: "Worker"}}
+
+	checkInvariant(t, stc, true, spreadInvariant, "mongo", "3")
+	checkInvariant(t, stc, false, spreadInvariant, "mongo", "4")
+	checkInvariant(t, stc, false, spreadInvariant, "web", "2")
+
+	stc.Machines = stc.Machines[:3]
+	checkInvariant(t, stc, false, spreadInvariant, "mongo", "3")
+	checkInvariant(t, stc, true, spreadInvariant, "mongo", "2")
+}
+
+func TestInvariantCheck(t *testing.T) {
+	check := func(expErr string, form invariantType, nodes ...string) {
+		err := invariant{Form: form, Nodes: nodes}.check()
+		if expErr == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, expErr)
+		}
+	}
+
+	check("", replicasInvariant, "a", "1")
+	check("", replicasInvariant, "a", "1", "2")
+	check("replicas invariants take 2 to 3 nodes, not 1", replicasInvariant, "a")
+	check(`"many" is not a non-negative integer`, spreadInvariant, "a", "many")
+	check(`"-1" is not a non-negative integer`, replicasInvariant, "a", "-1")
+	check("70000 is not a valid port", reachPortInvariant, "a", "b", "70000")
+	check("", reachPortInvariant, "a", "b", "53", "udp")
+	check(`reachPort invariants apply to tcp or udp, not "icmp"`,
+		reachPortInvariant, "a", "b", "53", "icmp")
+	check(`"x" is not a non-negative integer`, reachPortInvariant, "a", "b", "x",
+		"tcp")
+	check("isolated invariants take 1 nodes, not 2", isolationInvariant, "a", "b")
+
+	assert.Equal(t, []string{"a", "b"},
+		invariant{Form: reachPortInvariant, Nodes: []string{"a", "b", "80"}}.
+			labels())
+}
+
+// invariantStitch returns a Stitch with the given number of containers in each
+// label.
+func invariantStitch(labels map[string]int, conns []Connection) Stitch {
+	stc := Stitch{Connections: conns}
+	for name, count := range labels {
+		label := Label{Name: name}
+		for i := 0; i < count; i++ {
+			id := fmt.Sprintf("%s%d", name, i)
+			stc.Containers = append(stc.Containers,
+				Container{ID: id, Image: Image{Name: "image"}})
+			label.IDs = append(label.IDs, id)
+		}
+		stc.Labels = append(stc.Labels, label)
+	}
+	return stc
+}
+
+func checkInvariant(t *testing.T, stc Stitch, exp bool, form invariantType,
+	nodes ...string) {
+
+	inv := invariant{Form: form, Target: true, Nodes: nodes}
+	assert.NoError(t, inv.check())
+
+	graph, err := InitializeGraph(stc)
+	assert.NoError(t, err)
+	assert.Equal(t, exp, formImpls[form](graph, inv), "%s", inv)
+
+	inv.Target = false
+	assert.Equal(t, !exp, formImpls[form](graph, inv), "%s", inv)
+}
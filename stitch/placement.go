@@ -147,7 +147,7 @@ func (g *Graph) placeNodes() {
 		if _, ok := g.Nodes[node]; !ok {
 			panic(
 				fmt.Errorf(
-					"invalid node: %s, nodes: %s",
+					"invalid node: %s, nodes: %v",
 					node,
 					g.getNodes(),
 				),
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 )
 
 // A Stitch is an abstract representation of the policy language.
@@ -49,6 +52,22 @@ type Image struct {
 }
 
 // A Container may be instantiated in the stitch and queried by users.
+//
+// The CPU (in cores) and memory (in MiB) requests are reserved for the container
+// when it's scheduled, and the limits cap what it may use.  A request defaults to
+// its limit, and zero values are unrestricted.
+//
+// The data in Volumes is stored on the machine running the container, so containers
+// with volumes stay on that machine, unless they're Movable.
+//
+// Containers that exit are restarted according to their RestartPolicy, which
+// defaults to RestartAlways.  RestartOnFailure containers are restarted at most
+// MaxRetries times, or indefinitely if it's zero.
+//
+// SecretEnv and SecretFiles map environment variables and file paths to the names
+// of the secrets that hold their values.  The values are set with `quilt secret`
+// rather than in the blueprint, and are only decrypted by the worker running the
+// container.
 type Container struct {
 	ID                string            `json:",omitempty"`
 	Image             Image             `json:",omitempty"`
@@ -56,6 +75,56 @@ type Container struct {
 	Env               map[string]string `json:",omitempty"`
 	FilepathToContent map[string]string `json:",omitempty"`
 	Hostname          string            `json:",omitempty"`
+
+	CPURequest    float64 `json:",omitempty"`
+	CPULimit      float64 `json:",omitempty"`
+	MemoryRequest int     `json:",omitempty"`
+	MemoryLimit   int     `json:",omitempty"`
+
+	Volumes []Volume `json:",omitempty"`
+	Movable bool     `json:",omitempty"`
+
+	HealthCheck *HealthCheck `json:",omitempty"`
+
+	RestartPolicy string `json:",omitempty"`
+	MaxRetries    int    `json:",omitempty"`
+
+	SecretEnv   map[string]string `json:",omitempty"`
+	SecretFiles map[string]string `json:",omitempty"`
+}
+
+// The policies for restarting containers that exit.
+const (
+	RestartAlways    = "always"
+	RestartOnFailure = "on-failure"
+	RestartNever     = "never"
+)
+
+// A HealthCheck determines whether a container is serving, and so whether it's
+// reachable through the load balancers and DNS names of its labels.  If Command is
+// set, it's run in the container and passes if it exits with status 0.  Otherwise,
+// HTTPPath is requested from Port and passes with a 2xx or 3xx response, or if
+// there's no HTTPPath, the check passes if Port accepts TCP connections.
+//
+// Checks run every Interval seconds, fail if they take longer than Timeout
+// seconds, and the container is unhealthy after Retries consecutive failures.
+type HealthCheck struct {
+	Command  []string `json:",omitempty"`
+	HTTPPath string   `json:",omitempty"`
+	Port     int      `json:",omitempty"`
+
+	Interval int `json:",omitempty"`
+	Timeout  int `json:",omitempty"`
+	Retries  int `json:",omitempty"`
+}
+
+// A Volume mounts storage into a container at MountPath.  The storage is either the
+// Docker volume called Name, or the directory HostPath of the machine.
+type Volume struct {
+	Name      string `json:",omitempty"`
+	HostPath  string `json:",omitempty"`
+	MountPath string `json:",omitempty"`
+	ReadOnly  bool   `json:",omitempty"`
 }
 
 // A Label represents a logical group of containers.
@@ -66,14 +135,29 @@ type Label struct {
 }
 
 // A Connection allows containers implementing the From label to speak to containers
-// implementing the To label in ports in the range [MinPort, MaxPort]
+// implementing the To label in ports in the range [MinPort, MaxPort].  If Protocol
+// is set, only that protocol is allowed.  Otherwise, TCP, UDP and ICMP all are.
+//
+// Connections from the public internet are received by the containers on the same
+// ports, unless ContainerPort is set, in which case the single public port MinPort
+// is forwarded to ContainerPort.
 type Connection struct {
-	From    string `json:",omitempty"`
-	To      string `json:",omitempty"`
-	MinPort int    `json:",omitempty"`
-	MaxPort int    `json:",omitempty"`
+	From          string `json:",omitempty"`
+	To            string `json:",omitempty"`
+	MinPort       int    `json:",omitempty"`
+	MaxPort       int    `json:",omitempty"`
+	Protocol      string `json:",omitempty"`
+	ContainerPort int    `json:",omitempty"`
 }
 
+// The protocols to which a Connection may be restricted.  ICMP connections ignore
+// their ports.
+const (
+	TCP  = "tcp"
+	UDP  = "udp"
+	ICMP = "icmp"
+)
+
 // A ConnectionSlice allows for slices of Collections to be used in joins
 type ConnectionSlice []Connection
 
@@ -110,8 +194,33 @@ func (stitchr Range) Accepts(x float64) bool {
 
 var lookPath = exec.LookPath
 
-// FromFile gets a Stitch handle from a file on disk.
+// FromFile gets a Stitch handle from a file on disk.  YAML (.yml or .yaml) and JSON
+// (.json) blueprints are loaded natively, and any other file is evaluated as a
+// Javascript blueprint with Node.js.
 func FromFile(filename string) (Stitch, error) {
+	var parse func(string) (Stitch, error)
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yml", ".yaml":
+		parse = FromYAML
+	case ".json":
+		parse = fromJSONFile
+	default:
+		return fromJavascript(filename)
+	}
+
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return Stitch{}, err
+	}
+
+	stc, err := parse(string(contents))
+	if err != nil {
+		return Stitch{}, fmt.Errorf("%s: %s", filename, err)
+	}
+	return stc, nil
+}
+
+func fromJavascript(filename string) (Stitch, error) {
 	if _, err := lookPath("node"); err != nil {
 		return Stitch{}, errors.New(
 			"failed to locate Node.js. Is it installed and in your PATH?")
@@ -137,6 +246,14 @@ func FromFile(filename string) (Stitch, error) {
 	return FromJSON(string(out))
 }
 
+func fromJSONFile(jsonStr string) (Stitch, error) {
+	stc, err := FromJSON(jsonStr)
+	if err != nil {
+		return Stitch{}, jsonError(jsonStr, err)
+	}
+	return stc, nil
+}
+
 // FromJSON gets a Stitch handle from the deployment representation.
 func FromJSON(jsonStr string) (stc Stitch, err error) {
 	err = json.Unmarshal([]byte(jsonStr), &stc)
@@ -144,20 +261,25 @@ func FromJSON(jsonStr string) (stc Stitch, err error) {
 		return Stitch{}, err
 	}
 
+	if err := evalInvariants(stc); err != nil {
+		return Stitch{}, err
+	}
+
+	return stc, nil
+}
+
+// evalInvariants returns an error if any of the invariants of `stc` fail.
+func evalInvariants(stc Stitch) error {
 	if len(stc.Invariants) == 0 {
-		return stc, nil
+		return nil
 	}
 
 	graph, err := InitializeGraph(stc)
 	if err != nil {
-		return Stitch{}, err
+		return err
 	}
 
-	if err := checkInvariants(graph, stc.Invariants); err != nil {
-		return Stitch{}, err
-	}
-
-	return stc, nil
+	return checkInvariants(graph, stc.Invariants)
 }
 
 // String returns the Stitch in its deployment representation.
@@ -0,0 +1,376 @@
+package stitch
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/quilt/quilt/db"
+)
+
+// A ValidationError lists every problem found in a Stitch by Validate.
+type ValidationError []string
+
+func (err ValidationError) Error() string {
+	return fmt.Sprintf("invalid blueprint:\n  %s", strings.Join(err, "\n  "))
+}
+
+// Validate checks that the Stitch is internally consistent, and that it only uses
+// providers, roles and ports that Quilt can deploy.  It returns a ValidationError
+// describing every problem it finds, or nil if there are none.
+func (stitch Stitch) Validate() error {
+	var v validator
+	labels := v.labels(stitch)
+	v.containers(stitch)
+	v.connections(stitch, labels)
+	v.placements(stitch, labels)
+	v.machines(stitch)
+	v.invariants(stitch, labels)
+
+	if len(v) == 0 {
+		return nil
+	}
+	return ValidationError(v)
+}
+
+type validator []string
+
+func (v *validator) errorf(format string, args ...interface{}) {
+	*v = append(*v, fmt.Sprintf(format, args...))
+}
+
+// labels validates the labels of `stitch`, and returns the set of label names
+// that may be referred to by other objects.
+func (v *validator) labels(stitch Stitch) map[string]struct{} {
+	containers := map[string]struct{}{}
+	for _, c := range stitch.Containers {
+		containers[c.ID] = struct{}{}
+	}
+
+	labels := map[string]struct{}{PublicInternetLabel: {}}
+	for _, label := range stitch.Labels {
+		switch {
+		case label.Name == "":
+			v.errorf("label with containers %v: missing name", label.IDs)
+			continue
+		case label.Name == PublicInternetLabel:
+			v.errorf("label %q: name is reserved for the public internet",
+				label.Name)
+		default:
+			if _, ok := labels[label.Name]; ok {
+				v.errorf("label %q: name is used by another label",
+					label.Name)
+			}
+		}
+		labels[label.Name] = struct{}{}
+
+		for _, id := range label.IDs {
+			if _, ok := containers[id]; !ok {
+				v.errorf("label %q: undefined container %q",
+					label.Name, id)
+			}
+		}
+	}
+	return labels
+}
+
+func (v *validator) containers(stitch Stitch) {
+	ids := map[string]struct{}{}
+	hostnames := map[string]string{}
+	dockerfiles := map[string]string{}
+	for _, c := range stitch.Containers {
+		if c.ID == "" {
+			v.errorf("container with image %q: missing ID", c.Image.Name)
+		} else if _, ok := ids[c.ID]; ok {
+			v.errorf("container %q: ID is used by another container", c.ID)
+		}
+		ids[c.ID] = struct{}{}
+
+		if c.Image.Name == "" {
+			v.errorf("container %q: missing image", c.ID)
+		} else if c.Image.Dockerfile != "" {
+			dockerfile, ok := dockerfiles[c.Image.Name]
+			if ok && dockerfile != c.Image.Dockerfile {
+				v.errorf("container %q: image %q is built from "+
+					"different Dockerfiles", c.ID, c.Image.Name)
+			}
+			dockerfiles[c.Image.Name] = c.Image.Dockerfile
+		}
+
+		name := fmt.Sprintf("container %q", c.ID)
+		v.resource(name, "CPU", c.CPURequest, c.CPULimit)
+		v.resource(name, "memory", float64(c.MemoryRequest),
+			float64(c.MemoryLimit))
+		v.volumes(name, c.Volumes)
+		if c.HealthCheck != nil {
+			v.healthCheck(name, *c.HealthCheck)
+		}
+
+		v.secrets(name, c)
+
+		switch c.RestartPolicy {
+		case "", RestartAlways, RestartNever:
+			if c.MaxRetries != 0 {
+				v.errorf("%s: only on-failure restart policies have "+
+					"max retries", name)
+			}
+		case RestartOnFailure:
+			if c.MaxRetries < 0 {
+				v.errorf("%s: negative max retries", name)
+			}
+		default:
+			v.errorf("%s: unknown restart policy %q", name, c.RestartPolicy)
+		}
+
+		if c.Hostname == "" {
+			continue
+		}
+		if other, ok := hostnames[c.Hostname]; ok {
+			v.errorf("container %q: hostname %q is used by container %q",
+				c.ID, c.Hostname, other)
+		} else {
+			hostnames[c.Hostname] = c.ID
+		}
+	}
+}
+
+func (v *validator) resource(name, resource string, request, limit float64) {
+	switch {
+	case request < 0 || limit < 0:
+		v.errorf("%s: negative %s", name, resource)
+	case limit != 0 && request > limit:
+		v.errorf("%s: %s request %v exceeds its limit %v", name, resource,
+			request, limit)
+	}
+}
+
+// The names Docker accepts for volumes.
+var volumeName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+func (v *validator) volumes(name string, volumes []Volume) {
+	mountPaths := map[string]struct{}{}
+	for _, vol := range volumes {
+		switch {
+		case vol.Name != "" && vol.HostPath != "":
+			v.errorf("%s: volume at %q has both a name and a host path",
+				name, vol.MountPath)
+		case vol.Name == "" && vol.HostPath == "":
+			v.errorf("%s: volume at %q has neither a name nor a host path",
+				name, vol.MountPath)
+		case vol.Name != "" && !volumeName.MatchString(vol.Name):
+			v.errorf("%s: invalid volume name %q", name, vol.Name)
+		case vol.HostPath != "" && !path.IsAbs(vol.HostPath):
+			v.errorf("%s: volume host path %q isn't absolute", name,
+				vol.HostPath)
+		}
+
+		if !path.IsAbs(vol.MountPath) {
+			v.errorf("%s: volume mount path %q isn't absolute", name,
+				vol.MountPath)
+		} else if _, ok := mountPaths[vol.MountPath]; ok {
+			v.errorf("%s: mount path %q is used by another volume", name,
+				vol.MountPath)
+		}
+		mountPaths[vol.MountPath] = struct{}{}
+	}
+}
+
+// The names of secrets, as they are given to `quilt secret set`.
+var secretName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+func (v *validator) secrets(name string, c Container) {
+	for _, key := range sortedKeys(c.SecretEnv) {
+		if _, ok := c.Env[key]; ok {
+			v.errorf("%s: environment variable %q is also a secret", name,
+				key)
+		}
+		v.secretName(name, c.SecretEnv[key])
+	}
+
+	for _, file := range sortedKeys(c.SecretFiles) {
+		if _, ok := c.FilepathToContent[file]; ok {
+			v.errorf("%s: file %q is also a secret", name, file)
+		}
+		v.secretName(name, c.SecretFiles[file])
+	}
+}
+
+func (v *validator) secretName(name, secret string) {
+	if !secretName.MatchString(secret) {
+		v.errorf("%s: invalid secret name %q", name, secret)
+	}
+}
+
+// sortedKeys returns the keys of `m` in order, so that errors are reported
+// deterministically.
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (v *validator) healthCheck(name string, check HealthCheck) {
+	switch {
+	case len(check.Command) != 0 && (check.Port != 0 || check.HTTPPath != ""):
+		v.errorf("%s: health check has both a command and a port", name)
+	case len(check.Command) == 0 && check.Port == 0:
+		v.errorf("%s: health check has neither a command nor a port", name)
+	case check.Port < 0 || check.Port > 65535:
+		v.errorf("%s: invalid health check port %d", name, check.Port)
+	case check.HTTPPath != "" && !strings.HasPrefix(check.HTTPPath, "/"):
+		v.errorf("%s: health check path %q isn't absolute", name,
+			check.HTTPPath)
+	}
+
+	if check.Interval < 0 || check.Timeout < 0 || check.Retries < 0 {
+		v.errorf("%s: negative health check interval, timeout or retries",
+			name)
+	}
+}
+
+func (v *validator) connections(stitch Stitch, labels map[string]struct{}) {
+	for _, conn := range stitch.Connections {
+		name := fmt.Sprintf("connection %s->%s", conn.From, conn.To)
+		for _, label := range []string{conn.From, conn.To} {
+			if _, ok := labels[label]; !ok {
+				v.errorf("%s: undefined label %q", name, label)
+			}
+		}
+
+		if conn.From == PublicInternetLabel && conn.To == PublicInternetLabel {
+			v.errorf("%s: the public internet can't connect to itself", name)
+		}
+
+		switch conn.Protocol {
+		case ICMP:
+			if conn.ContainerPort != 0 {
+				v.errorf("%s: ICMP connections have no container port",
+					name)
+			}
+			continue
+		case "", TCP, UDP:
+		default:
+			v.errorf("%s: unknown protocol %q", name, conn.Protocol)
+		}
+
+		if conn.MinPort < 1 || conn.MaxPort > 65535 ||
+			conn.MinPort > conn.MaxPort {
+			v.errorf("%s: invalid port range %d-%d", name,
+				conn.MinPort, conn.MaxPort)
+		}
+
+		if conn.ContainerPort != 0 {
+			v.containerPort(name, conn)
+		}
+	}
+}
+
+// containerPort validates the forwarding of a public port to a container port.
+func (v *validator) containerPort(name string, conn Connection) {
+	switch {
+	case conn.From != PublicInternetLabel:
+		v.errorf("%s: only connections from the public internet can be "+
+			"forwarded to a container port", name)
+	case conn.MinPort != conn.MaxPort:
+		v.errorf("%s: port range %d-%d can't be forwarded to a container "+
+			"port", name, conn.MinPort, conn.MaxPort)
+	case conn.ContainerPort < 1 || conn.ContainerPort > 65535:
+		v.errorf("%s: invalid container port %d", name, conn.ContainerPort)
+	}
+}
+
+func (v *validator) placements(stitch Stitch, labels map[string]struct{}) {
+	for _, plcm := range stitch.Placements {
+		name := fmt.Sprintf("placement of %q", plcm.TargetLabel)
+		if _, ok := labels[plcm.TargetLabel]; !ok ||
+			plcm.TargetLabel == PublicInternetLabel {
+			v.errorf("%s: undefined label %q", name, plcm.TargetLabel)
+		}
+
+		if plcm.OtherLabel != "" {
+			if _, ok := labels[plcm.OtherLabel]; !ok {
+				v.errorf("%s: undefined label %q", name, plcm.OtherLabel)
+			}
+		}
+
+		if plcm.Provider != "" {
+			if _, err := db.ParseProvider(plcm.Provider); err != nil {
+				v.errorf("%s: unknown provider %q", name, plcm.Provider)
+			}
+		}
+	}
+}
+
+func (v *validator) machines(stitch Stitch) {
+	var hasMaster, hasWorker bool
+	ids := map[string]struct{}{}
+	floatingIPs := map[string]struct{}{}
+	for _, m := range stitch.Machines {
+		name := fmt.Sprintf("machine %q", m.ID)
+		if _, ok := ids[m.ID]; ok && m.ID != "" {
+			v.errorf("%s: ID is used by another machine", name)
+		}
+		ids[m.ID] = struct{}{}
+
+		if _, err := db.ParseProvider(m.Provider); err != nil {
+			v.errorf("%s: unknown provider %q", name, m.Provider)
+		}
+
+		switch role, err := db.ParseRole(m.Role); {
+		case err != nil:
+			v.errorf("%s: unknown role %q", name, m.Role)
+		case role == db.Master:
+			hasMaster = true
+		case role == db.Worker:
+			hasWorker = true
+		}
+
+		v.machineRange(name, "CPU", m.CPU)
+		v.machineRange(name, "RAM", m.RAM)
+
+		if m.DiskSize < 0 {
+			v.errorf("%s: negative disk size", name)
+		}
+
+		if m.FloatingIP == "" {
+			continue
+		}
+		if _, ok := floatingIPs[m.FloatingIP]; ok {
+			v.errorf("%s: floating IP %s is used by another machine",
+				name, m.FloatingIP)
+		}
+		floatingIPs[m.FloatingIP] = struct{}{}
+	}
+
+	if hasMaster && !hasWorker {
+		v.errorf("machines: a master was specified but no workers")
+	} else if hasWorker && !hasMaster {
+		v.errorf("machines: a worker was specified but no masters")
+	}
+}
+
+func (v *validator) machineRange(name, attr string, rng Range) {
+	if rng.Min < 0 || (rng.Max != 0 && rng.Min > rng.Max) {
+		v.errorf("%s: invalid %s range %v-%v", name, attr, rng.Min, rng.Max)
+	}
+}
+
+func (v *validator) invariants(stitch Stitch, labels map[string]struct{}) {
+	for _, inv := range stitch.Invariants {
+		if err := inv.check(); err != nil {
+			v.errorf("invariant %s: %s", inv, err)
+			continue
+		}
+
+		for _, node := range inv.labels() {
+			if _, ok := labels[node]; !ok {
+				v.errorf("invariant %s: undefined label %q", inv, node)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package stitch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	valid := Stitch{
+		Containers: []Container{
+			{ID: "a", Image: Image{Name: "nginx"}, Hostname: "a",
+				Volumes: []Volume{
+					{Name: "data", MountPath: "/data"},
+					{HostPath: "/etc/ssl", MountPath: "/ssl",
+						ReadOnly: true},
+				}},
+			{ID: "b", Image: Image{Name: "custom", Dockerfile: "FROM nginx"},
+				HealthCheck: &HealthCheck{HTTPPath: "/healthz", Port: 80,
+					Interval: 5}},
+			{ID: "c", Image: Image{Name: "custom", Dockerfile: "FROM nginx"},
+				CPURequest: 0.5, CPULimit: 1, MemoryLimit: 512,
+				HealthCheck:   &HealthCheck{Command: []string{"true"}},
+				RestartPolicy: RestartOnFailure, MaxRetries: 5},
+			{ID: "d", Image: Image{Name: "nginx"},
+				RestartPolicy: RestartNever},
+			{ID: "e", Image: Image{Name: "postgres"},
+				Env:         map[string]string{"PGUSER": "quilt"},
+				SecretEnv:   map[string]string{"PGPASSWORD": "pg.pass"},
+				SecretFiles: map[string]string{"/etc/ssl/key": "key"}},
+		},
+		Labels: []Label{
+			{Name: "web", IDs: []string{"a"}},
+			{Name: "custom", IDs: []string{"b", "c"}},
+		},
+		Connections: []Connection{
+			{From: "public", To: "web", MinPort: 80, MaxPort: 80},
+			{From: "web", To: "custom", MinPort: 1000, MaxPort: 2000},
+			{From: "public", To: "web", MinPort: 53, MaxPort: 53,
+				Protocol: UDP},
+			{From: "public", To: "web", Protocol: ICMP},
+			{From: "public", To: "web", MinPort: 8000, MaxPort: 8100},
+			{From: "public", To: "web", MinPort: 8443, MaxPort: 8443,
+				ContainerPort: 443},
+		},
+		Placements: []Placement{
+			{TargetLabel: "web", Exclusive: true, OtherLabel: "custom"},
+			{TargetLabel: "custom", Provider: "Amazon"},
+		},
+		Machines: []Machine{
+			{ID: "1", Provider: "Amazon", Role: "Master"},
+			{ID: "2", Provider: "Google", Role: "Worker",
+				CPU: Range{Min: 2}, RAM: Range{Min: 2, Max: 4},
+				FloatingIP: "8.8.8.8"},
+		},
+		Invariants: []invariant{
+			{Form: reachInvariant, Target: true,
+				Nodes: []string{"public", "web"}},
+			{Form: schedulabilityInvariant, Target: true},
+		},
+	}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, Stitch{}.Validate())
+
+	invalid := Stitch{
+		Containers: []Container{
+			{ID: "a", Image: Image{Name: "nginx"}, Hostname: "host"},
+			{ID: "a", Hostname: "host"},
+			{Image: Image{Name: "custom", Dockerfile: "FROM nginx"}},
+			{ID: "b", Image: Image{Name: "custom", Dockerfile: "FROM redis"}},
+			{ID: "c", Image: Image{Name: "nginx"}, CPULimit: -1,
+				MemoryRequest: 1024, MemoryLimit: 512},
+			{ID: "d", Image: Image{Name: "nginx"}, Volumes: []Volume{
+				{Name: "data", HostPath: "/data", MountPath: "/a"},
+				{MountPath: "/b"},
+				{Name: "-data", MountPath: "/c"},
+				{HostPath: "data", MountPath: "/c"},
+				{Name: "data", MountPath: "d"},
+			}},
+			{ID: "e", Image: Image{Name: "nginx"},
+				HealthCheck: &HealthCheck{Command: []string{"true"},
+					Port: 80}},
+			{ID: "f", Image: Image{Name: "nginx"},
+				HealthCheck: &HealthCheck{Retries: -1}},
+			{ID: "g", Image: Image{Name: "nginx"},
+				HealthCheck: &HealthCheck{Port: 80, HTTPPath: "healthz"}},
+			{ID: "h", Image: Image{Name: "nginx"},
+				RestartPolicy: "sometimes"},
+			{ID: "i", Image: Image{Name: "nginx"}, MaxRetries: 3},
+			{ID: "j", Image: Image{Name: "nginx"},
+				RestartPolicy: RestartOnFailure, MaxRetries: -1},
+			{ID: "k", Image: Image{Name: "nginx"},
+				Env: map[string]string{"PASS": "plain"},
+				SecretEnv: map[string]string{"PASS": "pass",
+					"KEY": "_key"}},
+			{ID: "l", Image: Image{Name: "nginx"},
+				FilepathToContent: map[string]string{"/key": "plain"},
+				SecretFiles:       map[string]string{"/key": "key/1"}},
+		},
+		Labels: []Label{
+			{Name: "web", IDs: []string{"a", "missing"}},
+			{Name: "web"},
+			{Name: "public"},
+			{IDs: []string{"b"}},
+		},
+		Connections: []Connection{
+			{From: "web", To: "db", MinPort: 80, MaxPort: 80},
+			{From: "public", To: "public", MinPort: 80, MaxPort: 80},
+			{From: "web", To: "web", MinPort: 90, MaxPort: 80},
+			{From: "web", To: "web", MinPort: 0, MaxPort: 70000},
+			{From: "web", To: "web", MinPort: 80, MaxPort: 80,
+				Protocol: "sctp"},
+			{From: "web", To: "web", MinPort: 80, MaxPort: 80,
+				ContainerPort: 8080},
+			{From: "public", To: "web", MinPort: 80, MaxPort: 81,
+				ContainerPort: 8080},
+			{From: "public", To: "web", MinPort: 80, MaxPort: 80,
+				ContainerPort: 70000},
+		},
+		Placements: []Placement{
+			{TargetLabel: "db", OtherLabel: "cache"},
+			{TargetLabel: "public"},
+			{TargetLabel: "web", Provider: "Azure"},
+		},
+		Machines: []Machine{
+			{ID: "1", Provider: "Azure", Role: "Worker",
+				CPU: Range{Min: 4, Max: 2}, DiskSize: -1},
+			{ID: "1", Provider: "Amazon", Role: "Boss",
+				FloatingIP: "8.8.8.8", RAM: Range{Min: -1}},
+			{ID: "2", Provider: "Amazon", Role: "Worker",
+				FloatingIP: "8.8.8.8"},
+		},
+		Invariants: []invariant{
+			{Form: "nearby", Nodes: []string{"web", "db"}},
+			{Form: reachInvariant, Nodes: []string{"web"}},
+			{Form: betweenInvariant, Nodes: []string{"web", "db", "cache"}},
+		},
+	}
+	assert.Equal(t, ValidationError{
+		`label "web": undefined container "missing"`,
+		`label "web": name is used by another label`,
+		`label "public": name is reserved for the public internet`,
+		`label with containers [b]: missing name`,
+		`container "a": ID is used by another container`,
+		`container "a": missing image`,
+		`container "a": hostname "host" is used by container "a"`,
+		`container with image "custom": missing ID`,
+		`container "b": image "custom" is built from different Dockerfiles`,
+		`container "c": negative CPU`,
+		`container "c": memory request 1024 exceeds its limit 512`,
+		`container "d": volume at "/a" has both a name and a host path`,
+		`container "d": volume at "/b" has neither a name nor a host path`,
+		`container "d": invalid volume name "-data"`,
+		`container "d": volume host path "data" isn't absolute`,
+		`container "d": mount path "/c" is used by another volume`,
+		`container "d": volume mount path "d" isn't absolute`,
+		`container "e": health check has both a command and a port`,
+		`container "f": health check has neither a command nor a port`,
+		`container "f": negative health check interval, timeout or retries`,
+		`container "g": health check path "healthz" isn't absolute`,
+		`container "h": unknown restart policy "sometimes"`,
+		`container "i": only on-failure restart policies have max retries`,
+		`container "j": negative max retries`,
+		`container "k": invalid secret name "_key"`,
+		`container "k": environment variable "PASS" is also a secret`,
+		`container "l": file "/key" is also a secret`,
+		`container "l": invalid secret name "key/1"`,
+		`connection web->db: undefined label "db"`,
+		`connection public->public: the public internet can't connect ` +
+			`to itself`,
+		`connection web->web: invalid port range 90-80`,
+		`connection web->web: invalid port range 0-70000`,
+		`connection web->web: unknown protocol "sctp"`,
+		`connection web->web: only connections from the public internet ` +
+			`can be forwarded to a container port`,
+		`connection public->web: port range 80-81 can't be forwarded to ` +
+			`a container port`,
+		`connection public->web: invalid container port 70000`,
+		`placement of "db": undefined label "db"`,
+		`placement of "db": undefined label "cache"`,
+		`placement of "public": undefined label "public"`,
+		`placement of "web": unknown provider "Azure"`,
+		`machine "1": unknown provider "Azure"`,
+		`machine "1": invalid CPU range 4-2`,
+		`machine "1": negative disk size`,
+		`machine "1": ID is used by another machine`,
+		`machine "1": unknown role "Boss"`,
+		`machine "1": invalid RAM range -1-0`,
+		`machine "2": floating IP 8.8.8.8 is used by another machine`,
+		`machines: a worker was specified but no masters`,
+		`invariant nearby false "web" "db": unknown invariant form "nearby"`,
+		`invariant reach false "web": reach invariants take 2 nodes, not 1`,
+		`invariant between false "web" "db" "cache": undefined label "db"`,
+		`invariant between false "web" "db" "cache": undefined label "cache"`,
+	}, invalid.Validate())
+
+	err := Stitch{Machines: []Machine{{Provider: "Amazon", Role: "Master"}}}.
+		Validate()
+	assert.EqualError(t, err, "invalid blueprint:\n"+
+		"  machines: a master was specified but no workers")
+}
@@ -0,0 +1,391 @@
+package stitch
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The YAML blueprint format is a declarative alternative to Javascript blueprints
+// for deployments that don't need a program to describe them.  It maps directly
+// onto the Stitch, except that containers and machines may be replicated with
+// `count`, and labels refer to containers by the ID given in the blueprint.
+type yamlBlueprint struct {
+	Namespace string   `yaml:"namespace"`
+	AdminACL  []string `yaml:"adminACL"`
+	MaxPrice  float64  `yaml:"maxPrice"`
+
+	Machines    []yamlMachine    `yaml:"machines"`
+	Containers  []yamlContainer  `yaml:"containers"`
+	Labels      []yamlLabel      `yaml:"labels"`
+	Connections []yamlConnection `yaml:"connections"`
+	Placements  []yamlPlacement  `yaml:"placements"`
+	Invariants  []yamlInvariant  `yaml:"invariants"`
+}
+
+type yamlMachine struct {
+	ID          string   `yaml:"id"`
+	Count       int      `yaml:"count"`
+	Provider    string   `yaml:"provider"`
+	Role        string   `yaml:"role"`
+	Size        string   `yaml:"size"`
+	CPU         Range    `yaml:"cpu"`
+	RAM         Range    `yaml:"ram"`
+	DiskSize    int      `yaml:"diskSize"`
+	Region      string   `yaml:"region"`
+	SSHKeys     []string `yaml:"sshKeys"`
+	FloatingIP  string   `yaml:"floatingIP"`
+	Preemptible bool     `yaml:"preemptible"`
+}
+
+type yamlContainer struct {
+	ID                string            `yaml:"id"`
+	Count             int               `yaml:"count"`
+	Image             string            `yaml:"image"`
+	Dockerfile        string            `yaml:"dockerfile"`
+	Command           []string          `yaml:"command"`
+	Env               map[string]string `yaml:"env"`
+	FilepathToContent map[string]string `yaml:"filepathToContent"`
+	Hostname          string            `yaml:"hostname"`
+	CPURequest        float64           `yaml:"cpuRequest"`
+	CPULimit          float64           `yaml:"cpuLimit"`
+	MemoryRequest     int               `yaml:"memoryRequest"`
+	MemoryLimit       int               `yaml:"memoryLimit"`
+	Volumes           []yamlVolume      `yaml:"volumes"`
+	Movable           bool              `yaml:"movable"`
+	HealthCheck       *yamlHealthCheck  `yaml:"healthCheck"`
+	RestartPolicy     string            `yaml:"restartPolicy"`
+	MaxRetries        int               `yaml:"maxRetries"`
+	SecretEnv         map[string]string `yaml:"secretEnv"`
+	SecretFiles       map[string]string `yaml:"secretFiles"`
+}
+
+type yamlHealthCheck struct {
+	Command  []string `yaml:"command"`
+	HTTPPath string   `yaml:"httpPath"`
+	Port     int      `yaml:"port"`
+	Interval int      `yaml:"interval"`
+	Timeout  int      `yaml:"timeout"`
+	Retries  int      `yaml:"retries"`
+}
+
+type yamlVolume struct {
+	Name      string `yaml:"name"`
+	HostPath  string `yaml:"hostPath"`
+	MountPath string `yaml:"mountPath"`
+	ReadOnly  bool   `yaml:"readOnly"`
+}
+
+type yamlLabel struct {
+	Name        string   `yaml:"name"`
+	Containers  []string `yaml:"containers"`
+	Annotations []string `yaml:"annotations"`
+}
+
+type yamlConnection struct {
+	From          string `yaml:"from"`
+	To            string `yaml:"to"`
+	Port          int    `yaml:"port"`
+	MinPort       int    `yaml:"minPort"`
+	MaxPort       int    `yaml:"maxPort"`
+	Protocol      string `yaml:"protocol"`
+	ContainerPort int    `yaml:"containerPort"`
+}
+
+type yamlPlacement struct {
+	Target     string `yaml:"target"`
+	Exclusive  bool   `yaml:"exclusive"`
+	OtherLabel string `yaml:"otherLabel"`
+	Provider   string `yaml:"provider"`
+	Size       string `yaml:"size"`
+	Region     string `yaml:"region"`
+	FloatingIP string `yaml:"floatingIP"`
+}
+
+type yamlInvariant struct {
+	Form invariantType `yaml:"form"`
+
+	// Target defaults to true, as invariants usually assert that something holds.
+	Target *bool    `yaml:"target"`
+	Nodes  []string `yaml:"nodes"`
+}
+
+// A lineError is an error in a blueprint file that can be attributed to a line.
+type lineError struct {
+	line int
+	msg  string
+}
+
+func (err lineError) Error() string {
+	return fmt.Sprintf("line %d: %s", err.line, err.msg)
+}
+
+// FromYAML gets a Stitch handle from a blueprint in the YAML blueprint format.
+func FromYAML(yamlStr string) (Stitch, error) {
+	var bp yamlBlueprint
+	decoder := yaml.NewDecoder(strings.NewReader(yamlStr))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&bp); err != nil && err != io.EOF {
+		return Stitch{}, yamlError(err)
+	}
+
+	// The decoded blueprint doesn't remember where each object was defined, so
+	// the document is parsed a second time to attribute errors to lines.
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(yamlStr), &doc); err != nil {
+		return Stitch{}, yamlError(err)
+	}
+
+	stc, err := bp.toStitch(sectionLines(doc))
+	if err != nil {
+		return Stitch{}, err
+	}
+
+	if err := evalInvariants(stc); err != nil {
+		return Stitch{}, err
+	}
+	return stc, nil
+}
+
+func (bp yamlBlueprint) toStitch(lines map[string][]int) (Stitch, error) {
+	stc := Stitch{
+		Namespace: bp.Namespace,
+		AdminACL:  bp.AdminACL,
+		MaxPrice:  bp.MaxPrice,
+	}
+
+	for _, m := range bp.Machines {
+		stc.Machines = append(stc.Machines, m.toStitch()...)
+	}
+	setMachineIDs(stc.Machines)
+
+	replicas := map[string][]string{}
+	for i, c := range bp.Containers {
+		if c.ID == "" {
+			return Stitch{}, lineError{lines["containers"][i],
+				"container has no id"}
+		}
+		if c.Image == "" {
+			return Stitch{}, lineError{lines["containers"][i],
+				fmt.Sprintf("container %q has no image", c.ID)}
+		}
+		if _, ok := replicas[c.ID]; ok {
+			return Stitch{}, lineError{lines["containers"][i],
+				fmt.Sprintf("container id %q is already used", c.ID)}
+		}
+		if c.Hostname != "" && c.Count > 1 {
+			return Stitch{}, lineError{lines["containers"][i], fmt.Sprintf(
+				"replicated container %q can't have a hostname", c.ID)}
+		}
+
+		for _, container := range c.toStitch() {
+			replicas[c.ID] = append(replicas[c.ID], container.ID)
+			stc.Containers = append(stc.Containers, container)
+		}
+	}
+
+	for i, l := range bp.Labels {
+		label := Label{Name: l.Name, Annotations: l.Annotations}
+		for _, id := range l.Containers {
+			ids, ok := replicas[id]
+			if !ok {
+				return Stitch{}, lineError{lines["labels"][i],
+					fmt.Sprintf("label %q refers to undefined "+
+						"container %q", l.Name, id)}
+			}
+			label.IDs = append(label.IDs, ids...)
+		}
+		stc.Labels = append(stc.Labels, label)
+	}
+
+	for i, c := range bp.Connections {
+		conn := Connection{From: c.From, To: c.To, MinPort: c.MinPort,
+			MaxPort: c.MaxPort, Protocol: c.Protocol,
+			ContainerPort: c.ContainerPort}
+		if c.Port != 0 {
+			if c.MinPort != 0 || c.MaxPort != 0 {
+				return Stitch{}, lineError{lines["connections"][i],
+					"connection has both a port and a port range"}
+			}
+			conn.MinPort, conn.MaxPort = c.Port, c.Port
+		}
+		stc.Connections = append(stc.Connections, conn)
+	}
+
+	for _, p := range bp.Placements {
+		stc.Placements = append(stc.Placements, Placement{
+			TargetLabel: p.Target,
+			Exclusive:   p.Exclusive,
+			OtherLabel:  p.OtherLabel,
+			Provider:    p.Provider,
+			Size:        p.Size,
+			Region:      p.Region,
+			FloatingIP:  p.FloatingIP,
+		})
+	}
+
+	for i, inv := range bp.Invariants {
+		target := inv.Target == nil || *inv.Target
+		stitchInv := invariant{Form: inv.Form, Target: target, Nodes: inv.Nodes}
+		if err := stitchInv.check(); err != nil {
+			return Stitch{}, lineError{lines["invariants"][i], err.Error()}
+		}
+		stc.Invariants = append(stc.Invariants, stitchInv)
+	}
+
+	return stc, nil
+}
+
+func (m yamlMachine) toStitch() []Machine {
+	machine := Machine{
+		Provider:    m.Provider,
+		Role:        m.Role,
+		Size:        m.Size,
+		CPU:         m.CPU,
+		RAM:         m.RAM,
+		DiskSize:    m.DiskSize,
+		Region:      m.Region,
+		SSHKeys:     m.SSHKeys,
+		FloatingIP:  m.FloatingIP,
+		Preemptible: m.Preemptible,
+	}
+
+	var machines []Machine
+	for _, id := range replicaIDs(m.ID, m.Count) {
+		machine.ID = id
+		machines = append(machines, machine)
+	}
+	return machines
+}
+
+func (c yamlContainer) toStitch() []Container {
+	var volumes []Volume
+	for _, v := range c.Volumes {
+		volumes = append(volumes, Volume(v))
+	}
+
+	var healthCheck *HealthCheck
+	if c.HealthCheck != nil {
+		check := HealthCheck(*c.HealthCheck)
+		healthCheck = &check
+	}
+
+	var containers []Container
+	for _, id := range replicaIDs(c.ID, c.Count) {
+		containers = append(containers, Container{
+			ID:                id,
+			Image:             Image{Name: c.Image, Dockerfile: c.Dockerfile},
+			Command:           c.Command,
+			Env:               c.Env,
+			FilepathToContent: c.FilepathToContent,
+			Hostname:          c.Hostname,
+			CPURequest:        c.CPURequest,
+			CPULimit:          c.CPULimit,
+			MemoryRequest:     c.MemoryRequest,
+			MemoryLimit:       c.MemoryLimit,
+			Volumes:           volumes,
+			Movable:           c.Movable,
+			HealthCheck:       healthCheck,
+			RestartPolicy:     c.RestartPolicy,
+			MaxRetries:        c.MaxRetries,
+			SecretEnv:         c.SecretEnv,
+			SecretFiles:       c.SecretFiles,
+		})
+	}
+	return containers
+}
+
+// replicaIDs returns the IDs of `count` replicas of the object with the given ID.
+// An object that isn't replicated keeps its ID.
+func replicaIDs(id string, count int) []string {
+	if count <= 1 {
+		return []string{id}
+	}
+
+	var ids []string
+	for i := 1; i <= count; i++ {
+		if id == "" {
+			ids = append(ids, "")
+		} else {
+			ids = append(ids, fmt.Sprintf("%s-%d", id, i))
+		}
+	}
+	return ids
+}
+
+// setMachineIDs deterministically sets the ID of machines that weren't given one
+// based on their attributes, so that deploying the same blueprint again doesn't
+// replace them.  Identical machines are told apart by their order.
+func setMachineIDs(machines []Machine) {
+	seen := map[string]int{}
+	for i, m := range machines {
+		if m.ID != "" {
+			continue
+		}
+
+		// SSH keys are left out so that changing them doesn't replace machines.
+		m.SSHKeys = nil
+		attrs, err := json.Marshal(m)
+		if err != nil {
+			panic(err)
+		}
+
+		key := string(attrs)
+		machines[i].ID = fmt.Sprintf("%x", sha1.Sum(
+			[]byte(fmt.Sprintf("%s%d", key, seen[key]))))
+		seen[key]++
+	}
+}
+
+// sectionLines returns the line of each item in the top level lists of a YAML
+// document, keyed by the name of the list.
+func sectionLines(doc yaml.Node) map[string][]int {
+	lines := map[string][]int{}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return lines
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return lines
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		for _, item := range value.Content {
+			lines[key.Value] = append(lines[key.Value], item.Line)
+		}
+	}
+	return lines
+}
+
+func yamlError(err error) error {
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		return errors.New(strings.Join(typeErr.Errors, "\n"))
+	}
+	return errors.New(strings.TrimPrefix(err.Error(), "yaml: "))
+}
+
+// jsonError adds the line at which a JSON blueprint failed to parse to `err`.
+func jsonError(jsonStr string, err error) error {
+	var offset int64
+	switch jsonErr := err.(type) {
+	case *json.SyntaxError:
+		offset = jsonErr.Offset
+	case *json.UnmarshalTypeError:
+		offset = jsonErr.Offset
+	default:
+		return err
+	}
+
+	if offset > int64(len(jsonStr)) {
+		offset = int64(len(jsonStr))
+	}
+	line := strings.Count(jsonStr[:offset], "\n") + 1
+	return lineError{line, err.Error()}
+}
@@ -0,0 +1,231 @@
+package stitch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const yamlBlueprintStr = `namespace: example
+adminACL: [local]
+maxPrice: 0.5
+machines:
+  - provider: Amazon
+    role: Master
+    size: m4.large
+  - provider: Amazon
+    role: Worker
+    cpu: {min: 2, max: 4}
+    ram: {min: 4}
+    count: 2
+containers:
+  - id: web
+    image: nginx
+    count: 2
+    cpuLimit: 0.5
+    memoryRequest: 128
+    memoryLimit: 256
+    healthCheck: {httpPath: /, port: 80, interval: 5}
+  - id: db
+    image: postgres
+    dockerfile: FROM postgres
+    command: [postgres, -N, "10"]
+    env: {USER: admin}
+    filepathToContent: {/etc/motd: hello}
+    hostname: db
+    volumes:
+      - {name: pgdata, mountPath: /var/lib/postgresql/data}
+      - {hostPath: /etc/ssl, mountPath: /ssl, readOnly: true}
+    restartPolicy: on-failure
+    maxRetries: 5
+    secretEnv: {PGPASSWORD: pg-password}
+labels:
+  - name: web
+    containers: [web]
+  - name: db
+    containers: [db]
+    annotations: [ACL]
+connections:
+  - from: public
+    to: web
+    port: 80
+  - from: web
+    to: db
+    minPort: 5432
+    maxPort: 5433
+  - from: public
+    to: db
+    port: 53
+    protocol: udp
+  - from: public
+    to: web
+    port: 8080
+    containerPort: 80
+placements:
+  - target: db
+    exclusive: true
+    otherLabel: web
+invariants:
+  - form: reach
+    nodes: [web, db]
+  - form: reach
+    target: false
+    nodes: [db, web]
+`
+
+func TestFromYAML(t *testing.T) {
+	stc, err := FromYAML(yamlBlueprintStr)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "example", stc.Namespace)
+	assert.Equal(t, []string{"local"}, stc.AdminACL)
+	assert.Equal(t, 0.5, stc.MaxPrice)
+
+	assert.Len(t, stc.Machines, 3)
+	assert.Equal(t, "Master", stc.Machines[0].Role)
+	assert.Equal(t, "m4.large", stc.Machines[0].Size)
+	assert.Equal(t, Range{Min: 2, Max: 4}, stc.Machines[1].CPU)
+	assert.Equal(t, Range{Min: 4}, stc.Machines[2].RAM)
+
+	// Identical machines get distinct IDs, which don't change between loads.
+	assert.NotEqual(t, "", stc.Machines[1].ID)
+	assert.NotEqual(t, stc.Machines[1].ID, stc.Machines[2].ID)
+	again, err := FromYAML(yamlBlueprintStr)
+	assert.NoError(t, err)
+	assert.Equal(t, stc.Machines, again.Machines)
+
+	assert.Equal(t, []Container{
+		{ID: "web-1", Image: Image{Name: "nginx"}, CPULimit: 0.5,
+			MemoryRequest: 128, MemoryLimit: 256,
+			HealthCheck: &HealthCheck{HTTPPath: "/", Port: 80,
+				Interval: 5}},
+		{ID: "web-2", Image: Image{Name: "nginx"}, CPULimit: 0.5,
+			MemoryRequest: 128, MemoryLimit: 256,
+			HealthCheck: &HealthCheck{HTTPPath: "/", Port: 80,
+				Interval: 5}},
+		{
+			ID:      "db",
+			Image:   Image{Name: "postgres", Dockerfile: "FROM postgres"},
+			Command: []string{"postgres", "-N", "10"},
+			Env:     map[string]string{"USER": "admin"},
+			FilepathToContent: map[string]string{
+				"/etc/motd": "hello"},
+			Hostname: "db",
+			Volumes: []Volume{
+				{Name: "pgdata", MountPath: "/var/lib/postgresql/data"},
+				{HostPath: "/etc/ssl", MountPath: "/ssl", ReadOnly: true},
+			},
+			RestartPolicy: RestartOnFailure,
+			MaxRetries:    5,
+			SecretEnv:     map[string]string{"PGPASSWORD": "pg-password"},
+		},
+	}, stc.Containers)
+
+	assert.Equal(t, []Label{
+		{Name: "web", IDs: []string{"web-1", "web-2"}},
+		{Name: "db", IDs: []string{"db"}, Annotations: []string{"ACL"}},
+	}, stc.Labels)
+
+	assert.Equal(t, []Connection{
+		{From: "public", To: "web", MinPort: 80, MaxPort: 80},
+		{From: "web", To: "db", MinPort: 5432, MaxPort: 5433},
+		{From: "public", To: "db", MinPort: 53, MaxPort: 53, Protocol: UDP},
+		{From: "public", To: "web", MinPort: 8080, MaxPort: 8080,
+			ContainerPort: 80},
+	}, stc.Connections)
+
+	assert.Equal(t, []Placement{
+		{TargetLabel: "db", Exclusive: true, OtherLabel: "web"},
+	}, stc.Placements)
+
+	assert.Equal(t, []invariant{
+		{Form: reachInvariant, Target: true, Nodes: []string{"web", "db"}},
+		{Form: reachInvariant, Target: false, Nodes: []string{"db", "web"}},
+	}, stc.Invariants)
+
+	stc, err = FromYAML("")
+	assert.NoError(t, err)
+	assert.Equal(t, Stitch{}, stc)
+}
+
+func TestFromYAMLErrors(t *testing.T) {
+	check := func(yamlStr, expErr string) {
+		_, err := FromYAML(yamlStr)
+		assert.EqualError(t, err, expErr)
+	}
+
+	check("namespace: foo\nmachine: []\n",
+		"line 2: field machine not found in type stitch.yamlBlueprint")
+	check("maxPrice: cheap\n",
+		"line 1: cannot unmarshal !!str `cheap` into float64")
+	check("containers:\n  - {id: web, image: [nginx]}\n",
+		"line 2: cannot unmarshal !!seq into string")
+	check("namespace: [\n", "line 1: did not find expected node content")
+
+	check("containers:\n  - image: nginx\n", "line 2: container has no id")
+	check("containers:\n  - id: web\n", `line 2: container "web" has no image`)
+	check("containers:\n  - {id: web, image: nginx}\n"+
+		"  - {id: web, image: nginx}\n",
+		`line 3: container id "web" is already used`)
+	check("containers:\n  - {id: web, image: nginx, count: 2, hostname: web}\n",
+		`line 2: replicated container "web" can't have a hostname`)
+	check("containers:\n  - {id: web, image: nginx}\n"+
+		"labels:\n  - name: web\n    containers: [web]\n"+
+		"  - name: db\n    containers: [db]\n",
+		`line 6: label "db" refers to undefined container "db"`)
+	check("connections:\n  - {from: a, to: b, port: 80, minPort: 80}\n",
+		"line 2: connection has both a port and a port range")
+	check("invariants:\n  - form: nearby\n",
+		`line 2: unknown invariant form "nearby"`)
+
+	check("containers:\n  - {id: web, image: nginx}\n"+
+		"labels:\n  - {name: web, containers: [web]}\n"+
+		"invariants:\n  - {form: reach, nodes: [public, web]}\n",
+		`invariant failed: reach true "public" "web"`)
+}
+
+func TestFromJSONFile(t *testing.T) {
+	_, err := fromJSONFile(
+		"{\n\"Namespace\": \"foo\",\n\"MaxPrice\": \"cheap\"\n}")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3: json: cannot unmarshal string")
+
+	_, err = fromJSONFile("{\n\"Namespace\": \"foo\"\n\"MaxPrice\": 1\n}")
+	assert.EqualError(t, err, "line 3: invalid character '\"' after "+
+		"object key:value pair")
+}
+
+func TestFromFileFormats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stitch")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+		return path
+	}
+
+	stc, err := FromFile(write("blueprint.yml", "namespace: yml\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "yml", stc.Namespace)
+
+	stc, err = FromFile(write("blueprint.YAML", "namespace: yaml\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "yaml", stc.Namespace)
+
+	stc, err = FromFile(write("blueprint.json", `{"Namespace": "json"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "json", stc.Namespace)
+
+	path := write("bad.yaml", "namespace: yaml\nmaxPrice: cheap\n")
+	_, err = FromFile(path)
+	assert.EqualError(t, err, path+
+		": line 2: cannot unmarshal !!str `cheap` into float64")
+
+	_, err = FromFile(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
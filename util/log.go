@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/quilt/quilt/metrics"
+
 	log "github.com/Sirupsen/logrus"
 )
 
@@ -29,7 +31,8 @@ func (f Formatter) Format(entry *log.Entry) ([]byte, error) {
 }
 
 // EventTimer is a utility struct that allows us to time how long loops take, as
-// well as how often they are triggered.
+// well as how often they are triggered.  Loop durations are also exported as
+// metrics.
 type EventTimer struct {
 	eventName string
 	lastStart time.Time
@@ -55,6 +58,8 @@ func (ltl *EventTimer) LogStart() {
 // LogEnd logs the end of a loop and how long it took to run.
 func (ltl *EventTimer) LogEnd() {
 	ltl.lastEnd = time.Now()
-	log.Debugf("%s event ended. It took %v", ltl.eventName,
-		ltl.lastEnd.Sub(ltl.lastStart))
+	duration := ltl.lastEnd.Sub(ltl.lastStart)
+	log.Debugf("%s event ended. It took %v", ltl.eventName, duration)
+	metrics.LoopDuration.WithLabelValues(ltl.eventName).Observe(
+		duration.Seconds())
 }